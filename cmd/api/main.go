@@ -2,13 +2,23 @@ package main
 
 import (
 	"log"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/datmedevil17/gopher-uptime/internal/config"
 	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/events"
+	"github.com/datmedevil17/gopher-uptime/internal/grpcapi"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/admin"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/notification"
 	"github.com/datmedevil17/gopher-uptime/internal/handlers/user"
 	"github.com/datmedevil17/gopher-uptime/internal/handlers/website"
 	"github.com/datmedevil17/gopher-uptime/internal/middleware"
+	"github.com/datmedevil17/gopher-uptime/internal/openapi"
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
 	"github.com/datmedevil17/gopher-uptime/internal/services"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/streadway/amqp"
 )
@@ -20,16 +30,24 @@ func main() {
 	cfg := config.Load()
 
 	// Connect to database with GORM
-	db, err := database.Connect(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.DatabaseURL, database.LogOptions{
+		Level:         cfg.DBLogLevel,
+		SlowThreshold: cfg.DBSlowThreshold,
+	})
 	if err != nil {
 		log.Fatal("❌ Database connection failed:", err)
 	}
 	log.Println("✅ Database connected")
 
-	// Run auto-migration
-	if err := database.AutoMigrate(db); err != nil {
+	// Run migrations
+	if err := database.Migrate(db); err != nil {
 		log.Fatal("❌ Migration failed:", err)
 	}
+	if cfg.DBAutoMigrate {
+		if err := database.AutoMigrate(db); err != nil {
+			log.Fatal("❌ Auto-migration failed:", err)
+		}
+	}
 
 	// Connect to RabbitMQ
 	conn, err := amqp.Dial(cfg.RabbitMQURL)
@@ -45,9 +63,33 @@ func main() {
 	}
 	defer ch.Close()
 
+	// Connect to the event bus shared with the hub
+	bus, err := events.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Event bus initialization failed:", err)
+	}
+	defer bus.Close()
+
+	// Validator presence shared with the hubs (Redis when REDIS_URL is set)
+	store, err := presence.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Presence store initialization failed:", err)
+	}
+	defer store.Close()
+
+	if cfg.MinCheckInterval <= 0 || cfg.MaxCheckInterval < cfg.MinCheckInterval {
+		log.Printf("⚠️  MIN_CHECK_INTERVAL must be positive and at most MAX_CHECK_INTERVAL, using 30s-24h")
+		cfg.MinCheckInterval, cfg.MaxCheckInterval = 30*time.Second, 24*time.Hour
+	}
+
+	if cfg.PayoutTokenDecimals < 0 || cfg.PayoutTokenDecimals > 18 {
+		log.Printf("⚠️  PAYOUT_TOKEN_DECIMALS must be between 0 and 18, using 9")
+		cfg.PayoutTokenDecimals = 9
+	}
+
 	// Initialize payout worker
-	if cfg.PlatformPrivateKey != "" {
-		worker, err := services.NewPayoutWorker(db, ch, cfg.PlatformPrivateKey)
+	if cfg.PayoutsEnabled() {
+		worker, err := services.NewPayoutWorker(db, ch, cfg)
 		if err != nil {
 			log.Fatal("❌ Failed to initialize payout worker:", err)
 		}
@@ -59,37 +101,113 @@ func main() {
 			}
 		}()
 	} else {
-		log.Println("⚠️  No PLATFORM_PRIVATE_KEY provided, payout worker disabled")
+		log.Println("⚠️  No PLATFORM_PRIVATE_KEY provided, payout worker disabled and payout requests refused")
+	}
+
+	// JWT signing keys
+	jwtCfg, err := utils.LoadJWTConfig(utils.JWTOptions{
+		Algorithm:      cfg.JWTAlgorithm,
+		Secret:         cfg.JWTSecret,
+		PrivateKeyPath: cfg.JWTPrivateKeyPath,
+		PublicKeyPath:  cfg.JWTPublicKeyPath,
+		Issuer:         cfg.JWTIssuer,
+		Audience:       cfg.JWTAudience,
+	})
+	if err != nil {
+		log.Fatal("❌ JWT configuration invalid:", err)
 	}
 
 	// Initialize Gin router
 	r := gin.Default()
 
 	// CORS middleware
-	// CORS middleware
-	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.CORSMiddleware(cfg))
+
+	// Request body limits
+	r.Use(middleware.BodyLimitMiddleware(cfg.MaxRequestBodyBytes, cfg.MaxJSONDepth, cfg.MaxJSONArrayLength))
+
+	// OpenAPI description, filled in once every route is registered
+	spec := openapi.New("Uptime Monitor API", "1.0.0")
+	if cfg.OpenAPIValidate {
+		r.Use(middleware.SchemaValidationMiddleware(spec))
+	}
+
+	// Maintenance mode: writes are refused, except logging in and switching it off
+	readOnly := middleware.NewReadOnlyMode(cfg.ReadOnly)
+	if cfg.ReadOnly {
+		log.Println("⚠️  Starting in read-only mode")
+	}
+	r.Use(middleware.ReadOnlyMiddleware(readOnly, "/api/v1/auth/login", "/api/v1/read-only"))
 
 	// Initialize handlers
-	websiteHandler := website.NewHandler(db)
-	userHandler := user.NewHandler(db, ch, cfg)
+	websiteHandler := website.NewHandler(db, cfg, bus)
+	userHandler := user.NewHandler(db, ch, cfg, jwtCfg)
+	adminHandler := admin.NewHandler(db, cfg, store, readOnly)
+	notificationHandler := notification.NewHandler(db, cfg)
 
 	// API routes
 	api := r.Group("/api/v1")
 	{
 		// Protected routes (require JWT authentication)
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+		protected.Use(middleware.AuthMiddleware(jwtCfg), middleware.ActiveUserMiddleware(db, cfg.DBQueryTimeout))
 		{
 			// Website management
 			protected.POST("/website", websiteHandler.CreateWebsite)
 			protected.GET("/websites", websiteHandler.GetWebsites)
+			protected.GET("/websites/status", websiteHandler.GetWebsitesStatus)
+			protected.GET("/websites/export", websiteHandler.ExportWebsites)
+			protected.POST("/websites/import", websiteHandler.ImportWebsites)
+			protected.POST("/websites/bulk-delete", websiteHandler.BulkDeleteWebsites)
 			protected.GET("/website/status", websiteHandler.GetWebsiteStatus)
+			protected.GET("/website/:id", websiteHandler.GetWebsite)
+			protected.GET("/website/:id/summary", websiteHandler.GetWebsiteSummary)
+			protected.GET("/website/:id/sla", websiteHandler.GetWebsiteSLA)
+			protected.GET("/website/:id/ticks", websiteHandler.ListTicks)
+			protected.POST("/website/:id/check-now", websiteHandler.CheckNow)
+			protected.POST("/website/:id/webhook-secret", websiteHandler.RotateWebhookSecret)
 			protected.DELETE("/website", websiteHandler.DeleteWebsite)
+
+			// Incidents across all of the user's websites
+			protected.GET("/incidents", websiteHandler.ListIncidents)
+
+			// The user's public status page
+			protected.GET("/status-page", websiteHandler.GetStatusPage)
+			protected.PUT("/status-page", websiteHandler.PutStatusPage)
+			protected.DELETE("/status-page", websiteHandler.DeleteStatusPage)
+
+			// Where incident notifications are sent
+			protected.POST("/notification-channels", notificationHandler.CreateChannel)
+			protected.GET("/notification-channels", notificationHandler.ListChannels)
+			protected.DELETE("/notification-channels/:id", notificationHandler.DeleteChannel)
+			protected.GET("/notification-channels/:id/deliveries", notificationHandler.ListDeliveries)
+
+			// Account
+			protected.DELETE("/me", userHandler.DeleteAccount)
+		}
+
+		// Admin routes (require X-Admin-Token)
+		adminRoutes := api.Group("")
+		adminRoutes.Use(middleware.AdminMiddleware(cfg.AdminToken))
+		{
+			adminRoutes.GET("/payouts", adminHandler.ListPayouts)
+			adminRoutes.GET("/validators", adminHandler.ListValidators)
+			adminRoutes.GET("/validators/online", adminHandler.ListOnlineValidators)
+			adminRoutes.GET("/validators/leaderboard", adminHandler.GetValidatorLeaderboard)
+			adminRoutes.GET("/validators/coverage", adminHandler.GetValidatorCoverage)
+			adminRoutes.GET("/validators/:validatorId", adminHandler.GetValidator)
+			adminRoutes.PUT("/validators/:validatorId/ban", adminHandler.PutValidatorBan)
+			adminRoutes.GET("/read-only", adminHandler.GetReadOnly)
+			adminRoutes.PUT("/read-only", adminHandler.PutReadOnly)
+			adminRoutes.POST("/invites", adminHandler.CreateInvite)
+			adminRoutes.GET("/invites", adminHandler.ListInvites)
 		}
 
 		// Public routes (or validator-only)
 		api.POST("/payout/:validatorId", userHandler.RequestPayout)
+		api.POST("/validator/register", userHandler.RegisterValidator)
 		api.GET("/validator/:validatorId/balance", userHandler.GetValidatorBalance)
+		api.GET("/validator/:validatorId/earnings", userHandler.GetValidatorEarnings)
 
 		// Auth routes
 		auth := api.Group("/auth")
@@ -99,6 +217,9 @@ func main() {
 		}
 	}
 
+	// Public status pages
+	r.GET("/status/:slug", websiteHandler.GetPublicStatusPage)
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -107,6 +228,26 @@ func main() {
 		})
 	})
 
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+	spec.AddRoutes(r.Routes(), apiRoutes)
+
+	// gRPC API alongside REST, backed by the same handlers
+	if cfg.GRPCPort != "" {
+		listener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Fatal("❌ gRPC listener failed:", err)
+		}
+		grpcServer := grpcapi.New(websiteHandler, userHandler, jwtCfg, db, cfg.DBQueryTimeout)
+		go func() {
+			log.Printf("🚀 gRPC server running on port %s", cfg.GRPCPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal("❌ gRPC server error:", err)
+			}
+		}()
+	}
+
 	// Start server
 	log.Printf("🚀 API Server running on port %s", cfg.Port)
 	if err := r.Run(":" + cfg.Port); err != nil {
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/admin"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/notification"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/user"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/website"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/openapi"
+)
+
+// pageQuery are the pagination parameters of list endpoints (utils.ParsePagination)
+var pageQuery = []string{"page", "page_size"}
+
+// apiRoutes describes the routes registered in main for /openapi.json, keyed by
+// method and path. Request types are the ones the handlers bind, so the spec and
+// the optional schema validation follow their binding rules.
+var apiRoutes = map[string]openapi.Route{
+	// Websites
+	"POST /api/v1/website":                             {Summary: "Create a website", Auth: openapi.BearerAuth, Request: website.CreateWebsiteRequest{}, Status: http.StatusCreated},
+	"GET /api/v1/websites":                             {Summary: "List websites with their latest ticks", Auth: openapi.BearerAuth, Query: []string{"ticks"}},
+	"GET /api/v1/websites/status":                      {Summary: "Current status of every website", Auth: openapi.BearerAuth},
+	"GET /api/v1/websites/export":                      {Summary: "Export websites", Auth: openapi.BearerAuth, Response: website.WebsiteExport{}},
+	"POST /api/v1/websites/import":                     {Summary: "Import exported websites", Auth: openapi.BearerAuth, Request: website.ImportWebsitesRequest{}},
+	"POST /api/v1/websites/bulk-delete":                {Summary: "Delete several websites", Auth: openapi.BearerAuth, Request: website.BulkDeleteRequest{}},
+	"GET /api/v1/website/status":                       {Summary: "Website with its recent ticks", Auth: openapi.BearerAuth, Query: []string{"websiteId"}, Response: models.Website{}},
+	"GET /api/v1/website/:id":                          {Summary: "Website configuration", Auth: openapi.BearerAuth, Response: models.Website{}},
+	"GET /api/v1/website/:id/summary":                  {Summary: "Uptime and latency summary", Auth: openapi.BearerAuth, Query: []string{"window"}},
+	"GET /api/v1/website/:id/sla":                      {Summary: "Uptime against the SLA target", Auth: openapi.BearerAuth, Query: []string{"period"}},
+	"GET /api/v1/website/:id/ticks":                    {Summary: "Page through check results", Auth: openapi.BearerAuth, Query: append([]string{"status", "validator_id", "from", "to"}, pageQuery...)},
+	"POST /api/v1/website/:id/check-now":               {Summary: "Check a website immediately", Auth: openapi.BearerAuth},
+	"POST /api/v1/website/:id/webhook-secret":          {Summary: "Rotate the webhook signing secret", Auth: openapi.BearerAuth},
+	"DELETE /api/v1/website":                           {Summary: "Delete a website", Auth: openapi.BearerAuth, Request: website.DeleteWebsiteRequest{}},
+	"GET /api/v1/incidents":                            {Summary: "Incidents across all websites", Auth: openapi.BearerAuth, Query: append([]string{"status"}, pageQuery...)},
+	"GET /api/v1/status-page":                          {Summary: "Status page settings", Auth: openapi.BearerAuth, Response: website.StatusPageResponse{}},
+	"PUT /api/v1/status-page":                          {Summary: "Create or update the status page", Auth: openapi.BearerAuth, Request: website.StatusPageRequest{}, Response: website.StatusPageResponse{}},
+	"DELETE /api/v1/status-page":                       {Summary: "Delete the status page", Auth: openapi.BearerAuth},
+	"GET /status/:slug":                                {Summary: "Public status page", Response: website.PublicStatusPage{}},
+	"POST /api/v1/notification-channels":               {Summary: "Add a notification channel", Auth: openapi.BearerAuth, Request: notification.CreateChannelRequest{}, Response: notification.ChannelResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/notification-channels":                {Summary: "List notification channels", Auth: openapi.BearerAuth},
+	"DELETE /api/v1/notification-channels/:id":         {Summary: "Delete a notification channel", Auth: openapi.BearerAuth},
+	"GET /api/v1/notification-channels/:id/deliveries": {Summary: "Delivery attempts of a channel", Auth: openapi.BearerAuth, Query: append([]string{"status"}, pageQuery...)},
+	"DELETE /api/v1/me":                                {Summary: "Delete the caller's account", Auth: openapi.BearerAuth, Request: user.DeleteAccountRequest{}},
+
+	// Admin
+	"GET /api/v1/payouts":                         {Summary: "List payouts", Auth: openapi.AdminAuth, Query: append([]string{"status", "validator_id", "from", "to", "min_amount", "max_amount"}, pageQuery...)},
+	"GET /api/v1/validators":                      {Summary: "List validators", Auth: openapi.AdminAuth, Query: append([]string{"status", "location"}, pageQuery...)},
+	"GET /api/v1/validators/online":               {Summary: "Validators connected to a hub", Auth: openapi.AdminAuth},
+	"GET /api/v1/validators/leaderboard":          {Summary: "Validators ranked by latency", Auth: openapi.AdminAuth, Query: []string{"window"}},
+	"GET /api/v1/validators/coverage":             {Summary: "Validators per region", Auth: openapi.AdminAuth},
+	"GET /api/v1/validators/:validatorId":         {Summary: "Validator details", Auth: openapi.AdminAuth, Response: models.Validator{}},
+	"PUT /api/v1/validators/:validatorId/ban":     {Summary: "Ban or unban a validator", Auth: openapi.AdminAuth, Request: admin.BanRequest{}},
+	"GET /api/v1/read-only":                       {Summary: "Read-only mode state", Auth: openapi.AdminAuth},
+	"PUT /api/v1/read-only":                       {Summary: "Switch read-only mode", Auth: openapi.AdminAuth, Request: admin.ReadOnlyRequest{}},
+	"POST /api/v1/invites":                        {Summary: "Create a signup invite", Auth: openapi.AdminAuth, Request: admin.CreateInviteRequest{}, Response: models.Invite{}, Status: http.StatusCreated},
+	"GET /api/v1/invites":                         {Summary: "List invites", Auth: openapi.AdminAuth, Query: append([]string{"status"}, pageQuery...)},
+	"POST /api/v1/payout/:validatorId":            {Summary: "Pay out a validator's balance"},
+	"POST /api/v1/validator/register":             {Summary: "Register a validator", Request: user.RegisterValidatorRequest{}, Status: http.StatusCreated},
+	"GET /api/v1/validator/:validatorId/balance":  {Summary: "Validator balance", Response: user.ValidatorBalance{}},
+	"GET /api/v1/validator/:validatorId/earnings": {Summary: "Validator earnings"},
+
+	// Auth
+	"POST /api/v1/auth/signup": {Summary: "Create an account", Request: user.SignupRequest{}, Status: http.StatusCreated},
+	"POST /api/v1/auth/login":  {Summary: "Log in for a JWT", Request: user.LoginRequest{}},
+
+	"GET /openapi.json": {Summary: "This document", Raw: true},
+	"GET /health":       {Summary: "Health check", Raw: true},
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// servedSpec registers every described route on a router, serves the document
+// built from it like main does, and decodes GET /openapi.json
+func servedSpec(t *testing.T) (*openapi.Document, map[string]map[string]json.RawMessage) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	spec := openapi.New("Uptime Monitor API", "1.0.0")
+	for route := range apiRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		if path == "/openapi.json" {
+			continue
+		}
+		r.Handle(method, path, func(c *gin.Context) {})
+	}
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+	spec.AddRoutes(r.Routes(), apiRoutes)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d", w.Code)
+	}
+	var served struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	return spec, served.Paths
+}
+
+func TestServedSpecDocumentsEndpoints(t *testing.T) {
+	_, paths := servedSpec(t)
+
+	for _, endpoint := range []string{
+		"post /api/v1/website",
+		"get /api/v1/website/{id}/ticks",
+		"put /api/v1/status-page",
+		"get /status/{slug}",
+		"put /api/v1/validators/{validatorId}/ban",
+		"post /api/v1/validator/register",
+	} {
+		method, path, _ := strings.Cut(endpoint, " ")
+		if _, ok := paths[path][method]; !ok {
+			t.Errorf("%s is not in the served spec", endpoint)
+		}
+	}
+	if _, ok := paths["/openapi.json"]["get"]; !ok {
+		t.Error("the spec doesn't describe itself")
+	}
+}
+
+func TestSpecRejectsBadCreateWebsite(t *testing.T) {
+	spec, _ := servedSpec(t)
+
+	if violations := spec.ValidateBody(http.MethodPost, "/api/v1/website", []byte(`{"url": "https://example.com", "timeout_ms": 5000}`)); len(violations) != 0 {
+		t.Errorf("valid website rejected: %v", violations)
+	}
+
+	violations := spec.ValidateBody(http.MethodPost, "/api/v1/website", []byte(`{"address_family": "ipx", "timeout_ms": 10}`))
+	rules := map[string]string{}
+	for _, v := range violations {
+		rules[v.Field] = v.Rule
+	}
+	want := map[string]string{"url": "required", "address_family": "enum", "timeout_ms": "minimum"}
+	for field, rule := range want {
+		if rules[field] != rule {
+			t.Errorf("%s violates %q, want %q (all: %v)", field, rules[field], rule, violations)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+)
+
+// LiveValidator is one validator connected to this hub
+type LiveValidator struct {
+	ValidatorID  string    `json:"validator_id"`
+	PublicKey    string    `json:"public_key"`
+	RemoteAddr   string    `json:"remote_addr"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	LastActivity time.Time `json:"last_activity"`
+	Assignments  int64     `json:"assignments"` // tasks this hub has sent it since it connected
+}
+
+// writeJSON writes v in the API's response envelope
+func writeJSON(w http.ResponseWriter, status int, v utils.Response) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("❌ Failed to write response: %v", err)
+	}
+}
+
+// requireAdmin guards hub admin endpoints with ADMIN_TOKEN in the X-Admin-Token
+// header, like the API's admin routes. They're disabled when no token is set.
+func (h *Hub) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.AdminToken == "" {
+			writeJSON(w, http.StatusForbidden, utils.Response{Error: "Admin API disabled", Code: utils.CodeForbidden})
+			return
+		}
+		provided := r.Header.Get("X-Admin-Token")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(h.cfg.AdminToken)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, utils.Response{Error: "Invalid admin token", Code: utils.CodeUnauthorized})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleListValidators - GET /admin/validators
+// Validators connected to this hub process, from memory, oldest connection first.
+func (h *Hub) handleListValidators(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, utils.Response{Error: "Method not allowed", Code: utils.CodeInvalidRequest})
+		return
+	}
+
+	h.mu.RLock()
+	validators := make([]LiveValidator, 0, len(h.validators))
+	for _, validator := range h.validators {
+		validators = append(validators, LiveValidator{
+			ValidatorID:  validator.ValidatorID,
+			PublicKey:    validator.PublicKey,
+			RemoteAddr:   validator.Conn.RemoteAddr().String(),
+			ConnectedAt:  validator.ConnectedAt,
+			LastActivity: validator.LastActivity(),
+			Assignments:  h.assignments.get(validator.ValidatorID),
+		})
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i].ConnectedAt.Before(validators[j].ConnectedAt)
+	})
+
+	writeJSON(w, http.StatusOK, utils.Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"hub_id":     h.cfg.HubID,
+			"validators": validators,
+			"count":      len(validators),
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+)
+
+const testAdminToken = "admin-secret"
+
+// adminResponse is utils.Response with the payload left undecoded
+type adminResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Code    string          `json:"code"`
+}
+
+// callAdmin sends a request to handler behind requireAdmin, presenting token
+func callAdmin(t *testing.T, h *Hub, handler http.HandlerFunc, method, token string) (int, adminResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/admin", nil)
+	if token != "" {
+		req.Header.Set("X-Admin-Token", token)
+	}
+	w := httptest.NewRecorder()
+	h.requireAdmin(handler)(w, req)
+
+	var resp adminResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// liveValidators lists the validators connected to h through the admin endpoint
+func liveValidators(t *testing.T, h *Hub) []LiveValidator {
+	t.Helper()
+
+	status, resp := callAdmin(t, h, h.handleListValidators, http.MethodGet, testAdminToken)
+	if status != http.StatusOK {
+		t.Fatalf("listing validators: status %d (%s)", status, resp.Code)
+	}
+	var data struct {
+		HubID      string          `json:"hub_id"`
+		Validators []LiveValidator `json:"validators"`
+		Count      int             `json:"count"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.HubID != h.cfg.HubID || data.Count != len(data.Validators) {
+		t.Fatalf("listed %d validators with count %d on hub %q", len(data.Validators), data.Count, data.HubID)
+	}
+	return data.Validators
+}
+
+func TestListValidatorsReflectsConnections(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.AdminToken = testAdminToken })
+	url := serveHub(t, h)
+
+	if live := liveValidators(t, h); len(live) != 0 {
+		t.Fatalf("%d validators listed before any connected", len(live))
+	}
+
+	first, second := newTestValidator(t, h.db), newTestValidator(t, h.db)
+	firstClient := dial(t, url, nil)
+	firstClient.signUp(first)
+	dial(t, url, nil).signUp(second)
+
+	live := liveValidators(t, h)
+	if len(live) != 2 || live[0].ValidatorID != first.model.ID || live[1].ValidatorID != second.model.ID {
+		t.Fatalf("listed %+v, want %s then %s, oldest connection first", live, first.model.ID, second.model.ID)
+	}
+	for _, v := range live {
+		if v.PublicKey == "" || v.RemoteAddr == "" || v.ConnectedAt.IsZero() {
+			t.Errorf("validator %s listed without its public key, address or connection time: %+v", v.ValidatorID, v)
+		}
+	}
+	if live[0].PublicKey != first.model.PublicKey {
+		t.Errorf("public key = %s, want %s", live[0].PublicKey, first.model.PublicKey)
+	}
+
+	firstClient.conn.Close()
+	waitFor(t, "the disconnected validator to drop out", func() bool {
+		live := liveValidators(t, h)
+		return len(live) == 1 && live[0].ValidatorID == second.model.ID
+	})
+}
+
+func TestAdminEndpointAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		token      string
+		method     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"disabled without a token", "", testAdminToken, http.MethodGet, http.StatusForbidden, utils.CodeForbidden},
+		{"missing token", testAdminToken, "", http.MethodGet, http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"wrong token", testAdminToken, "guess", http.MethodGet, http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"wrong method", testAdminToken, testAdminToken, http.MethodPost, http.StatusMethodNotAllowed, utils.CodeInvalidRequest},
+		{"authorized", testAdminToken, testAdminToken, http.MethodGet, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub(t, func(cfg *config.Config) { cfg.AdminToken = tt.configured })
+
+			status, resp := callAdmin(t, h, h.handleListValidators, tt.method, tt.token)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("status = %d, code = %q, want %d %q", status, resp.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/middleware"
+)
+
+// handshakeToken returns the token a websocket handshake offers: a bearer
+// Authorization header, or the token query parameter for browsers and runtimes
+// that can't set headers on the handshake. A malformed header offers nothing,
+// even when the query carries a token.
+func handshakeToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, _ := middleware.BearerToken(header)
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authorizeHandshake reports whether r carries one of the configured hub tokens.
+// With none configured every handshake is allowed and validators are trusted on
+// their signed signup alone.
+func (h *Hub) authorizeHandshake(r *http.Request) bool {
+	if len(h.cfg.HubAuthTokens) == 0 {
+		return true
+	}
+	token := handshakeToken(r)
+	if token == "" {
+		return false
+	}
+	for _, allowed := range h.cfg.HubAuthTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+	return false
+}
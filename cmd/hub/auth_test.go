@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+func TestHandshakeToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   string
+	}{
+		{"nothing offered", "", "", ""},
+		{"bearer header", "Bearer secret", "", "secret"},
+		{"scheme is case-insensitive", "bearer secret", "", "secret"},
+		{"query parameter", "", "secret", "secret"},
+		{"header wins over query", "Bearer from-header", "from-query", "from-header"},
+		{"other scheme", "Basic c2VjcmV0", "", ""},
+		{"malformed header ignores query", "Bearer", "secret", ""},
+		{"empty bearer token", "Bearer ", "", ""},
+		{"token with whitespace", "Bearer sec ret", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/ws"
+			if tt.query != "" {
+				target += "?token=" + url.QueryEscape(tt.query)
+			}
+			r := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if got := handshakeToken(r); got != tt.want {
+				t.Errorf("handshakeToken = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandshakeAuthorization(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.HubAuthTokens = []string{"old-token", "new-token"} })
+	hubURL := serveHub(t, h)
+
+	tests := []struct {
+		name   string
+		query  string
+		header string
+		want   int
+	}{
+		{"token in query", "?token=new-token", "", http.StatusSwitchingProtocols},
+		{"either configured token", "?token=old-token", "", http.StatusSwitchingProtocols},
+		{"token in header", "", "Bearer new-token", http.StatusSwitchingProtocols},
+		{"wrong token in query", "?token=guess", "", http.StatusUnauthorized},
+		{"empty token in query", "?token=", "", http.StatusUnauthorized},
+		{"no token", "", "", http.StatusUnauthorized},
+		{"wrong header beats valid query", "?token=new-token", "Bearer guess", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("Authorization", tt.header)
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial(hubURL+tt.query, header)
+			if conn != nil {
+				conn.Close()
+			}
+			if resp == nil {
+				t.Fatalf("no handshake response: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("handshake status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandshakeWithoutConfiguredTokens(t *testing.T) {
+	h := newTestHub(t)
+	// Validators are trusted on their signed signup alone, whatever token they offer
+	dial(t, serveHub(t, h)+"?token=anything", nil)
+	dial(t, serveHub(t, h), nil)
+}
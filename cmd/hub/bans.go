@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/gorilla/websocket"
+)
+
+// dropBanned disconnects the validators an admin banned after they signed up and
+// returns the others. Bans are made through the API, so they're read from the
+// database before each dispatch.
+func (h *Hub) dropBanned(validators []*ValidatorConnection) []*ValidatorConnection {
+	if len(validators) == 0 {
+		return validators
+	}
+
+	ids := make([]string, len(validators))
+	for i, v := range validators {
+		ids[i] = v.ValidatorID
+	}
+
+	db, cancel := h.query()
+	defer cancel()
+
+	var banned []string
+	if err := db.Model(&models.Validator{}).
+		Where("id IN ? AND banned = ?", ids, true).
+		Pluck("id", &banned).Error; err != nil {
+		log.Printf("❌ Failed to check validator bans: %v", err)
+		return validators
+	}
+	if len(banned) == 0 {
+		return validators
+	}
+
+	isBanned := make(map[string]bool, len(banned))
+	for _, id := range banned {
+		isBanned[id] = true
+	}
+
+	allowed := validators[:0:0]
+	for _, v := range validators {
+		if !isBanned[v.ValidatorID] {
+			allowed = append(allowed, v)
+			continue
+		}
+		log.Printf("🚨 Disconnecting banned validator %s", v.ValidatorID)
+		closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "validator is banned")
+		v.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+		h.removeValidator(v.Conn)
+	}
+	return allowed
+}
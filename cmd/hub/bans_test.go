@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+// ban marks v banned the way the admin API does
+func ban(t *testing.T, db *gorm.DB, v testValidator) {
+	t.Helper()
+
+	if err := db.Model(&models.Validator{}).Where("id = ?", v.model.ID).
+		Updates(map[string]interface{}{"banned": true, "banned_at": time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBannedValidatorSignupRejected(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	ban(t, h.db, v)
+
+	client := dial(t, serveHub(t, h), nil)
+	client.send(v.signup(t, client.challenge, protocol.Version))
+
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Text != "validator is banned" {
+		t.Fatalf("connection ended with %v, want the banned validator turned away", err)
+	}
+	if n := len(h.connectedValidators()); n != 0 {
+		t.Errorf("%d validators connected, want none", n)
+	}
+}
+
+func TestBannedValidatorReceivesNoTasks(t *testing.T) {
+	h := newTestHub(t)
+	url := serveHub(t, h)
+	good, banned := newTestValidator(t, h.db), newTestValidator(t, h.db)
+	goodClient, bannedClient := dial(t, url, nil), dial(t, url, nil)
+	goodClient.signUp(good)
+	bannedClient.signUp(banned)
+
+	// Banned while connected: the next dispatch disconnects it instead
+	ban(t, h.db, banned)
+	website := createWebsite(t, h.db, models.Website{})
+	trigger := models.CheckTrigger{ID: uuid.New().String(), WebsiteID: website.ID, RequestedAt: time.Now()}
+	if err := h.db.Create(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	h.handleCheckTrigger(trigger)
+
+	if sent := goodClient.nextTask(); sent.WebsiteID != website.ID {
+		t.Errorf("task for %s, want %s", sent.WebsiteID, website.ID)
+	}
+	var closeErr *websocket.CloseError
+	if err := bannedClient.closed(); !errors.As(err, &closeErr) || closeErr.Code != websocket.ClosePolicyViolation {
+		t.Errorf("banned connection ended with %v, want close code %d before any task", err, websocket.ClosePolicyViolation)
+	}
+
+	if err := h.db.Where("id = ?", trigger.ID).First(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	if trigger.DispatchedCount != 1 {
+		t.Errorf("dispatched to %d validators, want only the unbanned one", trigger.DispatchedCount)
+	}
+	waitFor(t, "the banned validator to be removed", func() bool {
+		connected := h.connectedValidators()
+		return len(connected) == 1 && connected[0].ValidatorID == good.model.ID
+	})
+}
+
+func TestBannedValidatorResultDropped(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{})
+	v := newTestValidator(t, h.db)
+
+	// Banned between being sent a task and reporting on it
+	ban(t, h.db, v)
+	if _, ok := recordResult(t, h, website, v, models.StatusGood, 100); ok {
+		t.Error("recorded a result from a banned validator")
+	}
+
+	var stored models.Validator
+	if err := h.db.Where("id = ?", v.model.ID).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.PendingPayouts != v.model.PendingPayouts {
+		t.Errorf("pending payouts = %v, want %v: banned validators earn nothing", stored.PendingPayouts, v.model.PendingPayouts)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+// checkKey identifies a kind of check: a check over one address family says
+// nothing about another
+type checkKey struct {
+	websiteID string
+	method    string
+}
+
+func checkKeyOf(website models.Website) checkKey {
+	return checkKey{websiteID: website.ID, method: website.AddressFamily}
+}
+
+// dispatchedCheck is a round of checks sent for a website
+type dispatchedCheck struct {
+	at   time.Time
+	sent int // validators the checks went to
+}
+
+// checkCache remembers the checks this hub dispatched recently, so a website
+// asked to be checked again within CHECK_REUSE_WINDOW reuses those results
+// instead of being re-dispatched. Entries expire lazily; there is at most one
+// per website and address family.
+type checkCache struct {
+	mu      sync.Mutex
+	window  time.Duration // 0 disables the cache
+	entries map[checkKey]dispatchedCheck
+}
+
+func newCheckCache(window time.Duration) *checkCache {
+	return &checkCache{window: window, entries: make(map[checkKey]dispatchedCheck)}
+}
+
+// fresh returns the website's checks dispatched within the window, if any
+func (c *checkCache) fresh(website models.Website, now time.Time) (dispatchedCheck, bool) {
+	if c.window <= 0 {
+		return dispatchedCheck{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := checkKeyOf(website)
+	check, ok := c.entries[key]
+	if !ok {
+		return dispatchedCheck{}, false
+	}
+	if now.Sub(check.at) >= c.window {
+		delete(c.entries, key)
+		return dispatchedCheck{}, false
+	}
+	return check, true
+}
+
+// store records that checks for website were just sent to sent validators
+func (c *checkCache) store(website models.Website, check dispatchedCheck) {
+	if c.window <= 0 || check.sent == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[checkKeyOf(website)] = check
+	c.mu.Unlock()
+}
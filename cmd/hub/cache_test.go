@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestCheckCache(t *testing.T) {
+	website := models.Website{ID: uuid.New().String(), AddressFamily: "auto"}
+	ipv6 := models.Website{ID: website.ID, AddressFamily: "ipv6"}
+	start := time.Now()
+
+	cache := newCheckCache(10 * time.Second)
+	if _, ok := cache.fresh(website, start); ok {
+		t.Fatal("empty cache has a fresh check")
+	}
+	cache.store(website, dispatchedCheck{at: start, sent: 3})
+
+	tests := []struct {
+		name    string
+		website models.Website
+		at      time.Duration // after the check
+		want    bool
+	}{
+		{"right away", website, 0, true},
+		{"within the window", website, 9 * time.Second, true},
+		{"another address family", ipv6, time.Second, false},
+		{"at the end of the window", website, 10 * time.Second, false},
+		{"after expiring", website, time.Second, false},
+	}
+	for _, tt := range tests {
+		check, ok := cache.fresh(tt.website, start.Add(tt.at))
+		if ok != tt.want {
+			t.Errorf("%s: fresh = %v, want %v", tt.name, ok, tt.want)
+		}
+		if ok && (check.sent != 3 || !check.at.Equal(start)) {
+			t.Errorf("%s: reused %+v, want the stored check", tt.name, check)
+		}
+	}
+}
+
+func TestCheckCacheIgnoresEmptyRounds(t *testing.T) {
+	website := models.Website{ID: uuid.New().String()}
+
+	disabled := newCheckCache(0)
+	disabled.store(website, dispatchedCheck{at: time.Now(), sent: 1})
+	if _, ok := disabled.fresh(website, time.Now()); ok {
+		t.Error("disabled cache reused a check")
+	}
+
+	// Nothing was checked, so there is nothing to reuse
+	cache := newCheckCache(time.Minute)
+	cache.store(website, dispatchedCheck{at: time.Now(), sent: 0})
+	if _, ok := cache.fresh(website, time.Now()); ok {
+		t.Error("reused a round that reached no validator")
+	}
+}
+
+// trigger stores a check-now request for website and has h handle it, returning
+// the trigger as the hub left it
+func trigger(t *testing.T, h *Hub, website models.Website) models.CheckTrigger {
+	t.Helper()
+
+	trigger := models.CheckTrigger{ID: uuid.New().String(), WebsiteID: website.ID, RequestedAt: time.Now()}
+	if err := h.db.Create(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	h.handleCheckTrigger(trigger)
+	if err := h.db.Where("id = ?", trigger.ID).First(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	return trigger
+}
+
+func TestCheckTriggerReusesRecentChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    time.Duration
+		wantTasks int // sent for the second request
+	}{
+		{"within the window", time.Minute, 0},
+		{"reuse disabled", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub(t, func(cfg *config.Config) { cfg.CheckReuseWindow = tt.window })
+			connectQueued(t, h, 2)
+			website := createWebsite(t, h.db, models.Website{})
+
+			first := trigger(t, h, website)
+			if first.DispatchedCount != 2 || first.ReusedFrom != nil || len(queuedTasks(h)) != 2 {
+				t.Fatalf("first request dispatched to %d (reused %v), want 2 fresh checks", first.DispatchedCount, first.ReusedFrom)
+			}
+
+			second := trigger(t, h, website)
+			sent := 0
+			for _, tasks := range queuedTasks(h) {
+				sent += len(tasks)
+			}
+			if sent != tt.wantTasks {
+				t.Errorf("second request sent %d tasks, want %d", sent, tt.wantTasks)
+			}
+			if second.DispatchedCount != 2 || second.DispatchedAt == nil {
+				t.Errorf("second request dispatched at %v to %d, want it answered by 2 checks", second.DispatchedAt, second.DispatchedCount)
+			}
+			if reused := second.ReusedFrom != nil; reused != (tt.wantTasks == 0) {
+				t.Errorf("second request reused = %v, want %v", reused, tt.wantTasks == 0)
+			}
+			if second.ReusedFrom != nil && (second.ReusedFrom.After(second.RequestedAt) || second.ReusedFrom.Before(first.RequestedAt)) {
+				t.Errorf("reused checks from %v, want those sent for the first request at %v", second.ReusedFrom, first.RequestedAt)
+			}
+		})
+	}
+}
+
+func TestScheduledRoundSkipsFreshCheck(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.CheckReuseWindow = time.Minute })
+	connectQueued(t, h, 1)
+	checked, other := createWebsite(t, h.db, models.Website{}), createWebsite(t, h.db, models.Website{})
+
+	trigger(t, h, checked)
+	queuedTasks(h)
+
+	// The round right after a check-now only checks the other website
+	h.dispatchAll([]models.Website{checked, other}, h.connectedValidators(), []string{h.cfg.HubID})
+	queued := queuedTasks(h)
+	if len(queued) != 1 {
+		t.Fatalf("round sent tasks to %d validators, want 1", len(queued))
+	}
+	for _, tasks := range queued {
+		if len(tasks) != 1 || tasks[0]["websiteId"] != other.ID {
+			t.Errorf("round sent %v, want a single task for %s", tasks, other.ID)
+		}
+	}
+}
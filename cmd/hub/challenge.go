@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+)
+
+// signupChallenge is the nonce the hub sends on connect; the validator must sign
+// it in its signup so the signature is bound to a hub-controlled value
+type signupChallenge struct {
+	value    string
+	issuedAt time.Time
+	used     bool
+}
+
+func newSignupChallenge() *signupChallenge {
+	return &signupChallenge{
+		value:    protocol.NewNonce(),
+		issuedAt: time.Now(),
+	}
+}
+
+// redeem accepts nonce once, if it matches the challenge and hasn't gone stale
+func (c *signupChallenge) redeem(nonce string, maxAge time.Duration) error {
+	switch {
+	case c.used:
+		return fmt.Errorf("challenge already used")
+	case nonce != c.value:
+		return fmt.Errorf("challenge mismatch")
+	case time.Since(c.issuedAt) > maxAge:
+		return fmt.Errorf("challenge expired")
+	}
+	c.used = true
+	return nil
+}
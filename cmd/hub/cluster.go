@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/cluster"
+)
+
+// activeHubs lists hub instances that currently hold validator connections,
+// always including this one
+func (h *Hub) activeHubs() []string {
+	seen := map[string]bool{h.cfg.HubID: true}
+
+	entries, err := h.presence.List(context.Background())
+	if err != nil {
+		log.Printf("⚠️  Failed to list presence, assuming a single hub: %v", err)
+	}
+	for _, entry := range entries {
+		seen[entry.HubID] = true
+	}
+
+	hubs := make([]string, 0, len(seen))
+	for id := range seen {
+		hubs = append(hubs, id)
+	}
+	sort.Strings(hubs)
+	return hubs
+}
+
+// claimWebsite reports whether this hub should dispatch websiteID now, holding it
+// for ttl. Ownership is spread across hubs by rendezvous hashing; the claim guards
+// against two hubs dispatching while their views of the cluster disagree.
+func (h *Hub) claimWebsite(websiteID string, hubs []string, ttl time.Duration) bool {
+	if cluster.Owner(websiteID, hubs) != h.cfg.HubID {
+		return false
+	}
+
+	claimed, err := h.cluster.Claim(context.Background(), websiteID, ttl)
+	if err != nil {
+		log.Printf("⚠️  Failed to claim %s for dispatch: %v", websiteID, err)
+		return false
+	}
+	return claimed
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+// peerHub returns a second hub sharing h's database, event bus, presence and
+// cluster coordination, as another instance of the same deployment would
+func peerHub(h *Hub, hubID string) *Hub {
+	cfg := *h.cfg
+	cfg.HubID = hubID
+	return NewHub(h.db, &cfg, h.events, h.presence, h.cluster)
+}
+
+// connectValidator signs a new validator located in location up to h
+func connectValidator(t *testing.T, h *Hub, location string) {
+	t.Helper()
+
+	v := newTestValidator(t, h.db)
+	if err := h.db.Model(&v.model).Update("location", location).Error; err != nil {
+		t.Fatal(err)
+	}
+	dial(t, serveHub(t, h), nil).signUp(v)
+}
+
+// pending is how many tasks h has in flight for website
+func pending(h *Hub, websiteID string) int {
+	h.callbackMu.RLock()
+	defer h.callbackMu.RUnlock()
+	return h.inFlight[websiteID]
+}
+
+func TestTwoHubsDispatchEachWebsiteOnce(t *testing.T) {
+	a := newTestHub(t, func(cfg *config.Config) { cfg.DispatchWorkers = 4 })
+	b := peerHub(a, "hub-peer")
+	connectValidator(t, a, "unknown")
+	connectValidator(t, b, "unknown")
+
+	websites := make([]models.Website, 20)
+	for i := range websites {
+		websites[i] = createWebsite(t, a.db, models.Website{})
+	}
+	if hubs := a.activeHubs(); len(hubs) != 2 {
+		t.Fatalf("active hubs %v, want both", hubs)
+	}
+
+	// Two cycles each, concurrently; the second finds every website claimed
+	var wg sync.WaitGroup
+	for _, h := range []*Hub{a, b, a, b} {
+		wg.Add(1)
+		go func(h *Hub) {
+			defer wg.Done()
+			h.monitorCycle()
+		}(h)
+	}
+	wg.Wait()
+
+	owned := map[*Hub]int{}
+	for _, website := range websites {
+		fromA, fromB := pending(a, website.ID), pending(b, website.ID)
+		if fromA+fromB != 1 {
+			t.Errorf("website dispatched %d times by %s and %d by %s, want once in all", fromA, a.cfg.HubID, fromB, b.cfg.HubID)
+		}
+		if fromA > 0 {
+			owned[a]++
+		} else {
+			owned[b]++
+		}
+	}
+	if owned[a] == 0 || owned[b] == 0 {
+		t.Errorf("hubs dispatched %d and %d websites, want both to share the work", owned[a], owned[b])
+	}
+}
+
+func TestHubsDisagreeingOnMembershipDispatchOnce(t *testing.T) {
+	a := newTestHub(t)
+	b := peerHub(a, "hub-peer")
+	connectValidator(t, a, "unknown")
+	connectValidator(t, b, "unknown")
+	website := createWebsite(t, a.db, models.Website{})
+
+	// Each hub believes it is alone, so both own every website; the claim decides
+	dispatched := a.dispatchAll([]models.Website{website}, a.connectedValidators(), []string{a.cfg.HubID}) +
+		b.dispatchAll([]models.Website{website}, b.connectedValidators(), []string{b.cfg.HubID})
+	if dispatched != 1 {
+		t.Fatalf("dispatched %d times, want 1", dispatched)
+	}
+}
+
+func TestHubWithoutEligibleValidatorsLeavesClaim(t *testing.T) {
+	a := newTestHub(t)
+	b := peerHub(a, "hub-peer")
+	connectValidator(t, a, "eu-west")
+	connectValidator(t, b, "us-east")
+	website := createWebsite(t, a.db, models.Website{Regions: []string{"us-east"}})
+
+	// a can't check the website, so it mustn't claim it away from b
+	if n := a.dispatchAll([]models.Website{website}, a.connectedValidators(), []string{a.cfg.HubID}); n != 0 {
+		t.Fatalf("hub without eligible validators dispatched %d", n)
+	}
+	if n := b.dispatchAll([]models.Website{website}, b.connectedValidators(), []string{b.cfg.HubID}); n != 1 {
+		t.Fatalf("hub with an eligible validator dispatched %d, want 1", n)
+	}
+	if pending(b, website.ID) != 1 {
+		t.Errorf("%d tasks pending on the eligible hub, want 1", pending(b, website.ID))
+	}
+}
+
+func TestDispatchFollowsWebsiteInterval(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.CheckInterval = time.Minute
+		cfg.CheckReuseWindow = 0 // longer than this test's interval
+	})
+	connectQueued(t, h, 1)
+	frequent := createWebsite(t, h.db, models.Website{IntervalSeconds: 2})
+	regular := createWebsite(t, h.db, models.Website{})
+	websites := []models.Website{frequent, regular}
+	hubs := []string{h.cfg.HubID}
+
+	if n := h.dispatchAll(websites, h.connectedValidators(), hubs); n != 2 {
+		t.Fatalf("first cycle dispatched %d websites, want both", n)
+	}
+	if n := h.dispatchAll(websites, h.connectedValidators(), hubs); n != 0 {
+		t.Fatalf("dispatched %d websites again before either was due", n)
+	}
+
+	// Once its 2s interval (less the claim slack) has passed, only the frequent
+	// website is due again
+	time.Sleep(2*time.Second - claimSlack + 100*time.Millisecond)
+	queuedTasks(h)
+	if n := h.dispatchAll(websites, h.connectedValidators(), hubs); n != 1 {
+		t.Fatalf("dispatched %d websites after 2s, want the frequent one", n)
+	}
+	for _, tasks := range queuedTasks(h) {
+		if len(tasks) != 1 || tasks[0]["websiteId"] != frequent.ID {
+			t.Errorf("sent %v, want one task for %s", tasks, frequent.ID)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/gorilla/websocket"
+)
+
+type ValidatorConnection struct {
+	ValidatorID  string
+	PublicKey    string
+	KeyID        string
+	Conn         *websocket.Conn
+	ConnectedAt  time.Time
+	RegisteredAt time.Time // when the validator was registered, for the longevity bonus
+	Location     string    // matched against websites' allowed regions
+
+	// ProtocolVersion is what the validator signed up with; older validators
+	// don't get multi-step checks
+	ProtocolVersion int
+
+	// lastActivity is when the validator last sent a message (pongs don't count),
+	// in unix nanoseconds; written by the read loop, read by the writer and admin
+	lastActivity atomic.Int64
+
+	// send queues outgoing messages for writePump so a slow validator never
+	// blocks the caller; overflowing it disconnects the validator
+	send      chan OutgoingMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	writeTimeout time.Duration
+	pingInterval time.Duration
+	idleTimeout  time.Duration // 0 never disconnects a quiet validator
+}
+
+func newValidatorConnection(validator models.Validator, conn *websocket.Conn, cfg *config.Config) *ValidatorConnection {
+	queueSize := cfg.ValidatorSendQueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	v := &ValidatorConnection{
+		ValidatorID:  validator.ID,
+		PublicKey:    validator.PublicKey,
+		KeyID:        validator.KeyID,
+		Conn:         conn,
+		ConnectedAt:  time.Now(),
+		RegisteredAt: validator.CreatedAt,
+		Location:     validator.Location,
+		send:         make(chan OutgoingMessage, queueSize),
+		done:         make(chan struct{}),
+		writeTimeout: cfg.HubWriteTimeout,
+		pingInterval: cfg.HubPingInterval,
+		idleTimeout:  cfg.HubIdleTimeout,
+	}
+	v.touch(v.ConnectedAt)
+	go v.writePump()
+	return v
+}
+
+// Send queues msg without blocking. It returns false if the connection is closed
+// or its queue is full, in which case the validator is disconnected.
+func (v *ValidatorConnection) Send(msg OutgoingMessage) bool {
+	select {
+	case <-v.done:
+		return false
+	default:
+	}
+
+	select {
+	case v.send <- msg:
+		return true
+	default:
+		log.Printf("⚠️  Send queue full for validator %s, disconnecting", v.ValidatorID)
+		v.Close()
+		return false
+	}
+}
+
+// touch records activity from the validator at now
+func (v *ValidatorConnection) touch(now time.Time) {
+	v.lastActivity.Store(now.UnixNano())
+}
+
+// LastActivity is when the validator last sent a message
+func (v *ValidatorConnection) LastActivity() time.Time {
+	return time.Unix(0, v.lastActivity.Load())
+}
+
+// Close stops the writer and closes the socket, which also ends the read loop
+func (v *ValidatorConnection) Close() {
+	v.closeOnce.Do(func() {
+		close(v.done)
+		v.Conn.Close()
+	})
+}
+
+// writePump is the only goroutine writing data messages to the socket. It also
+// pings the validator so the read deadline is refreshed by its pongs, and drops
+// validators that answer pings but haven't sent anything for idleTimeout.
+func (v *ValidatorConnection) writePump() {
+	ping := time.NewTicker(v.pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-v.done:
+			return
+		case msg := <-v.send:
+			v.Conn.SetWriteDeadline(time.Now().Add(v.writeTimeout))
+			if err := v.Conn.WriteJSON(msg); err != nil {
+				log.Printf("❌ Failed to write to validator %s: %v", v.ValidatorID, err)
+				v.Close()
+				return
+			}
+		case <-ping.C:
+			if idle := time.Since(v.LastActivity()); v.idleTimeout > 0 && idle > v.idleTimeout {
+				log.Printf("❌ Validator %s sent nothing for %s, disconnecting", v.ValidatorID, idle.Round(time.Second))
+				v.Close()
+				return
+			}
+			if err := v.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(v.writeTimeout)); err != nil {
+				log.Printf("❌ Failed to ping validator %s: %v", v.ValidatorID, err)
+				v.Close()
+				return
+			}
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestSlowValidatorDoesNotStallDispatch(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.ValidatorSendQueueSize = 4 })
+	url := serveHub(t, h)
+
+	fast := dial(t, url, nil)
+	fast.signUp(newTestValidator(t, h.db))
+	// Signed up, then never reads again, so the hub's writes to it back up
+	dial(t, url, nil).signUp(newTestValidator(t, h.db))
+
+	// Tasks big enough to fill the slow validator's socket buffers quickly
+	website := models.Website{
+		ID:         uuid.New().String(),
+		URL:        "https://example.com",
+		Assertions: []models.Assertion{{Type: models.AssertionRegex, Expression: strings.Repeat("x", 1<<20)}},
+	}
+
+	// Each dispatch returns at once and reaches the fast validator, even once the
+	// slow one's writes block
+	for i := 0; i < 20; i++ {
+		started := time.Now()
+		if sent := h.dispatchWebsite(website, h.connectedValidators()); sent == 0 {
+			t.Fatalf("dispatch %d reached no validator", i)
+		}
+		if took := time.Since(started); took > time.Second {
+			t.Fatalf("dispatch %d took %s", i, took)
+		}
+		if got := fast.nextTask(); got.WebsiteID != website.ID {
+			t.Fatalf("task %d for %s, want %s", i, got.WebsiteID, website.ID)
+		}
+	}
+
+	// Overflowing its queue disconnects the slow validator
+	waitFor(t, "the slow validator to be dropped", func() bool { return len(h.connectedValidators()) == 1 })
+}
+
+func TestTouchUpdatesLastActivity(t *testing.T) {
+	v := &ValidatorConnection{}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	v.touch(at)
+	if got := v.LastActivity(); !got.Equal(at) {
+		t.Errorf("LastActivity = %s, want %s", got, at)
+	}
+	v.touch(at.Add(time.Minute))
+	if got := v.LastActivity(); !got.Equal(at.Add(time.Minute)) {
+		t.Errorf("LastActivity = %s after more activity, want %s", got, at.Add(time.Minute))
+	}
+}
+
+func TestMessagesUpdateLastActivity(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.AdminToken = testAdminToken })
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	live := liveValidators(t, h)
+	if len(live) != 1 {
+		t.Fatalf("%d validators listed, want 1", len(live))
+	}
+	connected, signedUp := live[0].ConnectedAt, live[0].LastActivity
+	if signedUp.Before(connected) {
+		t.Fatalf("last activity %s before connecting at %s", signedUp, connected)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	client.send(IncomingMessage{Type: "noop"})
+	waitFor(t, "the message to count as activity", func() bool {
+		return liveValidators(t, h)[0].LastActivity.After(signedUp)
+	})
+	if got := liveValidators(t, h)[0].ConnectedAt; !got.Equal(connected) {
+		t.Errorf("connected at %s after activity, want it unchanged at %s", got, connected)
+	}
+}
+
+func TestIdleValidatorDisconnected(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubIdleTimeout = 200 * time.Millisecond
+		cfg.HubPingInterval = 50 * time.Millisecond
+		cfg.HubReadTimeout = time.Minute
+	})
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	// Answering pings alone doesn't keep the validator connected
+	started := time.Now()
+	if err := client.closed(); isTimeout(err) {
+		t.Fatalf("hub kept the idle validator connected: %v", err)
+	}
+	if took := time.Since(started); took < h.cfg.HubIdleTimeout {
+		t.Errorf("disconnected after %s, before the %s idle timeout", took, h.cfg.HubIdleTimeout)
+	}
+	waitFor(t, "the validator to be removed", func() bool { return len(h.connectedValidators()) == 0 })
+}
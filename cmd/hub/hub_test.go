@@ -0,0 +1,848 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/cluster"
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/events"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/datmedevil17/gopher-uptime/internal/signing"
+	"github.com/gagliardetto/solana-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+// newTestHub returns a hub over an empty test database with in-process event
+// bus, presence and cluster coordination. configure adjusts the defaults.
+func newTestHub(t *testing.T, configure ...func(*config.Config)) *Hub {
+	t.Helper()
+
+	cfg := config.Load()
+	cfg.HubID = "hub-test"
+	cfg.HubAuthTokens = nil
+	cfg.ValidatorSelection = selectionRandom
+	cfg.ValidatorsPerCheck = 0
+	cfg.MinValidators = 1
+	cfg.MaxPendingPayout = 0
+	cfg.LongevityBonusPerMonth = 0
+	cfg.IncidentOpenChecks = 1
+	cfg.IncidentResolveChecks = 1
+	for _, c := range configure {
+		c(cfg)
+	}
+
+	db := dbtest.Open(t)
+	bus := events.NewMemoryBus()
+	t.Cleanup(func() { bus.Close() })
+	return NewHub(db, cfg, bus, presence.NewMemoryStore(cfg.PresenceTTL), cluster.NewMemoryCoordinator())
+}
+
+// testValidator is a validator keypair registered in the hub's database
+type testValidator struct {
+	signer *signing.Ed25519Signer
+	model  models.Validator
+}
+
+func newTestValidator(t *testing.T, db *gorm.DB) testValidator {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := signing.NewEd25519Signer(solana.PrivateKey(key))
+	validator := models.Validator{
+		ID:        uuid.New().String(),
+		PublicKey: signer.PublicKey(),
+		KeyID:     signer.KeyID(),
+		Location:  "unknown",
+		IP:        "127.0.0.1",
+	}
+	if err := db.Create(&validator).Error; err != nil {
+		t.Fatalf("creating validator: %v", err)
+	}
+	return testValidator{signer: signer, model: validator}
+}
+
+// connection is v as the hub sees it once signed up, without a socket; enough
+// to receive task results. Tasks sent to it stay queued on its send channel.
+func (v testValidator) connection() *ValidatorConnection {
+	return &ValidatorConnection{
+		send:            make(chan OutgoingMessage, 64),
+		done:            make(chan struct{}),
+		ValidatorID:     v.model.ID,
+		PublicKey:       v.model.PublicKey,
+		KeyID:           v.model.KeyID,
+		ConnectedAt:     time.Now(),
+		RegisteredAt:    v.model.CreatedAt,
+		Location:        v.model.Location,
+		ProtocolVersion: protocol.Version,
+	}
+}
+
+// result is v's signed reply to the task callbackID sent with nonce
+func (v testValidator) result(t *testing.T, callbackID, nonce, status string, latency float64) IncomingMessage {
+	t.Helper()
+	return v.resultAt(t, callbackID, nonce, status, latency, time.Now())
+}
+
+// resultAt is result signed at signedAt
+func (v testValidator) resultAt(t *testing.T, callbackID, nonce, status string, latency float64, signedAt time.Time) IncomingMessage {
+	t.Helper()
+
+	timestamp := signedAt.Unix()
+	data, err := json.Marshal(ValidateIncoming{
+		CallbackID:    callbackID,
+		Status:        status,
+		Latency:       latency,
+		ValidatorID:   v.model.ID,
+		SignedMessage: v.signer.Sign([]byte(protocol.ValidateMessage(callbackID, timestamp, nonce))),
+		Timestamp:     timestamp,
+		Nonce:         nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return IncomingMessage{Type: "validate", Data: data}
+}
+
+// signup is v's signed signup answering challenge, speaking protocol version
+func (v testValidator) signup(t *testing.T, challenge string, version int) IncomingMessage {
+	t.Helper()
+	return v.signupAt(t, challenge, version, time.Now())
+}
+
+// signupAt is signup signed at signedAt
+func (v testValidator) signupAt(t *testing.T, challenge string, version int, signedAt time.Time) IncomingMessage {
+	t.Helper()
+
+	callbackID, timestamp := uuid.New().String(), signedAt.Unix()
+	data, err := json.Marshal(SignupIncoming{
+		PublicKey:       v.signer.PublicKey(),
+		SignedMessage:   v.signer.Sign([]byte(protocol.SignupMessage(callbackID, v.signer.PublicKey(), timestamp, challenge))),
+		KeyID:           v.signer.KeyID(),
+		Timestamp:       timestamp,
+		Nonce:           challenge,
+		CallbackID:      callbackID,
+		ProtocolVersion: version,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return IncomingMessage{Type: "signup", Data: data}
+}
+
+// serveHub serves h's websocket endpoint and returns its ws:// URL
+func serveHub(t *testing.T, h *Hub) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(h.handleWebSocket))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// testConn is a client connection to a test hub
+type testConn struct {
+	t         *testing.T
+	conn      *websocket.Conn
+	challenge string
+}
+
+// received is an OutgoingMessage as a validator reads it
+type received struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// dial connects to the hub at url and reads its signup challenge
+func dial(t *testing.T, url string, header http.Header) *testConn {
+	t.Helper()
+
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("dialing hub: %v (%v)", err, resp)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &testConn{t: t, conn: conn}
+	msg, err := c.read()
+	if err != nil {
+		t.Fatalf("reading challenge: %v", err)
+	}
+	var challenge struct {
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(msg.Data, &challenge); err != nil || msg.Type != "challenge" {
+		t.Fatalf("first message = %s %s, want a challenge", msg.Type, msg.Data)
+	}
+	c.challenge = challenge.Challenge
+	return c
+}
+
+// read returns the next message from the hub, waiting up to 5s
+func (c *testConn) read() (received, error) {
+	var msg received
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	err := c.conn.ReadJSON(&msg)
+	return msg, err
+}
+
+// expect reads messages until one of type msgType arrives, failing on errors
+func (c *testConn) expect(msgType string) json.RawMessage {
+	c.t.Helper()
+
+	for {
+		msg, err := c.read()
+		if err != nil {
+			c.t.Fatalf("waiting for %s: %v", msgType, err)
+		}
+		if msg.Type == msgType {
+			return msg.Data
+		}
+	}
+}
+
+func (c *testConn) send(msg IncomingMessage) {
+	c.t.Helper()
+
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.t.Fatalf("sending %s: %v", msg.Type, err)
+	}
+}
+
+// signUp registers v over c and waits for the hub's confirmation
+func (c *testConn) signUp(v testValidator) {
+	c.t.Helper()
+
+	c.send(v.signup(c.t, c.challenge, protocol.Version))
+	var confirmed struct {
+		ValidatorID string `json:"validatorId"`
+	}
+	if err := json.Unmarshal(c.expect("signup"), &confirmed); err != nil || confirmed.ValidatorID != v.model.ID {
+		c.t.Fatalf("signup confirmed %q, want %s", confirmed.ValidatorID, v.model.ID)
+	}
+}
+
+// task is a validate message as a validator reads it
+type task struct {
+	URL        string `json:"url"`
+	CallbackID string `json:"callbackId"`
+	WebsiteID  string `json:"websiteId"`
+	Nonce      string `json:"nonce"`
+}
+
+// nextTask waits for the hub to send c a validation task
+func (c *testConn) nextTask() task {
+	c.t.Helper()
+
+	var next task
+	if err := json.Unmarshal(c.expect("validate"), &next); err != nil {
+		c.t.Fatal(err)
+	}
+	return next
+}
+
+// closed waits for the hub to end the connection and returns the close error
+func (c *testConn) closed() error {
+	c.t.Helper()
+
+	for {
+		if _, err := c.read(); err != nil {
+			return err
+		}
+	}
+}
+
+// waitFor polls condition until it holds, failing t after 5s
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// tickCount is how many ticks website has
+func tickCount(t *testing.T, db *gorm.DB, websiteID string) int64 {
+	t.Helper()
+
+	var n int64
+	if err := db.Model(&models.WebsiteTick{}).Where("website_id = ?", websiteID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// createWebsite stores website with an owner, filling in the ids
+func createWebsite(t *testing.T, db *gorm.DB, website models.Website) models.Website {
+	t.Helper()
+
+	owner := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	website.ID = uuid.New().String()
+	website.UserID = owner.ID
+	if website.URL == "" {
+		website.URL = "https://example.com"
+	}
+	if err := db.Create(&website).Error; err != nil {
+		t.Fatalf("creating website: %v", err)
+	}
+	return website
+}
+
+// recordResult runs the callback of a task sent to v about website, as if v
+// had replied with status and latency, and returns the tick it recorded
+func recordResult(t *testing.T, h *Hub, website models.Website, v testValidator, status string, latency float64) (models.WebsiteTick, bool) {
+	t.Helper()
+
+	callbackID, nonce := uuid.New().String(), protocol.NewNonce()
+	h.createValidateCallback(website, v.connection(), nonce)(v.result(t, callbackID, nonce, status, latency))
+
+	var ticks []models.WebsiteTick
+	if err := h.db.Where("callback_id = ?", callbackID).Find(&ticks).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ticks) == 0 {
+		return models.WebsiteTick{}, false
+	}
+	return ticks[0], true
+}
+
+func TestValidateCallbackLatencyThreshold(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{LatencyThresholdMs: 500})
+	v := newTestValidator(t, h.db)
+
+	tests := []struct {
+		name     string
+		reported string
+		latency  float64
+		want     string
+	}{
+		{"fast", models.StatusGood, 120, models.StatusGood},
+		{"at the threshold", models.StatusGood, 500, models.StatusGood},
+		{"slow but up", models.StatusGood, 1800, models.StatusDegraded},
+		{"down", models.StatusBad, 30, models.StatusBad},
+		{"down and slow", models.StatusBad, 1800, models.StatusBad},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tick, ok := recordResult(t, h, website, v, tt.reported, tt.latency)
+			if !ok {
+				t.Fatal("no tick recorded")
+			}
+			if tick.Status != tt.want {
+				t.Errorf("status = %s, want %s", tick.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCallbackLatencyRange(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.MaxReportedLatency = 30 * time.Second })
+	website := createWebsite(t, h.db, models.Website{})
+	v := newTestValidator(t, h.db)
+
+	tests := []struct {
+		name     string
+		latency  float64
+		recorded bool
+	}{
+		{"negative", -1, false},
+		{"zero", 0, true},
+		{"sub-millisecond", 0.482, true},
+		{"in range", 850.5, true},
+		{"at the maximum", 30000, true},
+		{"just over the maximum", 30000.5, false},
+		{"absurdly large", 1e15, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tick, ok := recordResult(t, h, website, v, models.StatusGood, tt.latency)
+			if ok != tt.recorded {
+				t.Fatalf("recorded = %v, want %v", ok, tt.recorded)
+			}
+			if ok && tick.Latency != tt.latency {
+				t.Errorf("latency = %v, want %v", tick.Latency, tt.latency)
+			}
+		})
+	}
+
+	var credits int64
+	if err := h.db.Model(&models.EarningsLedger{}).Where("validator_id = ?", v.model.ID).Count(&credits).Error; err != nil {
+		t.Fatal(err)
+	}
+	if credits != 4 {
+		t.Errorf("%d credits, want 4: rejected results earn nothing", credits)
+	}
+}
+
+func TestValidateCallbackWithoutThreshold(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{})
+	v := newTestValidator(t, h.db)
+
+	tick, ok := recordResult(t, h, website, v, models.StatusGood, 20000)
+	if !ok {
+		t.Fatal("no tick recorded")
+	}
+	if tick.Status != models.StatusGood {
+		t.Errorf("status = %s, want %s", tick.Status, models.StatusGood)
+	}
+}
+
+func TestValidateCallbackRecordsProtocol(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{HTTPVersion: models.HTTPVersion2})
+	v := newTestValidator(t, h.db)
+
+	tests := []struct {
+		reported string
+		want     string
+	}{
+		{"HTTP/2.0", "HTTP/2.0"},
+		{"HTTP/1.1", "HTTP/1.1"},
+		{"HTTP/1.1,HTTP/2.0", "HTTP/1.1,HTTP/2.0"},
+		{"", ""},
+		{"SPDY/3", ""},
+		{"HTTP/1.1,HTTP/1.1,HTTP/1.1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reported, func(t *testing.T) {
+			callbackID, nonce := uuid.New().String(), protocol.NewNonce()
+			msg := v.result(t, callbackID, nonce, models.StatusGood, 10)
+			var validate ValidateIncoming
+			if err := json.Unmarshal(msg.Data, &validate); err != nil {
+				t.Fatal(err)
+			}
+			validate.Protocol = tt.reported
+			msg.Data, _ = json.Marshal(validate)
+			h.createValidateCallback(website, v.connection(), nonce)(msg)
+
+			var tick models.WebsiteTick
+			if err := h.db.Where("callback_id = ?", callbackID).First(&tick).Error; err != nil {
+				t.Fatalf("no tick recorded: %v", err)
+			}
+			if tick.Protocol != tt.want {
+				t.Errorf("recorded protocol %q, want %q", tick.Protocol, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCallbackCreditsLedger(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	websites := []models.Website{
+		createWebsite(t, h.db, models.Website{}),
+		createWebsite(t, h.db, models.Website{LatencyThresholdMs: 100}),
+	}
+
+	var ticks []models.WebsiteTick
+	for i, status := range []string{models.StatusGood, models.StatusBad, models.StatusGood, models.StatusGood, models.StatusBad} {
+		tick, ok := recordResult(t, h, websites[i%len(websites)], v, status, 250)
+		if !ok {
+			t.Fatalf("result %d: no tick recorded", i)
+		}
+		ticks = append(ticks, tick)
+	}
+
+	var entries []models.EarningsLedger
+	if err := h.db.Where("validator_id = ?", v.model.ID).Find(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(ticks) {
+		t.Fatalf("got %d ledger entries for %d ticks", len(entries), len(ticks))
+	}
+
+	byTick := make(map[string]models.EarningsLedger, len(entries))
+	var total float64
+	for _, entry := range entries {
+		byTick[entry.TickID] = entry
+		total += entry.Amount
+	}
+	for _, tick := range ticks {
+		entry, ok := byTick[tick.ID]
+		if !ok {
+			t.Errorf("tick %s has no ledger entry", tick.ID)
+		} else if entry.WebsiteID != tick.WebsiteID || entry.Amount <= 0 {
+			t.Errorf("ledger entry for tick %s = %+v, want a positive credit for website %s", tick.ID, entry, tick.WebsiteID)
+		}
+	}
+
+	var validator models.Validator
+	if err := h.db.Where("id = ?", v.model.ID).First(&validator).Error; err != nil {
+		t.Fatal(err)
+	}
+	if validator.PendingPayouts != total {
+		t.Errorf("pending payouts = %.2f, want the ledger total %.2f", validator.PendingPayouts, total)
+	}
+}
+
+// connectionOf is h's connection for the validator v
+func connectionOf(t *testing.T, h *Hub, v testValidator) *ValidatorConnection {
+	t.Helper()
+
+	for _, conn := range h.connectedValidators() {
+		if conn.ValidatorID == v.model.ID {
+			return conn
+		}
+	}
+	t.Fatalf("validator %s isn't connected", v.model.ID)
+	return nil
+}
+
+func TestValidateFromUnassignedValidatorIgnored(t *testing.T) {
+	h := newTestHub(t)
+	url := serveHub(t, h)
+	website := createWebsite(t, h.db, models.Website{})
+	assigned, intruder := newTestValidator(t, h.db), newTestValidator(t, h.db)
+	assignedClient, intruderClient := dial(t, url, nil), dial(t, url, nil)
+	assignedClient.signUp(assigned)
+	intruderClient.signUp(intruder)
+
+	if sent := h.dispatchWebsite(website, []*ValidatorConnection{connectionOf(t, h, assigned)}); sent != 1 {
+		t.Fatalf("dispatched %d tasks, want 1", sent)
+	}
+	task := assignedClient.nextTask()
+
+	// Another validator answering in its own name, or claiming the assigned
+	// validator's id, completes nothing
+	intruderClient.send(intruder.result(t, task.CallbackID, task.Nonce, models.StatusBad, 10))
+	claimed := intruder
+	claimed.model.ID = assigned.model.ID
+	intruderClient.send(claimed.result(t, task.CallbackID, task.Nonce, models.StatusBad, 10))
+	stillConnected(t, intruderClient, 200*time.Millisecond)
+
+	if n := tickCount(t, h.db, website.ID); n != 0 {
+		t.Fatalf("%d ticks recorded from the unassigned validator", n)
+	}
+	if n := pending(h, website.ID); n != 1 {
+		t.Fatalf("%d checks pending, want the assigned task kept", n)
+	}
+
+	// The assigned validator can still complete it
+	assignedClient.send(assigned.result(t, task.CallbackID, task.Nonce, models.StatusGood, 10))
+	waitFor(t, "the assigned result to be recorded", func() bool { return tickCount(t, h.db, website.ID) == 1 })
+
+	var tick models.WebsiteTick
+	if err := h.db.Where("website_id = ?", website.ID).First(&tick).Error; err != nil {
+		t.Fatal(err)
+	}
+	if tick.ValidatorID != assigned.model.ID || tick.Status != models.StatusGood {
+		t.Errorf("tick = %s from %s, want %s from the assigned validator", tick.Status, tick.ValidatorID, models.StatusGood)
+	}
+	var intruderCredits int64
+	if err := h.db.Model(&models.EarningsLedger{}).Where("validator_id = ?", intruder.model.ID).Count(&intruderCredits).Error; err != nil {
+		t.Fatal(err)
+	}
+	if intruderCredits != 0 {
+		t.Errorf("unassigned validator credited %d times", intruderCredits)
+	}
+}
+
+func TestValidateCallbackLongevityBonus(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.LongevityBonusPerMonth = 0.05
+		cfg.LongevityBonusMax = 0.25
+	})
+	website := createWebsite(t, h.db, models.Website{})
+
+	tests := []struct {
+		name string
+		age  time.Duration
+		want float64
+	}{
+		{"new validator", 0, models.CostPerValidation},
+		{"three months", 95 * 24 * time.Hour, 115},
+		{"capped", 2 * 365 * 24 * time.Hour, 125},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestValidator(t, h.db)
+			v.model.CreatedAt = time.Now().Add(-tt.age)
+			if err := h.db.Model(&v.model).Update("created_at", v.model.CreatedAt).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			tick, ok := recordResult(t, h, website, v, models.StatusGood, 100)
+			if !ok {
+				t.Fatal("no tick recorded")
+			}
+
+			var entry models.EarningsLedger
+			if err := h.db.Where("tick_id = ?", tick.ID).First(&entry).Error; err != nil {
+				t.Fatal(err)
+			}
+			var validator models.Validator
+			if err := h.db.Where("id = ?", v.model.ID).First(&validator).Error; err != nil {
+				t.Fatal(err)
+			}
+			if entry.Amount != tt.want || validator.PendingPayouts != tt.want {
+				t.Errorf("credited %.2f, pending %.2f, want %.2f", entry.Amount, validator.PendingPayouts, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateValidateCallbackRecordedOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		hubs func(h *Hub) []*Hub
+	}{
+		{"redelivered to the same hub", func(h *Hub) []*Hub { return []*Hub{h, h} }},
+		{"replayed to another hub", func(h *Hub) []*Hub { return []*Hub{h, peerHub(h, "hub-peer")} }},
+		{"delivered to several hubs at once", func(h *Hub) []*Hub {
+			return []*Hub{h, peerHub(h, "hub-b"), peerHub(h, "hub-c"), peerHub(h, "hub-d")}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub(t)
+			website := createWebsite(t, h.db, models.Website{})
+			v := newTestValidator(t, h.db)
+
+			callbackID, nonce := uuid.New().String(), protocol.NewNonce()
+			result := v.result(t, callbackID, nonce, models.StatusGood, 100)
+
+			var wg sync.WaitGroup
+			for _, hub := range tt.hubs(h) {
+				wg.Add(1)
+				go func(hub *Hub) {
+					defer wg.Done()
+					hub.createValidateCallback(website, v.connection(), nonce)(result)
+				}(hub)
+			}
+			wg.Wait()
+
+			if n := tickCount(t, h.db, website.ID); n != 1 {
+				t.Errorf("got %d ticks, want 1", n)
+			}
+
+			var entries []models.EarningsLedger
+			if err := h.db.Where("validator_id = ?", v.model.ID).Find(&entries).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("got %d ledger entries, want 1", len(entries))
+			}
+
+			var validator models.Validator
+			if err := h.db.Where("id = ?", v.model.ID).First(&validator).Error; err != nil {
+				t.Fatal(err)
+			}
+			if validator.PendingPayouts != entries[0].Amount {
+				t.Errorf("pending payouts = %.2f, want the single credit %.2f", validator.PendingPayouts, entries[0].Amount)
+			}
+		})
+	}
+}
+
+func TestDeletedValidatorSignupRejected(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	if err := h.db.Delete(&v.model).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	client := dial(t, serveHub(t, h), nil)
+	client.send(v.signup(t, client.challenge, protocol.Version))
+
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Text != "validator has been deleted" {
+		t.Fatalf("connection ended with %v, want the deleted validator turned away", err)
+	}
+	var n int64
+	h.db.Unscoped().Model(&models.Validator{}).Where("public_key = ?", v.model.PublicKey).Count(&n)
+	if n != 1 {
+		t.Errorf("%d validators with the key, want the deleted one only", n)
+	}
+}
+
+func TestRepeatedSignupOnConnection(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	client := dial(t, serveHub(t, h), nil)
+
+	client.signUp(v)
+	registered := connectionOf(t, h, v)
+
+	// Signing up again is answered without registering the connection twice
+	for i := 0; i < 2; i++ {
+		client.signUp(v)
+	}
+	if connected := h.connectedValidators(); len(connected) != 1 || connected[0] != registered {
+		t.Fatalf("%d connections after repeated signups, want the first one only", len(connected))
+	}
+	var n int64
+	h.db.Unscoped().Model(&models.Validator{}).Where("public_key = ?", v.model.PublicKey).Count(&n)
+	if n != 1 {
+		t.Errorf("%d validators with the key, want 1", n)
+	}
+
+	// The connection keeps working
+	website := createWebsite(t, h.db, models.Website{})
+	h.dispatchWebsite(website, h.connectedValidators())
+	if task := client.nextTask(); task.WebsiteID != website.ID {
+		t.Errorf("task for %s, want %s", task.WebsiteID, website.ID)
+	}
+}
+
+func TestSignupWithAnotherKeyOnConnectionRejected(t *testing.T) {
+	h := newTestHub(t)
+	v, other := newTestValidator(t, h.db), newTestValidator(t, h.db)
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(v)
+
+	client.send(other.signup(t, client.challenge, protocol.Version))
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Text != "connection already registered as validator "+v.model.ID {
+		t.Fatalf("connection ended with %v, want the second key rejected", err)
+	}
+	waitFor(t, "the connection to be dropped", func() bool { return len(h.connectedValidators()) == 0 })
+
+	// Neither key stays registered through the closed connection
+	h.mu.RLock()
+	_, otherConnected := h.validators[other.model.ID]
+	h.mu.RUnlock()
+	if otherConnected {
+		t.Error("second key registered on the first key's connection")
+	}
+}
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 and its key
+// to t's temp dir, returning their paths and a pool trusting the certificate
+func writeCertificate(t *testing.T) (certFile, keyFile string, roots *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hub-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "hub.crt"), filepath.Join(dir, "hub.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots = x509.NewCertPool()
+	roots.AddCert(cert)
+	return certFile, keyFile, roots
+}
+
+func TestServeTLS(t *testing.T) {
+	certFile, keyFile, roots := writeCertificate(t)
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubTLSCertFile = certFile
+		cfg.HubTLSKeyFile = keyFile
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(h.handleWebSocket)}
+	served := make(chan error, 1)
+	go func() { served <- serve(server, ln, h.cfg) }()
+	t.Cleanup(func() {
+		server.Close()
+		if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("serve: %v", err)
+		}
+	})
+	addr := ln.Addr().String()
+
+	// A validator trusting the certificate signs up over wss
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = &tls.Config{RootCAs: roots}
+	conn, _, err := dialer.Dial("wss://"+addr, nil)
+	if err != nil {
+		t.Fatalf("dialing wss: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := &testConn{t: t, conn: conn}
+	var challenge struct {
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(client.expect("challenge"), &challenge); err != nil {
+		t.Fatal(err)
+	}
+	client.challenge = challenge.Challenge
+	client.signUp(newTestValidator(t, h.db))
+
+	// Plain ws and untrusted certificates are refused
+	if _, _, err := websocket.DefaultDialer.Dial("ws://"+addr, nil); err == nil {
+		t.Error("plain ws connected to the TLS hub")
+	}
+	if _, _, err := websocket.DefaultDialer.Dial("wss://"+addr, nil); err == nil {
+		t.Error("connected without trusting the hub's certificate")
+	}
+}
+
+func TestServeTLSNeedsCertificateAndKey(t *testing.T) {
+	certFile, _, _ := writeCertificate(t)
+	cfg := &config.Config{HubTLSCertFile: certFile}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := serve(&http.Server{}, ln, cfg); err == nil || errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("serve with a certificate but no key = %v, want a configuration error", err)
+	}
+}
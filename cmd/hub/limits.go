@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// remoteIP is the address conn comes from, without the port
+func remoteIP(conn *websocket.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// registrationsFull reports whether ip already registered HUB_MAX_REGISTRATIONS_PER_IP
+// validators, deleted ones included so they can't be churned through
+func (h *Hub) registrationsFull(ip string) (bool, error) {
+	if h.cfg.HubMaxRegistrationsPerIP <= 0 {
+		return false, nil
+	}
+
+	db, cancel := h.query()
+	defer cancel()
+
+	var registered int64
+	if err := db.Unscoped().Model(&models.Validator{}).Where("ip = ?", ip).Count(&registered).Error; err != nil {
+		return false, err
+	}
+	return registered >= int64(h.cfg.HubMaxRegistrationsPerIP), nil
+}
+
+// addValidator makes connection the validator's live connection, unless that
+// would break HUB_MAX_VALIDATORS_PER_IP or HUB_REJECT_DUPLICATE_KEYS; the returned
+// reason then says why. A connection the validator already had is closed.
+func (h *Hub) addValidator(connection *ValidatorConnection) string {
+	ip := remoteIP(connection.Conn)
+
+	h.mu.Lock()
+	previous, connected := h.validators[connection.ValidatorID]
+	if connected && h.cfg.HubRejectDuplicateKeys {
+		h.mu.Unlock()
+		return "validator key is already connected"
+	}
+	if h.cfg.HubMaxValidatorsPerIP > 0 {
+		fromIP := 0
+		for id, v := range h.validators {
+			if id != connection.ValidatorID && remoteIP(v.Conn) == ip {
+				fromIP++
+			}
+		}
+		if fromIP >= h.cfg.HubMaxValidatorsPerIP {
+			h.mu.Unlock()
+			return fmt.Sprintf("%d validators already connected from %s", fromIP, ip)
+		}
+	}
+	h.validators[connection.ValidatorID] = connection
+	h.mu.Unlock()
+
+	if connected {
+		log.Printf("🔌 Validator %s reconnected, closing its previous connection", connection.ValidatorID)
+		previous.Close()
+	}
+	return ""
+}
+
+// capCredit returns the part of credit that fits under maxPending for the
+// validator's pending balance. The row stays locked until tx ends, so concurrent
+// results for the same validator can't both fill the last of the room.
+func capCredit(tx *gorm.DB, validatorID string, credit, maxPending float64) (float64, error) {
+	var pending []float64
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Model(&models.Validator{}).
+		Where("id = ?", validatorID).
+		Pluck("pending_payouts", &pending).Error; err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		// Unknown validators are turned away when their balance is updated
+		return credit, nil
+	}
+
+	room := math.Round((maxPending-pending[0])*100) / 100
+	return math.Max(0, math.Min(credit, room)), nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// unregistered is a validator keypair the hub hasn't seen, so signing up with it
+// creates a new validator
+func unregistered(t *testing.T, h *Hub) testValidator {
+	t.Helper()
+
+	v := newTestValidator(t, h.db)
+	if err := h.db.Unscoped().Delete(&v.model).Error; err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// rejected fails t unless the hub closed c with reason
+func rejected(t *testing.T, c *testConn, reason string) {
+	t.Helper()
+
+	var closeErr *websocket.CloseError
+	if err := c.closed(); !errors.As(err, &closeErr) || closeErr.Text != reason {
+		t.Fatalf("connection ended with %v, want it closed with %q", err, reason)
+	}
+}
+
+func TestMaxValidatorsPerIP(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.HubMaxValidatorsPerIP = 2 })
+	url := serveHub(t, h)
+
+	first, second := dial(t, url, nil), dial(t, url, nil)
+	first.signUp(newTestValidator(t, h.db))
+	second.signUp(newTestValidator(t, h.db))
+
+	third := dial(t, url, nil)
+	third.send(newTestValidator(t, h.db).signup(t, third.challenge, protocol.Version))
+	rejected(t, third, "2 validators already connected from 127.0.0.1")
+	if n := len(h.connectedValidators()); n != 2 {
+		t.Errorf("%d validators connected, want 2", n)
+	}
+
+	// Once one disconnects there is room again
+	first.conn.Close()
+	waitFor(t, "the first validator to disconnect", func() bool { return len(h.connectedValidators()) == 1 })
+	dial(t, url, nil).signUp(newTestValidator(t, h.db))
+}
+
+func TestMaxRegistrationsPerIP(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.HubMaxRegistrationsPerIP = 2 })
+	url := serveHub(t, h)
+
+	// Registers through the hub, recording the address it connected from
+	for i := 0; i < 2; i++ {
+		client := dial(t, url, nil)
+		client.send(unregistered(t, h).signup(t, client.challenge, protocol.Version))
+		client.expect("signup")
+	}
+	var fromIP int64
+	if err := h.db.Model(&models.Validator{}).Where("ip = ?", "127.0.0.1").Count(&fromIP).Error; err != nil {
+		t.Fatal(err)
+	}
+	if fromIP != 2 {
+		t.Fatalf("%d validators registered from 127.0.0.1, want 2", fromIP)
+	}
+
+	// Deleting one doesn't free its slot
+	if err := h.db.Where("ip = ?", "127.0.0.1").Limit(1).Delete(&models.Validator{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	extra := dial(t, url, nil)
+	v := unregistered(t, h)
+	extra.send(v.signup(t, extra.challenge, protocol.Version))
+	rejected(t, extra, "too many validators registered from 127.0.0.1")
+
+	var created int64
+	if err := h.db.Unscoped().Model(&models.Validator{}).Where("public_key = ?", v.model.PublicKey).Count(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created != 0 {
+		t.Error("rejected signup registered the validator")
+	}
+}
+
+func TestDuplicateKeyConnections(t *testing.T) {
+	tests := []struct {
+		name   string
+		reject bool
+	}{
+		{"replaced", false},
+		{"rejected", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub(t, func(cfg *config.Config) { cfg.HubRejectDuplicateKeys = tt.reject })
+			url := serveHub(t, h)
+			v := newTestValidator(t, h.db)
+
+			first := dial(t, url, nil)
+			first.signUp(v)
+			firstConn := connectionOf(t, h, v)
+
+			second := dial(t, url, nil)
+			if tt.reject {
+				second.send(v.signup(t, second.challenge, protocol.Version))
+				rejected(t, second, "validator key is already connected")
+			} else {
+				second.signUp(v)
+				if err := first.closed(); err == nil {
+					t.Error("first connection left open after the key reconnected")
+				}
+			}
+
+			connected := h.connectedValidators()
+			if len(connected) != 1 {
+				t.Fatalf("%d connections for one key, want 1", len(connected))
+			}
+			if kept := connected[0] == firstConn; kept != tt.reject {
+				t.Errorf("kept the first connection = %v, want %v", kept, tt.reject)
+			}
+		})
+	}
+}
+
+// pendingAndEarned reads v's pending balance and the credits in its ledger
+func pendingAndEarned(t *testing.T, h *Hub, v testValidator) (float64, []float64) {
+	t.Helper()
+
+	var validator models.Validator
+	if err := h.db.Where("id = ?", v.model.ID).First(&validator).Error; err != nil {
+		t.Fatal(err)
+	}
+	var earned []float64
+	if err := h.db.Model(&models.EarningsLedger{}).Where("validator_id = ?", v.model.ID).Order("created_at").Pluck("amount", &earned).Error; err != nil {
+		t.Fatal(err)
+	}
+	return validator.PendingPayouts, earned
+}
+
+func TestMaxPendingPayout(t *testing.T) {
+	credit := models.ValidationCredit(0, 0, 0)
+	maxPending := 2.5 * credit
+
+	tests := []struct {
+		name        string
+		pending     float64 // before the check
+		wantCredit  float64
+		wantPending float64
+	}{
+		{"well under the cap", 0, credit, credit},
+		{"partly over the cap", 2 * credit, maxPending - 2*credit, maxPending},
+		{"at the cap", maxPending, 0, maxPending},
+		{"over a lowered cap", 3 * credit, 0, 3 * credit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub(t, func(cfg *config.Config) { cfg.MaxPendingPayout = maxPending })
+			website := createWebsite(t, h.db, models.Website{})
+			v := newTestValidator(t, h.db)
+			if err := h.db.Model(&v.model).Update("pending_payouts", tt.pending).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			// The check is recorded either way
+			if _, ok := recordResult(t, h, website, v, models.StatusGood, 100); !ok {
+				t.Fatal("check not recorded")
+			}
+
+			pending, earned := pendingAndEarned(t, h, v)
+			if pending != tt.wantPending {
+				t.Errorf("pending payouts = %.2f, want %.2f", pending, tt.wantPending)
+			}
+			wantEarned := []float64{tt.wantCredit}
+			if tt.wantCredit == 0 {
+				wantEarned = nil
+			}
+			if !slices.Equal(earned, wantEarned) {
+				t.Errorf("ledger %v, want %v", earned, wantEarned)
+			}
+		})
+	}
+}
+
+func TestMaxPendingPayoutAcrossChecks(t *testing.T) {
+	credit := models.ValidationCredit(0, 0, 0)
+	h := newTestHub(t, func(cfg *config.Config) { cfg.MaxPendingPayout = 2 * credit })
+	website := createWebsite(t, h.db, models.Website{})
+	v := newTestValidator(t, h.db)
+
+	for i := 0; i < 4; i++ {
+		recordResult(t, h, website, v, models.StatusGood, 100)
+	}
+	if n := tickCount(t, h.db, website.ID); n != 4 {
+		t.Errorf("%d checks recorded, want all 4", n)
+	}
+	pending, earned := pendingAndEarned(t, h, v)
+	if pending != 2*credit || len(earned) != 2 {
+		t.Errorf("pending %.2f from %d credits, want %.2f from 2", pending, len(earned), 2*credit)
+	}
+
+	// Paying out makes room again
+	if err := h.db.Model(&v.model).Update("pending_payouts", 0).Error; err != nil {
+		t.Fatal(err)
+	}
+	recordResult(t, h, website, v, models.StatusGood, 100)
+	if pending, _ := pendingAndEarned(t, h, v); pending != credit {
+		t.Errorf("pending %.2f after a payout and one check, want %.2f", pending, credit)
+	}
+}
@@ -1,21 +1,45 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
-	
-	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
+
+	"github.com/datmedevil17/gopher-uptime/internal/cluster"
 	"github.com/datmedevil17/gopher-uptime/internal/config"
 	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/events"
+	"github.com/datmedevil17/gopher-uptime/internal/incidents"
 	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/notify"
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/datmedevil17/gopher-uptime/internal/signing"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-const COST_PER_VALIDATION = 100 // lamports
+const (
+	// schedulerTick is how often the hub looks for websites due a check, and so
+	// how late past its interval a check can be dispatched
+	schedulerTick = 10 * time.Second
+	// claimSlack ends a website's dispatch claim just before its next check is due
+	claimSlack = time.Second
+)
 
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
@@ -23,18 +47,24 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-type ValidatorConnection struct {
-	ValidatorID string
-	PublicKey   string
-	Conn        *websocket.Conn
-}
-
 type Hub struct {
 	db         *gorm.DB
+	cfg        *config.Config
+	events     events.Bus
+	presence   presence.Store
+	cluster    cluster.Coordinator
+	replay     *replayGuard
 	validators map[string]*ValidatorConnection
 	mu         sync.RWMutex
-	callbacks  map[string]func(IncomingMessage)
+	callbacks  map[string]*pendingTask
+	inFlight   map[string]int // pending tasks per website
 	callbackMu sync.RWMutex
+	weights    validatorWeights
+	notifier   *notify.Dispatcher
+	checks     *checkCache
+	monitor    *monitorState
+
+	assignments *assignmentCounts // tasks assigned per connected validator, for VALIDATOR_SELECTION=fair
 }
 
 type IncomingMessage struct {
@@ -43,19 +73,46 @@ type IncomingMessage struct {
 }
 
 type SignupIncoming struct {
-	IP            string `json:"ip"`
-	PublicKey     string `json:"publicKey"`
-	SignedMessage string `json:"signedMessage"`
-	CallbackID    string `json:"callbackId"`
+	IP              string `json:"ip"`
+	PublicKey       string `json:"publicKey"`
+	SignedMessage   string `json:"signedMessage"`
+	KeyID           string `json:"keyId"`
+	Timestamp       int64  `json:"timestamp"`
+	Nonce           string `json:"nonce"`
+	CallbackID      string `json:"callbackId"`
+	ProtocolVersion int    `json:"protocolVersion"`
 }
 
 type ValidateIncoming struct {
 	CallbackID    string  `json:"callbackId"`
 	Status        string  `json:"status"`
-	Latency       float64 `json:"latency"`
+	Latency       float64 `json:"latency"` // milliseconds; fractions carry sub-millisecond precision
+	Detail        string  `json:"detail"`
+	Protocol      string  `json:"protocol"` // negotiated HTTP protocol; sent by version 6 validators
 	ValidatorID   string  `json:"validatorId"`
 	WebsiteID     string  `json:"websiteId"`
 	SignedMessage string  `json:"signedMessage"`
+	Timestamp     int64   `json:"timestamp"`
+	Nonce         string  `json:"nonce"`
+}
+
+// maxProtocolLength bounds the protocol a validator may report, like the
+// WebsiteTick column
+const maxProtocolLength = 20
+
+// reportedProtocol is the protocol a validator reported, or empty if it doesn't
+// look like one: an HTTP version, or one per family for dual-stack checks
+// ("HTTP/1.1,HTTP/2.0")
+func reportedProtocol(protocol string) string {
+	if protocol == "" || len(protocol) > maxProtocolLength {
+		return ""
+	}
+	for _, proto := range strings.Split(protocol, ",") {
+		if !strings.HasPrefix(proto, "HTTP/") {
+			return ""
+		}
+	}
+	return protocol
 }
 
 type OutgoingMessage struct {
@@ -63,15 +120,33 @@ type OutgoingMessage struct {
 	Data interface{} `json:"data"`
 }
 
-func NewHub(db *gorm.DB) *Hub {
+func NewHub(db *gorm.DB, cfg *config.Config, bus events.Bus, store presence.Store, coordinator cluster.Coordinator) *Hub {
 	return &Hub{
 		db:         db,
+		cfg:        cfg,
+		events:     bus,
+		presence:   store,
+		cluster:    coordinator,
+		replay:     newReplayGuard(cfg.SignatureMaxAge),
 		validators: make(map[string]*ValidatorConnection),
-		callbacks:  make(map[string]func(IncomingMessage)),
+		callbacks:  make(map[string]*pendingTask),
+		inFlight:   make(map[string]int),
+		notifier:   notify.NewDispatcher(db, cfg),
+		checks:     newCheckCache(cfg.CheckReuseWindow),
+		monitor:    newMonitorState(cfg.MonitoringPaused),
+
+		assignments: newAssignmentCounts(),
 	}
 }
 
 func (h *Hub) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Reject before upgrading so unauthenticated clients never hold a connection
+	if !h.authorizeHandshake(r) {
+		log.Printf("❌ Rejected connection from %s: missing or invalid hub token", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("❌ Upgrade error: %v", err)
@@ -81,13 +156,64 @@ func (h *Hub) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("🔌 New WebSocket connection")
 
+	// Idle or oversized connections are dropped; pongs and messages extend the deadline
+	conn.SetReadLimit(h.cfg.HubMaxMessageBytes)
+	conn.SetReadDeadline(time.Now().Add(h.cfg.HubReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(h.cfg.HubReadTimeout))
+	})
+
+	// The validator signs this challenge in its signup
+	challenge := newSignupChallenge()
+	conn.SetWriteDeadline(time.Now().Add(h.cfg.HubWriteTimeout))
+	if err := conn.WriteJSON(OutgoingMessage{
+		Type: "challenge",
+		Data: map[string]interface{}{
+			"challenge":       challenge.value,
+			"protocolVersion": protocol.Version,
+		},
+	}); err != nil {
+		log.Printf("❌ Failed to send signup challenge: %v", err)
+		return
+	}
+
+	limiter := newMessageLimiter(h.cfg.HubMessageRate, h.cfg.HubMessageBurst)
+	var validator *ValidatorConnection // set once the signup is accepted
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("❌ Read error: %v", err)
+			switch {
+			case errors.Is(err, websocket.ErrReadLimit):
+				log.Printf("❌ Message exceeds %d bytes, disconnecting", h.cfg.HubMaxMessageBytes)
+			case isTimeout(err):
+				log.Printf("❌ Connection idle for %s, disconnecting", h.cfg.HubReadTimeout)
+			default:
+				log.Printf("❌ Read error: %v", err)
+			}
 			h.removeValidator(conn)
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(h.cfg.HubReadTimeout))
+
+		if validator == nil {
+			validator = h.validatorForConn(conn)
+		}
+		if validator != nil {
+			validator.touch(time.Now())
+		}
+
+		// Throttle floods before they reach signature checks and the database
+		if !limiter.allow(time.Now()) {
+			if limiter.flooding() {
+				log.Printf("❌ Connection exceeded %.0f messages/s, disconnecting", h.cfg.HubMessageRate)
+				closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "message rate exceeded")
+				conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+				h.removeValidator(conn)
+				return
+			}
+			continue
+		}
 
 		var msg IncomingMessage
 		if err := json.Unmarshal(message, &msg); err != nil {
@@ -97,38 +223,134 @@ func (h *Hub) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 		switch msg.Type {
 		case "signup":
-			h.handleSignup(conn, msg.Data)
+			if !h.negotiateVersion(conn, msg.Data) {
+				h.removeValidator(conn)
+				return
+			}
+			h.handleSignup(conn, msg.Data, challenge)
 		case "validate":
-			h.handleValidate(msg.Data)
+			h.handleValidate(conn, msg.Data)
+		case "status":
+			h.handleStatus(conn, msg.Data)
 		}
 	}
 }
 
-func (h *Hub) handleSignup(conn *websocket.Conn, data json.RawMessage) {
+// negotiateVersion rejects validators speaking an incompatible protocol version,
+// closing the socket with a reason they can log
+func (h *Hub) negotiateVersion(conn *websocket.Conn, data json.RawMessage) bool {
+	var signup SignupIncoming
+	if err := json.Unmarshal(data, &signup); err != nil {
+		// Malformed signups are reported by handleSignup
+		return true
+	}
+	if protocol.Supported(signup.ProtocolVersion) {
+		return true
+	}
+
+	reason := fmt.Sprintf("unsupported protocol version %d (hub supports %d-%d)",
+		protocol.Normalize(signup.ProtocolVersion), protocol.MinVersion, protocol.Version)
+	log.Printf("❌ Rejecting validator %s: %s", signup.PublicKey, reason)
+
+	closeMsg := websocket.FormatCloseMessage(protocol.CloseUnsupportedVersion, reason)
+	if err := conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
+		log.Printf("❌ Failed to send close frame: %v", err)
+	}
+	return false
+}
+
+// rejectSignup closes a connection whose signup could not be authenticated
+func (h *Hub) rejectSignup(conn *websocket.Conn, publicKey, reason string) {
+	log.Printf("❌ Rejecting signup from %s: %s", publicKey, reason)
+
+	closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	if err := conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
+		log.Printf("❌ Failed to send close frame: %v", err)
+	}
+	conn.Close()
+}
+
+func (h *Hub) handleSignup(conn *websocket.Conn, data json.RawMessage, challenge *signupChallenge) {
 	var signup SignupIncoming
 	if err := json.Unmarshal(data, &signup); err != nil {
 		log.Printf("❌ Signup unmarshal error: %v", err)
 		return
 	}
 
-	// TODO: Verify signature using nacl (skipped for brevity)
-	// verified := verifyMessage(...)
+	// Validators predating key IDs always signed with ed25519
+	keyID := signup.KeyID
+	if keyID == "" {
+		derived, err := signing.Ed25519KeyID(signup.PublicKey)
+		if err != nil {
+			h.rejectSignup(conn, signup.PublicKey, err.Error())
+			return
+		}
+		keyID = derived
+	}
+
+	message := protocol.SignupMessage(signup.CallbackID, signup.PublicKey, signup.Timestamp, signup.Nonce)
+	if err := signing.Verify(signing.AlgorithmOf(keyID), signup.PublicKey, []byte(message), signup.SignedMessage); err != nil {
+		h.rejectSignup(conn, signup.PublicKey, "signature verification failed: "+err.Error())
+		return
+	}
+	// A connection registers once. Repeating its signup is answered again instead
+	// of registering it twice; a signup for another key ends the connection.
+	if existing := h.validatorForConn(conn); existing != nil {
+		if existing.PublicKey != signup.PublicKey {
+			h.rejectSignup(conn, signup.PublicKey, "connection already registered as validator "+existing.ValidatorID)
+			return
+		}
+		log.Printf("🔄 Repeated signup from %s on the same connection", existing.ValidatorID)
+		h.confirmSignup(existing, signup.CallbackID)
+		return
+	}
+
+	// Only checked once the signature is valid, so forged messages can't burn the challenge
+	if err := challenge.redeem(signup.Nonce, h.cfg.SignatureMaxAge); err != nil {
+		h.rejectSignup(conn, signup.PublicKey, err.Error())
+		return
+	}
+	if err := h.replay.check(signup.Timestamp, signup.Nonce); err != nil {
+		h.rejectSignup(conn, signup.PublicKey, "replayed signup: "+err.Error())
+		return
+	}
+
+	db, cancel := h.query()
+	defer cancel()
 
 	var validator models.Validator
 
-	// Find or create validator using GORM
-	result := h.db.Where("public_key = ?", signup.PublicKey).First(&validator)
+	// Find or create validator using GORM. Deleted validators are looked up too
+	// so they're turned away rather than colliding on the unique public key.
+	result := db.Unscoped().Where("public_key = ?", signup.PublicKey).First(&validator)
+
+	if result.Error == nil && validator.DeletedAt.Valid {
+		h.rejectSignup(conn, signup.PublicKey, "validator has been deleted")
+		return
+	} else if result.Error == nil && validator.Banned {
+		h.rejectSignup(conn, signup.PublicKey, "validator is banned")
+		return
+	} else if result.Error == gorm.ErrRecordNotFound {
+		full, err := h.registrationsFull(remoteIP(conn))
+		if err != nil {
+			log.Printf("❌ Database error: %v", err)
+			return
+		}
+		if full {
+			h.rejectSignup(conn, signup.PublicKey, "too many validators registered from "+remoteIP(conn))
+			return
+		}
 
-	if result.Error == gorm.ErrRecordNotFound {
 		// Create new validator
 		validator = models.Validator{
 			ID:        uuid.New().String(),
 			PublicKey: signup.PublicKey,
+			KeyID:     keyID,
 			Location:  "unknown",
-			IP:        signup.IP,
+			IP:        remoteIP(conn),
 		}
 
-		if err := h.db.Create(&validator).Error; err != nil {
+		if err := db.Create(&validator).Error; err != nil {
 			log.Printf("❌ Failed to create validator: %v", err)
 			return
 		}
@@ -136,137 +358,289 @@ func (h *Hub) handleSignup(conn *websocket.Conn, data json.RawMessage) {
 	} else if result.Error != nil {
 		log.Printf("❌ Database error: %v", result.Error)
 		return
+	} else if validator.KeyID != keyID {
+		if err := db.Model(&validator).Update("key_id", keyID).Error; err != nil {
+			log.Printf("❌ Failed to update key id: %v", err)
+			return
+		}
 	}
 
 	// Store validator connection
-	h.mu.Lock()
-	h.validators[validator.ID] = &ValidatorConnection{
-		ValidatorID: validator.ID,
-		PublicKey:   validator.PublicKey,
-		Conn:        conn,
+	connection := newValidatorConnection(validator, conn, h.cfg)
+	connection.ProtocolVersion = protocol.Normalize(signup.ProtocolVersion)
+	if reason := h.addValidator(connection); reason != "" {
+		h.rejectSignup(conn, signup.PublicKey, reason)
+		connection.Close()
+		return
 	}
-	h.mu.Unlock()
 
-	// Send response
+	h.registerPresence(connection)
+	h.confirmSignup(connection, signup.CallbackID)
+}
+
+// confirmSignup tells a registered validator its ID
+func (h *Hub) confirmSignup(connection *ValidatorConnection, callbackID string) {
 	response := OutgoingMessage{
 		Type: "signup",
-		Data: map[string]string{
-			"validatorId": validator.ID,
-			"callbackId":  signup.CallbackID,
+		Data: map[string]interface{}{
+			"validatorId":     connection.ValidatorID,
+			"callbackId":      callbackID,
+			"protocolVersion": protocol.Version,
 		},
 	}
 
-	if err := conn.WriteJSON(response); err != nil {
-		log.Printf("❌ Failed to send signup response: %v", err)
+	if !connection.Send(response) {
+		log.Printf("❌ Failed to send signup response to %s", connection.ValidatorID)
 	} else {
-		log.Printf("✅ Validator registered: %s (%s)", validator.ID, validator.PublicKey)
+		log.Printf("✅ Validator registered: %s (%s)", connection.ValidatorID, connection.PublicKey)
 	}
 }
 
-func (h *Hub) handleValidate(data json.RawMessage) {
+func (h *Hub) handleValidate(conn *websocket.Conn, data json.RawMessage) {
+	sender := h.validatorForConn(conn)
+	if sender == nil {
+		log.Println("⚠️  Validate result before signup, ignoring")
+		return
+	}
+
 	var validate ValidateIncoming
 	if err := json.Unmarshal(data, &validate); err != nil {
 		log.Printf("❌ Validate unmarshal error: %v", err)
 		return
 	}
 
-	// Execute callback
-	h.callbackMu.RLock()
-	callback, exists := h.callbacks[validate.CallbackID]
-	h.callbackMu.RUnlock()
-
-	if exists {
-		var msg IncomingMessage
-		msg.Type = "validate"
-		msg.Data = data
-		callback(msg)
+	if validate.ValidatorID != sender.ValidatorID {
+		log.Printf("❌ Rejected result for %s from %s: claims to be validator %s",
+			validate.CallbackID, sender.ValidatorID, validate.ValidatorID)
+		return
+	}
 
-		// Remove callback after execution
-		h.callbackMu.Lock()
-		delete(h.callbacks, validate.CallbackID)
-		h.callbackMu.Unlock()
+	// Execute callback; taking it first frees the website's in-flight slot
+	task, err := h.takeTask(validate.CallbackID, sender.ValidatorID)
+	if err != nil {
+		// Late replies to expired tasks are expected; completing someone else's isn't
+		if errors.Is(err, errTaskNotAssigned) {
+			log.Printf("❌ Rejected result for %s from %s: %v", validate.CallbackID, sender.ValidatorID, err)
+		}
+		return
 	}
+
+	var msg IncomingMessage
+	msg.Type = "validate"
+	msg.Data = data
+	task.handle(msg)
 }
 
 func (h *Hub) removeValidator(conn *websocket.Conn) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-
+	var removed *ValidatorConnection
 	for id, validator := range h.validators {
 		if validator.Conn == conn {
 			delete(h.validators, id)
-			log.Printf("🔌 Validator disconnected: %s", id)
+			removed = validator
 			break
 		}
 	}
+	h.mu.Unlock()
+
+	if removed != nil {
+		removed.Close()
+		h.assignments.remove(removed.ValidatorID)
+		h.deregisterPresence(removed.ValidatorID)
+		log.Printf("🔌 Validator disconnected: %s", removed.ValidatorID)
+	}
 }
 
-func (h *Hub) startMonitoring() {
-	ticker := time.NewTicker(60 * time.Second)
+// startMonitoring runs a monitoring cycle every schedulerTick until ctx is done,
+// skipping them while monitoring is paused
+func (h *Hub) startMonitoring(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
 	defer ticker.Stop()
 
-	log.Println("🔄 Starting monitoring loop (every 60 seconds)")
-
-	for range ticker.C {
-		var websites []models.Website
+	log.Printf("🔄 Starting monitoring loop (every %s by default)", h.cfg.CheckInterval)
+	if h.monitor.paused() {
+		log.Println("⚠️  Monitoring is paused, no scheduled checks will be dispatched")
+	}
 
-		// Fetch all active websites using GORM
-		if err := h.db.Where("disabled = ?", false).Find(&websites).Error; err != nil {
-			log.Printf("❌ Failed to fetch websites: %v", err)
-			continue
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🔌 Monitoring loop stopped")
+			return
+		case <-ticker.C:
+			h.monitorTick()
 		}
+	}
+}
 
-		if len(websites) == 0 {
-			log.Println("⚠️  No websites to monitor")
-			continue
-		}
+// monitorTick runs a monitoring cycle unless monitoring is paused, reporting
+// whether it did
+func (h *Hub) monitorTick() bool {
+	if h.monitor.paused() {
+		return false
+	}
+	h.monitorCycle()
+	return true
+}
 
-		// Get current validators
-		h.mu.RLock()
-		validators := make([]*ValidatorConnection, 0, len(h.validators))
-		for _, v := range h.validators {
-			validators = append(validators, v)
-		}
-		h.mu.RUnlock()
+// monitorCycle dispatches the websites this hub owns that are due a check
+func (h *Hub) monitorCycle() {
+	started := time.Now()
+	var websites []models.Website
 
-		if len(validators) == 0 {
-			log.Println("⚠️  No validators connected")
-			continue
-		}
+	// Fetch all active websites using GORM (soft-deleted rows are excluded)
+	db, cancel := h.query()
+	err := db.Find(&websites).Error
+	cancel()
+	if err != nil {
+		log.Printf("❌ Failed to fetch websites: %v", err)
+		return
+	}
 
-		log.Printf("📊 Monitoring %d websites with %d validators", len(websites), len(validators))
+	if len(websites) == 0 {
+		log.Println("⚠️  No websites to monitor")
+		h.monitor.record(started, 0, 0, 0, 0)
+		return
+	}
 
-		// Send validation tasks
-		for _, website := range websites {
-			for _, validator := range validators {
-				callbackID := uuid.New().String()
+	validators := h.dropBanned(h.connectedValidators())
+	if len(validators) == 0 {
+		log.Println("⚠️  No validators connected")
+		h.monitor.record(started, len(websites), 0, 0, 0)
+		return
+	}
 
-				// Register callback
-				h.callbackMu.Lock()
-				h.callbacks[callbackID] = h.createValidateCallback(website.ID, validator.PublicKey)
-				h.callbackMu.Unlock()
+	if h.cfg.ValidatorSelection == selectionWeighted {
+		h.refreshWeights()
+	}
 
-				// Send validation request
-				msg := OutgoingMessage{
-					Type: "validate",
-					Data: map[string]interface{}{
-						"url":        website.URL,
-						"callbackId": callbackID,
-						"websiteId":  website.ID,
-					},
-				}
+	// Only dispatch the websites this hub owns so each is checked once cluster-wide.
+	// The claim lasts one check interval, so it also holds back websites that
+	// aren't due yet.
+	hubs := h.activeHubs()
+	dispatched := h.dispatchAll(websites, validators, hubs)
+	h.monitor.record(started, len(websites), dispatched, len(validators), len(hubs))
 
-				if err := validator.Conn.WriteJSON(msg); err != nil {
-					log.Printf("❌ Failed to send to validator %s: %v", validator.ValidatorID, err)
-				} else {
-					log.Printf("📤 Sent validation task: %s to %s", website.URL, validator.ValidatorID)
+	if dispatched > 0 {
+		log.Printf("📊 Monitored %d/%d websites with %d validators (%d hubs active)",
+			dispatched, len(websites), len(validators), len(hubs))
+	}
+}
+
+// dispatchAll claims and dispatches websites with HUB_DISPATCH_WORKERS workers,
+// so claim round trips don't add up over a cycle, and returns how many were
+// dispatched. Writes to each validator stay serialized by its send queue.
+func (h *Hub) dispatchAll(websites []models.Website, validators []*ValidatorConnection, hubs []string) int {
+	jobs := make(chan models.Website)
+	var dispatched atomic.Int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < max(h.cfg.DispatchWorkers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for website := range jobs {
+				// Leave websites none of our validators may check to a hub that has some
+				if len(eligible(website, validators)) == 0 {
+					continue
+				}
+				if !h.claimWebsite(website.ID, hubs, website.CheckInterval(h.cfg.CheckInterval)-claimSlack) {
+					continue
 				}
+				// Just checked on demand; those results stand in for this round
+				if _, ok := h.checks.fresh(website, time.Now()); ok {
+					continue
+				}
+				h.dispatchWebsite(website, h.selectValidators(website, validators))
+				dispatched.Add(1)
 			}
+		}()
+	}
+
+	for _, website := range websites {
+		jobs <- website
+	}
+	close(jobs)
+	wg.Wait()
+	return int(dispatched.Load())
+}
+
+// connectedValidators snapshots the currently registered validators
+func (h *Hub) connectedValidators() []*ValidatorConnection {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	validators := make([]*ValidatorConnection, 0, len(h.validators))
+	for _, v := range h.validators {
+		validators = append(validators, v)
+	}
+	return validators
+}
+
+// dispatchWebsite sends a validation task for website to each validator and returns
+// how many were sent successfully. Validators come already counted as assigned,
+// and those the task isn't sent to are released.
+func (h *Hub) dispatchWebsite(website models.Website, validators []*ValidatorConnection) int {
+	if required := website.RequiredValidators(h.cfg.MinValidators); len(validators) < required {
+		if len(website.Regions) > 0 {
+			log.Printf("⚠️  Insufficient coverage for %s: %d validators available in %s, %d required",
+				website.URL, len(validators), strings.Join(website.Regions, ", "), required)
+		} else {
+			log.Printf("⚠️  Insufficient coverage for %s: %d validators available, %d required",
+				website.URL, len(validators), required)
+		}
+	}
+
+	dispatchedAt := time.Now()
+	sent := 0
+	for i, validator := range validators {
+		callbackID := uuid.New().String()
+		nonce := protocol.NewNonce()
+
+		// Register callback, unless the website already has too many checks pending
+		if !h.registerTask(callbackID, website.ID, validator.ValidatorID, h.taskTimeout(website), h.createValidateCallback(website, validator, nonce)) {
+			log.Printf("⚠️  %s has %d checks in flight, skipping remaining validators",
+				website.URL, h.cfg.MaxInFlightPerWebsite)
+			h.assignments.release(validators[i:])
+			break
+		}
+
+		// Send validation request
+		msg := OutgoingMessage{
+			Type: "validate",
+			Data: map[string]interface{}{
+				"url":           website.URL,
+				"callbackId":    callbackID,
+				"websiteId":     website.ID,
+				"assertions":    website.Assertions,
+				"steps":         website.Steps,
+				"addressFamily": website.AddressFamily,
+				"httpVersion":   website.HTTPVersion,
+				"timeoutMs":     website.TimeoutMs,
+				"nonce":         nonce,
+			},
+		}
+
+		// Queued rather than written inline so one slow validator can't stall the others
+		if !validator.Send(msg) {
+			log.Printf("❌ Failed to queue task for validator %s", validator.ValidatorID)
+			h.takeTask(callbackID, validator.ValidatorID)
+			h.assignments.release(validators[i : i+1])
+		} else {
+			sent++
+			log.Printf("📤 Sent validation task: %s to %s", website.URL, validator.ValidatorID)
 		}
 	}
+
+	h.checks.store(website, dispatchedCheck{at: dispatchedAt, sent: sent})
+	return sent
 }
 
-func (h *Hub) createValidateCallback(websiteID, validatorPublicKey string) func(IncomingMessage) {
+// createValidateCallback handles the reply to a single task; nonce is the value
+// sent with the task, which the validator must sign back
+func (h *Hub) createValidateCallback(website models.Website, validator *ValidatorConnection, nonce string) func(IncomingMessage) {
+	websiteID := website.ID
+
 	return func(msg IncomingMessage) {
 		var validate ValidateIncoming
 		if err := json.Unmarshal(msg.Data, &validate); err != nil {
@@ -274,10 +648,43 @@ func (h *Hub) createValidateCallback(websiteID, validatorPublicKey string) func(
 			return
 		}
 
-		// TODO: Verify signature
+		// Slow but successful checks are Degraded rather than Good
+		if validate.Status == models.StatusGood && website.LatencyThresholdMs > 0 &&
+			validate.Latency > float64(website.LatencyThresholdMs) {
+			validate.Status = models.StatusDegraded
+		}
+
+		if validate.Nonce != nonce {
+			log.Printf("❌ Rejected result from %s for %s: nonce mismatch", validator.ValidatorID, websiteID)
+			return
+		}
+
+		message := protocol.ValidateMessage(validate.CallbackID, validate.Timestamp, validate.Nonce)
+		if err := signing.Verify(signing.AlgorithmOf(validator.KeyID), validator.PublicKey, []byte(message), validate.SignedMessage); err != nil {
+			log.Printf("❌ Rejected result from %s for %s: %v", validator.ValidatorID, websiteID, err)
+			return
+		}
+		if err := h.replay.check(validate.Timestamp, validate.Nonce); err != nil {
+			log.Printf("❌ Rejected result from %s for %s: %v", validator.ValidatorID, websiteID, err)
+			return
+		}
+
+		// Absurd latencies would skew every average they're part of
+		maxLatency := float64(h.cfg.MaxReportedLatency.Milliseconds())
+		if validate.Latency < 0 || validate.Latency > maxLatency {
+			log.Printf("❌ Rejected result from %s for %s: latency %.0fms outside 0-%.0fms",
+				validator.ValidatorID, websiteID, validate.Latency, maxLatency)
+			return
+		}
+
+		// Credit the validator the task was sent to, not whatever ID the message claims
+		validate.ValidatorID = validator.ValidatorID
+
+		db, cancel := h.query()
+		defer cancel()
 
 		// Use GORM transaction
-		tx := h.db.Begin()
+		tx := db.Begin()
 		defer func() {
 			if r := recover(); r != nil {
 				tx.Rollback()
@@ -291,22 +698,72 @@ func (h *Hub) createValidateCallback(websiteID, validatorPublicKey string) func(
 			ValidatorID: validate.ValidatorID,
 			Status:      validate.Status,
 			Latency:     validate.Latency,
+			Detail:      validate.Detail,
+			Protocol:    reportedProtocol(validate.Protocol),
 			CreatedAt:   time.Now(),
+			CallbackID:  &validate.CallbackID,
 		}
 
-		if err := tx.Create(&tick).Error; err != nil {
+		// A result seen before (redelivered, or replayed to another hub) is a no-op,
+		// so the validator is never credited twice for one task
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tick)
+		if result.Error != nil {
+			tx.Rollback()
+			log.Printf("❌ Failed to create tick: %v", result.Error)
+			return
+		}
+		if result.RowsAffected == 0 {
 			tx.Rollback()
-			log.Printf("❌ Failed to create tick: %v", err)
+			log.Printf("⚠️  Ignoring duplicate result %s from %s", validate.CallbackID, validate.ValidatorID)
 			return
 		}
 
-		// Update validator pending payouts
-		if err := tx.Model(&models.Validator{}).
-			Where("id = ?", validate.ValidatorID).
-			UpdateColumn("pending_payouts", gorm.Expr("pending_payouts + ?", COST_PER_VALIDATION)).
-			Error; err != nil {
+		tenure := tick.CreatedAt.Sub(validator.RegisteredAt)
+		credit := models.ValidationCredit(tenure, h.cfg.LongevityBonusPerMonth, h.cfg.LongevityBonusMax)
+		if h.cfg.MaxPendingPayout > 0 {
+			capped, err := capCredit(tx, validate.ValidatorID, credit, h.cfg.MaxPendingPayout)
+			if err != nil {
+				tx.Rollback()
+				log.Printf("❌ Failed to read pending payouts: %v", err)
+				return
+			}
+			if capped < credit {
+				log.Printf("💰 Validator %s reached MAX_PENDING_PAYOUT, credited %.2f of %.2f", validate.ValidatorID, capped, credit)
+			}
+			credit = capped
+		}
+
+		// Record the credit in the ledger so lifetime earnings survive payouts
+		entry := models.EarningsLedger{
+			ID:          uuid.New().String(),
+			ValidatorID: validate.ValidatorID,
+			WebsiteID:   websiteID,
+			TickID:      tick.ID,
+			Amount:      credit,
+			CreatedAt:   tick.CreatedAt,
+		}
+
+		if credit > 0 {
+			if err := tx.Create(&entry).Error; err != nil {
+				tx.Rollback()
+				log.Printf("❌ Failed to record earnings: %v", err)
+				return
+			}
+		}
+
+		// Update validator pending payouts; a validator banned since the task was
+		// sent earns nothing and its result is dropped
+		result = tx.Model(&models.Validator{}).
+			Where("id = ? AND banned = ?", validate.ValidatorID, false).
+			UpdateColumn("pending_payouts", gorm.Expr("pending_payouts + ?", entry.Amount))
+		if result.Error != nil {
+			tx.Rollback()
+			log.Printf("❌ Failed to update payouts: %v", result.Error)
+			return
+		}
+		if result.RowsAffected == 0 {
 			tx.Rollback()
-			log.Printf("❌ Failed to update payouts: %v", err)
+			log.Printf("⚠️  Ignoring result %s from banned validator %s", validate.CallbackID, validate.ValidatorID)
 			return
 		}
 
@@ -317,6 +774,83 @@ func (h *Hub) createValidateCallback(websiteID, validatorPublicKey string) func(
 		}
 
 		log.Printf("✅ Tick recorded: %s - %s (%s)", websiteID, validate.Status, validate.ValidatorID)
+
+		h.publish(events.Event{
+			Type:        events.TypeTickRecorded,
+			WebsiteID:   websiteID,
+			OccurredAt:  tick.CreatedAt,
+			TickID:      tick.ID,
+			ValidatorID: tick.ValidatorID,
+			Status:      tick.Status,
+			Latency:     tick.Latency,
+			Detail:      tick.Detail,
+		})
+
+		h.updateIncident(website, tick)
+	}
+}
+
+// updateIncident opens, escalates or resolves website's incident after tick is
+// recorded
+func (h *Hub) updateIncident(website models.Website, tick models.WebsiteTick) {
+	db, cancel := h.query()
+	defer cancel()
+
+	change, err := incidents.Update(db, website, h.cfg.MinValidators, incidents.Thresholds{
+		OpenChecks:            h.cfg.IncidentOpenChecks,
+		ResolveChecks:         h.cfg.IncidentResolveChecks,
+		DegradedOpenChecks:    h.cfg.DegradedOpenChecks,
+		DegradedResolveChecks: h.cfg.DegradedResolveChecks,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update incident for %s: %v", website.URL, err)
+		return
+	}
+	if change.Opened != nil {
+		if change.Opened.Severity == models.StatusDegraded {
+			log.Printf("🐢 Degraded incident opened for %s: %s", website.URL, change.Opened.Cause)
+		} else {
+			log.Printf("🚨 Incident opened for %s: %s", website.URL, change.Opened.Cause)
+		}
+		h.alertIncident(db, website, change.Opened, tick)
+	}
+	if change.Escalated != nil {
+		log.Printf("🚨 Degraded incident for %s escalated to an outage: %s", website.URL, change.Escalated.Cause)
+		h.alertIncident(db, website, change.Escalated, tick)
+	}
+	if change.Resolved != nil {
+		log.Printf("✅ Incident resolved for %s after %s", website.URL,
+			change.Resolved.Duration(time.Now()).Round(time.Second))
+		// Only announce recoveries of outages that were announced
+		if change.Resolved.Notified {
+			go h.notifier.Notify(website, notify.IncidentMessage(website, *change.Resolved, tick))
+		}
+	}
+}
+
+// alertIncident notifies website's owner of an opened or escalated incident,
+// unless the website's alert cooldown holds it back
+func (h *Hub) alertIncident(db *gorm.DB, website models.Website, incident *models.Incident, tick models.WebsiteTick) {
+	alert, err := incidents.AlertOpened(db, incident, website.AlertCooldown(h.cfg.AlertCooldown))
+	if err != nil {
+		log.Printf("❌ Failed to record alert for %s: %v", website.URL, err)
+	} else if !alert {
+		log.Printf("🔇 Not alerting on %s: within its alert cooldown", website.URL)
+	} else {
+		go h.notifier.Notify(website, notify.IncidentMessage(website, *incident, tick))
+	}
+}
+
+// query scopes database work to DB_QUERY_TIMEOUT so a stuck database can't
+// wedge a connection handler or loop
+func (h *Hub) query() (*gorm.DB, context.CancelFunc) {
+	return database.WithTimeout(context.Background(), h.db, h.cfg.DBQueryTimeout)
+}
+
+// publish sends an event to API subscribers; failures are logged, never fatal
+func (h *Hub) publish(event events.Event) {
+	if err := h.events.Publish(context.Background(), event); err != nil {
+		log.Printf("⚠️  Failed to publish %s event: %v", event.Type, err)
 	}
 }
 
@@ -324,27 +858,174 @@ func main() {
 	cfg := config.Load()
 
 	// Connect to database
-	db, err := database.Connect(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.DatabaseURL, database.LogOptions{
+		Level:         cfg.DBLogLevel,
+		SlowThreshold: cfg.DBSlowThreshold,
+	})
 	if err != nil {
 		log.Fatal("❌ Database connection failed:", err)
 	}
 
 	// Run migrations
-	if err := database.AutoMigrate(db); err != nil {
+	if err := database.Migrate(db); err != nil {
 		log.Fatal("❌ Migration failed:", err)
 	}
+	if cfg.DBAutoMigrate {
+		if err := database.AutoMigrate(db); err != nil {
+			log.Fatal("❌ Auto-migration failed:", err)
+		}
+	}
+
+	if cfg.TaskTimeoutMargin <= 0 {
+		cfg.TaskTimeoutMargin = 30 * time.Second
+		log.Printf("⚠️  TASK_TIMEOUT_MARGIN must be positive, using %s", cfg.TaskTimeoutMargin)
+	}
+
+	if cfg.DispatchWorkers < 1 {
+		cfg.DispatchWorkers = 1
+		log.Printf("⚠️  HUB_DISPATCH_WORKERS must be at least 1, using %d", cfg.DispatchWorkers)
+	}
+
+	if cfg.MaxReportedLatency <= 0 {
+		cfg.MaxReportedLatency = time.Minute
+		log.Printf("⚠️  MAX_REPORTED_LATENCY must be positive, using %s", cfg.MaxReportedLatency)
+	}
+
+	if cfg.CheckInterval < schedulerTick {
+		cfg.CheckInterval = time.Minute
+		log.Printf("⚠️  CHECK_INTERVAL must be at least %s, using %s", schedulerTick, cfg.CheckInterval)
+	}
+
+	if cfg.AlertCooldown < 0 {
+		cfg.AlertCooldown = 0
+		log.Printf("⚠️  ALERT_COOLDOWN can't be negative, disabling the alert cooldown")
+	}
+
+	if cfg.IncidentOpenChecks < 1 {
+		cfg.IncidentOpenChecks = 1
+		log.Printf("⚠️  INCIDENT_OPEN_CHECKS must be at least 1, using %d", cfg.IncidentOpenChecks)
+	}
+	if cfg.IncidentResolveChecks < 1 {
+		cfg.IncidentResolveChecks = 1
+		log.Printf("⚠️  INCIDENT_RESOLVE_CHECKS must be at least 1, using %d", cfg.IncidentResolveChecks)
+	}
+	if cfg.DegradedOpenChecks < 0 {
+		cfg.DegradedOpenChecks = 0
+		log.Printf("⚠️  DEGRADED_OPEN_CHECKS can't be negative, disabling Degraded incidents")
+	}
+	if cfg.DegradedResolveChecks < 1 {
+		cfg.DegradedResolveChecks = 1
+		log.Printf("⚠️  DEGRADED_RESOLVE_CHECKS must be at least 1, using %d", cfg.DegradedResolveChecks)
+	}
+
+	if cfg.RollupInterval <= 0 {
+		cfg.RollupInterval = 5 * time.Minute
+		log.Printf("⚠️  ROLLUP_INTERVAL must be positive, using %s", cfg.RollupInterval)
+	}
+
+	if cfg.LongevityBonusPerMonth < 0 || cfg.LongevityBonusMax < 0 {
+		log.Printf("⚠️  LONGEVITY_BONUS_PER_MONTH and LONGEVITY_BONUS_MAX can't be negative, disabling the longevity bonus")
+		cfg.LongevityBonusPerMonth = 0
+	}
+
+	if cfg.ValidatorSelection != selectionRandom && cfg.ValidatorSelection != selectionWeighted && cfg.ValidatorSelection != selectionFair {
+		log.Printf("⚠️  Invalid VALIDATOR_SELECTION=%q, using default %s", cfg.ValidatorSelection, selectionRandom)
+		cfg.ValidatorSelection = selectionRandom
+	}
+	if cfg.ValidatorMinWeight <= 0 || cfg.ValidatorMinWeight > 1 {
+		log.Printf("⚠️  VALIDATOR_MIN_WEIGHT must be in (0, 1], using 0.05")
+		cfg.ValidatorMinWeight = 0.05
+	}
+
+	// Pings must arrive before the read deadline or idle validators get dropped
+	if cfg.HubPingInterval <= 0 || cfg.HubPingInterval >= cfg.HubReadTimeout {
+		cfg.HubPingInterval = cfg.HubReadTimeout * 9 / 10
+		log.Printf("⚠️  HUB_PING_INTERVAL must be below HUB_READ_TIMEOUT, using %s", cfg.HubPingInterval)
+	}
+
+	// Connect to the event bus shared with the API
+	bus, err := events.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Event bus initialization failed:", err)
+	}
+	defer bus.Close()
+
+	// Shared validator presence (Redis when REDIS_URL is set)
+	store, err := presence.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Presence store initialization failed:", err)
+	}
+	defer store.Close()
+
+	// Dispatch coordination between hub instances (Redis when REDIS_URL is set)
+	coordinator, err := cluster.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Cluster coordinator initialization failed:", err)
+	}
+	defer coordinator.Close()
 
 	// Create hub
-	hub := NewHub(db)
+	hub := NewHub(db, cfg, bus, store, coordinator)
 
 	// Setup HTTP handler
 	http.HandleFunc("/", hub.handleWebSocket)
+	http.HandleFunc("/admin/validators", hub.requireAdmin(hub.handleListValidators))
+	http.HandleFunc("/admin/monitoring", hub.requireAdmin(hub.handleMonitoring))
+
+	// Stopped by SIGINT/SIGTERM: the server stops accepting connections and the
+	// monitoring loop finishes its current cycle before the hub exits
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Start monitoring in background
-	go hub.startMonitoring()
+	monitoring := make(chan struct{})
+	go func() {
+		hub.startMonitoring(ctx)
+		close(monitoring)
+	}()
+	go hub.pollCheckTriggers()
+	go hub.heartbeatPresence()
+	go hub.expireTasks()
+	go hub.refreshRollups()
 
 	// Start server
 	port := "8081"
+	server := &http.Server{Addr: ":" + port}
+	go func() {
+		<-ctx.Done()
+		log.Println("🔌 Shutting down hub...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("❌ Hub server shutdown: %v", err)
+		}
+	}()
+
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		log.Fatal("❌ Hub server failed:", err)
+	}
 	log.Printf("🚀 Hub server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
-}
\ No newline at end of file
+	if err := serve(server, ln, cfg); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal("❌ Hub server failed:", err)
+	}
+
+	<-monitoring
+	log.Println("✅ Hub stopped")
+}
+
+// serve runs server on ln until it's shut down, over TLS (wss) when a
+// certificate is configured
+func serve(server *http.Server, ln net.Listener, cfg *config.Config) error {
+	if cfg.HubTLSCertFile == "" && cfg.HubTLSKeyFile == "" {
+		return server.Serve(ln)
+	}
+	if cfg.HubTLSCertFile == "" || cfg.HubTLSKeyFile == "" {
+		ln.Close()
+		return errors.New("HUB_TLS_CERT_FILE and HUB_TLS_KEY_FILE must be set together")
+	}
+
+	log.Println("🔒 Serving wss")
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	return server.ServeTLS(ln, cfg.HubTLSCertFile, cfg.HubTLSKeyFile)
+}
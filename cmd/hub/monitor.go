@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+)
+
+// MonitorStatus is the state of the hub's monitoring loop
+type MonitorStatus struct {
+	Paused     bool       `json:"paused"`
+	LastCycle  *time.Time `json:"last_cycle"` // start of the last cycle; none run while paused
+	Websites   int        `json:"websites"`   // active websites seen by the last cycle
+	Dispatched int        `json:"dispatched"` // websites this hub sent checks for in the last cycle
+	Validators int        `json:"validators"` // validators the last cycle could choose from
+	Hubs       int        `json:"hubs"`       // hubs sharing the websites in the last cycle
+}
+
+// monitorState is shared by the monitoring loop and the admin API
+type monitorState struct {
+	mu     sync.Mutex
+	status MonitorStatus
+}
+
+func newMonitorState(paused bool) *monitorState {
+	return &monitorState{status: MonitorStatus{Paused: paused}}
+}
+
+func (m *monitorState) paused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status.Paused
+}
+
+// setPaused reports whether the loop was paused before
+func (m *monitorState) setPaused(paused bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	was := m.status.Paused
+	m.status.Paused = paused
+	return was
+}
+
+// record stores what a cycle started at `at` did
+func (m *monitorState) record(at time.Time, websites, dispatched, validators, hubs int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.LastCycle = &at
+	m.status.Websites = websites
+	m.status.Dispatched = dispatched
+	m.status.Validators = validators
+	m.status.Hubs = hubs
+}
+
+func (m *monitorState) snapshot() MonitorStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// MonitoringRequest pauses or resumes the monitoring loop
+type MonitoringRequest struct {
+	Paused *bool `json:"paused"`
+}
+
+// handleMonitoring - GET, PUT /admin/monitoring
+// Reports the monitoring loop's last cycle; PUT {"paused": true|false} stops or
+// restarts scheduled dispatch on this hub until it restarts.
+func (h *Hub) handleMonitoring(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req MonitoringRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil || req.Paused == nil {
+			writeJSON(w, http.StatusBadRequest, utils.Response{Error: `Body must be {"paused": true|false}`, Code: utils.CodeInvalidRequest})
+			return
+		}
+		if was := h.monitor.setPaused(*req.Paused); was != *req.Paused {
+			if *req.Paused {
+				log.Println("⚠️  Monitoring paused by admin")
+			} else {
+				log.Println("✅ Monitoring resumed by admin")
+			}
+		}
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, utils.Response{Error: "Method not allowed", Code: utils.CodeInvalidRequest})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, utils.Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"hub_id":     h.cfg.HubID,
+			"monitoring": h.monitor.snapshot(),
+		},
+	})
+}
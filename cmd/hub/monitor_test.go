@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/cluster"
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+)
+
+// callMonitoring sends body to the monitoring admin endpoint with method
+func callMonitoring(t *testing.T, h *Hub, method, body string) (int, adminResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/admin/monitoring", strings.NewReader(body))
+	req.Header.Set("X-Admin-Token", testAdminToken)
+	w := httptest.NewRecorder()
+	h.requireAdmin(h.handleMonitoring)(w, req)
+
+	var resp adminResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// setPaused pauses or resumes h's monitoring through the admin API and returns
+// the state it reports
+func setPaused(t *testing.T, h *Hub, paused bool) MonitorStatus {
+	t.Helper()
+
+	body := `{"paused": false}`
+	if paused {
+		body = `{"paused": true}`
+	}
+	status, resp := callMonitoring(t, h, http.MethodPut, body)
+	if status != http.StatusOK {
+		t.Fatalf("setting paused %v: status %d (%s)", paused, status, resp.Code)
+	}
+	var data struct {
+		Monitoring MonitorStatus `json:"monitoring"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data.Monitoring
+}
+
+func TestPauseStopsDispatch(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.AdminToken = testAdminToken
+		cfg.MonitoringPaused = false
+	})
+	connectQueued(t, h, 1)
+	website := createWebsite(t, h.db, models.Website{})
+
+	if state := setPaused(t, h, true); !state.Paused {
+		t.Fatal("monitoring not reported paused")
+	}
+	for i := 0; i < 3; i++ {
+		if h.monitorTick() {
+			t.Fatal("a cycle ran while paused")
+		}
+	}
+	if queued := queuedTasks(h); len(queued) != 0 {
+		t.Fatalf("paused hub sent %v", queued)
+	}
+	if state := h.monitor.snapshot(); state.LastCycle != nil {
+		t.Errorf("last cycle %v while paused, want none", state.LastCycle)
+	}
+
+	if state := setPaused(t, h, false); state.Paused {
+		t.Fatal("monitoring still reported paused")
+	}
+	started := time.Now()
+	if !h.monitorTick() {
+		t.Fatal("no cycle ran after resuming")
+	}
+	queued := queuedTasks(h)
+	if len(queued) != 1 {
+		t.Fatalf("resumed hub sent tasks to %d validators, want 1", len(queued))
+	}
+	for _, tasks := range queued {
+		if len(tasks) != 1 || tasks[0]["websiteId"] != website.ID {
+			t.Errorf("sent %v, want one task for %s", tasks, website.ID)
+		}
+	}
+
+	state := h.monitor.snapshot()
+	if state.LastCycle == nil || state.LastCycle.Before(started) {
+		t.Errorf("last cycle %v, want the one just run", state.LastCycle)
+	}
+	if state.Websites != 1 || state.Dispatched != 1 || state.Validators != 1 || state.Hubs != 1 {
+		t.Errorf("cycle recorded as %+v, want 1 website dispatched to 1 validator on 1 hub", state)
+	}
+}
+
+func TestMonitoringPausedAtStartup(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.AdminToken = testAdminToken
+		cfg.MonitoringPaused = true
+	})
+	connectQueued(t, h, 1)
+	createWebsite(t, h.db, models.Website{})
+
+	status, resp := callMonitoring(t, h, http.MethodGet, "")
+	var data struct {
+		HubID      string        `json:"hub_id"`
+		Monitoring MonitorStatus `json:"monitoring"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || status != http.StatusOK {
+		t.Fatalf("status %d (%s)", status, resp.Code)
+	}
+	if !data.Monitoring.Paused || data.HubID != h.cfg.HubID {
+		t.Errorf("hub %s reported %+v, want hub %s paused", data.HubID, data.Monitoring, h.cfg.HubID)
+	}
+	if h.monitorTick() || len(queuedTasks(h)) != 0 {
+		t.Error("hub started paused dispatched checks")
+	}
+}
+
+func TestHandleMonitoringErrors(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.AdminToken = testAdminToken })
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{"missing flag", http.MethodPut, `{}`, http.StatusBadRequest},
+		{"not a boolean", http.MethodPut, `{"paused": "yes"}`, http.StatusBadRequest},
+		{"malformed", http.MethodPut, `{"paused":`, http.StatusBadRequest},
+		{"wrong method", http.MethodPost, `{"paused": true}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := callMonitoring(t, h, tt.method, tt.body)
+			if status != tt.wantStatus || resp.Code != utils.CodeInvalidRequest {
+				t.Errorf("status = %d, code = %s, want %d %s", status, resp.Code, tt.wantStatus, utils.CodeInvalidRequest)
+			}
+			if h.monitor.paused() {
+				t.Error("a rejected request paused monitoring")
+			}
+		})
+	}
+}
+
+func TestStartMonitoringStops(t *testing.T) {
+	h := newTestHub(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := make(chan struct{})
+	go func() {
+		h.startMonitoring(ctx)
+		close(stopped)
+	}()
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitoring loop still running after its context was cancelled")
+	}
+}
+
+// slowCoordinator claims like the in-memory coordinator after a delay, standing
+// in for the Redis round trip of each claim
+type slowCoordinator struct {
+	cluster.Coordinator
+	delay time.Duration
+}
+
+func (c slowCoordinator) Claim(ctx context.Context, websiteID string, ttl time.Duration) (bool, error) {
+	time.Sleep(c.delay)
+	return c.Coordinator.Claim(ctx, websiteID, ttl)
+}
+
+// timeDispatch dispatches websites to two validators with workers and returns
+// how long it took, failing t unless each validator got every website once
+func timeDispatch(t *testing.T, workers, websites int) time.Duration {
+	t.Helper()
+
+	h := newTestHub(t, func(cfg *config.Config) { cfg.DispatchWorkers = workers })
+	h.cluster = slowCoordinator{Coordinator: cluster.NewMemoryCoordinator(), delay: 10 * time.Millisecond}
+	connectQueued(t, h, 2)
+	all := make([]models.Website, websites)
+	for i := range all {
+		all[i] = createWebsite(t, h.db, models.Website{})
+	}
+
+	started := time.Now()
+	dispatched := h.dispatchAll(all, h.connectedValidators(), []string{h.cfg.HubID})
+	elapsed := time.Since(started)
+
+	if dispatched != websites {
+		t.Fatalf("%d workers dispatched %d websites, want %d", workers, dispatched, websites)
+	}
+	for validatorID, tasks := range queuedTasks(h) {
+		seen := map[interface{}]bool{}
+		for _, task := range tasks {
+			seen[task["websiteId"]] = true
+		}
+		if len(tasks) != websites || len(seen) != websites {
+			t.Errorf("validator %s got %d tasks for %d websites, want one for each of %d", validatorID, len(tasks), len(seen), websites)
+		}
+	}
+	return elapsed
+}
+
+func TestDispatchWorkersSpeedUpLargeFanOut(t *testing.T) {
+	const websites = 30
+
+	sequential := timeDispatch(t, 1, websites)
+	pooled := timeDispatch(t, 8, websites)
+	if pooled > sequential/2 {
+		t.Errorf("8 workers took %s, one took %s; want the pool at least twice as fast", pooled, sequential)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
+)
+
+// registerPresence publishes a newly connected validator to the shared presence store
+func (h *Hub) registerPresence(v *ValidatorConnection) {
+	if err := h.presence.Register(context.Background(), presence.Entry{
+		ValidatorID: v.ValidatorID,
+		PublicKey:   v.PublicKey,
+		HubID:       h.cfg.HubID,
+		ConnectedAt: v.ConnectedAt,
+	}); err != nil {
+		log.Printf("⚠️  Failed to register presence for %s: %v", v.ValidatorID, err)
+	}
+}
+
+func (h *Hub) deregisterPresence(validatorID string) {
+	if err := h.presence.Deregister(context.Background(), validatorID); err != nil {
+		log.Printf("⚠️  Failed to deregister presence for %s: %v", validatorID, err)
+	}
+}
+
+// heartbeatPresence keeps this hub's validators alive in the presence store.
+// Entries that expired (e.g. after a Redis outage) are registered again.
+func (h *Hub) heartbeatPresence() {
+	ticker := time.NewTicker(h.cfg.PresenceHeartbeatInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, v := range h.connectedValidators() {
+			err := h.presence.Heartbeat(context.Background(), v.ValidatorID)
+			if errors.Is(err, presence.ErrNotRegistered) {
+				h.registerPresence(v)
+			} else if err != nil {
+				log.Printf("⚠️  Presence heartbeat failed for %s: %v", v.ValidatorID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "time"
+
+// messageLimiter is a token bucket over the messages read from one validator
+// connection. It's only touched by that connection's read loop, so it needs no
+// locking.
+type messageLimiter struct {
+	rate    float64 // tokens added per second; 0 disables limiting
+	burst   float64
+	tokens  float64
+	last    time.Time
+	dropped int // consecutive messages over the limit
+}
+
+func newMessageLimiter(rate float64, burst int) *messageLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &messageLimiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// allow reports whether a message arriving at now is within the limit
+func (l *messageLimiter) allow(now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		l.dropped++
+		return false
+	}
+	l.tokens--
+	l.dropped = 0
+	return true
+}
+
+// flooding reports whether the connection kept sending through a whole burst's
+// worth of dropped messages, at which point throttling isn't enough
+func (l *messageLimiter) flooding() bool {
+	return float64(l.dropped) > l.burst
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/gorilla/websocket"
+)
+
+func TestMessageLimiterThrottlesBursts(t *testing.T) {
+	l := newMessageLimiter(10, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if !l.allow(now) {
+			t.Fatalf("message %d within the burst was throttled", i)
+		}
+	}
+	if l.allow(now) {
+		t.Fatal("message past the burst was allowed")
+	}
+
+	// 10/s refills one token every 100ms
+	if l.allow(now.Add(50 * time.Millisecond)) {
+		t.Error("allowed before a token was refilled")
+	}
+	if !l.allow(now.Add(150 * time.Millisecond)) {
+		t.Error("throttled after a token was refilled")
+	}
+
+	// A long pause refills up to the burst, not beyond it
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !l.allow(later) {
+			t.Fatalf("message %d after a pause was throttled", i)
+		}
+	}
+	if l.allow(later) {
+		t.Error("a pause refilled past the burst")
+	}
+}
+
+func TestMessageLimiterFlooding(t *testing.T) {
+	l := newMessageLimiter(1, 2)
+	now := time.Now()
+	l.allow(now)
+	l.allow(now)
+
+	// Dropping up to a burst's worth is throttling; more than that is a flood
+	for i := 0; i < 2; i++ {
+		l.allow(now)
+		if l.flooding() {
+			t.Fatalf("flooding after %d dropped messages", i+1)
+		}
+	}
+	l.allow(now)
+	if !l.flooding() {
+		t.Error("not flooding after more than a burst of dropped messages")
+	}
+
+	// An allowed message resets the count
+	l.allow(now.Add(time.Second))
+	if l.flooding() {
+		t.Error("still flooding after a message was allowed")
+	}
+}
+
+func TestMessageLimiterDisabled(t *testing.T) {
+	l := newMessageLimiter(0, 0)
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		if !l.allow(now) {
+			t.Fatalf("message %d throttled with limiting disabled", i)
+		}
+	}
+}
+
+func TestFloodingConnectionThrottled(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubMessageRate = 1
+		cfg.HubMessageBurst = 3
+	})
+	client := dial(t, serveHub(t, h), nil)
+	v := newTestValidator(t, h.db)
+
+	// The burst is spent, so the signup is dropped unanswered
+	for i := 0; i < 3; i++ {
+		client.send(IncomingMessage{Type: "noop"})
+	}
+	client.send(v.signup(t, client.challenge, protocol.Version))
+	time.Sleep(300 * time.Millisecond)
+	if n := len(h.connectedValidators()); n != 0 {
+		t.Fatalf("%d validators connected, want the throttled signup dropped", n)
+	}
+
+	// Once a token is refilled the connection is served again
+	time.Sleep(time.Second)
+	client.signUp(v)
+}
+
+func TestFloodingConnectionDisconnected(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubMessageRate = 1
+		cfg.HubMessageBurst = 5
+	})
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	for i := 0; i < 20; i++ {
+		if err := client.conn.WriteJSON(IncomingMessage{Type: "noop"}); err != nil {
+			break // the hub already hung up
+		}
+	}
+
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Code != websocket.ClosePolicyViolation {
+		t.Errorf("connection ended with %v, want close code %d", err, websocket.ClosePolicyViolation)
+	}
+	waitFor(t, "the validator to be removed", func() bool { return len(h.connectedValidators()) == 0 })
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// replayGuard rejects signed messages that are stale or reuse a nonce. Nonces
+// only need remembering for as long as their timestamp is still acceptable.
+type replayGuard struct {
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	seen     map[string]time.Time
+	expiries nonceExpiries // seen nonces, soonest to expire first
+}
+
+func newReplayGuard(maxAge time.Duration) *replayGuard {
+	return &replayGuard{
+		maxAge: maxAge,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// check validates timestamp (unix seconds) and records nonce as used
+func (g *replayGuard) check(timestamp int64, nonce string) error {
+	now := g.now()
+	signedAt := time.Unix(timestamp, 0)
+	if signedAt.Before(now.Add(-g.maxAge)) || signedAt.After(now.Add(g.maxAge)) {
+		return fmt.Errorf("timestamp %d outside the accepted %s window", timestamp, g.maxAge)
+	}
+	if nonce == "" {
+		return fmt.Errorf("missing nonce")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Only the expired nonces are visited, so a check costs O(log n) amortized
+	for len(g.expiries) > 0 && now.After(g.expiries[0].expiresAt) {
+		expired := heap.Pop(&g.expiries).(seenNonce)
+		delete(g.seen, expired.nonce)
+	}
+	if _, used := g.seen[nonce]; used {
+		return fmt.Errorf("nonce %s already used", nonce)
+	}
+	// The message is accepted until signedAt+maxAge, so remember the nonce that long
+	expiresAt := signedAt.Add(g.maxAge)
+	g.seen[nonce] = expiresAt
+	heap.Push(&g.expiries, seenNonce{nonce: nonce, expiresAt: expiresAt})
+	return nil
+}
+
+// seenNonce is a used nonce and when it can be forgotten
+type seenNonce struct {
+	nonce     string
+	expiresAt time.Time
+}
+
+// nonceExpiries is a min-heap of seen nonces by expiry. Timestamps may be skewed
+// either way within the window, so nonces don't expire in the order they arrive.
+type nonceExpiries []seenNonce
+
+func (q nonceExpiries) Len() int           { return len(q) }
+func (q nonceExpiries) Less(i, j int) bool { return q[i].expiresAt.Before(q[j].expiresAt) }
+func (q nonceExpiries) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *nonceExpiries) Push(x interface{}) { *q = append(*q, x.(seenNonce)) }
+
+func (q *nonceExpiries) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+func TestReplayGuardCheck(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		signedAt time.Time
+		nonce    string
+		wantErr  string
+	}{
+		{"fresh", now, "a", ""},
+		{"just inside the window", now.Add(-50 * time.Second), "b", ""},
+		{"expired", now.Add(-2 * time.Minute), "c", "outside the accepted 1m0s window"},
+		{"from the future", now.Add(2 * time.Minute), "d", "outside the accepted 1m0s window"},
+		{"missing nonce", now, "", "missing nonce"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newReplayGuard(time.Minute).check(tt.signedAt.Unix(), tt.nonce)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("rejected: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("check = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplayGuardRejectsReusedNonce(t *testing.T) {
+	guard := newReplayGuard(time.Minute)
+	now := time.Now().Unix()
+
+	if err := guard.check(now, "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.check(now, "nonce-1"); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Errorf("replayed nonce: %v, want it rejected", err)
+	}
+	// A later timestamp doesn't make the nonce usable again
+	if err := guard.check(now+1, "nonce-1"); err == nil {
+		t.Error("replayed nonce with a new timestamp was accepted")
+	}
+	if err := guard.check(now, "nonce-2"); err != nil {
+		t.Errorf("fresh nonce: %v", err)
+	}
+}
+
+func TestExpiredResultNotRecorded(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{})
+	v := newTestValidator(t, h.db)
+
+	callbackID, nonce := uuid.New().String(), protocol.NewNonce()
+	stale := v.resultAt(t, callbackID, nonce, models.StatusGood, 10, time.Now().Add(-2*h.cfg.SignatureMaxAge))
+	h.createValidateCallback(website, v.connection(), nonce)(stale)
+
+	if n := tickCount(t, h.db, website.ID); n != 0 {
+		t.Errorf("%d ticks recorded from a result signed too long ago", n)
+	}
+}
+
+func TestReplayedResultRecordedOnce(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{})
+	v := newTestValidator(t, h.db)
+
+	// Captured and resent with its valid signature, as an attacker would
+	callbackID, nonce := uuid.New().String(), protocol.NewNonce()
+	result := v.result(t, callbackID, nonce, models.StatusGood, 10)
+	callback := h.createValidateCallback(website, v.connection(), nonce)
+	callback(result)
+	callback(result)
+
+	if n := tickCount(t, h.db, website.ID); n != 1 {
+		t.Errorf("%d ticks recorded, want the replay rejected", n)
+	}
+}
+
+func TestExpiredSignupRejected(t *testing.T) {
+	h := newTestHub(t)
+	client := dial(t, serveHub(t, h), nil)
+	signedAt := time.Now().Add(-2 * h.cfg.SignatureMaxAge)
+	client.send(newTestValidator(t, h.db).signupAt(t, client.challenge, protocol.Version, signedAt))
+
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Code != websocket.ClosePolicyViolation {
+		t.Fatalf("connection ended with %v, want a policy violation", err)
+	}
+	if !strings.Contains(closeErr.Text, "outside the accepted") {
+		t.Errorf("close reason %q, want the timestamp window", closeErr.Text)
+	}
+	if n := len(h.connectedValidators()); n != 0 {
+		t.Errorf("%d validators connected after an expired signup", n)
+	}
+}
+
+func TestReplayGuardForgetsExpiredNonces(t *testing.T) {
+	guard := newReplayGuard(time.Minute)
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+
+	// Skewed clocks: "late" was signed ahead of "early" but arrives first
+	for _, n := range []struct {
+		nonce    string
+		signedAt time.Time
+	}{
+		{"late", now.Add(50 * time.Second)},
+		{"early", now.Add(-50 * time.Second)},
+		{"current", now},
+	} {
+		if err := guard.check(n.signedAt.Unix(), n.nonce); err != nil {
+			t.Fatalf("%s: %v", n.nonce, err)
+		}
+	}
+
+	// Past early's expiry only it is forgotten
+	now = now.Add(20 * time.Second)
+	if err := guard.check(now.Unix(), "next"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := guard.seen["early"]; ok || len(guard.seen) != 3 || len(guard.expiries) != 3 {
+		t.Errorf("remembering %v (%d queued), want early forgotten", guard.seen, len(guard.expiries))
+	}
+
+	// Once every window has passed only the latest nonce is kept
+	now = now.Add(3 * time.Minute)
+	if err := guard.check(now.Unix(), "last"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := guard.seen["last"]; !ok || len(guard.seen) != 1 || len(guard.expiries) != 1 {
+		t.Errorf("remembering %v (%d queued), want only last", guard.seen, len(guard.expiries))
+	}
+}
+
+func TestSignupChallengeRedeem(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     time.Duration
+		nonce   func(c *signupChallenge) string
+		wantErr string
+	}{
+		{"correct", 0, func(c *signupChallenge) string { return c.value }, ""},
+		{"another challenge", 0, func(*signupChallenge) string { return protocol.NewNonce() }, "challenge mismatch"},
+		{"stale", 2 * time.Minute, func(c *signupChallenge) string { return c.value }, "challenge expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := newSignupChallenge()
+			challenge.issuedAt = challenge.issuedAt.Add(-tt.age)
+			err := challenge.redeem(tt.nonce(challenge), time.Minute)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("rejected: %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("redeem = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignupChallengeRedeemedOnce(t *testing.T) {
+	challenge := newSignupChallenge()
+	if err := challenge.redeem(challenge.value, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := challenge.redeem(challenge.value, time.Minute); err == nil || err.Error() != "challenge already used" {
+		t.Errorf("second redeem = %v, want it rejected", err)
+	}
+}
+
+func TestSignupWithAnotherChallengeRejected(t *testing.T) {
+	h := newTestHub(t)
+	client := dial(t, serveHub(t, h), nil)
+
+	// Signed over a challenge this connection was never sent, e.g. one captured elsewhere
+	client.send(newTestValidator(t, h.db).signup(t, protocol.NewNonce(), protocol.Version))
+
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Code != websocket.ClosePolicyViolation {
+		t.Fatalf("connection ended with %v, want a policy violation", err)
+	}
+	if closeErr.Text != "challenge mismatch" {
+		t.Errorf("close reason %q, want challenge mismatch", closeErr.Text)
+	}
+	if n := len(h.connectedValidators()); n != 0 {
+		t.Errorf("%d validators connected", n)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/rollups"
+)
+
+// refreshRollups keeps the hourly tick rollups behind long uptime windows
+// current, backfilling them on first run
+func (h *Hub) refreshRollups() {
+	h.refreshRollupsOnce()
+
+	ticker := time.NewTicker(h.cfg.RollupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.refreshRollupsOnce()
+	}
+}
+
+func (h *Hub) refreshRollupsOnce() {
+	db, cancel := h.query()
+	defer cancel()
+
+	rows, err := rollups.Refresh(db, time.Now())
+	if err != nil {
+		log.Printf("❌ Failed to refresh tick rollups: %v", err)
+		return
+	}
+	if rows > 0 {
+		log.Printf("📊 Refreshed %d hourly tick rollups", rows)
+	}
+}
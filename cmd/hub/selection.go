@@ -0,0 +1,283 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+)
+
+// Validator selection modes (VALIDATOR_SELECTION)
+const (
+	selectionRandom   = "random"
+	selectionWeighted = "weighted"
+	selectionFair     = "fair"
+)
+
+// selectionStatsWindow is how far back ticks count towards a validator's weight
+const selectionStatsWindow = time.Hour
+
+// referenceLatencyMs halves the weight of a validator whose median latency is this slow
+const referenceLatencyMs = 1000.0
+
+// validatorWeights holds the per-validator selection weights refreshed each
+// monitoring cycle. Validators without recent ticks get the maximum weight so new
+// ones are evaluated quickly.
+type validatorWeights struct {
+	mu      sync.RWMutex
+	weights map[string]float64
+}
+
+func (w *validatorWeights) get(validatorID string) float64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if weight, ok := w.weights[validatorID]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (w *validatorWeights) set(weights map[string]float64) {
+	w.mu.Lock()
+	w.weights = weights
+	w.mu.Unlock()
+}
+
+// assignmentCounts tracks how many tasks each connected validator has been
+// assigned by this hub, for fair selection. Validators are counted when they are
+// picked, so parallel dispatch workers see each other's picks, and released if
+// the task is never sent.
+type assignmentCounts struct {
+	mu      sync.Mutex
+	counts  map[string]int64
+	offsets map[string]int64 // where each validator joined the ranking
+}
+
+func newAssignmentCounts() *assignmentCounts {
+	return &assignmentCounts{counts: make(map[string]int64), offsets: make(map[string]int64)}
+}
+
+// add counts a task for each of validators
+func (a *assignmentCounts) add(validators []*ValidatorConnection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, v := range validators {
+		a.counts[v.ValidatorID]++
+	}
+}
+
+// release takes back the tasks counted for validators that were never sent them
+func (a *assignmentCounts) release(validators []*ValidatorConnection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, v := range validators {
+		if a.counts[v.ValidatorID] > 0 {
+			a.counts[v.ValidatorID]--
+		}
+	}
+}
+
+// remove forgets a disconnected validator; if it reconnects it starts level
+// with the others again
+func (a *assignmentCounts) remove(validatorID string) {
+	a.mu.Lock()
+	delete(a.counts, validatorID)
+	delete(a.offsets, validatorID)
+	a.mu.Unlock()
+}
+
+func (a *assignmentCounts) get(validatorID string) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.counts[validatorID]
+}
+
+// pick selects the count least assigned validators and counts a task for each
+// under one lock, so concurrent callers never all pick the same validators.
+// Validators seen for the first time join level with the least assigned of the
+// others, so a newcomer isn't handed every task until it has caught up with
+// validators that have been connected for days.
+func (a *assignmentCounts) pick(validators []*ValidatorConnection, count int, random func() float64) []*ValidatorConnection {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	floor := int64(-1)
+	for _, v := range validators {
+		if n, ok := a.counts[v.ValidatorID]; ok && (floor < 0 || n+a.offsets[v.ValidatorID] < floor) {
+			floor = n + a.offsets[v.ValidatorID]
+		}
+	}
+	ranking := make([]int64, len(validators))
+	for i, v := range validators {
+		if _, ok := a.counts[v.ValidatorID]; !ok {
+			a.counts[v.ValidatorID] = 0
+			a.offsets[v.ValidatorID] = max(floor, 0)
+		}
+		ranking[i] = a.counts[v.ValidatorID] + a.offsets[v.ValidatorID]
+	}
+
+	selected := leastAssigned(validators, ranking, count, random)
+	for _, v := range selected {
+		a.counts[v.ValidatorID]++
+	}
+	return selected
+}
+
+// leastAssigned picks the count validators that have been sent the fewest tasks,
+// breaking ties at random, so over many cycles every eligible validator gets
+// about the same share of the work
+func leastAssigned(validators []*ValidatorConnection, counts []int64, count int, random func() float64) []*ValidatorConnection {
+	if count >= len(validators) {
+		return validators
+	}
+
+	type ranked struct {
+		count     int64
+		tiebreak  float64
+		validator *ValidatorConnection
+	}
+	ranks := make([]ranked, len(validators))
+	for i, v := range validators {
+		ranks[i] = ranked{count: counts[i], tiebreak: random(), validator: v}
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].count != ranks[j].count {
+			return ranks[i].count < ranks[j].count
+		}
+		return ranks[i].tiebreak < ranks[j].tiebreak
+	})
+
+	selected := make([]*ValidatorConnection, count)
+	for i := range selected {
+		selected[i] = ranks[i].validator
+	}
+	return selected
+}
+
+// refreshWeights recomputes weights from the success rate and median latency of
+// each validator's recent checks
+func (h *Hub) refreshWeights() {
+	var rows []struct {
+		ValidatorID   string
+		SuccessRate   float64
+		MedianLatency *float64
+	}
+
+	db, cancel := h.query()
+	defer cancel()
+
+	err := db.Raw(`
+		SELECT
+			validator_id,
+			AVG(CASE WHEN status <> ? THEN 1.0 ELSE 0 END) AS success_rate,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY latency::float8) FILTER (WHERE status <> ?) AS median_latency
+		FROM "WebsiteTick"
+		WHERE created_at >= ?
+		GROUP BY validator_id`,
+		models.StatusBad, models.StatusBad, time.Now().Add(-selectionStatsWindow),
+	).Scan(&rows).Error
+	if err != nil {
+		log.Printf("⚠️  Failed to refresh validator weights, keeping previous ones: %v", err)
+		return
+	}
+
+	weights := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		weight := row.SuccessRate
+		if row.MedianLatency != nil {
+			weight *= referenceLatencyMs / (referenceLatencyMs + *row.MedianLatency)
+		}
+		weights[row.ValidatorID] = weight
+	}
+	h.weights.set(weights)
+}
+
+// eligible keeps the validators that may check website: those located in one of
+// its allowed regions and, for multi-step checks, header assertions and forced
+// HTTP versions, speaking a protocol that has them
+func eligible(website models.Website, validators []*ValidatorConnection) []*ValidatorConnection {
+	headers := website.HasHeaderAssertions()
+	forced := website.ForcesHTTPVersion()
+	if len(website.Regions) == 0 && len(website.Steps) == 0 && !headers && !forced {
+		return validators
+	}
+	allowed := make([]*ValidatorConnection, 0, len(validators))
+	for _, v := range validators {
+		if !website.AllowsRegion(v.Location) {
+			continue
+		}
+		if len(website.Steps) > 0 && v.ProtocolVersion < protocol.StepsVersion {
+			continue
+		}
+		if headers && v.ProtocolVersion < protocol.HeaderAssertionsVersion {
+			continue
+		}
+		if forced && v.ProtocolVersion < protocol.HTTPVersionsVersion {
+			continue
+		}
+		allowed = append(allowed, v)
+	}
+	return allowed
+}
+
+// selectValidators picks which validators check website this cycle. With
+// VALIDATORS_PER_CHECK unset every validator is used; otherwise the subset is never
+// smaller than the website's required coverage. Validators that aren't eligible
+// for the website are never picked. The validators picked are counted as
+// assigned; dispatchWebsite releases any it can't send the task to.
+func (h *Hub) selectValidators(website models.Website, validators []*ValidatorConnection) []*ValidatorConnection {
+	validators = eligible(website, validators)
+	count := h.cfg.ValidatorsPerCheck
+	if count <= 0 || count >= len(validators) {
+		h.assignments.add(validators)
+		return validators
+	}
+	if required := website.RequiredValidators(h.cfg.MinValidators); count < required {
+		count = required
+	}
+	if h.cfg.ValidatorSelection == selectionFair {
+		return h.assignments.pick(validators, count, rand.Float64)
+	}
+
+	weights := make([]float64, len(validators))
+	for i, v := range validators {
+		weights[i] = 1
+		if h.cfg.ValidatorSelection == selectionWeighted {
+			// The floor keeps poorly rated validators in rotation so they can recover
+			weights[i] = math.Max(h.weights.get(v.ValidatorID), h.cfg.ValidatorMinWeight)
+		}
+	}
+	selected := weightedSample(validators, weights, count, rand.Float64)
+	h.assignments.add(selected)
+	return selected
+}
+
+// weightedSample draws count items without replacement, each with probability
+// proportional to its weight (Efraimidis-Spirakis: keep the largest u^(1/w))
+func weightedSample(validators []*ValidatorConnection, weights []float64, count int, random func() float64) []*ValidatorConnection {
+	if count >= len(validators) {
+		return validators
+	}
+
+	type keyed struct {
+		key       float64
+		validator *ValidatorConnection
+	}
+	keys := make([]keyed, len(validators))
+	for i, v := range validators {
+		keys[i] = keyed{key: math.Pow(random(), 1/weights[i]), validator: v}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+
+	selected := make([]*ValidatorConnection, count)
+	for i := range selected {
+		selected[i] = keys[i].validator
+	}
+	return selected
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/google/uuid"
+)
+
+// connections returns n connected validators in location, without sockets or
+// database rows
+func connections(n int, location string) []*ValidatorConnection {
+	validators := make([]*ValidatorConnection, n)
+	for i := range validators {
+		validators[i] = &ValidatorConnection{
+			ValidatorID:     fmt.Sprintf("%s-%d", location, i),
+			Location:        location,
+			ProtocolVersion: protocol.Version,
+		}
+	}
+	return validators
+}
+
+func TestSelectValidatorsCoverage(t *testing.T) {
+	three := 3
+	tests := []struct {
+		name      string
+		perCheck  int
+		available int
+		website   models.Website
+		want      int
+	}{
+		{"every validator when unset", 0, 5, models.Website{}, 5},
+		{"per-check subset meets the default", 2, 5, models.Website{}, 2},
+		{"raised to the website's minimum", 2, 5, models.Website{MinValidators: &three}, 3},
+		{"all there are when coverage is unmet", 2, 2, models.Website{MinValidators: &three}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Hub{
+				cfg:         &config.Config{ValidatorsPerCheck: tt.perCheck, MinValidators: 1, ValidatorSelection: selectionRandom},
+				assignments: newAssignmentCounts(),
+			}
+			if got := h.selectValidators(tt.website, connections(tt.available, "eu")); len(got) != tt.want {
+				t.Errorf("selected %d validators, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectValidatorsRegions(t *testing.T) {
+	validators := append(append(connections(2, "eu-west"), connections(1, "us-east")...), connections(3, "ap-south")...)
+
+	tests := []struct {
+		name     string
+		regions  []string
+		perCheck int
+		want     map[string]bool // locations that may be picked
+		wantN    int
+	}{
+		{"unrestricted", nil, 0, map[string]bool{"eu-west": true, "us-east": true, "ap-south": true}, 6},
+		{"one region", []string{"eu-west"}, 0, map[string]bool{"eu-west": true}, 2},
+		{"regions match case-insensitively", []string{"EU-West", "us-east"}, 0, map[string]bool{"eu-west": true, "us-east": true}, 3},
+		{"subset stays in region", []string{"eu-west", "us-east"}, 1, map[string]bool{"eu-west": true, "us-east": true}, 1},
+		{"no validator in region", []string{"sa-east"}, 0, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Hub{
+				cfg:         &config.Config{ValidatorsPerCheck: tt.perCheck, MinValidators: 1, ValidatorSelection: selectionRandom},
+				assignments: newAssignmentCounts(),
+			}
+			website := models.Website{Regions: tt.regions}
+			for i := 0; i < 50; i++ {
+				selected := h.selectValidators(website, validators)
+				if len(selected) != tt.wantN {
+					t.Fatalf("selected %d validators, want %d", len(selected), tt.wantN)
+				}
+				for _, v := range selected {
+					if !tt.want[v.Location] {
+						t.Fatalf("selected %s in %s, outside %v", v.ValidatorID, v.Location, tt.regions)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEligibleSteps(t *testing.T) {
+	validators := connections(2, "eu-west")
+	validators[0].ProtocolVersion = protocol.StepsVersion - 1
+	validators[1].ProtocolVersion = protocol.StepsVersion
+
+	tests := []struct {
+		name    string
+		website models.Website
+		want    []*ValidatorConnection
+	}{
+		{"single request", models.Website{}, validators},
+		{"multi-step", models.Website{Steps: []models.CheckStep{{URL: "https://example.com/login"}, {URL: "https://example.com/dashboard"}}}, validators[1:]},
+		{"multi-step out of region", models.Website{Regions: []string{"us-east"}, Steps: []models.CheckStep{{URL: "https://example.com"}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eligible(tt.website, validators)
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d eligible validators, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("eligible[%d] = %s, want %s", i, got[i].ValidatorID, tt.want[i].ValidatorID)
+				}
+			}
+		})
+	}
+}
+
+func TestEligibleHeaderAssertions(t *testing.T) {
+	validators := connections(2, "eu-west")
+	validators[0].ProtocolVersion = protocol.HeaderAssertionsVersion - 1
+	validators[1].ProtocolVersion = protocol.HeaderAssertionsVersion
+	header := models.Assertion{Type: models.AssertionHeader, Expression: "Strict-Transport-Security"}
+	body := models.Assertion{Type: models.AssertionRegex, Expression: "ok"}
+
+	tests := []struct {
+		name    string
+		website models.Website
+		want    []*ValidatorConnection
+	}{
+		{"body assertions", models.Website{Assertions: []models.Assertion{body}}, validators},
+		{"header assertion", models.Website{Assertions: []models.Assertion{body, header}}, validators[1:]},
+		{"header assertion in a step", models.Website{Steps: []models.CheckStep{{URL: "https://example.com"}, {URL: "https://example.com/a", Assertions: []models.Assertion{header}}}}, validators[1:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eligible(tt.website, validators)
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d eligible validators, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("eligible[%d] = %s, want %s", i, got[i].ValidatorID, tt.want[i].ValidatorID)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchOnlyToRegion(t *testing.T) {
+	h := newTestHub(t)
+	locations := map[string]string{}
+	for id := range connectQueued(t, h, 4) {
+		locations[id] = []string{"eu-west", "us-east"}[len(locations)%2]
+	}
+	h.mu.Lock()
+	for id, location := range locations {
+		h.validators[id].Location = location
+	}
+	h.mu.Unlock()
+
+	website := createWebsite(t, h.db, models.Website{Regions: []string{"us-east"}})
+	if n := h.dispatchAll([]models.Website{website}, h.connectedValidators(), []string{h.cfg.HubID}); n != 1 {
+		t.Fatalf("dispatched %d websites, want 1", n)
+	}
+
+	queued := queuedTasks(h)
+	if len(queued) != 2 {
+		t.Errorf("tasks sent to %d validators, want the 2 in us-east", len(queued))
+	}
+	for id := range queued {
+		if locations[id] != "us-east" {
+			t.Errorf("task sent to %s in %s", id, locations[id])
+		}
+	}
+}
+
+// selectionShares runs selectValidators trials times, picking one of validators
+// each time, and returns the share of picks each validator got
+func selectionShares(h *Hub, validators []*ValidatorConnection, trials int) map[string]float64 {
+	picks := make(map[string]int)
+	for i := 0; i < trials; i++ {
+		for _, v := range h.selectValidators(models.Website{}, validators) {
+			picks[v.ValidatorID]++
+		}
+	}
+	shares := make(map[string]float64, len(picks))
+	for id, n := range picks {
+		shares[id] = float64(n) / float64(trials)
+	}
+	return shares
+}
+
+func TestWeightedSampleProportionalToWeight(t *testing.T) {
+	validators := connections(2, "eu")
+	random := rand.New(rand.NewSource(1)).Float64
+
+	const trials = 20000
+	heavy := 0
+	for i := 0; i < trials; i++ {
+		if weightedSample(validators, []float64{3, 1}, 1, random)[0] == validators[0] {
+			heavy++
+		}
+	}
+	// A weight of 3 against 1 should win three draws in four
+	if share := float64(heavy) / trials; math.Abs(share-0.75) > 0.02 {
+		t.Errorf("expected the heavier validator in about 75%% of draws, got %.1f%%", share*100)
+	}
+}
+
+func TestEligibleForcedHTTPVersion(t *testing.T) {
+	validators := connections(2, "eu-west")
+	validators[0].ProtocolVersion = protocol.HTTPVersionsVersion - 1
+	validators[1].ProtocolVersion = protocol.HTTPVersionsVersion
+
+	tests := []struct {
+		httpVersion string
+		want        int
+	}{
+		{"", 2},
+		{models.HTTPVersionAuto, 2},
+		{models.HTTPVersion1, 1},
+		{models.HTTPVersion2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.httpVersion, func(t *testing.T) {
+			got := eligible(models.Website{HTTPVersion: tt.httpVersion}, validators)
+			if len(got) != tt.want {
+				t.Fatalf("%d eligible validators, want %d", len(got), tt.want)
+			}
+			if tt.want == 1 && got[0] != validators[1] {
+				t.Errorf("eligible %s, want the version %d validator", got[0].ValidatorID, protocol.HTTPVersionsVersion)
+			}
+		})
+	}
+}
+
+func TestSelectValidatorsWeightingShiftsSelection(t *testing.T) {
+	validators := connections(4, "eu")
+	weights := map[string]float64{
+		"eu-0": 1,    // fast and reliable
+		"eu-1": 0.25, // slow or often failing
+		"eu-2": 0,    // failing every check
+		// eu-3 has no recent ticks and counts as fully weighted
+	}
+	newHub := func(selection string) *Hub {
+		h := &Hub{
+			cfg:         &config.Config{ValidatorsPerCheck: 1, MinValidators: 1, ValidatorSelection: selection, ValidatorMinWeight: 0.05},
+			assignments: newAssignmentCounts(),
+		}
+		h.weights.set(weights)
+		return h
+	}
+
+	const trials = 20000
+	t.Run("random ignores weights", func(t *testing.T) {
+		shares := selectionShares(newHub(selectionRandom), validators, trials)
+		for _, v := range validators {
+			if share := shares[v.ValidatorID]; math.Abs(share-0.25) > 0.03 {
+				t.Errorf("%s: expected about 25%% of picks, got %.1f%%", v.ValidatorID, share*100)
+			}
+		}
+	})
+
+	t.Run("weighted follows weights", func(t *testing.T) {
+		shares := selectionShares(newHub(selectionWeighted), validators, trials)
+
+		// With one pick, each validator's share is its weight over the total
+		total := 1 + 0.25 + 0.05 + 1
+		want := map[string]float64{"eu-0": 1 / total, "eu-1": 0.25 / total, "eu-2": 0.05 / total, "eu-3": 1 / total}
+		for id, expected := range want {
+			if share := shares[id]; math.Abs(share-expected) > 0.03 {
+				t.Errorf("%s: expected about %.1f%% of picks, got %.1f%%", id, expected*100, share*100)
+			}
+		}
+		// The floor keeps the failing validator in rotation
+		if shares["eu-2"] == 0 {
+			t.Error("expected the zero-weight validator to be picked occasionally")
+		}
+	})
+}
+
+func TestRefreshWeights(t *testing.T) {
+	dbtest.RequirePostgres(t) // medians use percentile_cont
+
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{})
+	fast, slow, flaky := newTestValidator(t, h.db), newTestValidator(t, h.db), newTestValidator(t, h.db)
+
+	tick := func(v testValidator, status string, latency float64, at time.Time) {
+		t.Helper()
+		err := h.db.Create(&models.WebsiteTick{
+			ID:          uuid.New().String(),
+			WebsiteID:   website.ID,
+			ValidatorID: v.model.ID,
+			Status:      status,
+			Latency:     latency,
+			CreatedAt:   at,
+		}).Error
+		if err != nil {
+			t.Fatalf("creating tick: %v", err)
+		}
+	}
+	now := time.Now()
+	tick(fast, models.StatusGood, 0, now)
+	tick(slow, models.StatusGood, referenceLatencyMs, now)
+	tick(flaky, models.StatusGood, 0, now)
+	tick(flaky, models.StatusBad, 0, now)
+	// Older than the stats window
+	tick(slow, models.StatusBad, 0, now.Add(-2*selectionStatsWindow))
+
+	h.refreshWeights()
+
+	want := map[string]float64{fast.model.ID: 1, slow.model.ID: 0.5, flaky.model.ID: 0.5, "unseen": 1}
+	for id, expected := range want {
+		if got := h.weights.get(id); math.Abs(got-expected) > 1e-9 {
+			t.Errorf("validator %s: expected weight %v, got %v", id, expected, got)
+		}
+	}
+}
+
+func TestAssignmentCountsConcurrentPick(t *testing.T) {
+	validators := connections(8, "eu")
+	a := newAssignmentCounts()
+
+	// Parallel workers picking at once each see the others' picks
+	const workers, picks, perPick = 16, 250, 3
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < picks; j++ {
+				if got := a.pick(validators, perPick, rand.Float64); len(got) != perPick {
+					t.Errorf("picked %d validators, want %d", len(got), perPick)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var total, least, most int64 = 0, math.MaxInt64, 0
+	for _, v := range validators {
+		n := a.get(v.ValidatorID)
+		total += n
+		least, most = min(least, n), max(most, n)
+	}
+	if total != workers*picks*perPick {
+		t.Errorf("counted %d assignments, want %d", total, workers*picks*perPick)
+	}
+	if most-least > 1 {
+		t.Errorf("assignments range from %d to %d, want them level", least, most)
+	}
+}
+
+func TestAssignmentCountsNewcomerStartsLevel(t *testing.T) {
+	validators := connections(3, "eu")
+	a := newAssignmentCounts()
+	for i := 0; i < 10; i++ {
+		a.pick(validators[:2], 2, rand.Float64)
+	}
+
+	// The newcomer joins level with the others rather than taking every task,
+	// so three single picks go to each validator once
+	for i := 0; i < 3; i++ {
+		a.pick(validators, 1, rand.Float64)
+	}
+	want := []int64{11, 11, 1}
+	for i, v := range validators {
+		if got := a.get(v.ValidatorID); got != want[i] {
+			t.Errorf("%s has %d assignments, want %d", v.ValidatorID, got, want[i])
+		}
+	}
+}
+
+func TestFairSelectionConverges(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.ValidatorSelection = selectionFair
+		cfg.ValidatorsPerCheck = 2
+		cfg.DispatchWorkers = 8
+	})
+	connectQueued(t, h, 6)
+	websites := make([]models.Website, 60)
+	for i := range websites {
+		websites[i] = createWebsite(t, h.db, models.Website{})
+	}
+
+	// 120 tasks over six validators, dispatched by parallel workers
+	if dispatched := h.dispatchAll(websites, h.connectedValidators(), []string{h.cfg.HubID}); dispatched != len(websites) {
+		t.Fatalf("dispatched %d websites, want %d", dispatched, len(websites))
+	}
+	for validatorID, tasks := range queuedTasks(h) {
+		if len(tasks) != 20 {
+			t.Errorf("validator %s was sent %d tasks, want 20", validatorID, len(tasks))
+		}
+		if got := h.assignments.get(validatorID); got != int64(len(tasks)) {
+			t.Errorf("validator %s has %d assignments for %d tasks sent", validatorID, got, len(tasks))
+		}
+	}
+}
+
+func TestUnsentTasksReleased(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.ValidatorSelection = selectionFair
+		cfg.ValidatorsPerCheck = 3
+		cfg.MaxInFlightPerWebsite = 1
+	})
+	connectQueued(t, h, 4)
+	website := createWebsite(t, h.db, models.Website{})
+
+	// Three are picked, but the cap lets only one task out
+	if sent := h.dispatchWebsite(website, h.selectValidators(website, h.connectedValidators())); sent != 1 {
+		t.Fatalf("sent %d tasks, want 1", sent)
+	}
+	var total int64
+	for _, v := range h.connectedValidators() {
+		total += h.assignments.get(v.ValidatorID)
+	}
+	if total != 1 {
+		t.Errorf("counted %d assignments for one task sent", total)
+	}
+}
+
+func TestRemoveValidatorForgetsAssignments(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(v)
+	h.assignments.add([]*ValidatorConnection{connectionOf(t, h, v)})
+
+	client.conn.Close()
+	waitFor(t, "the validator to disconnect", func() bool { return len(h.connectedValidators()) == 0 })
+	h.assignments.mu.Lock()
+	_, tracked := h.assignments.counts[v.model.ID]
+	h.assignments.mu.Unlock()
+	if tracked {
+		t.Error("disconnected validator's assignments are still tracked")
+	}
+}
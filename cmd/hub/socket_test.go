@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+func TestOversizedMessageDisconnects(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.HubMaxMessageBytes = 1024 })
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	client.send(IncomingMessage{Type: "status", Data: []byte(`"` + strings.Repeat("x", 4096) + `"`)})
+
+	var closeErr *websocket.CloseError
+	if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseMessageTooBig {
+		t.Errorf("connection ended with %v, want close code %d", err, websocket.CloseMessageTooBig)
+	}
+	waitFor(t, "the validator to be removed", func() bool { return len(h.connectedValidators()) == 0 })
+}
+
+func TestMessageWithinLimitKeepsConnection(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.HubMaxMessageBytes = 1024 })
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	client.send(IncomingMessage{Type: "status", Data: []byte(`"` + strings.Repeat("x", 512) + `"`)})
+	stillConnected(t, client, 300*time.Millisecond)
+}
+
+func TestIdleConnectionTimesOut(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubReadTimeout = 200 * time.Millisecond
+		cfg.HubPingInterval = time.Hour // no pongs to extend the deadline
+	})
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	started := time.Now()
+	if err := client.closed(); isTimeout(err) {
+		t.Fatalf("hub kept the idle connection open: %v", err)
+	}
+	if took := time.Since(started); took > 2*time.Second {
+		t.Errorf("idle connection dropped after %s, want about %s", took, h.cfg.HubReadTimeout)
+	}
+	waitFor(t, "the validator to be removed", func() bool { return len(h.connectedValidators()) == 0 })
+}
+
+func TestActivityExtendsReadDeadline(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubReadTimeout = 200 * time.Millisecond
+		cfg.HubPingInterval = time.Hour
+	})
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	// Messages every half timeout keep the connection open well past one timeout
+	for i := 0; i < 6; i++ {
+		time.Sleep(100 * time.Millisecond)
+		client.send(IncomingMessage{Type: "noop"})
+	}
+	if n := len(h.connectedValidators()); n != 1 {
+		t.Fatalf("%d validators connected, want the active one", n)
+	}
+}
+
+func TestPongsExtendReadDeadline(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.HubReadTimeout = 200 * time.Millisecond
+		cfg.HubPingInterval = 50 * time.Millisecond
+		cfg.HubIdleTimeout = 0
+	})
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+
+	// Reading answers the hub's pings without sending any messages
+	stillConnected(t, client, 600*time.Millisecond)
+}
+
+// stillConnected reads from c for d, failing t if the hub ends the connection
+func stillConnected(t *testing.T, c *testConn, d time.Duration) {
+	t.Helper()
+
+	c.conn.SetReadDeadline(time.Now().Add(d))
+	for {
+		var msg received
+		err := c.conn.ReadJSON(&msg)
+		if err == nil {
+			continue
+		}
+		if !isTimeout(err) {
+			t.Fatalf("connection ended: %v", err)
+		}
+		return
+	}
+}
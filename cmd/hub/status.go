@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/gorilla/websocket"
+)
+
+type StatusIncoming struct {
+	InFlight        int   `json:"inFlight"`
+	ChecksCompleted int64 `json:"checksCompleted"`
+	ChecksFailed    int64 `json:"checksFailed"`
+	ReportErrors    int64 `json:"reportErrors"`
+	UptimeSeconds   int64 `json:"uptimeSeconds"`
+}
+
+// handleStatus stores a validator's periodic self-reported health
+func (h *Hub) handleStatus(conn *websocket.Conn, data json.RawMessage) {
+	validator := h.validatorForConn(conn)
+	if validator == nil {
+		log.Println("⚠️  Status report before signup, ignoring")
+		return
+	}
+
+	var status StatusIncoming
+	if err := json.Unmarshal(data, &status); err != nil {
+		log.Printf("❌ Status unmarshal error: %v", err)
+		return
+	}
+
+	db, cancel := h.query()
+	defer cancel()
+
+	if err := db.Model(&models.Validator{}).
+		Where("id = ?", validator.ValidatorID).
+		UpdateColumns(map[string]interface{}{
+			"in_flight_checks":   status.InFlight,
+			"checks_completed":   status.ChecksCompleted,
+			"checks_failed":      status.ChecksFailed,
+			"report_errors":      status.ReportErrors,
+			"uptime_seconds":     status.UptimeSeconds,
+			"status_reported_at": time.Now(),
+		}).Error; err != nil {
+		log.Printf("❌ Failed to store status for %s: %v", validator.ValidatorID, err)
+	}
+}
+
+// validatorForConn returns the signed-up validator using conn, if any
+func (h *Hub) validatorForConn(conn *websocket.Conn) *ValidatorConnection {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, validator := range h.validators {
+		if validator.Conn == conn {
+			return validator
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+func statusMessage(t *testing.T, status StatusIncoming) IncomingMessage {
+	t.Helper()
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return IncomingMessage{Type: "status", Data: data}
+}
+
+// storedValidator reloads validatorID from the database
+func storedValidator(t *testing.T, h *Hub, validatorID string) models.Validator {
+	t.Helper()
+
+	var validator models.Validator
+	if err := h.db.Where("id = ?", validatorID).First(&validator).Error; err != nil {
+		t.Fatal(err)
+	}
+	return validator
+}
+
+func TestStatusUpdatesValidator(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(v)
+
+	before := time.Now()
+	client.send(statusMessage(t, StatusIncoming{InFlight: 3, ChecksCompleted: 120, ChecksFailed: 7, ReportErrors: 2, UptimeSeconds: 3600}))
+	waitFor(t, "the status to be stored", func() bool { return storedValidator(t, h, v.model.ID).StatusReportedAt != nil })
+
+	got := storedValidator(t, h, v.model.ID)
+	if got.InFlightChecks != 3 || got.ChecksCompleted != 120 || got.ChecksFailed != 7 || got.ReportErrors != 2 || got.UptimeSeconds != 3600 {
+		t.Errorf("stored in flight %d, completed %d, failed %d, report errors %d, uptime %d; want 3, 120, 7, 2, 3600",
+			got.InFlightChecks, got.ChecksCompleted, got.ChecksFailed, got.ReportErrors, got.UptimeSeconds)
+	}
+	if got.StatusReportedAt.Before(before.Add(-time.Second)) {
+		t.Errorf("reported at %s, want about now", got.StatusReportedAt)
+	}
+
+	// A later report replaces the counters, including with zeroes
+	client.send(statusMessage(t, StatusIncoming{ChecksCompleted: 121, UptimeSeconds: 3660}))
+	waitFor(t, "the second status", func() bool { return storedValidator(t, h, v.model.ID).UptimeSeconds == 3660 })
+	if got := storedValidator(t, h, v.model.ID); got.InFlightChecks != 0 || got.ChecksCompleted != 121 || got.ChecksFailed != 0 {
+		t.Errorf("after second report: in flight %d, completed %d, failed %d; want 0, 121, 0",
+			got.InFlightChecks, got.ChecksCompleted, got.ChecksFailed)
+	}
+}
+
+func TestStatusBeforeSignupIgnored(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	client := dial(t, serveHub(t, h), nil)
+
+	client.send(statusMessage(t, StatusIncoming{ChecksCompleted: 99}))
+	// Signing up afterwards proves the status message was read first
+	client.signUp(v)
+
+	if got := storedValidator(t, h, v.model.ID); got.StatusReportedAt != nil || got.ChecksCompleted != 0 {
+		t.Errorf("status from an anonymous connection stored: completed %d at %v", got.ChecksCompleted, got.StatusReportedAt)
+	}
+}
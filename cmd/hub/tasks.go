@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+var (
+	errUnknownTask     = errors.New("no pending task for callback")
+	errTaskNotAssigned = errors.New("callback was assigned to another validator")
+)
+
+// pendingTask is a dispatched check awaiting the validator's reply
+type pendingTask struct {
+	websiteID   string
+	validatorID string // only this validator may complete the task
+	handle      func(IncomingMessage)
+	expiresAt   time.Time
+}
+
+// taskSweepInterval is how often unanswered tasks are looked for
+const taskSweepInterval = 5 * time.Second
+
+// taskTimeout is how long to wait for a validator's reply about website: its
+// check timeout (twice over for dual-stack checks, which run one family after
+// the other) plus a margin for queueing and the round trip
+func (h *Hub) taskTimeout(website models.Website) time.Duration {
+	timeout := website.CheckTimeout(h.cfg.CheckTimeout)
+	if website.AddressFamily == "dual" {
+		timeout *= 2
+	}
+	return timeout + h.cfg.TaskTimeoutMargin
+}
+
+// registerTask records a task about to be sent that expires after ttl. It returns
+// false without registering when the website already has MaxInFlightPerWebsite
+// checks pending.
+func (h *Hub) registerTask(callbackID, websiteID, validatorID string, ttl time.Duration, handle func(IncomingMessage)) bool {
+	h.callbackMu.Lock()
+	defer h.callbackMu.Unlock()
+
+	if limit := h.cfg.MaxInFlightPerWebsite; limit > 0 && h.inFlight[websiteID] >= limit {
+		return false
+	}
+
+	h.callbacks[callbackID] = &pendingTask{
+		websiteID:   websiteID,
+		validatorID: validatorID,
+		handle:      handle,
+		expiresAt:   time.Now().Add(ttl),
+	}
+	h.inFlight[websiteID]++
+	return true
+}
+
+// takeTask removes and returns the task for callbackID, freeing its in-flight
+// slot. A task assigned to a validator other than validatorID is left pending so
+// another validator can't complete (or cancel) it.
+func (h *Hub) takeTask(callbackID, validatorID string) (*pendingTask, error) {
+	h.callbackMu.Lock()
+	defer h.callbackMu.Unlock()
+
+	task, ok := h.callbacks[callbackID]
+	if !ok {
+		return nil, errUnknownTask
+	}
+	if task.validatorID != validatorID {
+		return nil, errTaskNotAssigned
+	}
+	h.forgetTask(callbackID, task)
+	return task, nil
+}
+
+// forgetTask must be called with callbackMu held
+func (h *Hub) forgetTask(callbackID string, task *pendingTask) {
+	delete(h.callbacks, callbackID)
+	if h.inFlight[task.websiteID]--; h.inFlight[task.websiteID] <= 0 {
+		delete(h.inFlight, task.websiteID)
+	}
+}
+
+// expireTasks drops tasks whose validator never replied so they stop counting
+// against the website's in-flight limit
+func (h *Hub) expireTasks() {
+	ticker := time.NewTicker(taskSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if expired := h.sweepTasks(time.Now()); expired > 0 {
+			log.Printf("⏱️  Expired %d unanswered validation tasks", expired)
+		}
+	}
+}
+
+// sweepTasks forgets the tasks expired at now and returns how many there were
+func (h *Hub) sweepTasks(now time.Time) int {
+	h.callbackMu.Lock()
+	defer h.callbackMu.Unlock()
+
+	expired := 0
+	for callbackID, task := range h.callbacks {
+		if now.After(task.expiresAt) {
+			h.forgetTask(callbackID, task)
+			expired++
+		}
+	}
+	return expired
+}
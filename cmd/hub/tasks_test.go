@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+// connectQueued adds n validators to h whose tasks stay on their send queues
+func connectQueued(t *testing.T, h *Hub, n int) map[string]testValidator {
+	t.Helper()
+
+	validators := make(map[string]testValidator, n)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := 0; i < n; i++ {
+		v := newTestValidator(t, h.db)
+		validators[v.model.ID] = v
+		h.validators[v.model.ID] = v.connection()
+	}
+	return validators
+}
+
+// queuedTasks drains the tasks sent to each of h's validators
+func queuedTasks(h *Hub) map[string][]map[string]interface{} {
+	queued := make(map[string][]map[string]interface{})
+	for _, conn := range h.connectedValidators() {
+		for len(conn.send) > 0 {
+			msg := <-conn.send
+			if data, ok := msg.Data.(map[string]interface{}); ok && msg.Type == "validate" {
+				queued[conn.ValidatorID] = append(queued[conn.ValidatorID], data)
+			}
+		}
+	}
+	return queued
+}
+
+func TestInFlightCapAcrossCycle(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.MaxInFlightPerWebsite = 2 })
+	validators := connectQueued(t, h, 5)
+	website := createWebsite(t, h.db, models.Website{})
+	other := createWebsite(t, h.db, models.Website{})
+
+	// Five validators are connected, but the website only gets two checks
+	h.dispatchAll([]models.Website{website}, h.connectedValidators(), []string{h.cfg.HubID})
+	queued := queuedTasks(h)
+	if n := pending(h, website.ID); n != 2 || len(queued) != 2 {
+		t.Fatalf("%d checks pending on %d validators, want 2 on 2", n, len(queued))
+	}
+
+	// The cap is per website: another website still gets its own two
+	if sent := h.dispatchWebsite(other, h.connectedValidators()); sent != 2 {
+		t.Errorf("other website dispatched to %d validators, want 2", sent)
+	}
+	queuedTasks(h)
+
+	// While both are pending no further checks go out
+	if sent := h.dispatchWebsite(website, h.connectedValidators()); sent != 0 {
+		t.Fatalf("dispatched %d more checks at the cap", sent)
+	}
+
+	// A reply frees one slot
+	for validatorID, tasks := range queued {
+		task := tasks[0]
+		pendingTask, err := h.takeTask(task["callbackId"].(string), validatorID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pendingTask.handle(validators[validatorID].result(t, task["callbackId"].(string), task["nonce"].(string), models.StatusGood, 10))
+		break
+	}
+	if sent := h.dispatchWebsite(website, h.connectedValidators()); sent != 1 {
+		t.Fatalf("dispatched %d checks after one reply, want 1", sent)
+	}
+
+	// Unanswered checks free their slots once they expire
+	if expired := h.sweepTasks(time.Now().Add(h.taskTimeout(website) + time.Second)); expired != 2+2 {
+		t.Errorf("expired %d tasks, want 2 for each website", expired)
+	}
+	if sent := h.dispatchWebsite(website, h.connectedValidators()); sent != 2 {
+		t.Errorf("dispatched %d checks after the rest expired, want 2", sent)
+	}
+}
+
+func TestSweepKeepsUnexpiredTasks(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.MaxInFlightPerWebsite = 2 })
+	connectQueued(t, h, 2)
+	website := createWebsite(t, h.db, models.Website{})
+
+	h.dispatchWebsite(website, h.connectedValidators())
+	if expired := h.sweepTasks(time.Now()); expired != 0 {
+		t.Errorf("expired %d tasks still within their timeout", expired)
+	}
+	if n := pending(h, website.ID); n != 2 {
+		t.Errorf("%d checks pending, want 2", n)
+	}
+}
+
+func TestTakeTaskOnlyByAssignedValidator(t *testing.T) {
+	h := newTestHub(t)
+	website := createWebsite(t, h.db, models.Website{})
+	h.registerTask("callback", website.ID, "assigned", time.Minute, func(IncomingMessage) {})
+
+	if _, err := h.takeTask("callback", "other"); !errors.Is(err, errTaskNotAssigned) {
+		t.Fatalf("taking another validator's task: %v, want %v", err, errTaskNotAssigned)
+	}
+	if n := pending(h, website.ID); n != 1 {
+		t.Fatalf("%d checks pending after a mismatched take, want the task kept", n)
+	}
+
+	if _, err := h.takeTask("callback", "assigned"); err != nil {
+		t.Fatalf("assigned validator's take: %v", err)
+	}
+	if _, err := h.takeTask("callback", "assigned"); !errors.Is(err, errUnknownTask) {
+		t.Errorf("second take: %v, want %v", err, errUnknownTask)
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) {
+		cfg.CheckTimeout = 10 * time.Second
+		cfg.TaskTimeoutMargin = 30 * time.Second
+	})
+
+	tests := []struct {
+		name    string
+		website models.Website
+		want    time.Duration
+	}{
+		{"default check timeout", models.Website{}, 40 * time.Second},
+		{"website timeout", models.Website{TimeoutMs: 60_000}, 90 * time.Second},
+		{"dual stack runs twice", models.Website{TimeoutMs: 60_000, AddressFamily: "dual"}, 150 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.taskTimeout(tt.website); got != tt.want {
+				t.Errorf("taskTimeout = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighTimeoutTaskOutlivesLowTimeout(t *testing.T) {
+	h := newTestHub(t, func(cfg *config.Config) { cfg.TaskTimeoutMargin = 5 * time.Second })
+	connectQueued(t, h, 1)
+	fast := createWebsite(t, h.db, models.Website{TimeoutMs: 1_000})
+	slow := createWebsite(t, h.db, models.Website{TimeoutMs: 60_000})
+	dispatched := time.Now()
+	h.dispatchWebsite(fast, h.connectedValidators())
+	h.dispatchWebsite(slow, h.connectedValidators())
+
+	// Validators are told each website's timeout
+	for _, tasks := range queuedTasks(h) {
+		for _, task := range tasks {
+			want := fast.TimeoutMs
+			if task["websiteId"] == slow.ID {
+				want = slow.TimeoutMs
+			}
+			if got, _ := task["timeoutMs"].(int); got != want {
+				t.Errorf("task for %s carries timeoutMs %v, want %v", task["websiteId"], task["timeoutMs"], want)
+			}
+		}
+	}
+
+	// Past the fast website's 6s, well within the slow one's 65s
+	if expired := h.sweepTasks(dispatched.Add(10 * time.Second)); expired != 1 {
+		t.Fatalf("expired %d tasks after 10s, want only the fast website's", expired)
+	}
+	if pending(h, fast.ID) != 0 || pending(h, slow.ID) != 1 {
+		t.Fatalf("pending: fast %d, slow %d, want the slow website's task kept", pending(h, fast.ID), pending(h, slow.ID))
+	}
+
+	if expired := h.sweepTasks(dispatched.Add(70 * time.Second)); expired != 1 {
+		t.Errorf("expired %d tasks after 70s, want the slow website's", expired)
+	}
+}
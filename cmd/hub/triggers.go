@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/events"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+// pollCheckTriggers dispatches on-demand checks requested through the API's
+// check-now endpoint, outside the regular monitoring cycle
+func (h *Hub) pollCheckTriggers() {
+	ticker := time.NewTicker(h.cfg.CheckTriggerPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		var triggers []models.CheckTrigger
+
+		// Ignore stale triggers the API has already given up on
+		db, cancel := h.query()
+		err := db.Where("claimed_by = ? AND requested_at >= ?", "", time.Now().Add(-h.cfg.CheckNowTimeout)).
+			Order("requested_at").
+			Find(&triggers).Error
+		cancel()
+		if err != nil {
+			log.Printf("❌ Failed to fetch check triggers: %v", err)
+			continue
+		}
+
+		for _, trigger := range triggers {
+			h.handleCheckTrigger(trigger)
+		}
+	}
+}
+
+func (h *Hub) handleCheckTrigger(trigger models.CheckTrigger) {
+	db, cancel := h.query()
+	defer cancel()
+
+	// Claim the trigger first so only one hub instance dispatches it
+	result := db.Model(&models.CheckTrigger{}).
+		Where("id = ? AND claimed_by = ?", trigger.ID, "").
+		Update("claimed_by", h.cfg.HubID)
+	if result.Error != nil {
+		log.Printf("❌ Failed to claim check trigger %s: %v", trigger.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	sent := 0
+	var reusedFrom *time.Time
+
+	var website models.Website
+	if err := db.Where("id = ?", trigger.WebsiteID).First(&website).Error; err != nil {
+		log.Printf("⚠️  Check trigger %s for unknown website %s: %v", trigger.ID, trigger.WebsiteID, err)
+	} else if recent, ok := h.checks.fresh(website, time.Now()); ok {
+		// The API waits for the results of those checks instead
+		sent, reusedFrom = recent.sent, &recent.at
+		log.Printf("⚡ Check-now for %s reuses the checks sent %s ago", website.URL, time.Since(recent.at).Round(time.Millisecond))
+	} else {
+		validators := eligible(website, h.dropBanned(h.connectedValidators()))
+		h.assignments.add(validators)
+		sent = h.dispatchWebsite(website, validators)
+		log.Printf("⚡ Check-now dispatched for %s to %d validators", website.URL, sent)
+	}
+
+	now := time.Now()
+	if err := db.Model(&trigger).Updates(map[string]interface{}{
+		"dispatched_at":    now,
+		"dispatched_count": sent,
+		"reused_from":      reusedFrom,
+	}).Error; err != nil {
+		log.Printf("❌ Failed to mark check trigger %s dispatched: %v", trigger.ID, err)
+		return
+	}
+
+	h.publish(events.Event{
+		Type:       events.TypeCheckDispatched,
+		WebsiteID:  trigger.WebsiteID,
+		OccurredAt: now,
+		TriggerID:  trigger.ID,
+		Dispatched: sent,
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestCheckTriggerProducesTick(t *testing.T) {
+	h := newTestHub(t)
+	v := newTestValidator(t, h.db)
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(v)
+	website := createWebsite(t, h.db, models.Website{})
+
+	trigger := models.CheckTrigger{ID: uuid.New().String(), WebsiteID: website.ID, RequestedAt: time.Now()}
+	if err := h.db.Create(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	h.handleCheckTrigger(trigger)
+
+	if err := h.db.Where("id = ?", trigger.ID).First(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	if trigger.ClaimedBy != h.cfg.HubID || trigger.DispatchedAt == nil || trigger.DispatchedCount != 1 {
+		t.Fatalf("trigger claimed by %q, dispatched at %v to %d; want dispatched by this hub to 1 validator",
+			trigger.ClaimedBy, trigger.DispatchedAt, trigger.DispatchedCount)
+	}
+
+	sent := client.nextTask()
+	if sent.WebsiteID != website.ID || sent.URL != website.URL {
+		t.Fatalf("task for %s (%s), want %s", sent.WebsiteID, sent.URL, website.ID)
+	}
+	client.send(v.result(t, sent.CallbackID, sent.Nonce, models.StatusGood, 42))
+	waitFor(t, "the tick", func() bool { return tickCount(t, h.db, website.ID) == 1 })
+}
+
+func TestCheckTriggerDispatchedOnce(t *testing.T) {
+	h := newTestHub(t)
+	client := dial(t, serveHub(t, h), nil)
+	client.signUp(newTestValidator(t, h.db))
+	website := createWebsite(t, h.db, models.Website{})
+
+	trigger := models.CheckTrigger{ID: uuid.New().String(), WebsiteID: website.ID, RequestedAt: time.Now()}
+	if err := h.db.Create(&trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+	// A second poll, here or on another hub, finds the trigger already claimed
+	h.handleCheckTrigger(trigger)
+	h.handleCheckTrigger(trigger)
+
+	h.callbackMu.RLock()
+	pending := h.inFlight[website.ID]
+	h.callbackMu.RUnlock()
+	if pending != 1 {
+		t.Errorf("%d checks pending for the website, want 1", pending)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/gorilla/websocket"
+)
+
+func TestSignupRejectsUnsupportedVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    int
+		shown      int  // how the hub reports the version in its close reason
+		registered bool // signed up with a supported version first
+	}{
+		{"unversioned", 0, 1, false},
+		{"too old", protocol.MinVersion - 1, protocol.MinVersion - 1, false},
+		{"too new", protocol.Version + 1, protocol.Version + 1, false},
+		{"too old after registering", protocol.MinVersion - 1, protocol.MinVersion - 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub(t)
+			v := newTestValidator(t, h.db)
+			client := dial(t, serveHub(t, h), nil)
+			if tt.registered {
+				client.signUp(v)
+			}
+			client.send(v.signup(t, client.challenge, tt.version))
+
+			var closeErr *websocket.CloseError
+			if err := client.closed(); !errors.As(err, &closeErr) || closeErr.Code != protocol.CloseUnsupportedVersion {
+				t.Fatalf("connection ended with %v, want close code %d", err, protocol.CloseUnsupportedVersion)
+			}
+			want := fmt.Sprintf("unsupported protocol version %d (hub supports %d-%d)", tt.shown, protocol.MinVersion, protocol.Version)
+			if !strings.Contains(closeErr.Text, want) {
+				t.Errorf("close reason %q, want %q", closeErr.Text, want)
+			}
+			// A connection already registered is dropped along with its socket
+			waitFor(t, "the validator to be removed", func() bool { return len(h.connectedValidators()) == 0 })
+		})
+	}
+}
+
+func TestSignupAcceptsSupportedVersions(t *testing.T) {
+	for _, version := range []int{protocol.MinVersion, protocol.Version} {
+		t.Run(fmt.Sprint(version), func(t *testing.T) {
+			h := newTestHub(t)
+			v := newTestValidator(t, h.db)
+			client := dial(t, serveHub(t, h), nil)
+			client.send(v.signup(t, client.challenge, version))
+
+			var confirmed struct {
+				ProtocolVersion int `json:"protocolVersion"`
+			}
+			if err := json.Unmarshal(client.expect("signup"), &confirmed); err != nil || confirmed.ProtocolVersion != protocol.Version {
+				t.Fatalf("signup confirmed with version %d, want the hub's %d", confirmed.ProtocolVersion, protocol.Version)
+			}
+			validators := h.connectedValidators()
+			if len(validators) != 1 || validators[0].ProtocolVersion != version {
+				t.Fatalf("connected %d validators, want one speaking version %d", len(validators), version)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up            apply all pending migrations
+  up-to <id>    apply pending migrations up to and including <id>
+  down          roll back the last applied migration
+  down-to <id>  roll back until <id> is the last applied migration`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	db, err := database.Connect(cfg.DatabaseURL, database.LogOptions{
+		Level:         cfg.DBLogLevel,
+		SlowThreshold: cfg.DBSlowThreshold,
+	})
+	if err != nil {
+		log.Fatal("❌ Database connection failed:", err)
+	}
+
+	command := os.Args[1]
+	switch command {
+	case "up":
+		err = database.Migrate(db)
+	case "up-to":
+		err = database.MigrateTo(db, argID())
+	case "down":
+		err = database.RollbackLast(db)
+	case "down-to":
+		err = database.RollbackTo(db, argID())
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("❌ migrate %s failed: %v", command, err)
+	}
+	log.Printf("✅ migrate %s done", command)
+}
+
+func argID() string {
+	if len(os.Args) < 3 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	return os.Args[2]
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/services"
+	"github.com/streadway/amqp"
+)
+
+const usage = `Usage: payouts <command> [flags]
+
+Commands:
+  replay-dlq    move dead-lettered payouts back onto payout_queue
+                  -limit N    replay at most N messages (default 10)
+                  -dry-run    list the messages without moving them`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "replay-dlq" {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("replay-dlq", flag.ExitOnError)
+	limit := flags.Int("limit", 10, "replay at most this many messages")
+	dryRun := flags.Bool("dry-run", false, "list the messages without moving them")
+	flags.Parse(os.Args[2:])
+
+	if *limit < 1 {
+		log.Fatal("❌ -limit must be at least 1")
+	}
+
+	cfg := config.Load()
+
+	db, err := database.Connect(cfg.DatabaseURL, database.LogOptions{
+		Level:         cfg.DBLogLevel,
+		SlowThreshold: cfg.DBSlowThreshold,
+	})
+	if err != nil {
+		log.Fatal("❌ Database connection failed:", err)
+	}
+
+	conn, err := amqp.Dial(cfg.RabbitMQURL)
+	if err != nil {
+		log.Fatal("❌ Failed to connect to RabbitMQ:", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Fatal("❌ Failed to open RabbitMQ channel:", err)
+	}
+	defer ch.Close()
+
+	letters, err := services.ReplayDeadLetters(ch, db, services.ReplayOptions{
+		Limit:        *limit,
+		DryRun:       *dryRun,
+		QueryTimeout: cfg.DBQueryTimeout,
+	})
+
+	verb := "Replayed"
+	if *dryRun {
+		verb = "Would replay"
+	}
+	for _, letter := range letters {
+		log.Printf("🔄 %s payout for validator %s (%.2f lamports): %s", verb, letter.ValidatorID, letter.Amount, letter.Reason)
+		if letter.TxSignature != "" {
+			log.Printf("   ⚠️  Earlier transaction %s may have landed; check it before replaying", letter.TxSignature)
+		}
+	}
+
+	if err != nil {
+		log.Fatalf("❌ replay-dlq failed after %d messages: %v", len(letters), err)
+	}
+	log.Printf("✅ %s %d dead-lettered payouts", verb, len(letters))
+}
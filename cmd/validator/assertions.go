@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxAssertionBodyBytes bounds how much of a response body is read for assertions
+const maxAssertionBodyBytes = 1 << 20 // 1 MiB
+
+type Assertion struct {
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+	Expected   string `json:"expected"`
+}
+
+// checkAssertions evaluates every assertion against resp. Header assertions use
+// its headers; a bounded prefix of the body is read only if another assertion needs it.
+func checkAssertions(resp *http.Response, assertions []Assertion) error {
+	var data []byte
+	read := false
+
+	for i, a := range assertions {
+		if a.Type != "header" && !read {
+			var err error
+			if data, err = io.ReadAll(io.LimitReader(resp.Body, maxAssertionBodyBytes)); err != nil {
+				return fmt.Errorf("failed to read body: %w", err)
+			}
+			read = true
+		}
+
+		var err error
+		switch a.Type {
+		case "header":
+			err = assertHeader(resp.Header, a.Expression, a.Expected)
+		case "jsonpath":
+			err = assertJSONPath(data, a.Expression, a.Expected)
+		case "regex":
+			err = assertRegex(data, a.Expression, a.Expected)
+		default:
+			err = fmt.Errorf("unknown assertion type %q", a.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("assertion %d failed: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// assertJSONPath resolves a simple path ($.a.b[0].c) and compares it with expected.
+// An empty expected value only requires the path to exist.
+func assertJSONPath(data []byte, path, expected string) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("body is not valid JSON: %w", err)
+	}
+
+	value, err := lookupJSONPath(doc, path)
+	if err != nil {
+		return err
+	}
+	if expected == "" {
+		return nil
+	}
+
+	actual, ok := value.(string)
+	if !ok {
+		encoded, _ := json.Marshal(value)
+		actual = string(encoded)
+	}
+	if actual != expected {
+		return fmt.Errorf("%s = %q, expected %q", path, actual, expected)
+	}
+	return nil
+}
+
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	current := doc
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not an object", key)
+			}
+			if current, ok = obj[key]; !ok {
+				return nil, fmt.Errorf("key %q not found", key)
+			}
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			segment := rest[1:end]
+			rest = rest[end+1:]
+
+			if quoted := strings.Trim(segment, `'"`); quoted != segment {
+				obj, ok := current.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("%q is not an object", quoted)
+				}
+				if current, ok = obj[quoted]; !ok {
+					return nil, fmt.Errorf("key %q not found", quoted)
+				}
+				continue
+			}
+
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", segment)
+			}
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("index %d applied to non-array", index)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("index %d out of range", index)
+			}
+			current = arr[index]
+		default:
+			// Allow paths without the leading "$."
+			rest = "." + rest
+		}
+	}
+	return current, nil
+}
+
+// assertRegex requires the body to match pattern. When expected is set, the first
+// capture group (or the whole match if there are none) must equal it.
+func assertRegex(data []byte, pattern, expected string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+
+	match := re.FindSubmatch(data)
+	if match == nil {
+		return fmt.Errorf("body does not match %q", pattern)
+	}
+	if expected == "" {
+		return nil
+	}
+
+	actual := match[0]
+	if len(match) > 1 {
+		actual = match[1]
+	}
+	if string(actual) != expected {
+		return fmt.Errorf("%q captured %q, expected %q", pattern, actual, expected)
+	}
+	return nil
+}
+
+// assertHeader requires the response to carry header name. When expected is set,
+// one of its values must equal it, ignoring surrounding whitespace.
+func assertHeader(header http.Header, name, expected string) error {
+	values := header.Values(name)
+	if len(values) == 0 {
+		return fmt.Errorf("header %s missing", name)
+	}
+	if expected == "" {
+		return nil
+	}
+
+	for _, value := range values {
+		if strings.TrimSpace(value) == strings.TrimSpace(expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("header %s = %q, expected %q", name, strings.Join(values, ", "), expected)
+}
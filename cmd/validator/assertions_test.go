@@ -0,0 +1,166 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func response(body string, header http.Header) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestCheckAssertionsJSONPath(t *testing.T) {
+	body := `{"status":"ok","checks":[{"name":"db","up":true}],"version":3}`
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		wantErr   string
+	}{
+		{"matching string", Assertion{Type: "jsonpath", Expression: "$.status", Expected: "ok"}, ""},
+		{"nested array element", Assertion{Type: "jsonpath", Expression: "$.checks[0].name", Expected: "db"}, ""},
+		{"non-string value", Assertion{Type: "jsonpath", Expression: "$.checks[0].up", Expected: "true"}, ""},
+		{"existence only", Assertion{Type: "jsonpath", Expression: "$.version"}, ""},
+		{"mismatched value", Assertion{Type: "jsonpath", Expression: "$.status", Expected: "down"}, `$.status = "ok", expected "down"`},
+		{"missing key", Assertion{Type: "jsonpath", Expression: "$.uptime"}, `key "uptime" not found`},
+		{"index out of range", Assertion{Type: "jsonpath", Expression: "$.checks[1]"}, "index 1 out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAssertions(response(body, nil), []Assertion{tt.assertion})
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckAssertionsJSONPathInvalidBody(t *testing.T) {
+	err := checkAssertions(response("<html>down</html>", nil), []Assertion{{Type: "jsonpath", Expression: "$.status"}})
+	checkError(t, err, "body is not valid JSON")
+}
+
+func TestCheckAssertionsRegex(t *testing.T) {
+	body := `<p>build version: "42"</p>`
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		wantErr   string
+	}{
+		{"match", Assertion{Type: "regex", Expression: `build version`}, ""},
+		{"capture group", Assertion{Type: "regex", Expression: `version:\s*"(\d+)"`, Expected: "42"}, ""},
+		{"whole match without groups", Assertion{Type: "regex", Expression: `\d+`, Expected: "42"}, ""},
+		{"no match", Assertion{Type: "regex", Expression: `maintenance`}, `body does not match "maintenance"`},
+		{"wrong capture", Assertion{Type: "regex", Expression: `version:\s*"(\d+)"`, Expected: "43"}, `captured "42", expected "43"`},
+		{"invalid pattern", Assertion{Type: "regex", Expression: `(`}, "invalid regex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAssertions(response(body, nil), []Assertion{tt.assertion})
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckAssertionsReportsFailingIndex(t *testing.T) {
+	assertions := []Assertion{
+		{Type: "jsonpath", Expression: "$.status", Expected: "ok"},
+		{Type: "regex", Expression: `"status"`},
+		{Type: "jsonpath", Expression: "$.status", Expected: "degraded"},
+	}
+	err := checkAssertions(response(`{"status":"ok"}`, nil), assertions)
+	checkError(t, err, "assertion 2 failed")
+}
+
+func TestCheckAssertionsHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Strict-Transport-Security", " max-age=63072000 ")
+	header.Add("Vary", "Accept-Encoding")
+	header.Add("Vary", "Origin")
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		wantErr   string
+	}{
+		{"present", Assertion{Type: "header", Expression: "Strict-Transport-Security"}, ""},
+		{"name is case-insensitive", Assertion{Type: "header", Expression: "strict-transport-security"}, ""},
+		{"matching value", Assertion{Type: "header", Expression: "Strict-Transport-Security", Expected: "max-age=63072000"}, ""},
+		{"one of several values", Assertion{Type: "header", Expression: "Vary", Expected: "Origin"}, ""},
+		{"missing", Assertion{Type: "header", Expression: "Content-Security-Policy"}, "header Content-Security-Policy missing"},
+		{"mismatched value", Assertion{Type: "header", Expression: "Strict-Transport-Security", Expected: "max-age=0"},
+			`expected "max-age=0"`},
+		{"mismatched among several", Assertion{Type: "header", Expression: "Vary", Expected: "Cookie"}, `header Vary = "Accept-Encoding, Origin"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAssertions(response("", header), []Assertion{tt.assertion})
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// failingBody errors when read
+type failingBody struct{}
+
+func (failingBody) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+func (failingBody) Close() error             { return nil }
+
+func TestCheckAssertionsHeaderOnlySkipsBody(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Ok": {"1"}}, Body: failingBody{}}
+	if err := checkAssertions(resp, []Assertion{{Type: "header", Expression: "X-Ok"}}); err != nil {
+		t.Fatalf("header assertion read the body: %v", err)
+	}
+	err := checkAssertions(resp, []Assertion{{Type: "header", Expression: "X-Ok"}, {Type: "regex", Expression: "ok"}})
+	checkError(t, err, "failed to read body")
+}
+
+func TestCheckWebsiteHeaderAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Strict-Transport-Security", "max-age=63072000")
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	v := newTestValidatorClient(t)
+
+	tests := []struct {
+		name       string
+		assertion  Assertion
+		wantStatus string
+	}{
+		{"present", Assertion{Type: "header", Expression: "Strict-Transport-Security"}, "Good"},
+		{"matching value", Assertion{Type: "header", Expression: "Strict-Transport-Security", Expected: "max-age=63072000"}, "Good"},
+		{"missing", Assertion{Type: "header", Expression: "Content-Security-Policy"}, "Bad"},
+		{"mismatched value", Assertion{Type: "header", Expression: "Strict-Transport-Security", Expected: "max-age=0"}, "Bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := v.checkWebsite(ValidateData{URL: server.URL, Assertions: []Assertion{tt.assertion}})
+			if result.Status != tt.wantStatus {
+				t.Errorf("result = %s (%s), want %s", result.Status, result.Detail, tt.wantStatus)
+			}
+			if tt.wantStatus == "Bad" && !strings.Contains(result.Detail, "header") {
+				t.Errorf("detail %q doesn't name the header", result.Detail)
+			}
+		})
+	}
+}
+
+// checkError fails t unless err contains want, or is nil when want is empty
+func checkError(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Fatalf("expected an error containing %q, got none", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Fatalf("expected an error containing %q, got %q", want, err)
+	}
+}
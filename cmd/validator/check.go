@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+)
+
+// Address family preferences a website can request
+const (
+	familyAuto = "auto"
+	familyIPv4 = "ipv4"
+	familyIPv6 = "ipv6"
+	familyDual = "dual" // check both families and report the worse result
+)
+
+// HTTP versions a website can request
+const (
+	httpVersionAuto = "auto"  // HTTP/2 if the server offers it over TLS, otherwise HTTP/1.1
+	httpVersion1    = "http1" // never negotiate HTTP/2
+	httpVersion2    = "http2" // fail the check unless HTTP/2 was negotiated
+)
+
+// clientKey picks the shared client for an address family and HTTP version
+type clientKey struct {
+	family      string
+	httpVersion string
+}
+
+// checkResult is the outcome of a single HTTP check
+type checkResult struct {
+	Status   string
+	Detail   string
+	Latency  float64 // milliseconds, to the microsecond
+	Protocol string  // of the last response, e.g. HTTP/1.1 (empty if none arrived)
+}
+
+// latencyBetween is the time from start to end in milliseconds. It keeps
+// microseconds so fast checks aren't reported as 0ms.
+func latencyBetween(start, end time.Time) float64 {
+	return float64(end.Sub(start).Microseconds()) / 1000
+}
+
+// checkWebsite runs the check over the website's preferred address family and
+// HTTP version
+func (v *ValidatorClient) checkWebsite(data ValidateData) checkResult {
+	httpVersion := data.HTTPVersion
+	if httpVersion != httpVersion1 && httpVersion != httpVersion2 {
+		httpVersion = httpVersionAuto
+	}
+	client := func(family string) *http.Client {
+		return v.httpClients[clientKey{family: family, httpVersion: httpVersion}]
+	}
+
+	switch data.AddressFamily {
+	case familyIPv4, familyIPv6:
+		return v.runCheck(client(data.AddressFamily), data)
+	case familyDual:
+		ipv4 := v.runCheck(client(familyIPv4), data)
+		ipv6 := v.runCheck(client(familyIPv6), data)
+
+		combined := checkResult{
+			Status:   "Good",
+			Latency:  max(ipv4.Latency, ipv6.Latency),
+			Protocol: ipv4.Protocol,
+		}
+		if ipv4.Protocol != ipv6.Protocol {
+			combined.Protocol = strings.Join([]string{ipv4.Protocol, ipv6.Protocol}, ",")
+		}
+		var details []string
+		for _, family := range []struct {
+			name   string
+			result checkResult
+		}{{familyIPv4, ipv4}, {familyIPv6, ipv6}} {
+			entry := family.name + ": " + family.result.Status
+			if family.result.Detail != "" {
+				entry += " (" + family.result.Detail + ")"
+			}
+			details = append(details, entry)
+			if family.result.Status != "Good" {
+				combined.Status = "Bad"
+			}
+		}
+		combined.Detail = strings.Join(details, "; ")
+		return combined
+	default:
+		return v.runCheck(client(familyAuto), data)
+	}
+}
+
+// runCheck performs the website's steps, or a single GET of its URL, with the given
+// client. The per-check timeout covers the whole sequence.
+func (v *ValidatorClient) runCheck(client *http.Client, data ValidateData) checkResult {
+	timeout := v.checkTimeout
+	if data.TimeoutMs > 0 {
+		timeout = time.Duration(data.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	steps := data.Steps
+	if len(steps) == 0 {
+		steps = []Step{{Method: http.MethodGet, URL: data.URL, Assertions: data.Assertions}}
+	} else {
+		// Cookies set by one step are sent by the next, and only within this check
+		jar, _ := cookiejar.New(nil)
+		stepClient := *client
+		stepClient.Jar = jar
+		client = &stepClient
+	}
+
+	startTime := time.Now()
+	result := checkResult{Status: "Good"}
+
+	// Latency runs until the last response arrived, not until its body was read
+	var responded time.Time
+	headers := http.Header{}
+	for i, step := range steps {
+		for name, value := range step.Headers {
+			headers.Set(name, value)
+		}
+		var err error
+		responded, result.Protocol, err = runStep(ctx, client, step, headers, data.HTTPVersion == httpVersion2)
+		if err != nil {
+			result.Status = "Bad"
+			result.Detail = err.Error()
+			if len(steps) > 1 {
+				result.Detail = fmt.Sprintf("step %d (%s %s): %s", i+1, step.method(), step.URL, err)
+			}
+			break
+		}
+	}
+
+	if responded.IsZero() {
+		responded = time.Now()
+	}
+	result.Latency = latencyBetween(startTime, responded)
+	return result
+}
+
+// runStep sends one request and checks its protocol, status code and assertions.
+// It returns when the response arrived and its protocol, or the zero time and an
+// empty protocol if none did. requireHTTP2 fails responses that came over HTTP/1.x.
+func runStep(ctx context.Context, client *http.Client, step Step, headers http.Header, requireHTTP2 bool) (time.Time, string, error) {
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(step.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, step.method(), step.URL, body)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	req.Header = headers.Clone()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	responded := time.Now()
+	defer resp.Body.Close()
+
+	// Cleartext requests never get HTTP/2, since validators don't speak h2c
+	if requireHTTP2 && resp.ProtoMajor != 2 {
+		err = fmt.Errorf("server did not negotiate HTTP/2 (got %s)", resp.Proto)
+	} else if expected := step.expectedStatus(); resp.StatusCode != expected {
+		err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	} else {
+		err = checkAssertions(resp, step.Assertions)
+	}
+
+	// Drain what's left so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxAssertionBodyBytes))
+	return responded, resp.Proto, err
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+)
+
+// familyRecorder hands out clients that note which address family dialed
+type familyRecorder struct {
+	mu     sync.Mutex
+	dialed []string
+}
+
+// clients replaces v's clients with recording ones for every family and HTTP version
+func (r *familyRecorder) clients(v *ValidatorClient) {
+	for key := range v.httpClients {
+		family := key.family
+		var dialer net.Dialer
+		v.httpClients[key] = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				r.mu.Lock()
+				r.dialed = append(r.dialed, family)
+				r.mu.Unlock()
+				return dialer.DialContext(ctx, network, addr)
+			},
+			DisableKeepAlives: true,
+		}}
+	}
+}
+
+func (r *familyRecorder) families() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.dialed)
+}
+
+func TestCheckWebsiteAddressFamily(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(ok))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		family string
+		want   []string
+	}{
+		{"", []string{familyAuto}},
+		{familyAuto, []string{familyAuto}},
+		{familyIPv4, []string{familyIPv4}},
+		{familyIPv6, []string{familyIPv6}},
+		{familyDual, []string{familyIPv4, familyIPv6}},
+		{"ipx", []string{familyAuto}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			v := newTestValidatorClient(t)
+			recorder := &familyRecorder{}
+			recorder.clients(v)
+
+			if result := v.checkWebsite(ValidateData{URL: server.URL, AddressFamily: tt.family}); result.Status != "Good" {
+				t.Fatalf("check: %s (%s)", result.Status, result.Detail)
+			}
+			if got := recorder.families(); !slices.Equal(got, tt.want) {
+				t.Errorf("dialed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// trustServer makes every client of v trust server's test certificate
+func trustServer(v *ValidatorClient, server *httptest.Server) {
+	roots := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	for _, client := range v.httpClients {
+		client.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+	}
+}
+
+func TestCheckWebsiteHTTPVersion(t *testing.T) {
+	newServer := func(h2 bool) (*httptest.Server, *[]string) {
+		var mu sync.Mutex
+		var served []string
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			served = append(served, r.Proto)
+			mu.Unlock()
+		}))
+		server.EnableHTTP2 = h2
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server, &served
+	}
+
+	tests := []struct {
+		name         string
+		h2           bool
+		httpVersion  string
+		wantStatus   string
+		wantProtocol string
+		wantDetail   string
+	}{
+		{"h2 server, auto", true, httpVersionAuto, "Good", "HTTP/2.0", ""},
+		{"h2 server, unset", true, "", "Good", "HTTP/2.0", ""},
+		{"h2 server, forced HTTP/1.1", true, httpVersion1, "Good", "HTTP/1.1", ""},
+		{"h2 server, forced HTTP/2", true, httpVersion2, "Good", "HTTP/2.0", ""},
+		{"h1 server, auto", false, httpVersionAuto, "Good", "HTTP/1.1", ""},
+		{"h1 server, forced HTTP/1.1", false, httpVersion1, "Good", "HTTP/1.1", ""},
+		{"h1 server, forced HTTP/2", false, httpVersion2, "Bad", "HTTP/1.1", "server did not negotiate HTTP/2 (got HTTP/1.1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, served := newServer(tt.h2)
+			v := newTestValidatorClient(t)
+			trustServer(v, server)
+
+			result := v.checkWebsite(ValidateData{URL: server.URL, HTTPVersion: tt.httpVersion})
+			if result.Status != tt.wantStatus || result.Detail != tt.wantDetail {
+				t.Errorf("check: %s (%s), want %s (%s)", result.Status, result.Detail, tt.wantStatus, tt.wantDetail)
+			}
+			if result.Protocol != tt.wantProtocol {
+				t.Errorf("recorded protocol %q, want %q", result.Protocol, tt.wantProtocol)
+			}
+			if len(*served) != 1 || (*served)[0] != tt.wantProtocol {
+				t.Errorf("server saw %v, want one %s request", *served, tt.wantProtocol)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRestrictsNetwork(t *testing.T) {
+	cfg := config.Load()
+	ipv4, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipv6, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		ipv4.Close()
+		t.Skipf("no IPv6 loopback: %v", err)
+	}
+	for _, l := range []net.Listener{ipv4, ipv6} {
+		server := &httptest.Server{Listener: l, Config: &http.Server{Handler: http.HandlerFunc(ok)}}
+		server.Start()
+		t.Cleanup(server.Close)
+	}
+
+	tests := []struct {
+		network string
+		addr    string
+		reached bool
+	}{
+		{"tcp4", ipv4.Addr().String(), true},
+		{"tcp4", ipv6.Addr().String(), false},
+		{"tcp6", ipv6.Addr().String(), true},
+		{"tcp6", ipv4.Addr().String(), false},
+		{"tcp", ipv4.Addr().String(), true},
+		{"tcp", ipv6.Addr().String(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.network+" to "+tt.addr, func(t *testing.T) {
+			client, err := newHTTPClient(cfg, tt.network, httpVersionAuto, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Get("http://" + tt.addr)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if reached := err == nil; reached != tt.reached {
+				t.Errorf("reached = %v (%v), want %v", reached, err, tt.reached)
+			}
+		})
+	}
+}
+
+func TestCheckWebsiteTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name      string
+		timeoutMs int
+		want      string
+	}{
+		{"website timeout shorter than the response", 100, "Bad"},
+		{"website timeout longer than CHECK_TIMEOUT", 2000, "Good"},
+		{"default CHECK_TIMEOUT", 0, "Bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestValidatorClient(t, func(cfg *config.Config) { cfg.CheckTimeout = 150 * time.Millisecond })
+
+			started := time.Now()
+			result := v.checkWebsite(ValidateData{URL: server.URL, TimeoutMs: tt.timeoutMs})
+			if result.Status != tt.want {
+				t.Errorf("status = %s (%s), want %s", result.Status, result.Detail, tt.want)
+			}
+			if took := time.Since(started); tt.want == "Bad" && took > 280*time.Millisecond {
+				t.Errorf("timed out check took %s, want it cut short", took)
+			}
+		})
+	}
+}
+
+func TestLatencyBetween(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{250 * time.Microsecond, 0.25},
+		{999 * time.Microsecond, 0.999},
+		{1500 * time.Microsecond, 1.5},
+		{250*time.Microsecond + 400*time.Nanosecond, 0.25}, // below a microsecond is dropped
+		{120 * time.Millisecond, 120},
+	}
+	for _, tt := range tests {
+		if got := latencyBetween(start, start.Add(tt.elapsed)); got != tt.want {
+			t.Errorf("latency over %s = %vms, want %vms", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestFastCheckLatencyNonZero(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	v := newTestValidatorClient(t)
+
+	// Warm up so the measured check reuses the connection and finishes quickly
+	v.checkWebsite(ValidateData{URL: server.URL})
+	result := v.checkWebsite(ValidateData{URL: server.URL})
+	if result.Status != "Good" {
+		t.Fatalf("status = %s (%s), want Good", result.Status, result.Detail)
+	}
+	if result.Latency <= 0 {
+		t.Errorf("latency = %vms, want a fast local check to report above 0", result.Latency)
+	}
+}
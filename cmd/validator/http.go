@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+// newHTTPClient builds a client shared across checks so connections to the same host
+// are kept alive and reused. network restricts dialing to "tcp4" or "tcp6" ("tcp"
+// leaves the choice to the system). Per-check timeouts are applied through the request
+// context rather than http.Client.Timeout. proxy picks the proxy for each request, and
+// resolver (nil for the system's) looks up the websites' hostnames. An httpVersion of
+// http1 never negotiates HTTP/2; whether an http2 check got it is up to runStep.
+func newHTTPClient(cfg *config.Config, network, httpVersion string, proxy func(*http.Request) (*url.URL, error), resolver *net.Resolver) (*http.Client, error) {
+	minVersion, err := parseTLSVersion(cfg.ValidatorTLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.CheckTimeout,
+		KeepAlive: cfg.ValidatorKeepAlive,
+		Resolver:  resolver,
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.ValidatorMaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.ValidatorMaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.ValidatorIdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       &tls.Config{MinVersion: minVersion},
+	}
+	if httpVersion == httpVersion1 {
+		// A non-nil, empty TLSNextProto keeps HTTP/2 out of the ALPN offer
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// checkProxy returns the proxy checks egress through: raw (VALIDATOR_PROXY_URL)
+// when set, otherwise HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment.
+// The returned URL is nil when checks go direct or follow the environment.
+func checkProxy(raw string) (func(*http.Request) (*url.URL, error), *url.URL, error) {
+	if raw == "" {
+		return http.ProxyFromEnvironment, nil, nil
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.Host == "" {
+		return nil, nil, fmt.Errorf("invalid VALIDATOR_PROXY_URL %q", raw)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, nil, fmt.Errorf("VALIDATOR_PROXY_URL scheme must be http, https or socks5, got %q", proxyURL.Scheme)
+	}
+	return http.ProxyURL(proxyURL), proxyURL, nil
+}
+
+// checkResolver returns a resolver that sends every lookup to server
+// (VALIDATOR_DNS_SERVER, port 53 when omitted), or nil for the system resolver
+func checkResolver(server string) (*net.Resolver, string, error) {
+	if server == "" {
+		return nil, "", nil
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	if _, port, err := net.SplitHostPort(server); err != nil || port == "" {
+		return nil, "", fmt.Errorf("invalid VALIDATOR_DNS_SERVER %q", server)
+	}
+
+	var dialer net.Dialer
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, server)
+		},
+	}, server, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q", version)
+	}
+}
+
+// newHubDialer builds the websocket dialer for the hub connection. wss:// hubs
+// are verified against the system roots, plus HubTLSCAFile for self-signed or
+// private CAs; HubTLSInsecureSkipVerify turns verification off for development.
+func newHubDialer(cfg *config.Config) (*websocket.Dialer, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.HubTLSInsecureSkipVerify,
+	}
+
+	if cfg.HubTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.HubTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read HUB_TLS_CA_FILE: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in HUB_TLS_CA_FILE %s", cfg.HubTLSCAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConfig
+	return &dialer, nil
+}
@@ -1,28 +1,37 @@
 package main
 
 import (
-	"crypto/ed25519"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/datmedevil17/gopher-uptime/internal/signing"
 	"github.com/gagliardetto/solana-go"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 type ValidatorClient struct {
-	conn        *websocket.Conn
-	connMu      sync.Mutex
-	keypair     solana.PrivateKey
-	validatorID string
-	callbacks   map[string]func(OutgoingMessage)
+	conn         *websocket.Conn
+	connMu       sync.Mutex
+	signer       signing.Signer
+	validatorID  string
+	callbacks    map[string]func(OutgoingMessage)
+	httpClients  map[clientKey]*http.Client
+	checkTimeout time.Duration
+	hubDialer    *websocket.Dialer
+	hubToken     string  // sent on the handshake when the hub requires one
+	maxLatency   float64 // milliseconds; the hub rejects results reporting more
+	stats        checkStats
 }
 
 type IncomingMessage struct {
@@ -41,41 +50,132 @@ type SignupData struct {
 }
 
 type ValidateData struct {
-	URL        string `json:"url"`
-	CallbackID string `json:"callbackId"`
-	WebsiteID  string `json:"websiteId"`
+	URL           string      `json:"url"`
+	CallbackID    string      `json:"callbackId"`
+	WebsiteID     string      `json:"websiteId"`
+	Assertions    []Assertion `json:"assertions"`
+	Steps         []Step      `json:"steps"` // run instead of a GET of URL when set
+	AddressFamily string      `json:"addressFamily"`
+	HTTPVersion   string      `json:"httpVersion"` // auto, http1 or http2; empty from hubs before version 6
+	TimeoutMs     int         `json:"timeoutMs"`   // 0 uses CHECK_TIMEOUT
+	Nonce         string      `json:"nonce"`
 }
 
-func NewValidatorClient(privateKey string) (*ValidatorClient, error) {
+func NewValidatorClient(privateKey string, cfg *config.Config) (*ValidatorClient, error) {
 	keypair, err := solana.PrivateKeyFromBase58(privateKey)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("✅ Validator initialized with public key: %s", keypair.PublicKey().String())
+	proxy, proxyURL, err := checkProxy(cfg.ValidatorProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		// Websites see the proxy's address, not this host's
+		log.Printf("🔌 Checks go through proxy %s", proxyURL.Redacted())
+	}
+
+	resolver, dnsServer, err := checkResolver(cfg.ValidatorDNSServer)
+	if err != nil {
+		return nil, err
+	}
+	if resolver != nil {
+		log.Printf("🔌 Resolving checked hostnames with %s", dnsServer)
+	}
+
+	// One shared client per address family and HTTP version so each keeps its own
+	// connection pool
+	httpClients := make(map[clientKey]*http.Client)
+	for family, network := range map[string]string{
+		familyAuto: "tcp",
+		familyIPv4: "tcp4",
+		familyIPv6: "tcp6",
+	} {
+		for _, httpVersion := range []string{httpVersionAuto, httpVersion1, httpVersion2} {
+			client, err := newHTTPClient(cfg, network, httpVersion, proxy, resolver)
+			if err != nil {
+				return nil, err
+			}
+			httpClients[clientKey{family: family, httpVersion: httpVersion}] = client
+		}
+	}
+
+	hubDialer, err := newHubDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := signing.NewEd25519Signer(keypair)
+	log.Printf("✅ Validator initialized with public key: %s (key %s)", signer.PublicKey(), signer.KeyID())
 
 	return &ValidatorClient{
-		keypair:   keypair,
-		callbacks: make(map[string]func(OutgoingMessage)),
+		signer:       signer,
+		callbacks:    make(map[string]func(OutgoingMessage)),
+		httpClients:  httpClients,
+		checkTimeout: cfg.CheckTimeout,
+		hubDialer:    hubDialer,
+		hubToken:     cfg.HubToken,
+		maxLatency:   float64(cfg.MaxReportedLatency.Milliseconds()),
+		stats:        checkStats{startedAt: time.Now()},
 	}, nil
 }
 
 func (v *ValidatorClient) Connect(hubURL string) error {
 	log.Printf("🔌 Connecting to hub: %s", hubURL)
 
-	conn, _, err := websocket.DefaultDialer.Dial(hubURL, nil)
+	var header http.Header
+	if v.hubToken != "" {
+		header = http.Header{"Authorization": {"Bearer " + v.hubToken}}
+	}
+
+	if strings.HasPrefix(hubURL, "ws://") {
+		log.Println("⚠️  Hub connection is not encrypted; use a wss:// HUB_URL outside development")
+	}
+
+	conn, resp, err := v.hubDialer.Dial(hubURL, header)
 	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("hub rejected the handshake token (HUB_TOKEN): %w", err)
+		}
 		return err
 	}
 	v.conn = conn
 
 	log.Println("✅ Connected to hub")
 
+	// The hub opens with a challenge that the signup must sign
+	challenge, err := v.readChallenge()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
 	// Start listening for messages
 	go v.listen()
 
 	// Sign up with hub
-	return v.signup()
+	return v.signup(challenge)
+}
+
+// challengeTimeout bounds how long to wait for the hub's signup challenge
+const challengeTimeout = 10 * time.Second
+
+func (v *ValidatorClient) readChallenge() (string, error) {
+	v.conn.SetReadDeadline(time.Now().Add(challengeTimeout))
+	defer v.conn.SetReadDeadline(time.Time{})
+
+	var msg OutgoingMessage
+	if err := v.conn.ReadJSON(&msg); err != nil {
+		return "", fmt.Errorf("failed to read signup challenge: %w", err)
+	}
+
+	data, _ := msg.Data.(map[string]interface{})
+	challenge, _ := data["challenge"].(string)
+	if msg.Type != "challenge" || challenge == "" {
+		return "", fmt.Errorf("hub did not send a signup challenge (got %q); it may run an incompatible protocol version", msg.Type)
+	}
+	return challenge, nil
 }
 
 func (v *ValidatorClient) listen() {
@@ -83,6 +183,10 @@ func (v *ValidatorClient) listen() {
 		var msg OutgoingMessage
 		err := v.conn.ReadJSON(&msg)
 		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && closeErr.Code == protocol.CloseUnsupportedVersion {
+				log.Fatalf("❌ Hub rejected this validator: %s. Upgrade the validator to a compatible release.", closeErr.Text)
+			}
 			log.Printf("❌ Read error: %v", err)
 			return
 		}
@@ -96,26 +200,46 @@ func (v *ValidatorClient) listen() {
 	}
 }
 
-func (v *ValidatorClient) signup() error {
+func (v *ValidatorClient) signup(challenge string) error {
 	callbackID := uuid.New().String()
-	message := "Signed message for " + callbackID + ", " + v.keypair.PublicKey().String()
-	signature := v.signMessage(message)
+	timestamp := time.Now().Unix()
+	signature := v.signer.Sign([]byte(protocol.SignupMessage(callbackID, v.signer.PublicKey(), timestamp, challenge)))
 
 	// Register callback for signup response
 	v.callbacks[callbackID] = func(msg OutgoingMessage) {
 		data := msg.Data.(map[string]interface{})
+
+		// Hubs predating versioning omit protocolVersion, which means version 1
+		hubVersion := 0
+		if version, ok := data["protocolVersion"].(float64); ok {
+			hubVersion = int(version)
+		}
+		if !protocol.Supported(hubVersion) {
+			reason := fmt.Sprintf("unsupported protocol version %d (validator supports %d-%d)",
+				protocol.Normalize(hubVersion), protocol.MinVersion, protocol.Version)
+			v.connMu.Lock()
+			v.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(protocol.CloseUnsupportedVersion, reason), time.Now().Add(time.Second))
+			v.connMu.Unlock()
+			log.Fatalf("❌ Incompatible hub: %s", reason)
+		}
+
 		v.validatorID = data["validatorId"].(string)
-		log.Printf("✅ Validator ID received: %s", v.validatorID)
+		log.Printf("✅ Validator ID received: %s (protocol v%d)", v.validatorID, protocol.Normalize(hubVersion))
 	}
 
 	// Send signup message
 	msg := IncomingMessage{
 		Type: "signup",
-		Data: mustMarshal(map[string]string{
-			"callbackId":    callbackID,
-			"ip":            "127.0.0.1",
-			"publicKey":     v.keypair.PublicKey().String(),
-			"signedMessage": signature,
+		Data: mustMarshal(map[string]interface{}{
+			"callbackId":      callbackID,
+			"ip":              "127.0.0.1",
+			"publicKey":       v.signer.PublicKey(),
+			"signedMessage":   signature,
+			"keyId":           v.signer.KeyID(),
+			"timestamp":       timestamp,
+			"nonce":           challenge,
+			"protocolVersion": protocol.Version,
 		}),
 	}
 
@@ -150,27 +274,33 @@ func (v *ValidatorClient) handleValidateRequest(data interface{}) {
 	go v.validateWebsite(validateData)
 }
 
-func (v *ValidatorClient) validateWebsite(data ValidateData) {
-	startTime := time.Now()
-
-	// Perform HTTP GET request
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// clampLatency brings a measured latency into the range the hub accepts
+func (v *ValidatorClient) clampLatency(latency float64) float64 {
+	latency = max(latency, 0)
+	if v.maxLatency > 0 {
+		latency = min(latency, v.maxLatency)
 	}
+	return latency
+}
 
-	resp, err := client.Get(data.URL)
-	latency := time.Since(startTime).Milliseconds()
+func (v *ValidatorClient) validateWebsite(data ValidateData) {
+	v.stats.inFlight.Add(1)
+	defer v.stats.inFlight.Add(-1)
 
-	status := "Bad"
-	if err == nil && resp.StatusCode == 200 {
-		status = "Good"
+	result := v.checkWebsite(data)
+	status, detail, latency := result.Status, result.Detail, v.clampLatency(result.Latency)
+	if latency != result.Latency {
+		log.Printf("⚠️  Clamping latency %.3fms for %s to %.3fms", result.Latency, data.URL, latency)
 	}
-	if resp != nil {
-		resp.Body.Close()
+
+	v.stats.completed.Add(1)
+	if status == "Bad" {
+		v.stats.failed.Add(1)
 	}
 
 	// Sign the response
-	signature := v.signMessage("Replying to " + data.CallbackID)
+	timestamp := time.Now().Unix()
+	signature := v.signer.Sign([]byte(protocol.ValidateMessage(data.CallbackID, timestamp, data.Nonce)))
 
 	// Send result back to hub
 	msg := IncomingMessage{
@@ -178,28 +308,28 @@ func (v *ValidatorClient) validateWebsite(data ValidateData) {
 		Data: mustMarshal(map[string]interface{}{
 			"callbackId":    data.CallbackID,
 			"status":        status,
-			"latency":       float64(latency),
+			"latency":       latency,
+			"detail":        detail,
+			"protocol":      result.Protocol,
 			"validatorId":   v.validatorID,
 			"websiteId":     data.WebsiteID,
 			"signedMessage": signature,
+			"timestamp":     timestamp,
+			"nonce":         data.Nonce,
 		}),
 	}
 
 	v.connMu.Lock()
 	if err := v.conn.WriteJSON(msg); err != nil {
 		v.connMu.Unlock()
+		v.stats.reportErrors.Add(1)
 		log.Printf("❌ Failed to send validation result: %v", err)
 	} else {
 		v.connMu.Unlock()
-		log.Printf("✅ Validation complete: %s - %s (%dms)", data.URL, status, latency)
+		log.Printf("✅ Validation complete: %s - %s (%.3fms)", data.URL, status, latency)
 	}
 }
 
-func (v *ValidatorClient) signMessage(message string) string {
-	signature := ed25519.Sign(ed25519.PrivateKey(v.keypair), []byte(message))
-	return base64.StdEncoding.EncodeToString(signature)
-}
-
 func mustMarshal(v interface{}) json.RawMessage {
 	data, _ := json.Marshal(v)
 	return data
@@ -215,7 +345,7 @@ func main() {
 	}
 
 	// Create validator client
-	client, err := NewValidatorClient(privateKey)
+	client, err := NewValidatorClient(privateKey, cfg)
 	if err != nil {
 		log.Fatal("❌ Failed to create validator:", err)
 	}
@@ -225,6 +355,8 @@ func main() {
 		log.Fatal("❌ Failed to connect to hub:", err)
 	}
 
+	go client.reportStatus(cfg.ValidatorStatusInterval)
+
 	log.Println("🚀 Validator running and waiting for tasks...")
 
 	// Wait for interrupt signal
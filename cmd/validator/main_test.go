@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+)
+
+func TestClampLatency(t *testing.T) {
+	v := newTestValidatorClient(t, func(cfg *config.Config) { cfg.MaxReportedLatency = 30 * time.Second })
+
+	tests := []struct {
+		name    string
+		latency float64
+		want    float64
+	}{
+		{"negative", -5, 0},
+		{"zero", 0, 0},
+		{"sub-millisecond", 0.25, 0.25},
+		{"in range", 1234.5, 1234.5},
+		{"at the maximum", 30000, 30000},
+		{"absurdly large", 1e12, 30000},
+		{"infinite", math.Inf(1), 30000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.clampLatency(tt.latency); got != tt.want {
+				t.Errorf("clampLatency(%v) = %v, want %v", tt.latency, got, tt.want)
+			}
+		})
+	}
+}
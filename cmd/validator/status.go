@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// checkStats are the counters reported to the hub in periodic status messages
+type checkStats struct {
+	startedAt    time.Time
+	inFlight     atomic.Int64
+	completed    atomic.Int64
+	failed       atomic.Int64 // checks that reported Bad
+	reportErrors atomic.Int64 // results that could not be sent to the hub
+}
+
+// reportStatus periodically sends the validator's own health to the hub
+func (v *ValidatorClient) reportStatus(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// Nothing to attribute the report to until signup completes
+		if v.validatorID == "" {
+			continue
+		}
+
+		msg := IncomingMessage{
+			Type: "status",
+			Data: mustMarshal(map[string]interface{}{
+				"inFlight":        v.stats.inFlight.Load(),
+				"checksCompleted": v.stats.completed.Load(),
+				"checksFailed":    v.stats.failed.Load(),
+				"reportErrors":    v.stats.reportErrors.Load(),
+				"uptimeSeconds":   int64(time.Since(v.stats.startedAt).Seconds()),
+			}),
+		}
+
+		v.connMu.Lock()
+		err := v.conn.WriteJSON(msg)
+		v.connMu.Unlock()
+		if err != nil {
+			log.Printf("❌ Failed to send status report: %v", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import "net/http"
+
+// Step is one request of a multi-step check, as sent by the hub
+type Step struct {
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers"`
+	Body         string            `json:"body"`
+	ExpectStatus int               `json:"expect_status"` // 0 expects 200
+	Assertions   []Assertion       `json:"assertions"`
+}
+
+func (s Step) method() string {
+	if s.Method == "" {
+		return http.MethodGet
+	}
+	return s.Method
+}
+
+func (s Step) expectedStatus() int {
+	if s.ExpectStatus == 0 {
+		return http.StatusOK
+	}
+	return s.ExpectStatus
+}
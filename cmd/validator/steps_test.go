@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// loginFlow is a site whose dashboard needs the session cookie set by logging in
+type loginFlow struct {
+	*httptest.Server
+	dashboardStatus int
+
+	mu       sync.Mutex
+	requests []string // method and path of every request, in order
+}
+
+func newLoginFlow(t *testing.T, dashboardStatus int) *loginFlow {
+	t.Helper()
+
+	f := &loginFlow{dashboardStatus: dashboardStatus}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		f.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/login":
+			if r.Method != http.MethodPost || r.Header.Get("X-Tenant") != "acme" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret", Path: "/"})
+		case "/dashboard":
+			// The header set by the first step is carried over
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s3cret" || r.Header.Get("X-Tenant") != "acme" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(f.dashboardStatus)
+			w.Write([]byte(`{"user": "alice"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *loginFlow) steps() []Step {
+	return []Step{
+		{Method: http.MethodPost, URL: f.URL + "/login", Headers: map[string]string{"X-Tenant": "acme"}, Body: "user=alice"},
+		{URL: f.URL + "/dashboard"},
+	}
+}
+
+func (f *loginFlow) requested() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func TestTwoStepCheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		dashboardStatus int
+		wantStatus      string
+		wantDetail      string // prefix, after the server URL
+	}{
+		{"both steps pass", http.StatusOK, "Good", ""},
+		{"second step fails", http.StatusInternalServerError, "Bad", "step 2 (GET /dashboard): unexpected status code 500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := newLoginFlow(t, tt.dashboardStatus)
+			v := newTestValidatorClient(t)
+
+			result := v.checkWebsite(ValidateData{URL: flow.URL, Steps: flow.steps()})
+			if result.Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", result.Status, result.Detail, tt.wantStatus)
+			}
+			if detail := strings.ReplaceAll(result.Detail, flow.URL, ""); detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", detail, tt.wantDetail)
+			}
+			if got := flow.requested(); len(got) != 2 || got[0] != "POST /login" || got[1] != "GET /dashboard" {
+				t.Errorf("requests = %v, want the login then the dashboard", got)
+			}
+		})
+	}
+}
+
+func TestStepFailureStopsCheck(t *testing.T) {
+	flow := newLoginFlow(t, http.StatusOK)
+	v := newTestValidatorClient(t)
+
+	// Without the tenant header the login is refused and the dashboard never fetched
+	steps := flow.steps()
+	steps[0].Headers = nil
+	result := v.checkWebsite(ValidateData{URL: flow.URL, Steps: steps})
+
+	if want := "step 1 (POST /login): unexpected status code 400"; result.Status != "Bad" || strings.ReplaceAll(result.Detail, flow.URL, "") != want {
+		t.Errorf("result = %s (%s), want Bad with %q", result.Status, result.Detail, want)
+	}
+	if got := flow.requested(); len(got) != 1 {
+		t.Errorf("requests = %v, want only the failed login", got)
+	}
+}
+
+func TestStepAssertionFailure(t *testing.T) {
+	flow := newLoginFlow(t, http.StatusOK)
+	v := newTestValidatorClient(t)
+
+	steps := flow.steps()
+	steps[1].Assertions = []Assertion{{Type: "jsonpath", Expression: "$.user", Expected: "bob"}}
+	result := v.checkWebsite(ValidateData{URL: flow.URL, Steps: steps})
+
+	if result.Status != "Bad" || !strings.HasPrefix(strings.ReplaceAll(result.Detail, flow.URL, ""), "step 2 (GET /dashboard): ") {
+		t.Errorf("result = %s (%s), want Bad naming step 2", result.Status, result.Detail)
+	}
+}
+
+func TestStepCookiesDontLeakBetweenChecks(t *testing.T) {
+	flow := newLoginFlow(t, http.StatusOK)
+	v := newTestValidatorClient(t)
+
+	if result := v.checkWebsite(ValidateData{URL: flow.URL, Steps: flow.steps()}); result.Status != "Good" {
+		t.Fatalf("login flow: %s (%s)", result.Status, result.Detail)
+	}
+	// A later check that skips the login has no session
+	steps := flow.steps()[1:]
+	steps[0].Headers = map[string]string{"X-Tenant": "acme"}
+	if result := v.checkWebsite(ValidateData{URL: flow.URL, Steps: steps}); result.Status != "Bad" {
+		t.Errorf("dashboard without logging in was %s, want Bad", result.Status)
+	}
+}
+
+func TestStepDefaults(t *testing.T) {
+	if got := (Step{}).method(); got != http.MethodGet {
+		t.Errorf("method = %s, want GET", got)
+	}
+	if got := (Step{}).expectedStatus(); got != http.StatusOK {
+		t.Errorf("expected status = %d, want 200", got)
+	}
+	if got := (Step{ExpectStatus: http.StatusFound}).expectedStatus(); got != http.StatusFound {
+		t.Errorf("expected status = %d, want 302", got)
+	}
+}
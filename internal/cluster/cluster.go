@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+)
+
+// Coordinator arbitrates which hub instance dispatches a website. A successful
+// Claim blocks every other hub from claiming the same website until ttl elapses.
+type Coordinator interface {
+	Claim(ctx context.Context, websiteID string, ttl time.Duration) (bool, error)
+	Close() error
+}
+
+// New returns a Redis-backed coordinator when REDIS_URL is set, otherwise an
+// in-memory one suitable for a single hub
+func New(cfg *config.Config) (Coordinator, error) {
+	if cfg.RedisURL == "" {
+		return NewMemoryCoordinator(), nil
+	}
+	return NewRedisCoordinator(cfg.RedisURL, cfg.HubID)
+}
+
+// Owner picks the node responsible for key using rendezvous hashing, so adding or
+// removing a node only moves the keys that node owned. Returns "" for no nodes.
+func Owner(key string, nodes []string) string {
+	var (
+		owner string
+		best  uint64
+	)
+	for _, node := range nodes {
+		h := fnv.New64a()
+		h.Write([]byte(node))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		if score := mix64(h.Sum64()); owner == "" || score > best || (score == best && node < owner) {
+			owner, best = node, score
+		}
+	}
+	return owner
+}
+
+// mix64 is the splitmix64 finalizer; FNV alone distributes poorly for short keys
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCoordinator holds claims for hubs running in the same process
+type MemoryCoordinator struct {
+	mu     sync.Mutex
+	claims map[string]time.Time
+}
+
+func NewMemoryCoordinator() *MemoryCoordinator {
+	return &MemoryCoordinator{claims: make(map[string]time.Time)}
+}
+
+func (c *MemoryCoordinator) Claim(ctx context.Context, websiteID string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := c.claims[websiteID]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	c.claims[websiteID] = now.Add(ttl)
+	return true, nil
+}
+
+func (c *MemoryCoordinator) Close() error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisClaimPrefix namespaces per-website dispatch claims
+const redisClaimPrefix = "gopher-uptime:dispatch:"
+
+// RedisCoordinator shares claims between hub instances
+type RedisCoordinator struct {
+	client *redis.Client
+	hubID  string
+}
+
+func NewRedisCoordinator(url, hubID string) (*RedisCoordinator, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis ping failed: %w", err)
+	}
+	return &RedisCoordinator{client: client, hubID: hubID}, nil
+}
+
+func (c *RedisCoordinator) Claim(ctx context.Context, websiteID string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, redisClaimPrefix+websiteID, c.hubID, ttl).Result()
+}
+
+func (c *RedisCoordinator) Close() error {
+	return c.client.Close()
+}
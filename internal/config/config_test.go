@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadDBLogging(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		wantLevel     string
+		wantThreshold time.Duration
+	}{
+		{"development defaults", map[string]string{"APP_ENV": EnvDevelopment}, "warn", 200 * time.Millisecond},
+		{"production defaults", map[string]string{"APP_ENV": EnvProduction}, "error", 200 * time.Millisecond},
+		{"unknown environment", map[string]string{"APP_ENV": "qa"}, "warn", 200 * time.Millisecond},
+		{"overridden", map[string]string{"APP_ENV": EnvProduction, "DB_LOG_LEVEL": "info", "DB_SLOW_QUERY_THRESHOLD": "1s"}, "info", time.Second},
+		{"slow queries disabled", map[string]string{"APP_ENV": EnvDevelopment, "DB_SLOW_QUERY_THRESHOLD": "0s"}, "warn", 0},
+		{"invalid threshold", map[string]string{"APP_ENV": EnvDevelopment, "DB_SLOW_QUERY_THRESHOLD": "soon"}, "warn", 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DB_LOG_LEVEL", "")
+			t.Setenv("DB_SLOW_QUERY_THRESHOLD", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg := Load()
+			if cfg.DBLogLevel != tt.wantLevel {
+				t.Errorf("expected DBLogLevel %q, got %q", tt.wantLevel, cfg.DBLogLevel)
+			}
+			if cfg.DBSlowThreshold != tt.wantThreshold {
+				t.Errorf("expected DBSlowThreshold %s, got %s", tt.wantThreshold, cfg.DBSlowThreshold)
+			}
+		})
+	}
+}
+
+func TestLoadIncidentThresholds(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantOpen    int
+		wantResolve int
+	}{
+		{"defaults", nil, 1, 1},
+		{"legacy setting covers both", map[string]string{"INCIDENT_CONFIRM_CHECKS": "3"}, 3, 3},
+		{"separate thresholds", map[string]string{"INCIDENT_OPEN_CHECKS": "2", "INCIDENT_RESOLVE_CHECKS": "5"}, 2, 5},
+		{"one overrides the legacy setting", map[string]string{"INCIDENT_CONFIRM_CHECKS": "3", "INCIDENT_RESOLVE_CHECKS": "4"}, 3, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("INCIDENT_CONFIRM_CHECKS", "")
+			t.Setenv("INCIDENT_OPEN_CHECKS", "")
+			t.Setenv("INCIDENT_RESOLVE_CHECKS", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg := Load()
+			if cfg.IncidentOpenChecks != tt.wantOpen {
+				t.Errorf("expected IncidentOpenChecks %d, got %d", tt.wantOpen, cfg.IncidentOpenChecks)
+			}
+			if cfg.IncidentResolveChecks != tt.wantResolve {
+				t.Errorf("expected IncidentResolveChecks %d, got %d", tt.wantResolve, cfg.IncidentResolveChecks)
+			}
+		})
+	}
+}
+
+func TestLoadDegradedThresholds(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantOpen    int
+		wantResolve int
+	}{
+		{"defaults", nil, 3, 1},
+		{"overrides", map[string]string{"DEGRADED_OPEN_CHECKS": "5", "DEGRADED_RESOLVE_CHECKS": "2"}, 5, 2},
+		{"disabled", map[string]string{"DEGRADED_OPEN_CHECKS": "0"}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEGRADED_OPEN_CHECKS", "")
+			t.Setenv("DEGRADED_RESOLVE_CHECKS", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg := Load()
+			if cfg.DegradedOpenChecks != tt.wantOpen {
+				t.Errorf("expected DegradedOpenChecks %d, got %d", tt.wantOpen, cfg.DegradedOpenChecks)
+			}
+			if cfg.DegradedResolveChecks != tt.wantResolve {
+				t.Errorf("expected DegradedResolveChecks %d, got %d", tt.wantResolve, cfg.DegradedResolveChecks)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// Profiles selected by APP_ENV
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Solana clusters payouts can be sent on
+const (
+	SolanaDevnetRPC  = "https://api.devnet.solana.com"
+	SolanaMainnetRPC = "https://api.mainnet-beta.solana.com"
+)
+
+// profile holds the defaults that differ between environments. Variables set in
+// the environment or in the .env files always win over them.
+type profile struct {
+	dbLogLevel  string
+	corsOrigins []string // none allows same-origin requests only
+	solanaRPC   string
+}
+
+var profiles = map[string]profile{
+	EnvDevelopment: {
+		dbLogLevel:  "warn",
+		corsOrigins: []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:5173", "http://127.0.0.1:5173"},
+		solanaRPC:   SolanaDevnetRPC,
+	},
+	EnvStaging: {
+		dbLogLevel: "warn",
+		solanaRPC:  SolanaDevnetRPC,
+	},
+	EnvProduction: {
+		dbLogLevel: "error",
+		solanaRPC:  SolanaMainnetRPC,
+	},
+}
+
+// profileFor returns the defaults of env; unknown environments get the
+// development ones
+func profileFor(env string) profile {
+	if p, ok := profiles[env]; ok {
+		return p
+	}
+	log.Printf("⚠️  Unknown APP_ENV %q, using %s defaults", env, EnvDevelopment)
+	return profiles[EnvDevelopment]
+}
+
+// loadEnvFiles loads .env.<APP_ENV> and then .env into the environment and
+// returns APP_ENV. Neither file overrides variables that are already set, so the
+// environment wins over the profile's file, which wins over the base file.
+func loadEnvFiles() string {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		// APP_ENV may itself come from the base file
+		if base, err := godotenv.Read(); err == nil {
+			env = base["APP_ENV"]
+		}
+	}
+	if env == "" {
+		env = EnvDevelopment
+	}
+
+	file := ".env." + env
+	if _, err := os.Stat(file); err == nil {
+		if err := godotenv.Load(file); err != nil {
+			log.Printf("⚠️  Failed to load %s: %v", file, err)
+		} else {
+			log.Printf("✅ Loaded %s", file)
+		}
+	}
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	return env
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// profileVars are the variables the profile tests set through files
+var profileVars = []string{"APP_ENV", "DB_LOG_LEVEL", "SOLANA_RPC_URL", "CORS_ALLOWED_ORIGINS", "HUB_URL"}
+
+// inEnvDir runs t in a temp dir holding files (name to contents) with
+// profileVars unset, restoring both afterwards
+func inEnvDir(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	for _, key := range profileVars {
+		t.Setenv(key, "") // restores the original value after t
+		os.Unsetenv(key)  // the .env files only fill in variables that are unset
+	}
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadProfileFile(t *testing.T) {
+	files := map[string]string{
+		".env":            "DB_LOG_LEVEL=silent\nHUB_URL=ws://base:8081\n",
+		".env.staging":    "DB_LOG_LEVEL=info\nSOLANA_RPC_URL=https://staging-rpc.example.com\n",
+		".env.production": "DB_LOG_LEVEL=error\nSOLANA_RPC_URL=https://prod-rpc.example.com\n",
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		files     map[string]string
+		wantEnv   string
+		wantLevel string
+		wantRPC   string
+		wantHub   string
+	}{
+		{"profile file over the base file", map[string]string{"APP_ENV": EnvStaging}, files, EnvStaging, "info", "https://staging-rpc.example.com", "ws://base:8081"},
+		{"other profile's file", map[string]string{"APP_ENV": EnvProduction}, files, EnvProduction, "error", "https://prod-rpc.example.com", "ws://base:8081"},
+		{"environment over both files", map[string]string{"APP_ENV": EnvStaging, "DB_LOG_LEVEL": "warn", "HUB_URL": "ws://env:8081"}, files, EnvStaging, "warn", "https://staging-rpc.example.com", "ws://env:8081"},
+		{"APP_ENV from the base file", nil, map[string]string{
+			".env":         "APP_ENV=staging\n",
+			".env.staging": "DB_LOG_LEVEL=info\n",
+		}, EnvStaging, "info", SolanaDevnetRPC, "ws://localhost:8081"},
+		{"no profile file", map[string]string{"APP_ENV": EnvProduction}, map[string]string{".env": "HUB_URL=ws://base:8081\n"}, EnvProduction, "error", SolanaMainnetRPC, "ws://base:8081"},
+		{"no files", nil, nil, EnvDevelopment, "warn", SolanaDevnetRPC, "ws://localhost:8081"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inEnvDir(t, tt.files)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg := Load()
+			if cfg.AppEnv != tt.wantEnv {
+				t.Errorf("expected AppEnv %q, got %q", tt.wantEnv, cfg.AppEnv)
+			}
+			if cfg.DBLogLevel != tt.wantLevel {
+				t.Errorf("expected DBLogLevel %q, got %q", tt.wantLevel, cfg.DBLogLevel)
+			}
+			if cfg.SolanaRPCURL != tt.wantRPC {
+				t.Errorf("expected SolanaRPCURL %q, got %q", tt.wantRPC, cfg.SolanaRPCURL)
+			}
+			if cfg.HubURL != tt.wantHub {
+				t.Errorf("expected HubURL %q, got %q", tt.wantHub, cfg.HubURL)
+			}
+		})
+	}
+}
+
+func TestLoadProfileDefaults(t *testing.T) {
+	tests := []struct {
+		env         string
+		wantLevel   string
+		wantRPC     string
+		wantOrigins []string
+	}{
+		{EnvDevelopment, "warn", SolanaDevnetRPC, profiles[EnvDevelopment].corsOrigins},
+		{EnvStaging, "warn", SolanaDevnetRPC, nil},
+		{EnvProduction, "error", SolanaMainnetRPC, nil},
+		{"qa", "warn", SolanaDevnetRPC, profiles[EnvDevelopment].corsOrigins},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			inEnvDir(t, nil)
+			t.Setenv("APP_ENV", tt.env)
+
+			cfg := Load()
+			if cfg.DBLogLevel != tt.wantLevel || cfg.SolanaRPCURL != tt.wantRPC {
+				t.Errorf("expected %s on %s, got %s on %s", tt.wantLevel, tt.wantRPC, cfg.DBLogLevel, cfg.SolanaRPCURL)
+			}
+			if !reflect.DeepEqual(cfg.CORSAllowedOrigins, tt.wantOrigins) {
+				t.Errorf("expected CORS origins %v, got %v", tt.wantOrigins, cfg.CORSAllowedOrigins)
+			}
+		})
+	}
+
+	// Explicit variables still override the profile
+	inEnvDir(t, nil)
+	t.Setenv("APP_ENV", EnvProduction)
+	t.Setenv("SOLANA_RPC_URL", SolanaDevnetRPC)
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com")
+	cfg := Load()
+	if cfg.SolanaRPCURL != SolanaDevnetRPC || !reflect.DeepEqual(cfg.CORSAllowedOrigins, []string{"https://app.example.com"}) {
+		t.Errorf("overrides ignored: RPC %s, origins %v", cfg.SolanaRPCURL, cfg.CORSAllowedOrigins)
+	}
+}
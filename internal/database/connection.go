@@ -2,6 +2,8 @@ package database
 
 import (
 	"log"
+	"os"
+	"strings"
 	"time"
 	
 	"github.com/datmedevil17/gopher-uptime/internal/models"
@@ -10,10 +12,40 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-func Connect(databaseURL string) (*gorm.DB, error) {
+// LogOptions controls GORM's SQL logging
+type LogOptions struct {
+	Level         string        // silent, error, warn or info
+	SlowThreshold time.Duration // queries slower than this are logged at warn level
+}
+
+// logLevels maps DB_LOG_LEVEL values to GORM log levels
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// newLogger builds the GORM logger; an unknown level falls back to warn
+func newLogger(opts LogOptions) logger.Interface {
+	level, ok := logLevels[strings.ToLower(opts.Level)]
+	if !ok {
+		log.Printf("⚠️  Invalid DB_LOG_LEVEL=%q, using default warn", opts.Level)
+		level = logger.Warn
+	}
+
+	return logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold:             opts.SlowThreshold,
+		LogLevel:                  level,
+		IgnoreRecordNotFoundError: level < logger.Info,
+		Colorful:                  true,
+	})
+}
+
+func Connect(databaseURL string, logOpts LogOptions) (*gorm.DB, error) {
 	// Configure GORM
 	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: newLogger(logOpts),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -40,7 +72,8 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 	return db, nil
 }
 
-// AutoMigrate creates all tables
+// AutoMigrate syncs tables with the current models. It's meant for local
+// development only (DB_AUTO_MIGRATE=true); deployments rely on Migrate.
 func AutoMigrate(db *gorm.DB) error {
 	log.Println("🔄 Running auto-migration...")
 	
@@ -50,6 +83,16 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Website{},
 		&models.WebsiteTick{},
 		&models.PayoutTransaction{},
+		&models.EarningsLedger{},
+		&models.CheckTrigger{},
+		&models.Incident{},
+		&models.NotificationChannel{},
+		&models.StatusPage{},
+		&models.StatusPageWebsite{},
+		&models.WebsiteTickRollup{},
+		&models.PayoutReplay{},
+		&models.WebhookDelivery{},
+		&models.Invite{},
 	)
 	
 	if err != nil {
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// loggerConfig returns the configuration GORM's logger was built with
+func loggerConfig(t *testing.T, l logger.Interface) logger.Config {
+	t.Helper()
+	config, ok := reflect.ValueOf(l).Elem().FieldByName("Config").Interface().(logger.Config)
+	if !ok {
+		t.Fatalf("logger %T has no Config", l)
+	}
+	return config
+}
+
+func TestNewLoggerLevel(t *testing.T) {
+	tests := []struct {
+		level          string
+		want           logger.LogLevel
+		ignoreNotFound bool
+	}{
+		{"silent", logger.Silent, true},
+		{"error", logger.Error, true},
+		{"warn", logger.Warn, true},
+		{"info", logger.Info, false},
+		{"INFO", logger.Info, false},
+		{"Error", logger.Error, true},
+		{"", logger.Warn, true},
+		{"verbose", logger.Warn, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			config := loggerConfig(t, newLogger(LogOptions{Level: tt.level, SlowThreshold: time.Second}))
+			if config.LogLevel != tt.want {
+				t.Errorf("expected level %v, got %v", tt.want, config.LogLevel)
+			}
+			if config.IgnoreRecordNotFoundError != tt.ignoreNotFound {
+				t.Errorf("expected IgnoreRecordNotFoundError %v, got %v", tt.ignoreNotFound, config.IgnoreRecordNotFoundError)
+			}
+			if config.SlowThreshold != time.Second {
+				t.Errorf("expected slow threshold 1s, got %s", config.SlowThreshold)
+			}
+		})
+	}
+}
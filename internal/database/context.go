@@ -0,0 +1,20 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WithTimeout binds db to parent with a deadline of timeout (none when timeout
+// is not positive). Cancelling parent, e.g. a client disconnecting, aborts the
+// in-flight query too. Call the returned cancel once the queries are done.
+func WithTimeout(parent context.Context, db *gorm.DB, timeout time.Duration) (*gorm.DB, context.CancelFunc) {
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(parent)
+		return db.WithContext(ctx), cancel
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return db.WithContext(ctx), cancel
+}
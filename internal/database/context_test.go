@@ -0,0 +1,77 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+// slowQuery counts far enough to take minutes unless it is aborted
+const slowQuery = `WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n WHERE x < 2000000000) SELECT count(*) FROM n`
+
+func TestWithTimeoutCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	db, done := database.WithTimeout(ctx, dbtest.Open(t), time.Minute)
+	defer done()
+
+	var users []models.User
+	if err := db.Find(&users).Error; !errors.Is(err, context.Canceled) {
+		t.Errorf("query on a cancelled context: %v, want context.Canceled", err)
+	}
+}
+
+func TestWithTimeoutAbortsRunningQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		cancel  time.Duration // when the parent context is cancelled, if at all
+		wantErr error
+	}{
+		{"deadline", 100 * time.Millisecond, 0, context.DeadlineExceeded},
+		{"parent cancelled", time.Minute, 100 * time.Millisecond, context.Canceled},
+		{"parent cancelled without a timeout", 0, 100 * time.Millisecond, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, cancelParent := context.WithCancel(context.Background())
+			defer cancelParent()
+			if tt.cancel > 0 {
+				time.AfterFunc(tt.cancel, cancelParent)
+			}
+
+			db, done := database.WithTimeout(parent, dbtest.Open(t), tt.timeout)
+			defer done()
+
+			started := time.Now()
+			err := db.Exec(slowQuery).Error
+			if took := time.Since(started); took > 10*time.Second {
+				t.Fatalf("query ran for %s before returning", took)
+			}
+			if err == nil {
+				t.Fatal("query completed, want it aborted")
+			}
+			// Drivers report the abort differently (SQLite says "interrupted"), so
+			// check why the query's context ended instead
+			if ctxErr := db.Statement.Context.Err(); !errors.Is(ctxErr, tt.wantErr) {
+				t.Errorf("query failed with %v after its context ended with %v, want %v", err, ctxErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithTimeoutLeavesFastQueries(t *testing.T) {
+	db, done := database.WithTimeout(context.Background(), dbtest.Open(t), time.Second)
+	defer done()
+
+	var count int64
+	if err := db.Model(&models.User{}).Count(&count).Error; err != nil {
+		t.Errorf("query within the timeout: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package dbtest opens throwaway databases for tests.
+//
+// Open gives each test an empty SQLite database with every model's table, so
+// tests run without a server. Set TEST_DATABASE_URL to a Postgres database to
+// run them against a fresh schema of it instead; code that relies on Postgres
+// SQL (DISTINCT ON, percentile_cont, lateral joins, migrations) is only tested
+// that way, through Postgres.
+package dbtest
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open returns an empty database with the current schema, removed when t ends.
+// It is migrated Postgres when TEST_DATABASE_URL is set and SQLite otherwise.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	if os.Getenv("TEST_DATABASE_URL") != "" {
+		db := Postgres(t)
+		if err := database.Migrate(db); err != nil {
+			t.Fatalf("migrating test database: %v", err)
+		}
+		return db
+	}
+
+	// A file rather than :memory: so every pooled connection sees the same data.
+	// Immediate transactions serialize writers the way row locks do on Postgres.
+	path := filepath.Join(t.TempDir(), "test.db")
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	db := open(t, sqlite.Open(dsn))
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	return db
+}
+
+// RequirePostgres skips t unless Open returns Postgres, for tests of code using
+// Postgres-only SQL
+func RequirePostgres(t testing.TB) {
+	t.Helper()
+
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		t.Skip("needs Postgres; TEST_DATABASE_URL is not set")
+	}
+}
+
+// Postgres returns an empty schema in the TEST_DATABASE_URL database, dropped
+// when t ends, and skips t when the variable isn't set
+func Postgres(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin := open(t, postgres.Open(databaseURL))
+	schema := "test_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	if err := admin.Exec(`CREATE SCHEMA ` + schema).Error; err != nil {
+		t.Fatalf("creating test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`).Error; err != nil {
+			t.Errorf("dropping test schema: %v", err)
+		}
+	})
+
+	return open(t, postgres.Open(withSearchPath(databaseURL, schema)))
+}
+
+func open(t testing.TB, dialector gorm.Dialector) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// withSearchPath points a URL or key=value connection string at schema
+func withSearchPath(databaseURL, schema string) string {
+	if u, err := url.Parse(databaseURL); err == nil && u.Scheme != "" {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return databaseURL + " search_path=" + schema
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170004_incidents
+
+type incidentsIncident struct {
+	ID         string     `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID  string     `gorm:"type:varchar(255);not null;index"`
+	StartedAt  time.Time  `gorm:"not null;index"`
+	ResolvedAt *time.Time `gorm:"index"`
+	Cause      string     `gorm:"type:text"`
+
+	Website *initialWebsite `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE"`
+}
+
+func (incidentsIncident) TableName() string { return "Incident" }
+
+// migrateIncidents creates the Incident table. The partial unique index allows
+// at most one ongoing incident per website.
+func migrateIncidents(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&incidentsIncident{}); err != nil {
+		return err
+	}
+	return tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_incident_ongoing ON "Incident" (website_id) WHERE resolved_at IS NULL`).Error
+}
+
+func rollbackIncidents(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&incidentsIncident{})
+}
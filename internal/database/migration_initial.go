@@ -0,0 +1,140 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170001_initial_schema. These mirror the models as
+// they were when migrations were introduced and must not be edited; later
+// changes belong in new migrations.
+
+type initialUser struct {
+	ID          string `gorm:"primaryKey;type:varchar(255)"`
+	Email       string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Password    string `gorm:"type:varchar(255);not null"`
+	MaxWebsites *int
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialUser) TableName() string { return "User" }
+
+type initialWebsite struct {
+	ID                 string `gorm:"primaryKey;type:varchar(255)"`
+	URL                string `gorm:"type:varchar(500);not null"`
+	UserID             string `gorm:"type:varchar(255);not null;index"`
+	Assertions         string `gorm:"type:jsonb"`
+	LatencyThresholdMs int    `gorm:"default:0"`
+	AddressFamily      string `gorm:"type:varchar(10);default:'auto'"`
+	MinValidators      *int
+	SLATarget          float64              `gorm:"type:decimal(6,3);default:0"`
+	Ticks              []initialWebsiteTick `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialWebsite) TableName() string { return "Website" }
+
+type initialValidator struct {
+	ID               string               `gorm:"primaryKey;type:varchar(255)"`
+	PublicKey        string               `gorm:"type:varchar(255);not null;uniqueIndex"`
+	KeyID            string               `gorm:"type:varchar(100)"`
+	Name             string               `gorm:"type:varchar(255)"`
+	Location         string               `gorm:"type:varchar(255)"`
+	IP               string               `gorm:"type:varchar(255)"`
+	PendingPayouts   float64              `gorm:"type:decimal(20,2);default:0"`
+	Ticks            []initialWebsiteTick `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	InFlightChecks   int   `gorm:"default:0"`
+	ChecksCompleted  int64 `gorm:"default:0"`
+	ChecksFailed     int64 `gorm:"default:0"`
+	ReportErrors     int64 `gorm:"default:0"`
+	UptimeSeconds    int64 `gorm:"default:0"`
+	StatusReportedAt *time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialValidator) TableName() string { return "Validator" }
+
+type initialWebsiteTick struct {
+	ID          string    `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID   string    `gorm:"type:varchar(255);not null;index"`
+	ValidatorID string    `gorm:"type:varchar(255);not null;index"`
+	Status      string    `gorm:"type:varchar(50);not null"`
+	Latency     float64   `gorm:"type:decimal(10,2)"`
+	Detail      string    `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"index"`
+
+	Website   *initialWebsite   `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE"`
+	Validator *initialValidator `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE"`
+}
+
+func (initialWebsiteTick) TableName() string { return "WebsiteTick" }
+
+type initialPayoutTransaction struct {
+	ID             string    `gorm:"primaryKey;type:varchar(255)"`
+	ValidatorID    string    `gorm:"type:varchar(255);not null;index"`
+	Amount         float64   `gorm:"type:decimal(20,2);not null"`
+	TransferAmount uint64    `gorm:"default:0"`
+	Status         string    `gorm:"type:varchar(50);not null;index"`
+	TxSignature    string    `gorm:"type:varchar(255)"`
+	ErrorMessage   string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"index"`
+	UpdatedAt      time.Time
+	Validator      *initialValidator `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE"`
+}
+
+func (initialPayoutTransaction) TableName() string { return "PayoutTransaction" }
+
+type initialEarningsLedger struct {
+	ID          string            `gorm:"primaryKey;type:varchar(255)"`
+	ValidatorID string            `gorm:"type:varchar(255);not null;index"`
+	WebsiteID   string            `gorm:"type:varchar(255);not null;index"`
+	TickID      string            `gorm:"type:varchar(255);not null;index"`
+	Amount      float64           `gorm:"type:decimal(20,2);not null"`
+	CreatedAt   time.Time         `gorm:"index"`
+	Validator   *initialValidator `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE"`
+}
+
+func (initialEarningsLedger) TableName() string { return "EarningsLedger" }
+
+type initialCheckTrigger struct {
+	ID              string          `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID       string          `gorm:"type:varchar(255);not null;index"`
+	RequestedAt     time.Time       `gorm:"not null;index"`
+	ClaimedBy       string          `gorm:"type:varchar(255);not null;default:''"`
+	DispatchedAt    *time.Time      `gorm:"index"`
+	DispatchedCount int             `gorm:"default:0"`
+	Website         *initialWebsite `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE"`
+}
+
+func (initialCheckTrigger) TableName() string { return "CheckTrigger" }
+
+// initialTables in creation order; rollback drops them in reverse
+var initialTables = []interface{}{
+	&initialUser{},
+	&initialValidator{},
+	&initialWebsite{},
+	&initialWebsiteTick{},
+	&initialPayoutTransaction{},
+	&initialEarningsLedger{},
+	&initialCheckTrigger{},
+}
+
+// migrateInitialSchema creates the schema previously managed by AutoMigrate.
+// On databases that AutoMigrate already set up it only fills in what's missing.
+func migrateInitialSchema(tx *gorm.DB) error {
+	return tx.AutoMigrate(initialTables...)
+}
+
+func rollbackInitialSchema(tx *gorm.DB) error {
+	for i := len(initialTables) - 1; i >= 0; i-- {
+		if err := tx.Migrator().DropTable(initialTables[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
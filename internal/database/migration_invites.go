@@ -0,0 +1,27 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170015_invites
+
+type invitesInvite struct {
+	Code      string  `gorm:"primaryKey;type:varchar(64)"`
+	Email     *string `gorm:"type:varchar(255)"`
+	UsedBy    *string `gorm:"type:varchar(255)"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (invitesInvite) TableName() string { return "Invite" }
+
+func migrateInvites(tx *gorm.DB) error {
+	return tx.AutoMigrate(&invitesInvite{})
+}
+
+func rollbackInvites(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&invitesInvite{})
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170005_notification_channels
+
+type notificationsChannel struct {
+	ID        string  `gorm:"primaryKey;type:varchar(255)"`
+	UserID    string  `gorm:"type:varchar(255);not null;index"`
+	WebsiteID *string `gorm:"type:varchar(255);index"`
+	Type      string  `gorm:"type:varchar(20);not null"`
+	Target    string  `gorm:"type:varchar(500);not null"`
+	Enabled   bool    `gorm:"default:true"`
+	CreatedAt time.Time
+}
+
+func (notificationsChannel) TableName() string { return "NotificationChannel" }
+
+func migrateNotificationChannels(tx *gorm.DB) error {
+	return tx.AutoMigrate(&notificationsChannel{})
+}
+
+func rollbackNotificationChannels(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&notificationsChannel{})
+}
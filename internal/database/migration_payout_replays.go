@@ -0,0 +1,28 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170008_payout_replays
+
+type payoutReplaysReplay struct {
+	ID            string    `gorm:"primaryKey;type:varchar(255)"`
+	TransactionID string    `gorm:"type:varchar(255);index"`
+	ValidatorID   string    `gorm:"type:varchar(255);index"`
+	Amount        float64   `gorm:"type:decimal(20,2)"`
+	Reason        string    `gorm:"type:text"`
+	ReplayedAt    time.Time `gorm:"index"`
+}
+
+func (payoutReplaysReplay) TableName() string { return "PayoutReplay" }
+
+func migratePayoutReplays(tx *gorm.DB) error {
+	return tx.AutoMigrate(&payoutReplaysReplay{})
+}
+
+func rollbackPayoutReplays(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&payoutReplaysReplay{})
+}
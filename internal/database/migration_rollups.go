@@ -0,0 +1,29 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170007_website_tick_rollups
+
+type rollupsWebsiteTickRollup struct {
+	WebsiteID    string    `gorm:"primaryKey;type:varchar(255)"`
+	Bucket       time.Time `gorm:"primaryKey;index"`
+	Good         int64     `gorm:"not null;default:0"`
+	Degraded     int64     `gorm:"not null;default:0"`
+	Bad          int64     `gorm:"not null;default:0"`
+	LatencyTotal float64   `gorm:"not null;default:0"`
+}
+
+func (rollupsWebsiteTickRollup) TableName() string { return "WebsiteTickRollup" }
+
+// migrateRollups creates the hourly rollup table; the hub backfills it
+func migrateRollups(tx *gorm.DB) error {
+	return tx.AutoMigrate(&rollupsWebsiteTickRollup{})
+}
+
+func rollbackRollups(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&rollupsWebsiteTickRollup{})
+}
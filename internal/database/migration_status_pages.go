@@ -0,0 +1,41 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170006_status_pages
+
+type statusPagesPage struct {
+	ID        string `gorm:"primaryKey;type:varchar(255)"`
+	UserID    string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Slug      string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Title     string `gorm:"type:varchar(200);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Websites []statusPagesWebsite `gorm:"foreignKey:StatusPageID;constraint:OnDelete:CASCADE"`
+}
+
+func (statusPagesPage) TableName() string { return "StatusPage" }
+
+type statusPagesWebsite struct {
+	StatusPageID string `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID    string `gorm:"primaryKey;type:varchar(255);index"`
+	Name         string `gorm:"type:varchar(100);not null"`
+	Position     int    `gorm:"not null;default:0"`
+
+	Website *initialWebsite `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE"`
+}
+
+func (statusPagesWebsite) TableName() string { return "StatusPageWebsite" }
+
+func migrateStatusPages(tx *gorm.DB) error {
+	return tx.AutoMigrate(&statusPagesPage{}, &statusPagesWebsite{})
+}
+
+func rollbackStatusPages(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&statusPagesWebsite{}, &statusPagesPage{})
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema snapshot for 202610170012_webhook_deliveries
+
+type webhookDeliveriesDelivery struct {
+	ID             string `gorm:"primaryKey;type:varchar(255)"`
+	ChannelID      string `gorm:"type:varchar(255);not null;index:idx_webhook_delivery_channel_created"`
+	WebsiteID      string `gorm:"type:varchar(255);not null;index"`
+	URL            string `gorm:"type:varchar(500);not null"`
+	Event          string `gorm:"type:varchar(50);not null"`
+	Attempt        int    `gorm:"not null"`
+	StatusCode     int
+	ResponseTimeMs int64     `gorm:"not null"`
+	Success        bool      `gorm:"not null"`
+	Status         string    `gorm:"type:varchar(20);not null"`
+	Error          string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"index:idx_webhook_delivery_channel_created"`
+}
+
+func (webhookDeliveriesDelivery) TableName() string { return "WebhookDelivery" }
+
+func migrateWebhookDeliveries(tx *gorm.DB) error {
+	return tx.AutoMigrate(&webhookDeliveriesDelivery{})
+}
+
+func rollbackWebhookDeliveries(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&webhookDeliveriesDelivery{})
+}
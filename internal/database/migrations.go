@@ -0,0 +1,377 @@
+package database
+
+import (
+	"log"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// migrationTable records which migrations have been applied
+const migrationTable = "SchemaMigration"
+
+// migrations lists every schema change in the order it must be applied. IDs are
+// timestamps and must never change once released; add new entries at the end.
+// Migrations describe the schema with their own structs rather than the current
+// models, so replaying them on a fresh database gives the same result forever.
+var migrations = []*gormigrate.Migration{
+	{
+		ID:       "202610170001_initial_schema",
+		Migrate:  migrateInitialSchema,
+		Rollback: rollbackInitialSchema,
+	},
+	{
+		ID: "202610170002_website_disabled_to_deleted_at",
+		// Websites used to be soft-deleted with a Disabled flag
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn("Website", "disabled") {
+				return nil
+			}
+			if err := tx.Exec(`UPDATE "Website" SET deleted_at = updated_at WHERE disabled = true AND deleted_at IS NULL`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE "Website" DROP COLUMN disabled`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			type website struct {
+				Disabled bool `gorm:"default:false"`
+			}
+			if !tx.Migrator().HasColumn("Website", "disabled") {
+				if err := tx.Table("Website").Migrator().AddColumn(&website{}, "Disabled"); err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`UPDATE "Website" SET disabled = true WHERE deleted_at IS NOT NULL`).Error
+		},
+	},
+	{
+		ID: "202610170003_website_tick_latest_indexes",
+		// Serve "latest N ticks for a website/validator" from the index alone. The
+		// composite indexes cover lookups by website_id/validator_id too, so the
+		// single-column ones are dropped.
+		Migrate: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`CREATE INDEX IF NOT EXISTS idx_website_tick_website_created ON "WebsiteTick" (website_id, created_at DESC)`,
+				`CREATE INDEX IF NOT EXISTS idx_website_tick_validator_created ON "WebsiteTick" (validator_id, created_at DESC)`,
+				`DROP INDEX IF EXISTS "idx_WebsiteTick_website_id"`,
+				`DROP INDEX IF EXISTS "idx_WebsiteTick_validator_id"`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`CREATE INDEX IF NOT EXISTS "idx_WebsiteTick_validator_id" ON "WebsiteTick" (validator_id)`,
+				`CREATE INDEX IF NOT EXISTS "idx_WebsiteTick_website_id" ON "WebsiteTick" (website_id)`,
+				`DROP INDEX IF EXISTS idx_website_tick_validator_created`,
+				`DROP INDEX IF EXISTS idx_website_tick_website_created`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:       "202610170004_incidents",
+		Migrate:  migrateIncidents,
+		Rollback: rollbackIncidents,
+	},
+	{
+		ID:       "202610170005_notification_channels",
+		Migrate:  migrateNotificationChannels,
+		Rollback: rollbackNotificationChannels,
+	},
+	{
+		ID:       "202610170006_status_pages",
+		Migrate:  migrateStatusPages,
+		Rollback: rollbackStatusPages,
+	},
+	{
+		ID:       "202610170007_website_tick_rollups",
+		Migrate:  migrateRollups,
+		Rollback: rollbackRollups,
+	},
+	{
+		ID:       "202610170008_payout_replays",
+		Migrate:  migratePayoutReplays,
+		Rollback: rollbackPayoutReplays,
+	},
+	{
+		ID: "202610170009_idempotent_ticks",
+		// A result delivered twice must record one tick and one credit
+		Migrate: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`ALTER TABLE "WebsiteTick" ADD COLUMN IF NOT EXISTS callback_id varchar(255)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_website_tick_callback ON "WebsiteTick" (callback_id)`,
+				`DROP INDEX IF EXISTS "idx_EarningsLedger_tick_id"`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_EarningsLedger_tick_id" ON "EarningsLedger" (tick_id)`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`DROP INDEX IF EXISTS "idx_EarningsLedger_tick_id"`,
+				`CREATE INDEX IF NOT EXISTS "idx_EarningsLedger_tick_id" ON "EarningsLedger" (tick_id)`,
+				`DROP INDEX IF EXISTS idx_website_tick_callback`,
+				`ALTER TABLE "WebsiteTick" DROP COLUMN IF EXISTS callback_id`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID: "202610170010_website_check_interval",
+		Migrate: func(tx *gorm.DB) error {
+			type website struct {
+				IntervalSeconds int `gorm:"default:0"`
+			}
+			if tx.Migrator().HasColumn("Website", "interval_seconds") {
+				return nil
+			}
+			return tx.Table("Website").Migrator().AddColumn(&website{}, "IntervalSeconds")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" DROP COLUMN IF EXISTS interval_seconds`).Error
+		},
+	},
+	{
+		ID: "202610170011_website_check_timeout",
+		Migrate: func(tx *gorm.DB) error {
+			type website struct {
+				TimeoutMs int `gorm:"default:0"`
+			}
+			if tx.Migrator().HasColumn("Website", "timeout_ms") {
+				return nil
+			}
+			return tx.Table("Website").Migrator().AddColumn(&website{}, "TimeoutMs")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" DROP COLUMN IF EXISTS timeout_ms`).Error
+		},
+	},
+	{
+		ID:       "202610170012_webhook_deliveries",
+		Migrate:  migrateWebhookDeliveries,
+		Rollback: rollbackWebhookDeliveries,
+	},
+	{
+		ID: "202610170013_website_webhook_secret",
+		// Existing websites get a random secret so all webhooks are signed
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE "Website" ADD COLUMN IF NOT EXISTS webhook_secret varchar(64)`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE "Website"
+				SET webhook_secret = replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', '')
+				WHERE webhook_secret IS NULL OR webhook_secret = ''`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" DROP COLUMN IF EXISTS webhook_secret`).Error
+		},
+	},
+	{
+		ID: "202610170014_rollup_latency_histogram",
+		// Existing rollups are dropped so the hub backfills them with histograms
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE "WebsiteTickRollup" ADD COLUMN IF NOT EXISTS latency_histogram jsonb`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`DELETE FROM "WebsiteTickRollup"`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "WebsiteTickRollup" DROP COLUMN IF EXISTS latency_histogram`).Error
+		},
+	},
+	{
+		ID:       "202610170015_invites",
+		Migrate:  migrateInvites,
+		Rollback: rollbackInvites,
+	},
+	{
+		ID: "202610170016_tick_latency_microseconds",
+		// Validators report latency to the microsecond so fast checks aren't 0ms
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "WebsiteTick" ALTER COLUMN latency TYPE decimal(12,3)`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "WebsiteTick" ALTER COLUMN latency TYPE decimal(10,2)`).Error
+		},
+	},
+	{
+		ID: "202610170017_validator_bans",
+		Migrate: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`ALTER TABLE "Validator" ADD COLUMN IF NOT EXISTS banned boolean NOT NULL DEFAULT false`,
+				`ALTER TABLE "Validator" ADD COLUMN IF NOT EXISTS banned_at timestamptz`,
+				`ALTER TABLE "Validator" ADD COLUMN IF NOT EXISTS ban_reason text`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Validator" DROP COLUMN IF EXISTS banned, DROP COLUMN IF EXISTS banned_at, DROP COLUMN IF EXISTS ban_reason`).Error
+		},
+	},
+	{
+		ID: "202610170018_alert_cooldown",
+		// Incidents before the cooldown existed were all alerted on
+		Migrate: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`ALTER TABLE "Incident" ADD COLUMN IF NOT EXISTS notified boolean NOT NULL DEFAULT false`,
+				`UPDATE "Incident" SET notified = true`,
+				`ALTER TABLE "Website" ADD COLUMN IF NOT EXISTS cooldown_seconds bigint`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`ALTER TABLE "Website" DROP COLUMN IF EXISTS cooldown_seconds`,
+				`ALTER TABLE "Incident" DROP COLUMN IF EXISTS notified`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID: "202610170019_website_regions",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" ADD COLUMN IF NOT EXISTS regions jsonb`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" DROP COLUMN IF EXISTS regions`).Error
+		},
+	},
+	{
+		ID: "202610170020_validator_last_payout",
+		// Backfilled from the payout history so the cooldown applies from the start
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE "Validator" ADD COLUMN IF NOT EXISTS last_payout_at timestamptz`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE "Validator" v
+				SET last_payout_at = p.last
+				FROM (
+					SELECT validator_id, MAX(created_at) AS last
+					FROM "PayoutTransaction"
+					WHERE status <> 'failed'
+					GROUP BY validator_id
+				) p
+				WHERE p.validator_id = v.id`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Validator" DROP COLUMN IF EXISTS last_payout_at`).Error
+		},
+	},
+	{
+		ID: "202610170021_website_steps",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" ADD COLUMN IF NOT EXISTS steps jsonb`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Website" DROP COLUMN IF EXISTS steps`).Error
+		},
+	},
+	{
+		ID: "202610170022_check_trigger_reuse",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "CheckTrigger" ADD COLUMN IF NOT EXISTS reused_from timestamptz`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "CheckTrigger" DROP COLUMN IF EXISTS reused_from`).Error
+		},
+	},
+	{
+		ID: "202610170023_http_version",
+		Migrate: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`ALTER TABLE "Website" ADD COLUMN IF NOT EXISTS http_version varchar(10) DEFAULT 'auto'`,
+				`ALTER TABLE "WebsiteTick" ADD COLUMN IF NOT EXISTS protocol varchar(20)`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, stmt := range []string{
+				`ALTER TABLE "WebsiteTick" DROP COLUMN IF EXISTS protocol`,
+				`ALTER TABLE "Website" DROP COLUMN IF EXISTS http_version`,
+			} {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID: "202610170024_incident_severity",
+		// Incidents before Degraded alerting were all outages
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Incident" ADD COLUMN IF NOT EXISTS severity varchar(20) NOT NULL DEFAULT 'Bad'`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE "Incident" DROP COLUMN IF EXISTS severity`).Error
+		},
+	},
+}
+
+func newMigrator(db *gorm.DB) *gormigrate.Gormigrate {
+	return gormigrate.New(db, &gormigrate.Options{
+		TableName:                 migrationTable,
+		IDColumnName:              "id",
+		IDColumnSize:              255,
+		UseTransaction:            true,
+		ValidateUnknownMigrations: true,
+	}, migrations)
+}
+
+// Migrate applies every pending migration
+func Migrate(db *gorm.DB) error {
+	log.Println("🔄 Running migrations...")
+
+	if err := newMigrator(db).Migrate(); err != nil {
+		return err
+	}
+
+	log.Println("✅ Migrations applied")
+	return nil
+}
+
+// MigrateTo applies pending migrations up to and including id
+func MigrateTo(db *gorm.DB, id string) error {
+	return newMigrator(db).MigrateTo(id)
+}
+
+// RollbackLast reverts the most recently applied migration
+func RollbackLast(db *gorm.DB) error {
+	return newMigrator(db).RollbackLast()
+}
+
+// RollbackTo reverts migrations until id is the last one applied
+func RollbackTo(db *gorm.DB, id string) error {
+	return newMigrator(db).RollbackTo(id)
+}
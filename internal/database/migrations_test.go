@@ -0,0 +1,119 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"gorm.io/gorm"
+)
+
+// The migrations use Postgres SQL, so these tests need TEST_DATABASE_URL and are
+// skipped without it.
+
+// tables are the models the migrations must create, as AutoMigrate does
+var tables = []interface{}{
+	&models.User{},
+	&models.Validator{},
+	&models.Website{},
+	&models.WebsiteTick{},
+	&models.PayoutTransaction{},
+	&models.EarningsLedger{},
+	&models.CheckTrigger{},
+	&models.Incident{},
+	&models.NotificationChannel{},
+	&models.StatusPage{},
+	&models.StatusPageWebsite{},
+	&models.WebsiteTickRollup{},
+	&models.PayoutReplay{},
+	&models.WebhookDelivery{},
+	&models.Invite{},
+}
+
+// applied lists the IDs of the migrations recorded as run
+func applied(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+
+	var ids []string
+	if err := db.Table("SchemaMigration").Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+// checkSchema fails t unless every model's table has all of the model's columns
+func checkSchema(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	for _, model := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("table %s missing", stmt.Schema.Table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("column %s.%s missing", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+}
+
+func TestMigrateMatchesModels(t *testing.T) {
+	db := dbtest.Postgres(t)
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	checkSchema(t, db)
+
+	// Running again is a no-op
+	before := applied(t, db)
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrating twice: %v", err)
+	}
+	if after := applied(t, db); len(after) != len(before) {
+		t.Errorf("second run recorded %d migrations, first %d", len(after), len(before))
+	}
+}
+
+func TestMigrationsRollBack(t *testing.T) {
+	db := dbtest.Postgres(t)
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	ids := applied(t, db)
+	if len(ids) == 0 {
+		t.Fatal("no migrations recorded")
+	}
+
+	// Roll every migration back in turn, then check each re-applies cleanly
+	for i := len(ids) - 1; i >= 0; i-- {
+		if err := database.RollbackLast(db); err != nil {
+			t.Fatalf("rolling back %s: %v", ids[i], err)
+		}
+		if got := applied(t, db); len(got) != i {
+			t.Fatalf("after rolling back %s, %d migrations recorded, want %d", ids[i], len(got), i)
+		}
+	}
+	for _, model := range tables {
+		if db.Migrator().HasTable(model) {
+			stmt := &gorm.Statement{DB: db}
+			stmt.Parse(model)
+			t.Errorf("table %s left after rolling everything back", stmt.Schema.Table)
+		}
+	}
+
+	for _, id := range ids {
+		if err := database.MigrateTo(db, id); err != nil {
+			t.Fatalf("re-applying %s: %v", id, err)
+		}
+	}
+	checkSchema(t, db)
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+)
+
+// Type identifies what happened
+type Type string
+
+const (
+	// TypeTickRecorded is published by the hub after a validator's result is stored
+	TypeTickRecorded Type = "tick.recorded"
+	// TypeCheckDispatched is published by the hub after it handles a check-now trigger
+	TypeCheckDispatched Type = "check.dispatched"
+)
+
+// Event is the message exchanged between the hub and API processes.
+// Only the fields relevant to Type are set.
+type Event struct {
+	Type       Type      `json:"type"`
+	WebsiteID  string    `json:"website_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// tick.recorded
+	TickID      string  `json:"tick_id,omitempty"`
+	ValidatorID string  `json:"validator_id,omitempty"`
+	Status      string  `json:"status,omitempty"`
+	Latency     float64 `json:"latency,omitempty"`
+	Detail      string  `json:"detail,omitempty"`
+
+	// check.dispatched
+	TriggerID  string `json:"trigger_id,omitempty"`
+	Dispatched int    `json:"dispatched,omitempty"`
+}
+
+// Bus is a fire-and-forget pub/sub channel. Delivery is best effort: slow
+// subscribers may miss events, so consumers must not rely on it for correctness.
+type Bus interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe delivers events until ctx is cancelled, then closes the channel
+	Subscribe(ctx context.Context) (<-chan Event, error)
+	Close() error
+}
+
+// New returns the bus selected by EVENT_BUS
+func New(cfg *config.Config) (Bus, error) {
+	switch cfg.EventBus {
+	case "", "memory":
+		return NewMemoryBus(), nil
+	case "redis":
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("EVENT_BUS=redis requires REDIS_URL")
+		}
+		return NewRedisBus(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown event bus %q (expected memory or redis)", cfg.EventBus)
+	}
+}
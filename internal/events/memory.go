@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before events are dropped
+const subscriberBuffer = 64
+
+// MemoryBus delivers events within a single process
+type MemoryBus struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subscribers: make(map[chan Event]struct{})}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("⚠️  Event subscriber is lagging, dropped %s for %s", event.Type, event.WebsiteID)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context) (<-chan Event, error) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return ch, nil
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.unsubscribe(ch)
+	}()
+	return ch, nil
+}
+
+func (b *MemoryBus) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+	b.closed = true
+	return nil
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// receive returns the next event on ch, failing t if none arrives in time
+func receive(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-ch:
+		if !ok {
+			t.Fatal("subscription closed, want an event")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return Event{}
+}
+
+// closed fails t unless ch is closed within a second
+func closed(t *testing.T, ch <-chan Event) {
+	t.Helper()
+
+	select {
+	case event, ok := <-ch:
+		if ok {
+			t.Fatalf("received %s, want the subscription closed", event.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription still open")
+	}
+}
+
+func TestMemoryBusDeliversToEverySubscriber(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first, _ := bus.Subscribe(ctx)
+	second, _ := bus.Subscribe(ctx)
+
+	published := Event{Type: TypeTickRecorded, WebsiteID: "w1", TickID: "t1", Status: "Good", Latency: 12.5}
+	if err := bus.Publish(ctx, published); err != nil {
+		t.Fatal(err)
+	}
+	for i, ch := range []<-chan Event{first, second} {
+		if got := receive(t, ch); got != published {
+			t.Errorf("subscriber %d received %+v, want %+v", i, got, published)
+		}
+	}
+}
+
+func TestMemoryBusPreservesOrder(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe(context.Background())
+	for _, id := range []string{"a", "b", "c"} {
+		bus.Publish(context.Background(), Event{Type: TypeCheckDispatched, TriggerID: id})
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		if got := receive(t, ch).TriggerID; got != want {
+			t.Fatalf("received trigger %s, want %s", got, want)
+		}
+	}
+}
+
+func TestMemoryBusUnsubscribesOnCancel(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _ := bus.Subscribe(ctx)
+	cancel()
+	closed(t, ch)
+
+	// Publishing after the subscriber left must not panic on the closed channel
+	if err := bus.Publish(context.Background(), Event{Type: TypeTickRecorded}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryBusDropsForLaggingSubscriber(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe(context.Background())
+
+	// A subscriber that never reads must not block the publisher
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < subscriberBuffer+10; i++ {
+			bus.Publish(context.Background(), Event{Type: TypeTickRecorded})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+	if len(ch) != subscriberBuffer {
+		t.Errorf("%d events buffered, want %d", len(ch), subscriberBuffer)
+	}
+}
+
+func TestMemoryBusClose(t *testing.T) {
+	bus := NewMemoryBus()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := bus.Subscribe(ctx)
+	bus.Close()
+	closed(t, ch)
+
+	late, err := bus.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed(t, late)
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisChannel is the Redis pub/sub channel shared by every process
+const redisChannel = "gopher-uptime:events"
+
+// RedisBus delivers events across processes through Redis pub/sub
+type RedisBus struct {
+	client *redis.Client
+}
+
+func NewRedisBus(url string) (*RedisBus, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis ping failed: %w", err)
+	}
+	return &RedisBus{client: client}, nil
+}
+
+func (b *RedisBus) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, redisChannel, payload).Err()
+}
+
+func (b *RedisBus) Subscribe(ctx context.Context) (<-chan Event, error) {
+	pubsub := b.client.Subscribe(ctx, redisChannel)
+	// Wait for the subscription to be confirmed so no events are missed after returning
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("redis subscribe failed: %w", err)
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	go func() {
+		defer close(ch)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event Event
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("❌ Invalid event payload: %v", err)
+					continue
+				}
+
+				select {
+				case ch <- event:
+				default:
+					log.Printf("⚠️  Event subscriber is lagging, dropped %s for %s", event.Type, event.WebsiteID)
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (b *RedisBus) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,65 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/grpcapi/uptimepb"
+	"github.com/datmedevil17/gopher-uptime/internal/middleware"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// publicMethods are callable without a token, like their REST routes
+var publicMethods = map[string]bool{
+	uptimepb.UptimeService_GetValidatorBalance_FullMethodName: true,
+}
+
+type userIDKey struct{}
+
+// userID is the caller authenticated by AuthInterceptor
+func userID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// AuthInterceptor is the gRPC counterpart of middleware.AuthMiddleware and
+// middleware.ActiveUserMiddleware: the JWT comes as "authorization: Bearer
+// <token>" metadata instead of a header, and tokens of deleted users are refused
+func AuthInterceptor(jwtCfg *utils.JWTConfig, db *gorm.DB, timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+		}
+
+		token, ok := middleware.BearerToken(values[0])
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata must be in the form 'Bearer <token>'")
+		}
+
+		id, err := utils.VerifyJWT(token, jwtCfg)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token: "+err.Error())
+		}
+
+		exists, err := middleware.UserExists(ctx, db, timeout, id)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "database error")
+		}
+		if !exists {
+			return nil, status.Error(codes.Unauthenticated, "user not found")
+		}
+
+		return handler(context.WithValue(ctx, userIDKey{}, id), req)
+	}
+}
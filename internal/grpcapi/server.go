@@ -0,0 +1,117 @@
+// Package grpcapi serves the API's core reads over gRPC, through the same
+// handler methods as the REST routes.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/grpcapi/uptimepb"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/user"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/website"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// Server implements uptimepb.UptimeServiceServer
+type Server struct {
+	uptimepb.UnimplementedUptimeServiceServer
+	websites *website.Handler
+	users    *user.Handler
+}
+
+func NewServer(websites *website.Handler, users *user.Handler) *Server {
+	return &Server{websites: websites, users: users}
+}
+
+// New returns a gRPC server with the uptime service registered behind JWT auth.
+// db is where the interceptor checks that a token's user still exists.
+func New(websites *website.Handler, users *user.Handler, jwtCfg *utils.JWTConfig, db *gorm.DB, timeout time.Duration) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(AuthInterceptor(jwtCfg, db, timeout)))
+	uptimepb.RegisterUptimeServiceServer(server, NewServer(websites, users))
+	return server
+}
+
+func (s *Server) ListWebsites(ctx context.Context, req *uptimepb.ListWebsitesRequest) (*uptimepb.ListWebsitesResponse, error) {
+	websites, err := s.websites.WebsitesWithTicks(ctx, userID(ctx), req.GetFullTicks())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to fetch websites")
+	}
+
+	response := &uptimepb.ListWebsitesResponse{Websites: make([]*uptimepb.WebsiteStatus, len(websites))}
+	for i, w := range websites {
+		response.Websites[i] = toWebsiteStatus(w.Website, w.Ticks)
+	}
+	return response, nil
+}
+
+func (s *Server) GetWebsiteStatus(ctx context.Context, req *uptimepb.GetWebsiteStatusRequest) (*uptimepb.WebsiteStatus, error) {
+	if req.GetWebsiteId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "website_id required")
+	}
+
+	w, err := s.websites.WebsiteStatus(ctx, userID(ctx), req.GetWebsiteId())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "website not found")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, "database error")
+	}
+	return toWebsiteStatus(w, w.Ticks), nil
+}
+
+func (s *Server) GetValidatorBalance(ctx context.Context, req *uptimepb.GetValidatorBalanceRequest) (*uptimepb.ValidatorBalance, error) {
+	if req.GetValidatorId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "validator_id required")
+	}
+
+	balance, err := s.users.ValidatorBalance(ctx, req.GetValidatorId())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "validator not found")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, "database error")
+	}
+
+	return &uptimepb.ValidatorBalance{
+		ValidatorId:         balance.ValidatorID,
+		PublicKey:           balance.PublicKey,
+		Currency:            balance.Currency,
+		PendingPayouts:      balance.PendingPayouts,
+		PendingPayoutsWhole: balance.PendingPayoutsSOL,
+		ValidatorSince:      timestamppb.New(balance.ValidatorSince),
+		TenureDays:          balance.TenureDays,
+	}, nil
+}
+
+func toWebsiteStatus(w models.Website, ticks []models.WebsiteTick) *uptimepb.WebsiteStatus {
+	result := &uptimepb.WebsiteStatus{
+		Website: &uptimepb.Website{
+			Id:                 w.ID,
+			Url:                w.URL,
+			IntervalSeconds:    int32(w.IntervalSeconds),
+			TimeoutMs:          int32(w.TimeoutMs),
+			LatencyThresholdMs: int32(w.LatencyThresholdMs),
+			SlaTarget:          w.SLATarget,
+			AddressFamily:      w.AddressFamily,
+			Regions:            w.Regions,
+			CreatedAt:          timestamppb.New(w.CreatedAt),
+		},
+		Ticks: make([]*uptimepb.Tick, len(ticks)),
+	}
+	for i, tick := range ticks {
+		result.Ticks[i] = &uptimepb.Tick{
+			Id:          tick.ID,
+			ValidatorId: tick.ValidatorID,
+			Status:      tick.Status,
+			LatencyMs:   tick.Latency,
+			Detail:      tick.Detail,
+			CreatedAt:   timestamppb.New(tick.CreatedAt),
+		}
+	}
+	return result
+}
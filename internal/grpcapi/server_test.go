@@ -0,0 +1,253 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/events"
+	"github.com/datmedevil17/gopher-uptime/internal/grpcapi/uptimepb"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/user"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/website"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+// testServer is the gRPC API served in memory
+type testServer struct {
+	db     *gorm.DB
+	jwtCfg *utils.JWTConfig
+	client uptimepb.UptimeServiceClient
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	cfg := config.Load()
+	jwtCfg, err := utils.LoadJWTConfig(utils.JWTOptions{
+		Algorithm: utils.JWTAlgorithmHS256,
+		Secret:    "test-secret",
+		Issuer:    "test",
+		Audience:  "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := dbtest.Open(t)
+	bus := events.NewMemoryBus()
+	t.Cleanup(func() { bus.Close() })
+
+	listener := bufconn.Listen(1 << 20)
+	server := New(website.NewHandler(db, cfg, bus), user.NewHandler(db, nil, cfg, jwtCfg), jwtCfg, db, cfg.DBQueryTimeout)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testServer{db: db, jwtCfg: jwtCfg, client: uptimepb.NewUptimeServiceClient(conn)}
+}
+
+// as returns a context carrying authorization metadata; empty sends none
+func as(t *testing.T, authorization string) context.Context {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	if authorization == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", authorization)
+}
+
+// token is a valid bearer token for userID
+func (s *testServer) token(t *testing.T, userID string) string {
+	t.Helper()
+
+	token, err := utils.GenerateJWT(userID, s.jwtCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// createWebsite stores a website for a new user and one check of it
+func (s *testServer) createWebsite(t *testing.T) (models.User, models.Website) {
+	t.Helper()
+
+	owner := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+	w := models.Website{ID: uuid.New().String(), UserID: owner.ID, URL: "https://example.com/" + uuid.New().String()}
+	tick := models.WebsiteTick{ID: uuid.New().String(), WebsiteID: w.ID, ValidatorID: validator.ID, Status: models.StatusGood, Latency: 42}
+	for _, row := range []interface{}{&owner, &validator, &w, &tick} {
+		if err := s.db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return owner, w
+}
+
+func TestListWebsitesAuth(t *testing.T) {
+	s := newTestServer(t)
+	// Without websites, so the listing needs no Postgres
+	owner := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := s.db.Create(&owner).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := s.client.ListWebsites(as(t, s.token(t, owner.ID)), &uptimepb.ListWebsitesRequest{})
+	if err != nil {
+		t.Fatalf("with a valid token: %v", err)
+	}
+	if len(resp.GetWebsites()) != 0 {
+		t.Errorf("listed %d websites for a user without any", len(resp.GetWebsites()))
+	}
+
+	forged, err := utils.LoadJWTConfig(utils.JWTOptions{Algorithm: utils.JWTAlgorithmHS256, Secret: "other-secret", Issuer: "test", Audience: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedToken, err := utils.GenerateJWT(owner.ID, forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{"no token", ""},
+		{"not a bearer token", "Basic dXNlcjpwYXNz"},
+		{"malformed token", "Bearer not-a-jwt"},
+		{"signed with another key", "Bearer " + forgedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.client.ListWebsites(as(t, tt.authorization), &uptimepb.ListWebsitesRequest{})
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("error = %v, want Unauthenticated", err)
+			}
+		})
+	}
+}
+
+func TestDeletedUserTokenRefused(t *testing.T) {
+	s := newTestServer(t)
+	owner := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := s.db.Create(&owner).Error; err != nil {
+		t.Fatal(err)
+	}
+	token := s.token(t, owner.ID)
+	if _, err := s.client.ListWebsites(as(t, token), &uptimepb.ListWebsitesRequest{}); err != nil {
+		t.Fatalf("before deleting the account: %v", err)
+	}
+
+	// Deleting the account revokes its tokens, though they haven't expired
+	if err := s.db.Unscoped().Delete(&owner).Error; err != nil {
+		t.Fatal(err)
+	}
+	for name, call := range map[string]func(context.Context) error{
+		"ListWebsites": func(ctx context.Context) error {
+			_, err := s.client.ListWebsites(ctx, &uptimepb.ListWebsitesRequest{})
+			return err
+		},
+		"GetWebsiteStatus": func(ctx context.Context) error {
+			_, err := s.client.GetWebsiteStatus(ctx, &uptimepb.GetWebsiteStatusRequest{WebsiteId: uuid.New().String()})
+			return err
+		},
+	} {
+		if err := call(as(t, token)); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s with a deleted user's token: error = %v, want Unauthenticated", name, err)
+		}
+	}
+}
+
+func TestListWebsites(t *testing.T) {
+	dbtest.RequirePostgres(t) // latest ticks use a LATERAL join
+
+	s := newTestServer(t)
+	owner, w := s.createWebsite(t)
+	s.createWebsite(t) // someone else's
+
+	resp, err := s.client.ListWebsites(as(t, s.token(t, owner.ID)), &uptimepb.ListWebsitesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetWebsites()) != 1 {
+		t.Fatalf("listed %d websites, want only the caller's", len(resp.GetWebsites()))
+	}
+	got := resp.GetWebsites()[0]
+	if got.GetWebsite().GetId() != w.ID || len(got.GetTicks()) != 1 || got.GetTicks()[0].GetLatencyMs() != 42 {
+		t.Errorf("listed %v, want %s with its check", got, w.ID)
+	}
+}
+
+func TestGetWebsiteStatus(t *testing.T) {
+	s := newTestServer(t)
+	owner, w := s.createWebsite(t)
+	other, _ := s.createWebsite(t)
+
+	resp, err := s.client.GetWebsiteStatus(as(t, s.token(t, owner.ID)), &uptimepb.GetWebsiteStatusRequest{WebsiteId: w.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetWebsite().GetUrl() != w.URL || len(resp.GetTicks()) != 1 {
+		t.Errorf("status %v, want %s with its check", resp, w.URL)
+	}
+
+	tests := []struct {
+		name      string
+		userID    string
+		websiteID string
+		want      codes.Code
+	}{
+		{"another user's website", other.ID, w.ID, codes.NotFound},
+		{"unknown website", owner.ID, uuid.New().String(), codes.NotFound},
+		{"missing id", owner.ID, "", codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.client.GetWebsiteStatus(as(t, s.token(t, tt.userID)), &uptimepb.GetWebsiteStatusRequest{WebsiteId: tt.websiteID})
+			if status.Code(err) != tt.want {
+				t.Errorf("error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetValidatorBalanceIsPublic(t *testing.T) {
+	s := newTestServer(t)
+	validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown", PendingPayouts: 2_500_000_000}
+	if err := s.db.Create(&validator).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Like GET /validator/:id/balance, no token is needed
+	balance, err := s.client.GetValidatorBalance(as(t, ""), &uptimepb.GetValidatorBalanceRequest{ValidatorId: validator.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.GetValidatorId() != validator.ID || balance.GetPendingPayouts() != 2_500_000_000 || balance.GetPublicKey() != validator.PublicKey {
+		t.Errorf("balance %v, want %s's 2500000000 pending", balance, validator.ID)
+	}
+
+	if _, err := s.client.GetValidatorBalance(as(t, ""), &uptimepb.GetValidatorBalanceRequest{ValidatorId: uuid.New().String()}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown validator: error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,658 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: uptime.proto
+
+package uptimepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Website struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url                string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	IntervalSeconds    int32                  `protobuf:"varint,3,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"` // 0 uses the hub's CHECK_INTERVAL
+	TimeoutMs          int32                  `protobuf:"varint,4,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`                   // 0 uses the validators' CHECK_TIMEOUT
+	LatencyThresholdMs int32                  `protobuf:"varint,5,opt,name=latency_threshold_ms,json=latencyThresholdMs,proto3" json:"latency_threshold_ms,omitempty"`
+	SlaTarget          float64                `protobuf:"fixed64,6,opt,name=sla_target,json=slaTarget,proto3" json:"sla_target,omitempty"`
+	AddressFamily      string                 `protobuf:"bytes,7,opt,name=address_family,json=addressFamily,proto3" json:"address_family,omitempty"`
+	Regions            []string               `protobuf:"bytes,8,rep,name=regions,proto3" json:"regions,omitempty"`
+	CreatedAt          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Website) Reset() {
+	*x = Website{}
+	mi := &file_uptime_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Website) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Website) ProtoMessage() {}
+
+func (x *Website) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Website.ProtoReflect.Descriptor instead.
+func (*Website) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Website) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Website) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Website) GetIntervalSeconds() int32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *Website) GetTimeoutMs() int32 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *Website) GetLatencyThresholdMs() int32 {
+	if x != nil {
+		return x.LatencyThresholdMs
+	}
+	return 0
+}
+
+func (x *Website) GetSlaTarget() float64 {
+	if x != nil {
+		return x.SlaTarget
+	}
+	return 0
+}
+
+func (x *Website) GetAddressFamily() string {
+	if x != nil {
+		return x.AddressFamily
+	}
+	return ""
+}
+
+func (x *Website) GetRegions() []string {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+func (x *Website) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Tick struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ValidatorId   string                 `protobuf:"bytes,2,opt,name=validator_id,json=validatorId,proto3" json:"validator_id,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // Good, Degraded or Bad
+	LatencyMs     float64                `protobuf:"fixed64,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	Detail        string                 `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tick) Reset() {
+	*x = Tick{}
+	mi := &file_uptime_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tick) ProtoMessage() {}
+
+func (x *Tick) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tick.ProtoReflect.Descriptor instead.
+func (*Tick) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Tick) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Tick) GetValidatorId() string {
+	if x != nil {
+		return x.ValidatorId
+	}
+	return ""
+}
+
+func (x *Tick) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Tick) GetLatencyMs() float64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+func (x *Tick) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *Tick) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type WebsiteStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Website       *Website               `protobuf:"bytes,1,opt,name=website,proto3" json:"website,omitempty"`
+	Ticks         []*Tick                `protobuf:"bytes,2,rep,name=ticks,proto3" json:"ticks,omitempty"` // newest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebsiteStatus) Reset() {
+	*x = WebsiteStatus{}
+	mi := &file_uptime_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebsiteStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebsiteStatus) ProtoMessage() {}
+
+func (x *WebsiteStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebsiteStatus.ProtoReflect.Descriptor instead.
+func (*WebsiteStatus) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WebsiteStatus) GetWebsite() *Website {
+	if x != nil {
+		return x.Website
+	}
+	return nil
+}
+
+func (x *WebsiteStatus) GetTicks() []*Tick {
+	if x != nil {
+		return x.Ticks
+	}
+	return nil
+}
+
+type ListWebsitesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FullTicks     bool                   `protobuf:"varint,1,opt,name=full_ticks,json=fullTicks,proto3" json:"full_ticks,omitempty"` // up to 100 ticks per website instead of the latest one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebsitesRequest) Reset() {
+	*x = ListWebsitesRequest{}
+	mi := &file_uptime_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebsitesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebsitesRequest) ProtoMessage() {}
+
+func (x *ListWebsitesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebsitesRequest.ProtoReflect.Descriptor instead.
+func (*ListWebsitesRequest) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListWebsitesRequest) GetFullTicks() bool {
+	if x != nil {
+		return x.FullTicks
+	}
+	return false
+}
+
+type ListWebsitesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Websites      []*WebsiteStatus       `protobuf:"bytes,1,rep,name=websites,proto3" json:"websites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebsitesResponse) Reset() {
+	*x = ListWebsitesResponse{}
+	mi := &file_uptime_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebsitesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebsitesResponse) ProtoMessage() {}
+
+func (x *ListWebsitesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebsitesResponse.ProtoReflect.Descriptor instead.
+func (*ListWebsitesResponse) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListWebsitesResponse) GetWebsites() []*WebsiteStatus {
+	if x != nil {
+		return x.Websites
+	}
+	return nil
+}
+
+type GetWebsiteStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebsiteId     string                 `protobuf:"bytes,1,opt,name=website_id,json=websiteId,proto3" json:"website_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWebsiteStatusRequest) Reset() {
+	*x = GetWebsiteStatusRequest{}
+	mi := &file_uptime_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWebsiteStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWebsiteStatusRequest) ProtoMessage() {}
+
+func (x *GetWebsiteStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWebsiteStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetWebsiteStatusRequest) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetWebsiteStatusRequest) GetWebsiteId() string {
+	if x != nil {
+		return x.WebsiteId
+	}
+	return ""
+}
+
+type GetValidatorBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ValidatorId   string                 `protobuf:"bytes,1,opt,name=validator_id,json=validatorId,proto3" json:"validator_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetValidatorBalanceRequest) Reset() {
+	*x = GetValidatorBalanceRequest{}
+	mi := &file_uptime_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetValidatorBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetValidatorBalanceRequest) ProtoMessage() {}
+
+func (x *GetValidatorBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetValidatorBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetValidatorBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetValidatorBalanceRequest) GetValidatorId() string {
+	if x != nil {
+		return x.ValidatorId
+	}
+	return ""
+}
+
+type ValidatorBalance struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ValidatorId         string                 `protobuf:"bytes,1,opt,name=validator_id,json=validatorId,proto3" json:"validator_id,omitempty"`
+	PublicKey           string                 `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Currency            string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	PendingPayouts      float64                `protobuf:"fixed64,4,opt,name=pending_payouts,json=pendingPayouts,proto3" json:"pending_payouts,omitempty"`                  // base units of the payout token
+	PendingPayoutsWhole float64                `protobuf:"fixed64,5,opt,name=pending_payouts_whole,json=pendingPayoutsWhole,proto3" json:"pending_payouts_whole,omitempty"` // whole tokens
+	ValidatorSince      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=validator_since,json=validatorSince,proto3" json:"validator_since,omitempty"`
+	TenureDays          int64                  `protobuf:"varint,7,opt,name=tenure_days,json=tenureDays,proto3" json:"tenure_days,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ValidatorBalance) Reset() {
+	*x = ValidatorBalance{}
+	mi := &file_uptime_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidatorBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatorBalance) ProtoMessage() {}
+
+func (x *ValidatorBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_uptime_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatorBalance.ProtoReflect.Descriptor instead.
+func (*ValidatorBalance) Descriptor() ([]byte, []int) {
+	return file_uptime_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ValidatorBalance) GetValidatorId() string {
+	if x != nil {
+		return x.ValidatorId
+	}
+	return ""
+}
+
+func (x *ValidatorBalance) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *ValidatorBalance) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ValidatorBalance) GetPendingPayouts() float64 {
+	if x != nil {
+		return x.PendingPayouts
+	}
+	return 0
+}
+
+func (x *ValidatorBalance) GetPendingPayoutsWhole() float64 {
+	if x != nil {
+		return x.PendingPayoutsWhole
+	}
+	return 0
+}
+
+func (x *ValidatorBalance) GetValidatorSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ValidatorSince
+	}
+	return nil
+}
+
+func (x *ValidatorBalance) GetTenureDays() int64 {
+	if x != nil {
+		return x.TenureDays
+	}
+	return 0
+}
+
+var File_uptime_proto protoreflect.FileDescriptor
+
+const file_uptime_proto_rawDesc = "" +
+	"\n" +
+	"\fuptime.proto\x12\tuptime.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc2\x02\n" +
+	"\aWebsite\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12)\n" +
+	"\x10interval_seconds\x18\x03 \x01(\x05R\x0fintervalSeconds\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x04 \x01(\x05R\ttimeoutMs\x120\n" +
+	"\x14latency_threshold_ms\x18\x05 \x01(\x05R\x12latencyThresholdMs\x12\x1d\n" +
+	"\n" +
+	"sla_target\x18\x06 \x01(\x01R\tslaTarget\x12%\n" +
+	"\x0eaddress_family\x18\a \x01(\tR\raddressFamily\x12\x18\n" +
+	"\aregions\x18\b \x03(\tR\aregions\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc3\x01\n" +
+	"\x04Tick\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fvalidator_id\x18\x02 \x01(\tR\vvalidatorId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x04 \x01(\x01R\tlatencyMs\x12\x16\n" +
+	"\x06detail\x18\x05 \x01(\tR\x06detail\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"d\n" +
+	"\rWebsiteStatus\x12,\n" +
+	"\awebsite\x18\x01 \x01(\v2\x12.uptime.v1.WebsiteR\awebsite\x12%\n" +
+	"\x05ticks\x18\x02 \x03(\v2\x0f.uptime.v1.TickR\x05ticks\"4\n" +
+	"\x13ListWebsitesRequest\x12\x1d\n" +
+	"\n" +
+	"full_ticks\x18\x01 \x01(\bR\tfullTicks\"L\n" +
+	"\x14ListWebsitesResponse\x124\n" +
+	"\bwebsites\x18\x01 \x03(\v2\x18.uptime.v1.WebsiteStatusR\bwebsites\"8\n" +
+	"\x17GetWebsiteStatusRequest\x12\x1d\n" +
+	"\n" +
+	"website_id\x18\x01 \x01(\tR\twebsiteId\"?\n" +
+	"\x1aGetValidatorBalanceRequest\x12!\n" +
+	"\fvalidator_id\x18\x01 \x01(\tR\vvalidatorId\"\xb3\x02\n" +
+	"\x10ValidatorBalance\x12!\n" +
+	"\fvalidator_id\x18\x01 \x01(\tR\vvalidatorId\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x02 \x01(\tR\tpublicKey\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12'\n" +
+	"\x0fpending_payouts\x18\x04 \x01(\x01R\x0ependingPayouts\x122\n" +
+	"\x15pending_payouts_whole\x18\x05 \x01(\x01R\x13pendingPayoutsWhole\x12C\n" +
+	"\x0fvalidator_since\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0evalidatorSince\x12\x1f\n" +
+	"\vtenure_days\x18\a \x01(\x03R\n" +
+	"tenureDays2\x8d\x02\n" +
+	"\rUptimeService\x12O\n" +
+	"\fListWebsites\x12\x1e.uptime.v1.ListWebsitesRequest\x1a\x1f.uptime.v1.ListWebsitesResponse\x12P\n" +
+	"\x10GetWebsiteStatus\x12\".uptime.v1.GetWebsiteStatusRequest\x1a\x18.uptime.v1.WebsiteStatus\x12Y\n" +
+	"\x13GetValidatorBalance\x12%.uptime.v1.GetValidatorBalanceRequest\x1a\x1b.uptime.v1.ValidatorBalanceBAZ?github.com/datmedevil17/gopher-uptime/internal/grpcapi/uptimepbb\x06proto3"
+
+var (
+	file_uptime_proto_rawDescOnce sync.Once
+	file_uptime_proto_rawDescData []byte
+)
+
+func file_uptime_proto_rawDescGZIP() []byte {
+	file_uptime_proto_rawDescOnce.Do(func() {
+		file_uptime_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_uptime_proto_rawDesc), len(file_uptime_proto_rawDesc)))
+	})
+	return file_uptime_proto_rawDescData
+}
+
+var file_uptime_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_uptime_proto_goTypes = []any{
+	(*Website)(nil),                    // 0: uptime.v1.Website
+	(*Tick)(nil),                       // 1: uptime.v1.Tick
+	(*WebsiteStatus)(nil),              // 2: uptime.v1.WebsiteStatus
+	(*ListWebsitesRequest)(nil),        // 3: uptime.v1.ListWebsitesRequest
+	(*ListWebsitesResponse)(nil),       // 4: uptime.v1.ListWebsitesResponse
+	(*GetWebsiteStatusRequest)(nil),    // 5: uptime.v1.GetWebsiteStatusRequest
+	(*GetValidatorBalanceRequest)(nil), // 6: uptime.v1.GetValidatorBalanceRequest
+	(*ValidatorBalance)(nil),           // 7: uptime.v1.ValidatorBalance
+	(*timestamppb.Timestamp)(nil),      // 8: google.protobuf.Timestamp
+}
+var file_uptime_proto_depIdxs = []int32{
+	8, // 0: uptime.v1.Website.created_at:type_name -> google.protobuf.Timestamp
+	8, // 1: uptime.v1.Tick.created_at:type_name -> google.protobuf.Timestamp
+	0, // 2: uptime.v1.WebsiteStatus.website:type_name -> uptime.v1.Website
+	1, // 3: uptime.v1.WebsiteStatus.ticks:type_name -> uptime.v1.Tick
+	2, // 4: uptime.v1.ListWebsitesResponse.websites:type_name -> uptime.v1.WebsiteStatus
+	8, // 5: uptime.v1.ValidatorBalance.validator_since:type_name -> google.protobuf.Timestamp
+	3, // 6: uptime.v1.UptimeService.ListWebsites:input_type -> uptime.v1.ListWebsitesRequest
+	5, // 7: uptime.v1.UptimeService.GetWebsiteStatus:input_type -> uptime.v1.GetWebsiteStatusRequest
+	6, // 8: uptime.v1.UptimeService.GetValidatorBalance:input_type -> uptime.v1.GetValidatorBalanceRequest
+	4, // 9: uptime.v1.UptimeService.ListWebsites:output_type -> uptime.v1.ListWebsitesResponse
+	2, // 10: uptime.v1.UptimeService.GetWebsiteStatus:output_type -> uptime.v1.WebsiteStatus
+	7, // 11: uptime.v1.UptimeService.GetValidatorBalance:output_type -> uptime.v1.ValidatorBalance
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_uptime_proto_init() }
+func file_uptime_proto_init() {
+	if File_uptime_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_uptime_proto_rawDesc), len(file_uptime_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_uptime_proto_goTypes,
+		DependencyIndexes: file_uptime_proto_depIdxs,
+		MessageInfos:      file_uptime_proto_msgTypes,
+	}.Build()
+	File_uptime_proto = out.File
+	file_uptime_proto_goTypes = nil
+	file_uptime_proto_depIdxs = nil
+}
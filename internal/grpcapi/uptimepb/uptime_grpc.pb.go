@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: uptime.proto
+
+package uptimepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UptimeService_ListWebsites_FullMethodName        = "/uptime.v1.UptimeService/ListWebsites"
+	UptimeService_GetWebsiteStatus_FullMethodName    = "/uptime.v1.UptimeService/GetWebsiteStatus"
+	UptimeService_GetValidatorBalance_FullMethodName = "/uptime.v1.UptimeService/GetValidatorBalance"
+)
+
+// UptimeServiceClient is the client API for UptimeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// UptimeService serves the REST API's core reads over gRPC. Website calls need
+// the JWT from /api/v1/auth/login as "authorization: Bearer <token>" metadata;
+// GetValidatorBalance is public, like its REST route.
+type UptimeServiceClient interface {
+	// The caller's websites, newest first, with their latest ticks
+	ListWebsites(ctx context.Context, in *ListWebsitesRequest, opts ...grpc.CallOption) (*ListWebsitesResponse, error)
+	// One of the caller's websites with its 100 newest ticks
+	GetWebsiteStatus(ctx context.Context, in *GetWebsiteStatusRequest, opts ...grpc.CallOption) (*WebsiteStatus, error)
+	GetValidatorBalance(ctx context.Context, in *GetValidatorBalanceRequest, opts ...grpc.CallOption) (*ValidatorBalance, error)
+}
+
+type uptimeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUptimeServiceClient(cc grpc.ClientConnInterface) UptimeServiceClient {
+	return &uptimeServiceClient{cc}
+}
+
+func (c *uptimeServiceClient) ListWebsites(ctx context.Context, in *ListWebsitesRequest, opts ...grpc.CallOption) (*ListWebsitesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebsitesResponse)
+	err := c.cc.Invoke(ctx, UptimeService_ListWebsites_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uptimeServiceClient) GetWebsiteStatus(ctx context.Context, in *GetWebsiteStatusRequest, opts ...grpc.CallOption) (*WebsiteStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebsiteStatus)
+	err := c.cc.Invoke(ctx, UptimeService_GetWebsiteStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uptimeServiceClient) GetValidatorBalance(ctx context.Context, in *GetValidatorBalanceRequest, opts ...grpc.CallOption) (*ValidatorBalance, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidatorBalance)
+	err := c.cc.Invoke(ctx, UptimeService_GetValidatorBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UptimeServiceServer is the server API for UptimeService service.
+// All implementations must embed UnimplementedUptimeServiceServer
+// for forward compatibility.
+//
+// UptimeService serves the REST API's core reads over gRPC. Website calls need
+// the JWT from /api/v1/auth/login as "authorization: Bearer <token>" metadata;
+// GetValidatorBalance is public, like its REST route.
+type UptimeServiceServer interface {
+	// The caller's websites, newest first, with their latest ticks
+	ListWebsites(context.Context, *ListWebsitesRequest) (*ListWebsitesResponse, error)
+	// One of the caller's websites with its 100 newest ticks
+	GetWebsiteStatus(context.Context, *GetWebsiteStatusRequest) (*WebsiteStatus, error)
+	GetValidatorBalance(context.Context, *GetValidatorBalanceRequest) (*ValidatorBalance, error)
+	mustEmbedUnimplementedUptimeServiceServer()
+}
+
+// UnimplementedUptimeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUptimeServiceServer struct{}
+
+func (UnimplementedUptimeServiceServer) ListWebsites(context.Context, *ListWebsitesRequest) (*ListWebsitesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListWebsites not implemented")
+}
+func (UnimplementedUptimeServiceServer) GetWebsiteStatus(context.Context, *GetWebsiteStatusRequest) (*WebsiteStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetWebsiteStatus not implemented")
+}
+func (UnimplementedUptimeServiceServer) GetValidatorBalance(context.Context, *GetValidatorBalanceRequest) (*ValidatorBalance, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetValidatorBalance not implemented")
+}
+func (UnimplementedUptimeServiceServer) mustEmbedUnimplementedUptimeServiceServer() {}
+func (UnimplementedUptimeServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeUptimeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UptimeServiceServer will
+// result in compilation errors.
+type UnsafeUptimeServiceServer interface {
+	mustEmbedUnimplementedUptimeServiceServer()
+}
+
+func RegisterUptimeServiceServer(s grpc.ServiceRegistrar, srv UptimeServiceServer) {
+	// If the following call panics, it indicates UnimplementedUptimeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UptimeService_ServiceDesc, srv)
+}
+
+func _UptimeService_ListWebsites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebsitesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UptimeServiceServer).ListWebsites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UptimeService_ListWebsites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UptimeServiceServer).ListWebsites(ctx, req.(*ListWebsitesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UptimeService_GetWebsiteStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebsiteStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UptimeServiceServer).GetWebsiteStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UptimeService_GetWebsiteStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UptimeServiceServer).GetWebsiteStatus(ctx, req.(*GetWebsiteStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UptimeService_GetValidatorBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValidatorBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UptimeServiceServer).GetValidatorBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UptimeService_GetValidatorBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UptimeServiceServer).GetValidatorBalance(ctx, req.(*GetValidatorBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UptimeService_ServiceDesc is the grpc.ServiceDesc for UptimeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UptimeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "uptime.v1.UptimeService",
+	HandlerType: (*UptimeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListWebsites",
+			Handler:    _UptimeService_ListWebsites_Handler,
+		},
+		{
+			MethodName: "GetWebsiteStatus",
+			Handler:    _UptimeService_GetWebsiteStatus_Handler,
+		},
+		{
+			MethodName: "GetValidatorBalance",
+			Handler:    _UptimeService_GetValidatorBalance_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "uptime.proto",
+}
@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BanRequest bans or unbans a validator
+type BanRequest struct {
+	Banned         *bool  `json:"banned" binding:"required"`
+	Reason         string `json:"reason" binding:"max=500"`
+	ForfeitPayouts bool   `json:"forfeit_payouts"` // zero the pending balance when banning
+}
+
+// PutValidatorBan - PUT /api/v1/validators/:validatorId/ban
+// Hubs refuse a banned validator's signup and disconnect it at their next
+// scheduling cycle.
+func (h *Handler) PutValidatorBan(c *gin.Context) {
+	var req BanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var validator models.Validator
+	var forfeited float64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", c.Param("validatorId")).
+			First(&validator).Error; err != nil {
+			return err
+		}
+
+		validator.Banned, validator.BannedAt, validator.BanReason = false, nil, ""
+		if *req.Banned {
+			now := time.Now()
+			validator.Banned, validator.BannedAt, validator.BanReason = true, &now, req.Reason
+		}
+		updates := map[string]interface{}{
+			"banned":     validator.Banned,
+			"banned_at":  validator.BannedAt,
+			"ban_reason": validator.BanReason,
+		}
+		if *req.Banned && req.ForfeitPayouts {
+			forfeited = validator.PendingPayouts
+			updates["pending_payouts"] = 0
+		}
+		return tx.Model(&validator).Updates(updates).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		utils.ErrorResponse(c, http.StatusNotFound, utils.CodeValidatorNotFound, "Validator not found")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to update validator")
+		return
+	}
+
+	if *req.Banned {
+		log.Printf("🚨 Validator %s banned by admin (forfeited %.2f): %s", validator.ID, forfeited, req.Reason)
+	} else {
+		log.Printf("✅ Validator %s unbanned by admin", validator.ID)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"validator_id":     validator.ID,
+		"banned":           validator.Banned,
+		"banned_at":        validator.BannedAt,
+		"ban_reason":       validator.BanReason,
+		"forfeited_payout": forfeited,
+	})
+}
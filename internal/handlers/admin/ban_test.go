@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// putBan sends body to PutValidatorBan for validatorID
+func putBan(t *testing.T, h *Handler, validatorID, body string) (int, envelope) {
+	t.Helper()
+
+	router := gin.New()
+	router.PUT("/validators/:validatorId/ban", h.PutValidatorBan)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/validators/"+validatorID+"/ban", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func TestPutValidatorBan(t *testing.T) {
+	h := newTestHandler(t)
+	validator := createValidator(t, h.db)
+	if err := h.db.Model(&validator).Update("pending_payouts", 5_000).Error; err != nil {
+		t.Fatal(err)
+	}
+	stored := func() models.Validator {
+		var v models.Validator
+		if err := h.db.Where("id = ?", validator.ID).First(&v).Error; err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	status, resp := putBan(t, h, validator.ID, `{"banned": true, "reason": "forged results"}`)
+	if status != http.StatusOK {
+		t.Fatalf("banning: status = %d (%s), want 200", status, resp.Error)
+	}
+	if v := stored(); !v.Banned || v.BannedAt == nil || v.BanReason != "forged results" || v.PendingPayouts != 5_000 {
+		t.Errorf("banned validator stored as %+v, want banned with its reason and balance kept", v)
+	}
+
+	status, resp = putBan(t, h, validator.ID, `{"banned": false}`)
+	if status != http.StatusOK {
+		t.Fatalf("unbanning: status = %d (%s), want 200", status, resp.Error)
+	}
+	if v := stored(); v.Banned || v.BannedAt != nil || v.BanReason != "" {
+		t.Errorf("unbanned validator stored as %+v, want the ban cleared", v)
+	}
+
+	status, resp = putBan(t, h, validator.ID, `{"banned": true, "forfeit_payouts": true}`)
+	var data struct {
+		Forfeited float64 `json:"forfeited_payout"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || status != http.StatusOK {
+		t.Fatalf("banning with forfeit: status = %d (%s)", status, resp.Error)
+	}
+	if v := stored(); !v.Banned || v.PendingPayouts != 0 || data.Forfeited != 5_000 {
+		t.Errorf("forfeited %v, balance now %v, want the 5000 pending forfeited", data.Forfeited, v.PendingPayouts)
+	}
+}
+
+func TestPutValidatorBanErrors(t *testing.T) {
+	h := newTestHandler(t)
+	validator := createValidator(t, h.db)
+
+	tests := []struct {
+		name        string
+		validatorID string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{"unknown validator", uuid.New().String(), `{"banned": true}`, http.StatusNotFound, utils.CodeValidatorNotFound},
+		{"missing flag", validator.ID, `{"reason": "spam"}`, http.StatusBadRequest, utils.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, resp := putBan(t, h, tt.validatorID, tt.body); status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("status = %d, code = %s, want %d %s", status, resp.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
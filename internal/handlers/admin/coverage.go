@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegionCoverage counts the validators registered in one location
+type RegionCoverage struct {
+	Location   string `json:"location"`
+	Validators int64  `json:"validators"`
+	Online     int    `json:"online"`
+}
+
+// GetValidatorCoverage - GET /api/v1/validators/coverage
+// Validator counts per location, most covered first. Online counts only reflect
+// validators on other processes when REDIS_URL is configured.
+func (h *Handler) GetValidatorCoverage(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	regions := []RegionCoverage{}
+	if err := db.Model(&models.Validator{}).
+		Select("location, COUNT(*) AS validators").
+		Group("location").
+		Scan(&regions).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute coverage")
+		return
+	}
+
+	entries, err := h.presence.List(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to load validator presence")
+		return
+	}
+
+	online := 0
+	if len(entries) > 0 {
+		ids := make([]string, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ValidatorID
+		}
+
+		var locations []string
+		if err := db.Model(&models.Validator{}).
+			Where("id IN ?", ids).
+			Pluck("location", &locations).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute coverage")
+			return
+		}
+
+		byLocation := make(map[string]int, len(locations))
+		for _, location := range locations {
+			byLocation[location]++
+		}
+		for i := range regions {
+			regions[i].Online = byLocation[regions[i].Location]
+			online += regions[i].Online
+		}
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].Validators != regions[j].Validators {
+			return regions[i].Validators > regions[j].Validators
+		}
+		return regions[i].Location < regions[j].Location
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"regions": regions,
+		"count":   len(regions),
+		"online":  online,
+	})
+}
@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
+)
+
+func TestGetValidatorCoverage(t *testing.T) {
+	h := newTestHandler(t)
+	online := []string{
+		createValidatorIn(t, h.db, "eu-central").ID,
+		createValidatorIn(t, h.db, "us-east").ID,
+	}
+	createValidatorIn(t, h.db, "eu-central")
+	createValidatorIn(t, h.db, "eu-central")
+	createValidatorIn(t, h.db, "us-east")
+	createValidatorIn(t, h.db, "ap-south")
+	for _, id := range online {
+		if err := h.presence.Register(context.Background(), presence.Entry{ValidatorID: id, HubID: "hub", ConnectedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got struct {
+		Regions []RegionCoverage `json:"regions"`
+		Count   int              `json:"count"`
+		Online  int              `json:"online"`
+	}
+	status, resp := get(t, h.GetValidatorCoverage, "/validators/coverage", "/validators/coverage", &got)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+
+	// Most covered first, ties by name
+	want := []RegionCoverage{
+		{Location: "eu-central", Validators: 3, Online: 1},
+		{Location: "us-east", Validators: 2, Online: 1},
+		{Location: "ap-south", Validators: 1, Online: 0},
+	}
+	if !reflect.DeepEqual(got.Regions, want) {
+		t.Errorf("regions = %+v, want %+v", got.Regions, want)
+	}
+	if got.Count != 3 || got.Online != 2 {
+		t.Errorf("count = %d, online = %d, want 3 regions with 2 online", got.Count, got.Online)
+	}
+}
+
+func TestGetValidatorCoverageWithoutValidators(t *testing.T) {
+	h := newTestHandler(t)
+
+	var got struct {
+		Regions []RegionCoverage `json:"regions"`
+		Count   int              `json:"count"`
+	}
+	status, _ := get(t, h.GetValidatorCoverage, "/validators/coverage", "/validators/coverage", &got)
+	if status != http.StatusOK || got.Regions == nil || got.Count != 0 {
+		t.Errorf("status = %d, regions = %v, count = %d, want 200 with an empty list", status, got.Regions, got.Count)
+	}
+}
@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/middleware"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	db       *gorm.DB
+	cfg      *config.Config
+	presence presence.Store
+	readOnly *middleware.ReadOnlyMode
+}
+
+func NewHandler(db *gorm.DB, cfg *config.Config, store presence.Store, readOnly *middleware.ReadOnlyMode) *Handler {
+	return &Handler{db: db, cfg: cfg, presence: store, readOnly: readOnly}
+}
+
+// ListPayouts - GET /api/v1/payouts?status=&validator_id=&from=&to=&min_amount=&max_amount=&page=&page_size=
+func (h *Handler) ListPayouts(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	query := db.Model(&models.PayoutTransaction{})
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if validatorID := c.Query("validator_id"); validatorID != "" {
+		query = query.Where("validator_id = ?", validatorID)
+	}
+
+	for param, clause := range map[string]string{"from": "created_at >= ?", "to": "created_at <= ?"} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, param+" must be an RFC3339 timestamp")
+				return
+			}
+			query = query.Where(clause, t)
+		}
+	}
+
+	for param, clause := range map[string]string{"min_amount": "amount >= ?", "max_amount": "amount <= ?"} {
+		if raw := c.Query(param); raw != "" {
+			amount, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, param+" must be a number")
+				return
+			}
+			query = query.Where(clause, amount)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count payouts")
+		return
+	}
+
+	var payouts []models.PayoutTransaction
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).
+		Limit(page.PageSize).
+		Find(&payouts).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch payouts")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"payouts":   payouts,
+		"total":     total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
+
+// ListOnlineValidators - GET /api/v1/validators/online
+// Only reflects validators on other processes when REDIS_URL is configured.
+func (h *Handler) ListOnlineValidators(c *gin.Context) {
+	entries, err := h.presence.List(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to load validator presence")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"validators": entries,
+		"count":      len(entries),
+	})
+}
+
+// ListValidators - GET /api/v1/validators?location=&page=&page_size=
+func (h *Handler) ListValidators(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	query := db.Model(&models.Validator{})
+	if location := c.Query("location"); location != "" {
+		query = query.Where("LOWER(location) = LOWER(?)", location)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count validators")
+		return
+	}
+
+	var validators []models.Validator
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).
+		Limit(page.PageSize).
+		Find(&validators).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch validators")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"validators": validators,
+		"total":      total,
+		"page":       page.Page,
+		"page_size":  page.PageSize,
+	})
+}
+
+// GetValidator - GET /api/v1/validators/:validatorId
+// Includes the validator's last self-reported health.
+func (h *Handler) GetValidator(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var validator models.Validator
+	result := db.Where("id = ?", c.Param("validatorId")).First(&validator)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeValidatorNotFound, "Validator not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, validator)
+}
@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/middleware"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/presence"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	cfg := config.Load()
+	return NewHandler(dbtest.Open(t), cfg, presence.NewMemoryStore(cfg.PresenceTTL), middleware.NewReadOnlyMode(false))
+}
+
+// envelope is utils.Response with the payload left undecoded
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Code    string          `json:"code"`
+}
+
+// get routes a GET for target through handler, mounted at route, and decodes
+// the payload of a successful response into data
+func get(t *testing.T, handler gin.HandlerFunc, route, target string, data interface{}) (int, envelope) {
+	t.Helper()
+
+	router := gin.New()
+	router.GET(route, handler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %s response %q: %v", target, w.Body.String(), err)
+	}
+	if resp.Success && data != nil {
+		if err := json.Unmarshal(resp.Data, data); err != nil {
+			t.Fatalf("decoding data %s: %v", resp.Data, err)
+		}
+	}
+	return w.Code, resp
+}
+
+func createValidator(t *testing.T, db *gorm.DB) models.Validator {
+	t.Helper()
+
+	validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+	if err := db.Create(&validator).Error; err != nil {
+		t.Fatalf("creating validator: %v", err)
+	}
+	return validator
+}
+
+// createValidatorIn stores a validator located in location
+func createValidatorIn(t *testing.T, db *gorm.DB, location string) models.Validator {
+	t.Helper()
+
+	validator := createValidator(t, db)
+	if err := db.Model(&validator).Update("location", location).Error; err != nil {
+		t.Fatal(err)
+	}
+	validator.Location = location
+	return validator
+}
+
+func createPayout(t *testing.T, db *gorm.DB, validatorID, status string, amount float64, at time.Time) models.PayoutTransaction {
+	t.Helper()
+
+	payout := models.PayoutTransaction{
+		ID:          uuid.New().String(),
+		ValidatorID: validatorID,
+		Amount:      amount,
+		Status:      status,
+		CreatedAt:   at,
+	}
+	if err := db.Create(&payout).Error; err != nil {
+		t.Fatalf("creating payout: %v", err)
+	}
+	return payout
+}
+
+func TestListPayoutsFilters(t *testing.T) {
+	h := newTestHandler(t)
+	alice, bob := createValidator(t, h.db), createValidator(t, h.db)
+
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	completedOld := createPayout(t, h.db, alice.ID, "completed", 100, day.AddDate(0, 0, -5))
+	failed := createPayout(t, h.db, alice.ID, "failed", 200, day.AddDate(0, 0, -1))
+	completedNew := createPayout(t, h.db, bob.ID, "completed", 300, day)
+	pending := createPayout(t, h.db, bob.ID, "pending", 400, day.AddDate(0, 0, 2))
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []models.PayoutTransaction // newest first
+	}{
+		{"everything", url.Values{}, []models.PayoutTransaction{pending, completedNew, failed, completedOld}},
+		{"by status", url.Values{"status": {"completed"}}, []models.PayoutTransaction{completedNew, completedOld}},
+		{"by validator", url.Values{"validator_id": {alice.ID}}, []models.PayoutTransaction{failed, completedOld}},
+		{"from", url.Values{"from": {day.AddDate(0, 0, -1).Format(time.RFC3339)}}, []models.PayoutTransaction{pending, completedNew, failed}},
+		{"to", url.Values{"to": {day.Format(time.RFC3339)}}, []models.PayoutTransaction{completedNew, failed, completedOld}},
+		{
+			"date range and status",
+			url.Values{"from": {day.AddDate(0, 0, -7).Format(time.RFC3339)}, "to": {day.AddDate(0, 0, -2).Format(time.RFC3339)}, "status": {"completed"}},
+			[]models.PayoutTransaction{completedOld},
+		},
+		{"empty range", url.Values{"from": {day.AddDate(0, 0, 3).Format(time.RFC3339)}}, nil},
+		{"amount", url.Values{"min_amount": {"150"}, "max_amount": {"300"}}, []models.PayoutTransaction{completedNew, failed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data struct {
+				Payouts []models.PayoutTransaction `json:"payouts"`
+				Total   int64                      `json:"total"`
+			}
+			status, resp := get(t, h.ListPayouts, "/payouts", "/payouts?"+tt.query.Encode(), &data)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			if data.Total != int64(len(tt.want)) || len(data.Payouts) != len(tt.want) {
+				t.Fatalf("got %d payouts of %d, want %d", len(data.Payouts), data.Total, len(tt.want))
+			}
+			for i := range tt.want {
+				if data.Payouts[i].ID != tt.want[i].ID {
+					t.Errorf("payouts[%d] = %s (%s), want %s (%s)", i,
+						data.Payouts[i].Status, data.Payouts[i].CreatedAt, tt.want[i].Status, tt.want[i].CreatedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestListPayoutsPagination(t *testing.T) {
+	h := newTestHandler(t)
+	validator := createValidator(t, h.db)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		createPayout(t, h.db, validator.ID, "completed", 10, start.Add(time.Duration(i)*time.Hour))
+	}
+
+	var data struct {
+		Payouts []models.PayoutTransaction `json:"payouts"`
+		Total   int64                      `json:"total"`
+	}
+	if status, resp := get(t, h.ListPayouts, "/payouts", "/payouts?page=3&page_size=2", &data); status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	if data.Total != 5 || len(data.Payouts) != 1 || !data.Payouts[0].CreatedAt.Equal(start) {
+		t.Errorf("page 3 = %d payouts of %d, want the oldest of 5", len(data.Payouts), data.Total)
+	}
+}
+
+func TestListPayoutsInvalidFilters(t *testing.T) {
+	h := newTestHandler(t)
+
+	for _, query := range []string{"from=yesterday", "to=2026-13-01", "min_amount=lots", "page=0", "page_size=1000"} {
+		t.Run(query, func(t *testing.T) {
+			if status, _ := get(t, h.ListPayouts, "/payouts", "/payouts?"+query, nil); status != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", status)
+			}
+		})
+	}
+}
+
+func TestListValidatorsByLocation(t *testing.T) {
+	h := newTestHandler(t)
+	frankfurt := []models.Validator{createValidatorIn(t, h.db, "eu-central"), createValidatorIn(t, h.db, "EU-Central")}
+	createValidatorIn(t, h.db, "us-east")
+	createValidator(t, h.db)
+
+	type listing struct {
+		Validators []models.Validator `json:"validators"`
+		Total      int64              `json:"total"`
+	}
+	tests := []struct {
+		name      string
+		query     string
+		wantTotal int64
+		wantIDs   []string // listed validators; a page of them when shorter than wantTotal
+	}{
+		{"one region", "?location=eu-central", 2, []string{frankfurt[0].ID, frankfurt[1].ID}},
+		{"case-insensitive", "?location=EU-CENTRAL", 2, []string{frankfurt[0].ID, frankfurt[1].ID}},
+		{"paged within the region", "?location=eu-central&page_size=1", 2, []string{frankfurt[1].ID}},
+		{"no validators there", "?location=ap-south", 0, []string{}},
+		{"no filter", "", 4, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got listing
+			status, resp := get(t, h.ListValidators, "/validators", "/validators"+tt.query, &got)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			if got.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", got.Total, tt.wantTotal)
+			}
+			if tt.wantIDs == nil {
+				return
+			}
+			ids := make(map[string]bool, len(got.Validators))
+			for _, v := range got.Validators {
+				ids[v.ID] = true
+			}
+			for _, id := range tt.wantIDs {
+				if !ids[id] {
+					t.Errorf("validator %s missing from %+v", id, got.Validators)
+				}
+			}
+			if len(got.Validators) != len(tt.wantIDs) {
+				t.Errorf("listed %d validators, want %d", len(got.Validators), len(tt.wantIDs))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInviteRequest optionally ties the invite to one email address
+type CreateInviteRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// newInviteCode returns a random 128-bit hex invite code
+func newInviteCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateInvite - POST /api/v1/invites
+func (h *Handler) CreateInvite(c *gin.Context) {
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to generate invite code")
+		return
+	}
+
+	invite := models.Invite{Code: code}
+	if email := strings.TrimSpace(req.Email); email != "" {
+		invite.Email = &email
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	if err := db.Create(&invite).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create invite")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, invite)
+}
+
+// ListInvites - GET /api/v1/invites?status=&page=&page_size=
+// status is unused or used; newest first.
+func (h *Handler) ListInvites(c *gin.Context) {
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	query := db.Model(&models.Invite{})
+	switch c.Query("status") {
+	case "":
+	case "unused":
+		query = query.Where("used_at IS NULL")
+	case "used":
+		query = query.Where("used_at IS NOT NULL")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "status must be unused or used")
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count invites")
+		return
+	}
+
+	var invites []models.Invite
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).
+		Limit(page.PageSize).
+		Find(&invites).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch invites")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"invites":   invites,
+		"total":     total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
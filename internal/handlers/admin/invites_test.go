@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// createInvite posts body to CreateInvite and decodes the created invite
+func createInvite(t *testing.T, h *Handler, body string) (int, models.Invite) {
+	t.Helper()
+
+	router := gin.New()
+	router.POST("/invites", h.CreateInvite)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/invites", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Data models.Invite `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp.Data
+}
+
+func TestCreateInvite(t *testing.T) {
+	h := newTestHandler(t)
+
+	status, open := createInvite(t, h, `{}`)
+	if status != http.StatusCreated || len(open.Code) != 32 || open.Email != nil {
+		t.Fatalf("status = %d, invite = %+v, want 201 with a code for anyone", status, open)
+	}
+	status, scoped := createInvite(t, h, `{"email": "ops@example.com"}`)
+	if status != http.StatusCreated || scoped.Email == nil || *scoped.Email != "ops@example.com" || scoped.Code == open.Code {
+		t.Fatalf("status = %d, invite = %+v, want 201 with a new code for ops@example.com", status, scoped)
+	}
+	if status, _ := createInvite(t, h, `{"email": "not-an-email"}`); status != http.StatusBadRequest {
+		t.Errorf("invalid email: status = %d, want 400", status)
+	}
+
+	var stored int64
+	if err := h.db.Model(&models.Invite{}).Count(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored != 2 {
+		t.Errorf("%d invites stored, want 2", stored)
+	}
+}
+
+func TestListInvitesByStatus(t *testing.T) {
+	h := newTestHandler(t)
+	_, unused := createInvite(t, h, `{}`)
+	_, used := createInvite(t, h, `{}`)
+	usedAt := time.Now()
+	if err := h.db.Model(&used).Update("used_at", usedAt).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{"", []string{used.Code, unused.Code}},
+		{"unused", []string{unused.Code}},
+		{"used", []string{used.Code}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			var data struct {
+				Invites []models.Invite `json:"invites"`
+				Total   int64           `json:"total"`
+			}
+			if status, resp := get(t, h.ListInvites, "/invites", "/invites?status="+tt.status, &data); status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			if data.Total != int64(len(tt.want)) || len(data.Invites) != len(tt.want) {
+				t.Fatalf("listed %d of %d invites, want %d", len(data.Invites), data.Total, len(tt.want))
+			}
+			for _, invite := range data.Invites {
+				if (invite.UsedAt != nil) != (invite.Code == used.Code) {
+					t.Errorf("invite %s listed with used_at %v", invite.Code, invite.UsedAt)
+				}
+			}
+		})
+	}
+
+	if status, _ := get(t, h.ListInvites, "/invites", "/invites?status=expired", nil); status != http.StatusBadRequest {
+		t.Errorf("unknown status: status = %d, want 400", status)
+	}
+}
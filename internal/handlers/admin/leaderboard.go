@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardEntry ranks one validator by the latency of its recent checks
+type LeaderboardEntry struct {
+	ValidatorID   string   `json:"validator_id"`
+	Name          string   `json:"name"`
+	Location      string   `json:"location"`
+	Checks        int64    `json:"checks"`
+	SuccessRate   float64  `json:"success_rate"`   // percentage of checks that weren't Bad
+	MedianLatency *float64 `json:"median_latency"` // over successful checks; null without any
+	P95Latency    *float64 `json:"p95_latency"`
+}
+
+// GetValidatorLeaderboard - GET /api/v1/validators/leaderboard?window=24h&page=&page_size=
+// Ranks validators by median latency (then success rate) over the window.
+func (h *Handler) GetValidatorLeaderboard(c *gin.Context) {
+	window := 24 * time.Hour
+	if raw := c.Query("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "Invalid window")
+			return
+		}
+		window = parsed
+	}
+
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	since := time.Now().Add(-window)
+
+	var total int64
+	if err := db.Raw(`
+		SELECT COUNT(DISTINCT t.validator_id)
+		FROM "WebsiteTick" t
+		JOIN "Validator" v ON v.id = t.validator_id AND v.deleted_at IS NULL
+		WHERE t.created_at >= ?`,
+		since,
+	).Scan(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count validators")
+		return
+	}
+
+	// Failed checks often end at the timeout, so latency only counts successful ones;
+	// validators without any sort last
+	entries := []LeaderboardEntry{}
+	if err := db.Raw(`
+		SELECT
+			t.validator_id,
+			v.name,
+			v.location,
+			COUNT(*) AS checks,
+			AVG(CASE WHEN t.status <> ? THEN 100.0 ELSE 0 END) AS success_rate,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY t.latency::float8) FILTER (WHERE t.status <> ?) AS median_latency,
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY t.latency::float8) FILTER (WHERE t.status <> ?) AS p95_latency
+		FROM "WebsiteTick" t
+		JOIN "Validator" v ON v.id = t.validator_id AND v.deleted_at IS NULL
+		WHERE t.created_at >= ?
+		GROUP BY t.validator_id, v.name, v.location
+		ORDER BY median_latency ASC NULLS LAST, success_rate DESC, t.validator_id
+		LIMIT ? OFFSET ?`,
+		models.StatusBad, models.StatusBad, models.StatusBad, since, page.PageSize, page.Offset(),
+	).Scan(&entries).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute leaderboard")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"window":     window.String(),
+		"validators": entries,
+		"total":      total,
+		"page":       page.Page,
+		"page_size":  page.PageSize,
+	})
+}
@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type leaderboardPage struct {
+	Window     string             `json:"window"`
+	Validators []LeaderboardEntry `json:"validators"`
+	Total      int64              `json:"total"`
+}
+
+func createWebsite(t *testing.T, db *gorm.DB) models.Website {
+	t.Helper()
+
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	website := models.Website{ID: uuid.New().String(), UserID: user.ID, URL: "https://example.com"}
+	if err := db.Create(&website).Error; err != nil {
+		t.Fatalf("creating website: %v", err)
+	}
+	return website
+}
+
+// createTicks stores one tick by validatorID per latency, all with status
+func createTicks(t *testing.T, db *gorm.DB, websiteID, validatorID, status string, at time.Time, latencies ...float64) {
+	t.Helper()
+
+	for _, latency := range latencies {
+		tick := models.WebsiteTick{
+			ID:          uuid.New().String(),
+			WebsiteID:   websiteID,
+			ValidatorID: validatorID,
+			Status:      status,
+			Latency:     latency,
+			CreatedAt:   at,
+		}
+		if err := db.Create(&tick).Error; err != nil {
+			t.Fatalf("creating tick: %v", err)
+		}
+	}
+}
+
+func TestValidatorLeaderboardOrdering(t *testing.T) {
+	dbtest.RequirePostgres(t) // percentiles use percentile_cont
+
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db)
+	now := time.Now()
+
+	fast, slow, flaky, down := createValidator(t, h.db), createValidator(t, h.db), createValidator(t, h.db), createValidator(t, h.db)
+	createTicks(t, h.db, website.ID, fast.ID, models.StatusGood, now, 10, 20, 30, 40)
+	createTicks(t, h.db, website.ID, slow.ID, models.StatusGood, now, 100, 200, 300)
+	// Same median as slow, but half its checks failed
+	createTicks(t, h.db, website.ID, flaky.ID, models.StatusGood, now, 100, 200, 300)
+	createTicks(t, h.db, website.ID, flaky.ID, models.StatusBad, now, 5, 5, 5)
+	createTicks(t, h.db, website.ID, down.ID, models.StatusBad, now, 1)
+
+	// Outside the window, and from a deleted validator
+	createTicks(t, h.db, website.ID, slow.ID, models.StatusGood, now.Add(-48*time.Hour), 1, 1, 1, 1, 1)
+	deleted := createValidator(t, h.db)
+	createTicks(t, h.db, website.ID, deleted.ID, models.StatusGood, now, 1)
+	if err := h.db.Delete(&deleted).Error; err != nil {
+		t.Fatalf("deleting validator: %v", err)
+	}
+
+	var page leaderboardPage
+	code, resp := get(t, h.GetValidatorLeaderboard, "/leaderboard", "/leaderboard?window=24h", &page)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %+v", code, resp)
+	}
+	if page.Total != 4 {
+		t.Errorf("expected 4 ranked validators, got %d", page.Total)
+	}
+
+	want := []struct {
+		id          string
+		checks      int64
+		successRate float64
+		median      *float64
+		p95         *float64
+	}{
+		{fast.ID, 4, 100, ptr(25), ptr(38.5)},
+		{slow.ID, 3, 100, ptr(200), ptr(290)},
+		{flaky.ID, 6, 50, ptr(200), ptr(290)},
+		{down.ID, 1, 0, nil, nil},
+	}
+	if len(page.Validators) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), page.Validators)
+	}
+	for i, w := range want {
+		got := page.Validators[i]
+		if got.ValidatorID != w.id {
+			t.Errorf("rank %d: expected validator %s, got %s", i+1, w.id, got.ValidatorID)
+			continue
+		}
+		if got.Checks != w.checks || got.SuccessRate != w.successRate {
+			t.Errorf("rank %d: expected %d checks at %.0f%%, got %d at %.2f%%", i+1, w.checks, w.successRate, got.Checks, got.SuccessRate)
+		}
+		checkLatency(t, "median", got.MedianLatency, w.median)
+		checkLatency(t, "p95", got.P95Latency, w.p95)
+	}
+}
+
+func TestValidatorLeaderboardPagination(t *testing.T) {
+	dbtest.RequirePostgres(t)
+
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db)
+
+	var ranked []string
+	for _, latency := range []float64{10, 20, 30, 40, 50} {
+		validator := createValidator(t, h.db)
+		createTicks(t, h.db, website.ID, validator.ID, models.StatusGood, time.Now(), latency)
+		ranked = append(ranked, validator.ID)
+	}
+
+	var page leaderboardPage
+	code, resp := get(t, h.GetValidatorLeaderboard, "/leaderboard", "/leaderboard?page=2&page_size=2", &page)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %+v", code, resp)
+	}
+	if page.Total != 5 {
+		t.Errorf("expected total 5, got %d", page.Total)
+	}
+	if len(page.Validators) != 2 || page.Validators[0].ValidatorID != ranked[2] || page.Validators[1].ValidatorID != ranked[3] {
+		t.Errorf("expected ranks 3 and 4, got %+v", page.Validators)
+	}
+}
+
+func TestValidatorLeaderboardInvalidQuery(t *testing.T) {
+	h := newTestHandler(t)
+
+	for _, target := range []string{
+		"/leaderboard?window=soon",
+		"/leaderboard?window=-1h",
+		"/leaderboard?window=0s",
+		"/leaderboard?page=0",
+		"/leaderboard?page_size=100000",
+	} {
+		code, resp := get(t, h.GetValidatorLeaderboard, "/leaderboard", target, nil)
+		if code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %+v", target, code, resp)
+		}
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// checkLatency allows for rounding in the percentiles Postgres interpolates
+func checkLatency(t *testing.T, what string, got, want *float64) {
+	t.Helper()
+
+	switch {
+	case want == nil && got != nil:
+		t.Errorf("expected no %s latency, got %v", what, *got)
+	case want != nil && got == nil:
+		t.Errorf("expected %s latency %v, got none", what, *want)
+	case want != nil && math.Abs(*got-*want) > 1e-9:
+		t.Errorf("expected %s latency %v, got %v", what, *want, *got)
+	}
+}
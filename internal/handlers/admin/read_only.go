@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyRequest switches maintenance mode on or off
+type ReadOnlyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// GetReadOnly - GET /api/v1/read-only
+func (h *Handler) GetReadOnly(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"read_only": h.readOnly.Enabled()})
+}
+
+// PutReadOnly - PUT /api/v1/read-only
+// Takes effect immediately for this API process only; READ_ONLY sets the state
+// it starts in.
+func (h *Handler) PutReadOnly(c *gin.Context) {
+	var req ReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	h.readOnly.Set(*req.Enabled)
+	if *req.Enabled {
+		log.Println("⚠️  Read-only mode enabled by admin")
+	} else {
+		log.Println("✅ Read-only mode disabled by admin")
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"read_only": h.readOnly.Enabled()})
+}
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// putReadOnly sends body to PutReadOnly and returns the status code
+func putReadOnly(h *Handler, body string) int {
+	router := gin.New()
+	router.PUT("/read-only", h.PutReadOnly)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/read-only", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestPutReadOnly(t *testing.T) {
+	h := newTestHandler(t)
+	readOnly := func() bool {
+		var got struct {
+			ReadOnly bool `json:"read_only"`
+		}
+		if status, resp := get(t, h.GetReadOnly, "/read-only", "/read-only", &got); status != http.StatusOK {
+			t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+		}
+		return got.ReadOnly
+	}
+
+	if readOnly() {
+		t.Fatal("read-only before it was enabled")
+	}
+	if status := putReadOnly(h, `{"enabled": true}`); status != http.StatusOK || !readOnly() || !h.readOnly.Enabled() {
+		t.Fatalf("enabling: status %d, read-only %v, want 200 and enabled", status, h.readOnly.Enabled())
+	}
+
+	// A body without the flag changes nothing
+	if status := putReadOnly(h, `{}`); status != http.StatusBadRequest || !readOnly() {
+		t.Errorf("empty body: status %d, read-only %v, want 400 and still enabled", status, h.readOnly.Enabled())
+	}
+
+	if status := putReadOnly(h, `{"enabled": false}`); status != http.StatusOK || readOnly() {
+		t.Errorf("disabling: status %d, read-only %v, want 200 and disabled", status, h.readOnly.Enabled())
+	}
+}
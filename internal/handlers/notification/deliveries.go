@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DeliveryResponse is one attempt from a webhook channel's delivery log
+type DeliveryResponse struct {
+	ID             string    `json:"id"`
+	WebsiteID      string    `json:"website_id"`
+	URL            string    `json:"url"`
+	Event          string    `json:"event"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code"` // 0 when no response arrived
+	ResponseTimeMs int64     `json:"response_time_ms"`
+	Success        bool      `json:"success"`
+	Status         string    `json:"status"` // delivered, retrying or failed
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ListDeliveries - GET /api/v1/notification-channels/:id/deliveries?status=&page=&page_size=
+// Lists a webhook channel's delivery attempts, most recent first.
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var channel models.NotificationChannel
+	if err := db.Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		Limit(1).
+		Find(&channel).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+	if channel.ID == "" {
+		utils.ErrorResponse(c, http.StatusNotFound, utils.CodeChannelNotFound, "Notification channel not found")
+		return
+	}
+
+	query := db.Model(&models.WebhookDelivery{}).Where("channel_id = ?", channel.ID)
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.DeliveryDelivered, models.DeliveryRetrying, models.DeliveryFailed:
+		query = query.Where("status = ?", status)
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "status must be delivered, retrying or failed")
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count deliveries")
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).
+		Limit(page.PageSize).
+		Find(&deliveries).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch deliveries")
+		return
+	}
+
+	response := make([]DeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		response[i] = DeliveryResponse{
+			ID:             delivery.ID,
+			WebsiteID:      delivery.WebsiteID,
+			URL:            delivery.URL,
+			Event:          delivery.Event,
+			Attempt:        delivery.Attempt,
+			StatusCode:     delivery.StatusCode,
+			ResponseTimeMs: delivery.ResponseTimeMs,
+			Success:        delivery.Success,
+			Status:         delivery.Status,
+			Error:          delivery.Error,
+			CreatedAt:      delivery.CreatedAt,
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"deliveries": response,
+		"total":      total,
+		"page":       page.Page,
+		"page_size":  page.PageSize,
+	})
+}
@@ -0,0 +1,169 @@
+package notification
+
+import (
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewHandler(db *gorm.DB, cfg *config.Config) *Handler {
+	return &Handler{db: db, cfg: cfg}
+}
+
+// DTO for creating a notification channel
+type CreateChannelRequest struct {
+	Type      string  `json:"type" binding:"required,oneof=webhook email slack discord pagerduty"`
+	Target    string  `json:"target" binding:"required,max=500"`
+	WebsiteID *string `json:"website_id" binding:"omitempty,uuid"`
+}
+
+// ChannelResponse is a configured notification channel
+type ChannelResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Target    string    `json:"target"`
+	WebsiteID *string   `json:"website_id"` // null when the channel covers every website
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toChannelResponse(channel models.NotificationChannel) ChannelResponse {
+	return ChannelResponse{
+		ID:        channel.ID,
+		Type:      channel.Type,
+		Target:    channel.Target,
+		WebsiteID: channel.WebsiteID,
+		Enabled:   channel.Enabled,
+		CreatedAt: channel.CreatedAt,
+	}
+}
+
+// pagerDutyKey matches a PagerDuty Events API v2 integration (routing) key
+var pagerDutyKey = regexp.MustCompile(`^[A-Za-z0-9]{32}$`)
+
+// validTarget checks that target suits the channel type: an address for email,
+// an integration key for PagerDuty, an http(s) URL otherwise
+func validTarget(channelType, target string) bool {
+	switch channelType {
+	case models.ChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		return err == nil && addr.Address == target
+	case models.ChannelPagerDuty:
+		return pagerDutyKey.MatchString(target)
+	}
+	u, err := url.Parse(target)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// CreateChannel - POST /api/v1/notification-channels
+// Channels without website_id receive notifications for all of the user's websites.
+func (h *Handler) CreateChannel(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req CreateChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if !validTarget(req.Type, req.Target) {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeValidationFailed, "target must be an email address for email channels, a 32-character integration key for pagerduty and an http(s) URL otherwise")
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	if req.WebsiteID != nil {
+		var count int64
+		if err := db.Model(&models.Website{}).
+			Where("id = ? AND user_id = ?", *req.WebsiteID, userID).
+			Count(&count).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+			return
+		}
+		if count == 0 {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeWebsiteNotFound, "Website not found")
+			return
+		}
+	}
+
+	channel := models.NotificationChannel{
+		ID:        uuid.New().String(),
+		UserID:    userID.(string),
+		WebsiteID: req.WebsiteID,
+		Type:      req.Type,
+		Target:    req.Target,
+		Enabled:   true,
+	}
+
+	if err := db.Create(&channel).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create notification channel")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, toChannelResponse(channel))
+}
+
+// ListChannels - GET /api/v1/notification-channels
+func (h *Handler) ListChannels(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var channels []models.NotificationChannel
+	if err := db.Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&channels).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch notification channels")
+		return
+	}
+
+	response := make([]ChannelResponse, len(channels))
+	for i, channel := range channels {
+		response[i] = toChannelResponse(channel)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"channels": response,
+	})
+}
+
+// DeleteChannel - DELETE /api/v1/notification-channels/:id
+func (h *Handler) DeleteChannel(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	result := db.Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		Delete(&models.NotificationChannel{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to delete notification channel")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, utils.CodeChannelNotFound, "Notification channel not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"message": "Notification channel deleted",
+	})
+}
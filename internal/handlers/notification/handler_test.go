@@ -0,0 +1,30 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+func TestValidTarget(t *testing.T) {
+	tests := []struct {
+		channelType string
+		target      string
+		want        bool
+	}{
+		{models.ChannelPagerDuty, "0123456789abcdef0123456789ABCDEF", true},
+		{models.ChannelPagerDuty, "0123456789abcdef", false},
+		{models.ChannelPagerDuty, "0123456789abcdef0123456789abcdef0", false},
+		{models.ChannelPagerDuty, "https://events.pagerduty.com/v2/enqueue", false},
+		{models.ChannelEmail, "ops@example.com", true},
+		{models.ChannelEmail, "Ops <ops@example.com>", false},
+		{models.ChannelSlack, "https://hooks.slack.com/services/T0/B0/x", true},
+		{models.ChannelWebhook, "ftp://example.com/hook", false},
+		{models.ChannelDiscord, "https://", false},
+	}
+	for _, tt := range tests {
+		if got := validTarget(tt.channelType, tt.target); got != tt.want {
+			t.Errorf("validTarget(%s, %q) = %v, want %v", tt.channelType, tt.target, got, tt.want)
+		}
+	}
+}
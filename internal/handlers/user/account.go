@@ -0,0 +1,94 @@
+package user
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DeleteAccountRequest confirms an account deletion with the account's password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// DeleteAccount - DELETE /api/v1/me
+// Permanently deletes the caller's account with its websites, their history,
+// notification channels and status page. Tokens issued to the account stop
+// working since the user no longer exists (middleware.ActiveUserMiddleware).
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var user models.User
+	if err := db.Where("id = ?", userID).First(&user).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUserNotFound, "User not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeInvalidCredentials, "Invalid credentials")
+		return
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return deleteUserData(tx, user)
+	}); err != nil {
+		log.Printf("❌ Failed to delete account %s: %v", user.ID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to delete account")
+		return
+	}
+
+	log.Printf("✅ Account deleted: %s", user.ID)
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"message": "Account deleted successfully",
+	})
+}
+
+// deleteUserData removes user and everything it owns. Ticks, incidents, check
+// triggers and status page entries go with their website (ON DELETE CASCADE);
+// the rest has no foreign key and is deleted explicitly. Validators keep the
+// earnings they made checking the websites.
+func deleteUserData(tx *gorm.DB, user models.User) error {
+	var websiteIDs []string
+	if err := tx.Unscoped().Model(&models.Website{}).Where("user_id = ?", user.ID).Pluck("id", &websiteIDs).Error; err != nil {
+		return err
+	}
+	if len(websiteIDs) > 0 {
+		if err := tx.Where("website_id IN ?", websiteIDs).Delete(&models.WebsiteTickRollup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("website_id IN ?", websiteIDs).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		// Soft-deleted websites are purged too
+		if err := tx.Unscoped().Where("id IN ?", websiteIDs).Delete(&models.Website{}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.NotificationChannel{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.StatusPage{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&user).Error
+}
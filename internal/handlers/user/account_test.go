@@ -0,0 +1,150 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// accountData is everything stored for one user's account
+type accountData struct {
+	user     models.User
+	websites []string
+	ticks    []string
+}
+
+// createAccount stores a user with password, two websites (one soft-deleted)
+// with their history, a notification channel and a status page
+func createAccount(t *testing.T, db *gorm.DB, password string) accountData {
+	t.Helper()
+
+	account := accountData{user: createUser(t, db, password)}
+	validator := createValidator(t, db, models.Validator{})
+	for i := 0; i < 2; i++ {
+		website := models.Website{ID: uuid.New().String(), UserID: account.user.ID, URL: "https://example.com"}
+		tick := models.WebsiteTick{ID: uuid.New().String(), WebsiteID: website.ID, ValidatorID: validator.ID, Status: models.StatusGood}
+		rows := []interface{}{
+			&website,
+			&tick,
+			&models.Incident{ID: uuid.New().String(), WebsiteID: website.ID, StartedAt: time.Now()},
+			&models.CheckTrigger{ID: uuid.New().String(), WebsiteID: website.ID, RequestedAt: time.Now()},
+			&models.WebsiteTickRollup{WebsiteID: website.ID, Bucket: time.Now().Truncate(time.Hour), Good: 1},
+		}
+		for _, row := range rows {
+			if err := db.Create(row).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		account.websites = append(account.websites, website.ID)
+		account.ticks = append(account.ticks, tick.ID)
+	}
+	if err := db.Delete(&models.Website{ID: account.websites[1]}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	page := models.StatusPage{ID: uuid.New().String(), UserID: account.user.ID, Slug: uuid.New().String(), Title: "Status",
+		Websites: []models.StatusPageWebsite{{WebsiteID: account.websites[0], Name: "Site"}}}
+	channel := models.NotificationChannel{ID: uuid.New().String(), UserID: account.user.ID, Type: "webhook", Target: "https://hooks.example.com"}
+	for _, row := range []interface{}{&page, &channel} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return account
+}
+
+// remaining counts the account's rows left in each table
+func (a accountData) remaining(t *testing.T, db *gorm.DB) map[string]int64 {
+	t.Helper()
+
+	counts := map[string]int64{}
+	count := func(name string, query *gorm.DB) {
+		var n int64
+		if err := query.Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		counts[name] = n
+	}
+	count("users", db.Unscoped().Model(&models.User{}).Where("id = ?", a.user.ID))
+	count("websites", db.Unscoped().Model(&models.Website{}).Where("id IN ?", a.websites))
+	count("ticks", db.Model(&models.WebsiteTick{}).Where("id IN ?", a.ticks))
+	count("incidents", db.Model(&models.Incident{}).Where("website_id IN ?", a.websites))
+	count("check triggers", db.Model(&models.CheckTrigger{}).Where("website_id IN ?", a.websites))
+	count("rollups", db.Model(&models.WebsiteTickRollup{}).Where("website_id IN ?", a.websites))
+	count("status pages", db.Model(&models.StatusPage{}).Where("user_id = ?", a.user.ID))
+	count("status page websites", db.Model(&models.StatusPageWebsite{}).Where("website_id IN ?", a.websites))
+	count("notification channels", db.Model(&models.NotificationChannel{}).Where("user_id = ?", a.user.ID))
+	return counts
+}
+
+func deleteAccount(t *testing.T, h *Handler, userID, password string) (int, envelope) {
+	t.Helper()
+	return serve(t, h.DeleteAccount, http.MethodDelete, "/me", "/me", userID, DeleteAccountRequest{Password: password})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	h := newTestHandler(t)
+	account := createAccount(t, h.db, "correct horse")
+	other := createAccount(t, h.db, "battery staple")
+	before := other.remaining(t, h.db)
+	for table, n := range account.remaining(t, h.db) {
+		if n == 0 {
+			t.Fatalf("account created without %s", table)
+		}
+	}
+
+	status, resp := deleteAccount(t, h, account.user.ID, "correct horse")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	for table, n := range account.remaining(t, h.db) {
+		if n != 0 {
+			t.Errorf("%d %s left after deleting the account", n, table)
+		}
+	}
+
+	// Other accounts are untouched
+	after := other.remaining(t, h.db)
+	for table, n := range before {
+		if after[table] != n {
+			t.Errorf("other account has %d %s, had %d", after[table], table, n)
+		}
+	}
+}
+
+func TestDeleteAccountRejected(t *testing.T) {
+	h := newTestHandler(t)
+	account := createAccount(t, h.db, "correct horse")
+	before := account.remaining(t, h.db)
+
+	tests := []struct {
+		name       string
+		userID     string
+		body       interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{"wrong password", account.user.ID, DeleteAccountRequest{Password: "guess"}, http.StatusUnauthorized, utils.CodeInvalidCredentials},
+		{"no password", account.user.ID, map[string]string{}, http.StatusBadRequest, utils.CodeValidationFailed},
+		{"unknown user", uuid.New().String(), DeleteAccountRequest{Password: "correct horse"}, http.StatusUnauthorized, utils.CodeUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.DeleteAccount, http.MethodDelete, "/me", "/me", tt.userID, tt.body)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("status = %d, code = %s, want %d %s", status, resp.Code, tt.wantStatus, tt.wantCode)
+			}
+			after := account.remaining(t, h.db)
+			for table, n := range before {
+				if after[table] != n {
+					t.Errorf("rejected deletion left %d %s, had %d", after[table], table, n)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
 	"github.com/datmedevil17/gopher-uptime/internal/models"
 	"github.com/datmedevil17/gopher-uptime/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -16,17 +23,26 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// PayoutQueue is where payout requests are published; *amqp.Channel implements it
+type PayoutQueue interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
 type Handler struct {
 	db       *gorm.DB
-	rabbitMQ *amqp.Channel
+	rabbitMQ PayoutQueue
 	cfg      *config.Config
+	jwt      *utils.JWTConfig
+	token    utils.Token // balances are stored in its base units
 }
 
-func NewHandler(db *gorm.DB, rabbitMQ *amqp.Channel, cfg *config.Config) *Handler {
+func NewHandler(db *gorm.DB, rabbitMQ PayoutQueue, cfg *config.Config, jwtCfg *utils.JWTConfig) *Handler {
 	return &Handler{
 		db:       db,
 		rabbitMQ: rabbitMQ,
 		cfg:      cfg,
+		jwt:      jwtCfg,
+		token:    utils.Token{Symbol: cfg.PayoutTokenSymbol, Decimals: cfg.PayoutTokenDecimals},
 	}
 }
 
@@ -38,10 +54,20 @@ type PayoutRequest struct {
 
 // RequestPayout - POST /api/v1/payout/:validatorId
 func (h *Handler) RequestPayout(c *gin.Context) {
+	// Without a worker the payout would sit in the queue after the balance was
+	// already deducted
+	if !h.cfg.PayoutsEnabled() {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, utils.CodePayoutsDisabled, "Payouts are disabled on this server")
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
 	validatorID := c.Param("validatorId")
 
 	// Start transaction with GORM
-	tx := h.db.Begin()
+	tx := db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -57,14 +83,28 @@ func (h *Handler) RequestPayout(c *gin.Context) {
 	if result.Error != nil {
 		tx.Rollback()
 		if result.Error == gorm.ErrRecordNotFound {
-			utils.ErrorResponse(c, http.StatusNotFound, "Validator not found")
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeValidatorNotFound, "Validator not found")
 		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Database error")
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
 		}
 		return
 	}
 
-	// Check pending balance
+	if validator.Banned {
+		tx.Rollback()
+		utils.ErrorResponse(c, http.StatusForbidden, utils.CodeValidatorBanned, "Validator is banned")
+		return
+	}
+
+	// Refused before the balance check so a drained validator learns when it can retry
+	if remaining := payoutCooldownRemaining(validator, h.cfg.PayoutCooldown, time.Now()); remaining > 0 {
+		tx.Rollback()
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		utils.ErrorResponse(c, http.StatusTooManyRequests, utils.CodePayoutCooldown,
+			fmt.Sprintf("Payout cooldown active, next payout allowed in %s", remaining.Round(time.Second)))
+		return
+	}
+
 	// Check pending balance
 	if validator.PendingPayouts <= 0 {
 		tx.Rollback()
@@ -86,11 +126,30 @@ func (h *Handler) RequestPayout(c *gin.Context) {
 	payoutJSON, err := json.Marshal(payoutReq)
 	if err != nil {
 		tx.Rollback()
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to serialize request")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to serialize request")
+		return
+	}
+
+	// Deduct the queued amount while the row is still locked. The balance guard makes
+	// this a no-op if it changed since it was read, so we never queue a stale amount.
+	result = tx.Model(&models.Validator{}).
+		Where("id = ? AND pending_payouts = ?", validator.ID, payoutReq.Amount).
+		UpdateColumns(map[string]interface{}{
+			"pending_payouts": gorm.Expr("pending_payouts - ?", payoutReq.Amount),
+			"last_payout_at":  time.Now(),
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to update balance")
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		utils.ErrorResponse(c, http.StatusConflict, utils.CodeBalanceChanged, "Balance changed, please retry")
 		return
 	}
 
-	// Publish to RabbitMQ
+	// Publish to RabbitMQ last so a failed balance update never queues a payout
 	err = h.rabbitMQ.Publish(
 		"",             // exchange
 		"payout_queue", // routing key
@@ -105,21 +164,13 @@ func (h *Handler) RequestPayout(c *gin.Context) {
 
 	if err != nil {
 		tx.Rollback()
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to queue payout")
-		return
-	}
-
-	// Reset pending payouts using GORM
-	result = tx.Model(&validator).Update("pending_payouts", 0)
-	if result.Error != nil {
-		tx.Rollback()
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update balance")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodePayoutFailed, "Failed to queue payout")
 		return
 	}
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to commit transaction")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to commit transaction")
 		return
 	}
 
@@ -130,33 +181,89 @@ func (h *Handler) RequestPayout(c *gin.Context) {
 	})
 }
 
-// GetValidatorBalance - GET /api/v1/validator/:validatorId/balance
-func (h *Handler) GetValidatorBalance(c *gin.Context) {
-	validatorID := c.Param("validatorId")
+// payoutCooldownRemaining is how long validator must still wait before its next
+// payout; 0 when it may request one now
+func payoutCooldownRemaining(validator models.Validator, cooldown time.Duration, now time.Time) time.Duration {
+	if cooldown <= 0 || validator.LastPayoutAt == nil {
+		return 0
+	}
+	return max(validator.LastPayoutAt.Add(cooldown).Sub(now), 0)
+}
+
+// ValidatorBalance is what a validator is owed
+type ValidatorBalance struct {
+	ValidatorID       string    `json:"validator_id"`
+	PublicKey         string    `json:"public_key"`
+	Currency          string    `json:"currency"`
+	PendingPayouts    float64   `json:"pending_payouts"`     // base units
+	PendingPayoutsSOL float64   `json:"pending_payouts_sol"` // whole tokens
+	ValidatorSince    time.Time `json:"validator_since"`
+	TenureDays        int64     `json:"tenure_days"`
+}
+
+// ValidatorBalance looks up a validator's balance, or returns gorm.ErrRecordNotFound
+func (h *Handler) ValidatorBalance(ctx context.Context, validatorID string) (ValidatorBalance, error) {
+	db, cancel := database.WithTimeout(ctx, h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
 
 	var validator models.Validator
-	result := h.db.Where("id = ?", validatorID).First(&validator)
+	if err := db.Where("id = ?", validatorID).First(&validator).Error; err != nil {
+		return ValidatorBalance{}, err
+	}
 
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			utils.ErrorResponse(c, http.StatusNotFound, "Validator not found")
+	return ValidatorBalance{
+		ValidatorID:       validator.ID,
+		PublicKey:         validator.PublicKey,
+		Currency:          h.token.Symbol,
+		PendingPayouts:    validator.PendingPayouts,
+		PendingPayoutsSOL: h.token.ToWhole(validator.PendingPayouts),
+		ValidatorSince:    validator.CreatedAt,
+		TenureDays:        tenureDays(validator.Tenure(time.Now())),
+	}, nil
+}
+
+// GetValidatorBalance - GET /api/v1/validator/:validatorId/balance
+func (h *Handler) GetValidatorBalance(c *gin.Context) {
+	balance, err := h.ValidatorBalance(c.Request.Context(), c.Param("validatorId"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeValidatorNotFound, "Validator not found")
 		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Database error")
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
 		}
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, gin.H{
-		"validator_id":        validator.ID,
-		"public_key":          validator.PublicKey,
-		"pending_payouts":     validator.PendingPayouts,
-		"pending_payouts_sol": validator.PendingPayouts / 1e9,
-	})
+	utils.SuccessResponse(c, http.StatusOK, balance)
 }
 
 type SignupRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=8"`
+	InviteCode string `json:"invite_code"` // required when INVITE_ONLY is set
+}
+
+// errInviteInvalid rejects a signup whose invite is unknown, used or meant for another email
+var errInviteInvalid = errors.New("invalid invite")
+
+// redeemInvite marks the invite code as used by user, inside the signup
+// transaction so two signups can't share one code
+func redeemInvite(tx *gorm.DB, code string, user models.User) error {
+	var invite models.Invite
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ? AND used_at IS NULL", code).
+		Take(&invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errInviteInvalid
+	} else if err != nil {
+		return err
+	}
+	if invite.Email != nil && !strings.EqualFold(*invite.Email, user.Email) {
+		return errInviteInvalid
+	}
+
+	now := time.Now()
+	return tx.Model(&invite).Updates(models.Invite{UsedBy: &user.ID, UsedAt: &now}).Error
 }
 
 type LoginRequest struct {
@@ -166,23 +273,31 @@ type LoginRequest struct {
 
 // Signup - POST /api/v1/auth/signup
 func (h *Handler) Signup(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
 	var req SignupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if h.cfg.InviteOnly && req.InviteCode == "" {
+		utils.ErrorResponse(c, http.StatusForbidden, utils.CodeInviteRequired, "Signup requires an invite code")
 		return
 	}
 
-	// Check if user exists
+	// Check if user exists (unscoped: a soft-deleted account keeps its email reserved)
 	var existingUser models.User
-	if result := h.db.Where("email = ?", req.Email).First(&existingUser); result.Error == nil {
-		utils.ErrorResponse(c, http.StatusConflict, "User already exists")
+	if result := db.Unscoped().Where("email = ?", req.Email).First(&existingUser); result.Error == nil {
+		utils.ErrorResponse(c, http.StatusConflict, utils.CodeUserExists, "User already exists")
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to hash password")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to hash password")
 		return
 	}
 
@@ -193,15 +308,28 @@ func (h *Handler) Signup(c *gin.Context) {
 		Password: string(hashedPassword),
 	}
 
-	if result := h.db.Create(&user); result.Error != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create user")
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if h.cfg.InviteOnly {
+			return redeemInvite(tx, req.InviteCode, user)
+		}
+		return nil
+	})
+	if errors.Is(err, errInviteInvalid) {
+		utils.ErrorResponse(c, http.StatusForbidden, utils.CodeInviteInvalid, "Invite code is invalid, already used or issued for another email")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create user")
 		return
 	}
 
 	// Generate JWT
-	token, err := utils.GenerateJWT(user.ID, h.cfg.JWTSecret)
+	token, err := utils.GenerateJWT(user.ID, h.jwt)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to generate token")
 		return
 	}
 
@@ -216,29 +344,32 @@ func (h *Handler) Signup(c *gin.Context) {
 
 // Login - POST /api/v1/auth/login
 func (h *Handler) Login(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
 	// Find user
 	var user models.User
-	if result := h.db.Where("email = ?", req.Email).First(&user); result.Error != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid credentials")
+	if result := db.Where("email = ?", req.Email).First(&user); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeInvalidCredentials, "Invalid credentials")
 		return
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid credentials")
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeInvalidCredentials, "Invalid credentials")
 		return
 	}
 
 	// Generate JWT
-	token, err := utils.GenerateJWT(user.ID, h.cfg.JWTSecret)
+	token, err := utils.GenerateJWT(user.ID, h.jwt)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to generate token")
 		return
 	}
 
@@ -0,0 +1,539 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestHandler returns a handler over an empty test database, without a
+// payout queue. configure adjusts the default config.
+func newTestHandler(t *testing.T, configure ...func(*config.Config)) *Handler {
+	t.Helper()
+
+	cfg := config.Load()
+	cfg.InviteOnly = false
+	for _, c := range configure {
+		c(cfg)
+	}
+	jwtCfg, err := utils.LoadJWTConfig(utils.JWTOptions{
+		Algorithm: utils.JWTAlgorithmHS256,
+		Secret:    "test-secret",
+		Issuer:    "test",
+		Audience:  "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewHandler(dbtest.Open(t), nil, cfg, jwtCfg)
+}
+
+// envelope is utils.Response with the payload left undecoded
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Code    string          `json:"code"`
+	Details json.RawMessage `json:"details"`
+}
+
+// serve routes one request for target through handler, mounted at route, as
+// userID (anonymous when empty), sending body as JSON unless it is nil
+func serve(t *testing.T, handler gin.HandlerFunc, method, route, target, userID string, body interface{}) (int, envelope) {
+	t.Helper()
+
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		handler(c)
+	})
+
+	var reader bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader.Reset(data)
+	}
+	req := httptest.NewRequest(method, target, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %s response %q: %v", target, w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// decode unmarshals the payload of a successful response into v
+func decode(t *testing.T, resp envelope, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		t.Fatalf("decoding data %s: %v", resp.Data, err)
+	}
+}
+
+// createUser stores a user who logs in with password
+func createUser(t *testing.T, db *gorm.DB, password string) models.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: string(hash)}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+func TestSignupValidationErrors(t *testing.T) {
+	h := newTestHandler(t)
+
+	tests := []struct {
+		name string
+		body gin.H
+		want []utils.FieldError
+	}{
+		{
+			"missing email",
+			gin.H{"password": "long enough"},
+			[]utils.FieldError{{Field: "email", Rule: "required", Message: "email is required"}},
+		},
+		{
+			"short password",
+			gin.H{"email": "someone@example.com", "password": "short"},
+			[]utils.FieldError{{Field: "password", Rule: "min", Message: "password must be at least 8 characters"}},
+		},
+		{
+			"both",
+			gin.H{"email": "not-an-email", "password": "short"},
+			[]utils.FieldError{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "password", Rule: "min", Message: "password must be at least 8 characters"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.Signup, http.MethodPost, "/signup", "/signup", "", tt.body)
+			if status != http.StatusBadRequest || resp.Code != utils.CodeValidationFailed {
+				t.Fatalf("status = %d, code = %s, want %d %s", status, resp.Code, http.StatusBadRequest, utils.CodeValidationFailed)
+			}
+
+			var fields []utils.FieldError
+			if err := json.Unmarshal(resp.Details, &fields); err != nil {
+				t.Fatalf("decoding details %s: %v", resp.Details, err)
+			}
+			if len(fields) != len(tt.want) {
+				t.Fatalf("details = %+v, want %+v", fields, tt.want)
+			}
+			for i := range fields {
+				if fields[i] != tt.want[i] {
+					t.Errorf("details[%d] = %+v, want %+v", i, fields[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSignupMalformedBody(t *testing.T) {
+	h := newTestHandler(t)
+
+	router := gin.New()
+	router.POST("/signup", h.Signup)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/signup", bytes.NewBufferString(`{"email":`)))
+
+	var resp envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusBadRequest || resp.Code != utils.CodeInvalidRequest || resp.Details != nil {
+		t.Errorf("status = %d, code = %s, details = %s, want %d %s without details",
+			w.Code, resp.Code, resp.Details, http.StatusBadRequest, utils.CodeInvalidRequest)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	h := newTestHandler(t)
+	existing := createUser(t, h.db, "correct horse")
+	inviteOnly := newTestHandler(t, func(cfg *config.Config) { cfg.InviteOnly = true })
+
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		body       gin.H
+		wantStatus int
+		wantCode   string
+	}{
+		{"signup with a taken email", h.Signup, gin.H{"email": existing.Email, "password": "long enough"}, http.StatusConflict, utils.CodeUserExists},
+		{"signup without an invite", inviteOnly.Signup, gin.H{"email": "new@example.com", "password": "long enough"}, http.StatusForbidden, utils.CodeInviteRequired},
+		{"login with a wrong password", h.Login, gin.H{"email": existing.Email, "password": "wrong horse"}, http.StatusUnauthorized, utils.CodeInvalidCredentials},
+		{"login as nobody", h.Login, gin.H{"email": "nobody@example.com", "password": "whatever"}, http.StatusUnauthorized, utils.CodeInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, tt.handler, http.MethodPost, "/", "/", "", tt.body)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("status = %d, code = %s, want %d %s", status, resp.Code, tt.wantStatus, tt.wantCode)
+			}
+			if resp.Success || resp.Error == "" {
+				t.Errorf("response = %+v, want a failure with a message", resp)
+			}
+		})
+	}
+}
+
+// createInvite stores an unused invite code, for email only when it is set
+func createInvite(t *testing.T, db *gorm.DB, email string) models.Invite {
+	t.Helper()
+
+	invite := models.Invite{Code: uuid.New().String()}
+	if email != "" {
+		invite.Email = &email
+	}
+	if err := db.Create(&invite).Error; err != nil {
+		t.Fatalf("creating invite: %v", err)
+	}
+	return invite
+}
+
+func TestSignupInviteOnly(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.InviteOnly = true })
+	signup := func(email, code string) (int, envelope) {
+		return serve(t, h.Signup, http.MethodPost, "/signup", "/signup", "",
+			SignupRequest{Email: email, Password: "long enough", InviteCode: code})
+	}
+
+	open := createInvite(t, h.db, "")
+	scoped := createInvite(t, h.db, "Invited@example.com")
+
+	tests := []struct {
+		name       string
+		email      string
+		code       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"valid invite", "first@example.com", open.Code, http.StatusCreated, ""},
+		{"used invite", "second@example.com", open.Code, http.StatusForbidden, utils.CodeInviteInvalid},
+		{"missing invite", "third@example.com", "", http.StatusForbidden, utils.CodeInviteRequired},
+		{"unknown invite", "third@example.com", "no-such-code", http.StatusForbidden, utils.CodeInviteInvalid},
+		{"invite for another email", "intruder@example.com", scoped.Code, http.StatusForbidden, utils.CodeInviteInvalid},
+		{"invite for this email", "invited@example.com", scoped.Code, http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := signup(tt.email, tt.code)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Fatalf("status = %d, code = %s (%s), want %d %q", status, resp.Code, resp.Error, tt.wantStatus, tt.wantCode)
+			}
+
+			// A rejected signup creates no account
+			var users int64
+			if err := h.db.Model(&models.User{}).Where("email = ?", tt.email).Count(&users).Error; err != nil {
+				t.Fatal(err)
+			}
+			if created := tt.wantStatus == http.StatusCreated; (users != 0) != created {
+				t.Errorf("%d accounts for %s after a signup answered %d", users, tt.email, status)
+			}
+		})
+	}
+
+	// Each invite was redeemed once, by the account that used it
+	for email, code := range map[string]string{"first@example.com": open.Code, "invited@example.com": scoped.Code} {
+		var user models.User
+		var invite models.Invite
+		if err := h.db.Where("email = ?", email).First(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := h.db.Where("code = ?", code).First(&invite).Error; err != nil {
+			t.Fatal(err)
+		}
+		if invite.UsedAt == nil || invite.UsedBy == nil || *invite.UsedBy != user.ID {
+			t.Errorf("invite %s used by %v at %v, want %s", code, invite.UsedBy, invite.UsedAt, user.ID)
+		}
+	}
+}
+
+func TestSignupIgnoresInvitesWhenOpen(t *testing.T) {
+	h := newTestHandler(t)
+	invite := createInvite(t, h.db, "")
+
+	status, resp := serve(t, h.Signup, http.MethodPost, "/signup", "/signup", "",
+		SignupRequest{Email: "open@example.com", Password: "long enough", InviteCode: invite.Code})
+	if status != http.StatusCreated {
+		t.Fatalf("status = %d (%s), want 201", status, resp.Error)
+	}
+	if err := h.db.Where("code = ?", invite.Code).First(&invite).Error; err != nil {
+		t.Fatal(err)
+	}
+	if invite.UsedAt != nil {
+		t.Error("open signup redeemed the invite")
+	}
+}
+
+func TestLoginSucceedsWithoutCode(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db, "correct horse")
+
+	status, resp := serve(t, h.Login, http.MethodPost, "/", "/", "", gin.H{"email": user.Email, "password": "correct horse"})
+	if status != http.StatusOK || !resp.Success || resp.Code != "" {
+		t.Errorf("status = %d, response = %+v, want a 200 success without a code", status, resp)
+	}
+}
+
+// recordingQueue is a PayoutQueue that keeps what's published to it
+type recordingQueue struct {
+	published []amqp.Publishing
+	err       error // returned by Publish instead of recording
+}
+
+func (q *recordingQueue) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.published = append(q.published, msg)
+	return nil
+}
+
+// newPayoutHandler returns a handler with a platform key configured, publishing
+// payouts to queue
+func newPayoutHandler(t *testing.T, queue PayoutQueue, configure ...func(*config.Config)) *Handler {
+	t.Helper()
+
+	h := newTestHandler(t, append([]func(*config.Config){func(cfg *config.Config) {
+		cfg.PlatformPrivateKey = "test-platform-key"
+		cfg.PayoutCooldown = 0
+	}}, configure...)...)
+	h.rabbitMQ = queue
+	return h
+}
+
+func requestPayout(t *testing.T, h *Handler, validatorID string) (int, envelope) {
+	t.Helper()
+	return serve(t, h.RequestPayout, http.MethodPost, "/payout/:validatorId", "/payout/"+validatorID, "", nil)
+}
+
+// pendingPayouts is the validator's current balance
+func pendingPayouts(t *testing.T, db *gorm.DB, validatorID string) float64 {
+	t.Helper()
+
+	var validator models.Validator
+	if err := db.Where("id = ?", validatorID).First(&validator).Error; err != nil {
+		t.Fatal(err)
+	}
+	return validator.PendingPayouts
+}
+
+func TestRequestPayoutQueuesBalance(t *testing.T) {
+	queue := &recordingQueue{}
+	h := newPayoutHandler(t, queue)
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 2_500_000_000})
+
+	status, resp := requestPayout(t, h, validator.ID)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	if len(queue.published) != 1 {
+		t.Fatalf("published %d payouts, want 1", len(queue.published))
+	}
+	var queued PayoutRequest
+	if err := json.Unmarshal(queue.published[0].Body, &queued); err != nil {
+		t.Fatal(err)
+	}
+	if queued.ValidatorID != validator.ID || queued.Amount != 2_500_000_000 || queued.PublicKey != validator.PublicKey {
+		t.Errorf("queued %+v, want the whole balance for %s", queued, validator.ID)
+	}
+	if balance := pendingPayouts(t, h.db, validator.ID); balance != 0 {
+		t.Errorf("balance = %v after queueing, want 0", balance)
+	}
+}
+
+func TestRequestPayoutPublishFailureKeepsBalance(t *testing.T) {
+	h := newPayoutHandler(t, &recordingQueue{err: errors.New("broker down")})
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 1_000})
+
+	status, resp := requestPayout(t, h, validator.ID)
+	if status != http.StatusInternalServerError || resp.Code != utils.CodePayoutFailed {
+		t.Errorf("status = %d, code = %s, want 500 %s", status, resp.Code, utils.CodePayoutFailed)
+	}
+	if balance := pendingPayouts(t, h.db, validator.ID); balance != 1_000 {
+		t.Errorf("balance = %v, want the unqueued 1000 kept", balance)
+	}
+}
+
+func TestRequestPayoutDisabled(t *testing.T) {
+	queue := &recordingQueue{}
+	h := newPayoutHandler(t, queue, func(cfg *config.Config) { cfg.PlatformPrivateKey = "" })
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 2_500_000_000})
+
+	status, resp := requestPayout(t, h, validator.ID)
+	if status != http.StatusServiceUnavailable || resp.Code != utils.CodePayoutsDisabled {
+		t.Fatalf("status = %d, code = %s, want 503 %s", status, resp.Code, utils.CodePayoutsDisabled)
+	}
+	if !strings.Contains(resp.Error, "disabled") {
+		t.Errorf("error = %q, want it to say payouts are disabled", resp.Error)
+	}
+	if len(queue.published) != 0 {
+		t.Errorf("published %d payouts with payouts disabled", len(queue.published))
+	}
+	if balance := pendingPayouts(t, h.db, validator.ID); balance != 2_500_000_000 {
+		t.Errorf("balance = %v, want it preserved", balance)
+	}
+	var validatorState models.Validator
+	if err := h.db.Where("id = ?", validator.ID).First(&validatorState).Error; err != nil {
+		t.Fatal(err)
+	}
+	if validatorState.LastPayoutAt != nil {
+		t.Error("refused payout started the cooldown")
+	}
+}
+
+func TestRequestPayoutBannedValidator(t *testing.T) {
+	queue := &recordingQueue{}
+	h := newPayoutHandler(t, queue)
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 1_000, Banned: true})
+
+	status, resp := requestPayout(t, h, validator.ID)
+	if status != http.StatusForbidden || resp.Code != utils.CodeValidatorBanned {
+		t.Fatalf("status = %d, code = %s, want 403 %s", status, resp.Code, utils.CodeValidatorBanned)
+	}
+	if len(queue.published) != 0 {
+		t.Errorf("published %d payouts for a banned validator", len(queue.published))
+	}
+	if balance := pendingPayouts(t, h.db, validator.ID); balance != 1_000 {
+		t.Errorf("balance = %v, want it left for the admin to forfeit or restore", balance)
+	}
+}
+
+func TestRequestPayoutCooldown(t *testing.T) {
+	const cooldown = time.Hour
+	tests := []struct {
+		name       string
+		lastPayout time.Duration // before now
+		wantStatus int
+	}{
+		{"just paid out", time.Minute, http.StatusTooManyRequests},
+		{"almost over", cooldown - time.Minute, http.StatusTooManyRequests},
+		{"cooldown over", cooldown + time.Minute, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &recordingQueue{}
+			h := newPayoutHandler(t, queue, func(cfg *config.Config) { cfg.PayoutCooldown = cooldown })
+			last := time.Now().Add(-tt.lastPayout)
+			validator := createValidator(t, h.db, models.Validator{PendingPayouts: 1_000, LastPayoutAt: &last})
+
+			status, resp := requestPayout(t, h, validator.ID)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", status, resp.Error, tt.wantStatus)
+			}
+
+			var stored models.Validator
+			if err := h.db.Where("id = ?", validator.ID).First(&stored).Error; err != nil {
+				t.Fatal(err)
+			}
+			if status == http.StatusOK {
+				if len(queue.published) != 1 || stored.PendingPayouts != 0 {
+					t.Errorf("published %d payouts leaving %v, want the balance queued", len(queue.published), stored.PendingPayouts)
+				}
+				if stored.LastPayoutAt == nil || !stored.LastPayoutAt.After(last) {
+					t.Errorf("last payout at %v, want the cooldown restarted", stored.LastPayoutAt)
+				}
+				return
+			}
+
+			remaining := (cooldown - tt.lastPayout).Round(time.Second)
+			if resp.Code != utils.CodePayoutCooldown || !strings.Contains(resp.Error, remaining.String()) {
+				t.Errorf("code = %s, error = %q, want %s with %s remaining", resp.Code, resp.Error, utils.CodePayoutCooldown, remaining)
+			}
+			if len(queue.published) != 0 || stored.PendingPayouts != 1_000 {
+				t.Errorf("published %d payouts leaving %v, want nothing queued during the cooldown", len(queue.published), stored.PendingPayouts)
+			}
+			if !stored.LastPayoutAt.Equal(last) {
+				t.Errorf("last payout at %v, want %v: a refused request doesn't extend the cooldown", stored.LastPayoutAt, last)
+			}
+		})
+	}
+}
+
+func TestRequestPayoutCooldownAfterPayout(t *testing.T) {
+	queue := &recordingQueue{}
+	h := newPayoutHandler(t, queue, func(cfg *config.Config) { cfg.PayoutCooldown = time.Hour })
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 1_000})
+
+	if status, resp := requestPayout(t, h, validator.ID); status != http.StatusOK {
+		t.Fatalf("first payout: status = %d (%s), want 200", status, resp.Error)
+	}
+	// Earned more straight away, but the second request is too soon
+	if err := h.db.Model(&models.Validator{}).Where("id = ?", validator.ID).Update("pending_payouts", 500).Error; err != nil {
+		t.Fatal(err)
+	}
+	if status, resp := requestPayout(t, h, validator.ID); status != http.StatusTooManyRequests || resp.Code != utils.CodePayoutCooldown {
+		t.Errorf("second payout: status = %d, code = %s, want 429 %s", status, resp.Code, utils.CodePayoutCooldown)
+	}
+	if len(queue.published) != 1 {
+		t.Errorf("published %d payouts, want only the first", len(queue.published))
+	}
+}
+
+func TestGetValidatorBalanceTokenDecimals(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbol   string
+		decimals int
+		want     float64
+	}{
+		{"SOL", "SOL", 9, 2.5},
+		{"six decimal token", "USDC", 6, 2500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, func(cfg *config.Config) {
+				cfg.PayoutTokenSymbol = tt.symbol
+				cfg.PayoutTokenDecimals = tt.decimals
+			})
+			validator := createValidator(t, h.db, models.Validator{PendingPayouts: 2_500_000_000})
+
+			status, resp := serve(t, h.GetValidatorBalance, http.MethodGet, "/validator/:validatorId/balance",
+				"/validator/"+validator.ID+"/balance", "", nil)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			var balance ValidatorBalance
+			decode(t, resp, &balance)
+			if balance.Currency != tt.symbol || balance.PendingPayouts != 2_500_000_000 || balance.PendingPayoutsSOL != tt.want {
+				t.Errorf("balance = %s %v (%v base units), want %s %v", balance.Currency, balance.PendingPayoutsSOL,
+					balance.PendingPayouts, tt.symbol, tt.want)
+			}
+		})
+	}
+}
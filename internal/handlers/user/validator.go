@@ -0,0 +1,154 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/datmedevil17/gopher-uptime/internal/signing"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RegisterValidatorRequest proves ownership of PublicKey by signing
+// protocol.RegisterMessage(PublicKey, Timestamp)
+type RegisterValidatorRequest struct {
+	PublicKey string `json:"public_key" binding:"required"`
+	KeyID     string `json:"key_id" binding:"omitempty,max=100"`
+	Timestamp int64  `json:"timestamp" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+	Name      string `json:"name" binding:"omitempty,max=255"`
+	Region    string `json:"region" binding:"omitempty,max=255"`
+}
+
+// RegisterValidator - POST /api/v1/validator/register
+// Pre-registers a validator key; the hub recognizes it on websocket signup.
+func (h *Handler) RegisterValidator(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var req RegisterValidatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	// Validators without a key ID use the default ed25519 scheme
+	keyID := req.KeyID
+	if keyID == "" {
+		derived, err := signing.Ed25519KeyID(req.PublicKey)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+			return
+		}
+		keyID = derived
+	}
+
+	signedAt := time.Unix(req.Timestamp, 0)
+	if age := time.Since(signedAt); age > h.cfg.SignatureMaxAge || age < -h.cfg.SignatureMaxAge {
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeInvalidSignature, "Signature timestamp expired")
+		return
+	}
+
+	message := protocol.RegisterMessage(req.PublicKey, req.Timestamp)
+	if err := signing.Verify(signing.AlgorithmOf(keyID), req.PublicKey, []byte(message), req.Signature); err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeInvalidSignature, "Invalid ownership proof")
+		return
+	}
+
+	var existing models.Validator
+	if result := db.Unscoped().Where("public_key = ?", req.PublicKey).First(&existing); result.Error == nil {
+		utils.ErrorResponse(c, http.StatusConflict, utils.CodeValidatorExists, "Validator already registered")
+		return
+	} else if result.Error != gorm.ErrRecordNotFound {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+
+	location := req.Region
+	if location == "" {
+		location = "unknown"
+	}
+
+	validator := models.Validator{
+		ID:        uuid.New().String(),
+		PublicKey: req.PublicKey,
+		KeyID:     keyID,
+		Name:      req.Name,
+		Location:  location,
+	}
+	if err := db.Create(&validator).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to register validator")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, gin.H{
+		"validator_id": validator.ID,
+		"public_key":   validator.PublicKey,
+		"key_id":       validator.KeyID,
+		"name":         validator.Name,
+		"region":       validator.Location,
+	})
+}
+
+// GetValidatorEarnings - GET /api/v1/validator/:validatorId/earnings
+func (h *Handler) GetValidatorEarnings(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	validatorID := c.Param("validatorId")
+
+	var validator models.Validator
+	result := db.Where("id = ?", validatorID).First(&validator)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeValidatorNotFound, "Validator not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		}
+		return
+	}
+
+	var lifetimeEarned float64
+	if err := db.Model(&models.EarningsLedger{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("validator_id = ?", validator.ID).
+		Scan(&lifetimeEarned).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+
+	var totalPaid float64
+	if err := db.Model(&models.PayoutTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("validator_id = ? AND status = ?", validator.ID, "completed").
+		Scan(&totalPaid).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+
+	tenure := validator.Tenure(time.Now())
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"validator_id":        validator.ID,
+		"validator_since":     validator.CreatedAt,
+		"tenure_days":         tenureDays(tenure),
+		"credit_multiplier":   models.LongevityMultiplier(tenure, h.cfg.LongevityBonusPerMonth, h.cfg.LongevityBonusMax),
+		"currency":            h.token.Symbol,
+		"lifetime_earned":     lifetimeEarned,
+		"lifetime_earned_sol": h.token.ToWhole(lifetimeEarned),
+		"total_paid":          totalPaid,
+		"total_paid_sol":      h.token.ToWhole(totalPaid),
+		"pending_payouts":     validator.PendingPayouts,
+		"pending_payouts_sol": h.token.ToWhole(validator.PendingPayouts),
+	})
+}
+
+// tenureDays is a tenure in whole days
+func tenureDays(tenure time.Duration) int64 {
+	return int64(tenure / (24 * time.Hour))
+}
@@ -0,0 +1,295 @@
+package user
+
+import (
+	"crypto/ed25519"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/protocol"
+	"github.com/datmedevil17/gopher-uptime/internal/signing"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gagliardetto/solana-go"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func createValidator(t *testing.T, db *gorm.DB, validator models.Validator) models.Validator {
+	t.Helper()
+
+	validator.ID = uuid.New().String()
+	if validator.PublicKey == "" {
+		validator.PublicKey = uuid.New().String()
+	}
+	if validator.Location == "" {
+		validator.Location = "unknown"
+	}
+	if err := db.Create(&validator).Error; err != nil {
+		t.Fatalf("creating validator: %v", err)
+	}
+	return validator
+}
+
+func credit(t *testing.T, db *gorm.DB, validatorID string, amount float64) {
+	t.Helper()
+
+	entry := models.EarningsLedger{
+		ID:          uuid.New().String(),
+		ValidatorID: validatorID,
+		WebsiteID:   uuid.New().String(),
+		TickID:      uuid.New().String(),
+		Amount:      amount,
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		t.Fatalf("creating ledger entry: %v", err)
+	}
+}
+
+func createPayout(t *testing.T, db *gorm.DB, validatorID, status string, amount float64) {
+	t.Helper()
+
+	payout := models.PayoutTransaction{ID: uuid.New().String(), ValidatorID: validatorID, Amount: amount, Status: status}
+	if err := db.Create(&payout).Error; err != nil {
+		t.Fatalf("creating payout: %v", err)
+	}
+}
+
+type earnings struct {
+	ValidatorID       string  `json:"validator_id"`
+	LifetimeEarned    float64 `json:"lifetime_earned"`
+	LifetimeEarnedSOL float64 `json:"lifetime_earned_sol"`
+	TotalPaid         float64 `json:"total_paid"`
+	PendingPayouts    float64 `json:"pending_payouts"`
+	TenureDays        int64   `json:"tenure_days"`
+	CreditMultiplier  float64 `json:"credit_multiplier"`
+}
+
+func getEarnings(t *testing.T, h *Handler, validatorID string) (int, envelope, earnings) {
+	t.Helper()
+
+	status, resp := serve(t, h.GetValidatorEarnings, http.MethodGet, "/validator/:validatorId/earnings",
+		"/validator/"+validatorID+"/earnings", "", nil)
+	var data earnings
+	if resp.Success {
+		decode(t, resp, &data)
+	}
+	return status, resp, data
+}
+
+func TestGetValidatorEarnings(t *testing.T) {
+	h := newTestHandler(t)
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 1_500_000_000})
+	other := createValidator(t, h.db, models.Validator{})
+
+	for _, amount := range []float64{1_000_000_000, 2_000_000_000, 2_500_000_000} {
+		credit(t, h.db, validator.ID, amount)
+	}
+	credit(t, h.db, other.ID, 9_000_000_000)
+	createPayout(t, h.db, validator.ID, "completed", 3_000_000_000)
+	createPayout(t, h.db, validator.ID, "completed", 1_000_000_000)
+	createPayout(t, h.db, validator.ID, "failed", 500_000_000)     // refunded
+	createPayout(t, h.db, validator.ID, "processing", 700_000_000) // not paid yet
+	createPayout(t, h.db, other.ID, "completed", 9_000_000_000)
+
+	status, resp, data := getEarnings(t, h, validator.ID)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	want := earnings{
+		ValidatorID:       validator.ID,
+		LifetimeEarned:    5_500_000_000,
+		LifetimeEarnedSOL: 5.5,
+		TotalPaid:         4_000_000_000,
+		PendingPayouts:    1_500_000_000,
+		CreditMultiplier:  1,
+	}
+	if data != want {
+		t.Errorf("earnings = %+v, want %+v", data, want)
+	}
+}
+
+func TestGetValidatorEarningsWithoutHistory(t *testing.T) {
+	h := newTestHandler(t)
+	validator := createValidator(t, h.db, models.Validator{})
+	if err := h.db.Model(&validator).Update("created_at", time.Now().AddDate(0, 0, -10)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, data := getEarnings(t, h, validator.ID)
+	if data.LifetimeEarned != 0 || data.TotalPaid != 0 || data.TenureDays != 10 {
+		t.Errorf("earnings = %+v, want nothing earned or paid over 10 days", data)
+	}
+}
+
+func TestGetValidatorEarningsLongevityMultiplier(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) {
+		cfg.LongevityBonusPerMonth = 0.05
+		cfg.LongevityBonusMax = 0.25
+	})
+
+	tests := []struct {
+		name string
+		age  int // days registered
+		want float64
+	}{
+		{"new", 0, 1},
+		{"two months", 65, 1.10},
+		{"capped", 400, 1.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := createValidator(t, h.db, models.Validator{})
+			if err := h.db.Model(&validator).Update("created_at", time.Now().AddDate(0, 0, -tt.age)).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, data := getEarnings(t, h, validator.ID)
+			if data.TenureDays != int64(tt.age) || math.Abs(data.CreditMultiplier-tt.want) > 1e-9 {
+				t.Errorf("tenure = %d days, multiplier = %v, want %d days and %v", data.TenureDays, data.CreditMultiplier, tt.age, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetValidatorEarningsTokenDecimals(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) {
+		cfg.PayoutTokenSymbol = "USDC"
+		cfg.PayoutTokenDecimals = 6
+	})
+	validator := createValidator(t, h.db, models.Validator{PendingPayouts: 500_000})
+	credit(t, h.db, validator.ID, 3_000_000)
+	createPayout(t, h.db, validator.ID, "completed", 1_500_000)
+
+	_, resp, data := getEarnings(t, h, validator.ID)
+	var converted struct {
+		Currency          string  `json:"currency"`
+		TotalPaidSOL      float64 `json:"total_paid_sol"`
+		PendingPayoutsSOL float64 `json:"pending_payouts_sol"`
+	}
+	decode(t, resp, &converted)
+	if converted.Currency != "USDC" || data.LifetimeEarnedSOL != 3 || converted.TotalPaidSOL != 1.5 || converted.PendingPayoutsSOL != 0.5 {
+		t.Errorf("earned %v, paid %v, pending %v %s, want 3, 1.5 and 0.5 USDC",
+			data.LifetimeEarnedSOL, converted.TotalPaidSOL, converted.PendingPayoutsSOL, converted.Currency)
+	}
+}
+
+func TestGetValidatorEarningsUnknownValidator(t *testing.T) {
+	h := newTestHandler(t)
+
+	status, resp, _ := getEarnings(t, h, uuid.New().String())
+	if status != http.StatusNotFound || resp.Code != utils.CodeValidatorNotFound {
+		t.Errorf("status = %d, code = %s, want 404 %s", status, resp.Code, utils.CodeValidatorNotFound)
+	}
+}
+
+// newSigner returns a fresh validator key
+func newSigner(t *testing.T) *signing.Ed25519Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signing.NewEd25519Signer(solana.PrivateKey(key))
+}
+
+// registration is a request registering signer's key, proven at signedAt
+func registration(signer *signing.Ed25519Signer, signedAt time.Time) RegisterValidatorRequest {
+	return RegisterValidatorRequest{
+		PublicKey: signer.PublicKey(),
+		Timestamp: signedAt.Unix(),
+		Signature: signer.Sign([]byte(protocol.RegisterMessage(signer.PublicKey(), signedAt.Unix()))),
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	h := newTestHandler(t)
+	signer := newSigner(t)
+
+	req := registration(signer, time.Now())
+	req.Name, req.Region = "probe-1", "eu-west"
+	status, resp := serve(t, h.RegisterValidator, http.MethodPost, "/validator/register", "/validator/register", "", req)
+	if status != http.StatusCreated {
+		t.Fatalf("status = %d (%s), want 201", status, resp.Error)
+	}
+	var registered struct {
+		ValidatorID string `json:"validator_id"`
+		KeyID       string `json:"key_id"`
+	}
+	decode(t, resp, &registered)
+	if registered.KeyID != signer.KeyID() {
+		t.Errorf("key id = %q, want %q derived from the public key", registered.KeyID, signer.KeyID())
+	}
+
+	var validator models.Validator
+	if err := h.db.Where("id = ?", registered.ValidatorID).First(&validator).Error; err != nil {
+		t.Fatalf("registered validator not stored: %v", err)
+	}
+	if validator.PublicKey != signer.PublicKey() || validator.Name != "probe-1" || validator.Location != "eu-west" {
+		t.Errorf("stored %+v", validator)
+	}
+
+	// The same key can't be registered twice
+	status, resp = serve(t, h.RegisterValidator, http.MethodPost, "/validator/register", "/validator/register", "", registration(signer, time.Now()))
+	if status != http.StatusConflict || resp.Code != utils.CodeValidatorExists {
+		t.Errorf("second registration: %d %s, want 409 %s", status, resp.Code, utils.CodeValidatorExists)
+	}
+}
+
+func TestRegisterValidatorInvalidProof(t *testing.T) {
+	signer, other := newSigner(t), newSigner(t)
+
+	tests := []struct {
+		name       string
+		req        func() RegisterValidatorRequest
+		wantStatus int
+		wantCode   string
+	}{
+		{"signed by another key", func() RegisterValidatorRequest {
+			req := registration(signer, time.Now())
+			req.Signature = registration(other, time.Now()).Signature
+			return req
+		}, http.StatusUnauthorized, utils.CodeInvalidSignature},
+		{"timestamp changed after signing", func() RegisterValidatorRequest {
+			req := registration(signer, time.Now())
+			req.Timestamp--
+			return req
+		}, http.StatusUnauthorized, utils.CodeInvalidSignature},
+		{"expired", func() RegisterValidatorRequest {
+			return registration(signer, time.Now().Add(-time.Hour))
+		}, http.StatusUnauthorized, utils.CodeInvalidSignature},
+		{"garbage signature", func() RegisterValidatorRequest {
+			req := registration(signer, time.Now())
+			req.Signature = "not base64!"
+			return req
+		}, http.StatusUnauthorized, utils.CodeInvalidSignature},
+		{"malformed public key", func() RegisterValidatorRequest {
+			req := registration(signer, time.Now())
+			req.PublicKey = "0OIl"
+			return req
+		}, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"missing signature", func() RegisterValidatorRequest {
+			req := registration(signer, time.Now())
+			req.Signature = ""
+			return req
+		}, http.StatusBadRequest, utils.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			status, resp := serve(t, h.RegisterValidator, http.MethodPost, "/validator/register", "/validator/register", "", tt.req())
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("got %d %s (%s), want %d %s", status, resp.Code, resp.Error, tt.wantStatus, tt.wantCode)
+			}
+
+			var n int64
+			h.db.Model(&models.Validator{}).Count(&n)
+			if n != 0 {
+				t.Errorf("%d validators registered without a valid proof", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package website
+
+import (
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxBulkDelete bounds how many websites one bulk delete may name
+const maxBulkDelete = 100
+
+// Per-website outcomes of a bulk delete
+const (
+	bulkDeleted  = "deleted"
+	bulkNotFound = "not_found"
+)
+
+type BulkDeleteRequest struct {
+	WebsiteIDs []string `json:"websiteIds" binding:"required,min=1,max=100,dive,required"` // maxBulkDelete
+}
+
+// BulkDeleteResult is the outcome for one id of a bulk delete
+type BulkDeleteResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // deleted or not_found
+}
+
+// BulkDeleteWebsites - POST /api/v1/websites/bulk-delete
+// Soft-deletes the listed websites of the caller in one transaction. Ids that
+// don't exist, are already deleted or belong to someone else are reported as
+// not_found and don't fail the rest. Repeated ids get a single result.
+func (h *Handler) BulkDeleteWebsites(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+	ids := uniqueIDs(req.WebsiteIDs)
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var owned []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Website{}).
+			Where("id IN ? AND user_id = ?", ids, userID).
+			Pluck("id", &owned).Error; err != nil {
+			return err
+		}
+		if len(owned) == 0 {
+			return nil
+		}
+		// Soft delete, like DeleteWebsite
+		return tx.Where("id IN ?", owned).Delete(&models.Website{}).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to delete websites")
+		return
+	}
+
+	deleted := make(map[string]bool, len(owned))
+	for _, id := range owned {
+		deleted[id] = true
+	}
+
+	results := make([]BulkDeleteResult, len(ids))
+	counts := map[string]int{bulkDeleted: 0, bulkNotFound: 0}
+	for i, id := range ids {
+		results[i] = BulkDeleteResult{ID: id, Status: bulkNotFound}
+		if deleted[id] {
+			results[i].Status = bulkDeleted
+		}
+		counts[results[i].Status]++
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"results":   results,
+		"deleted":   counts[bulkDeleted],
+		"not_found": counts[bulkNotFound],
+	})
+}
+
+// uniqueIDs drops repeats from ids, keeping the first of each in order
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,118 @@
+package website
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/google/uuid"
+)
+
+// bulkDeleteResponse is the payload of BulkDeleteWebsites
+type bulkDeleteResponse struct {
+	Results  []BulkDeleteResult `json:"results"`
+	Deleted  int                `json:"deleted"`
+	NotFound int                `json:"not_found"`
+}
+
+func bulkDelete(t *testing.T, h *Handler, userID string, ids []string) bulkDeleteResponse {
+	t.Helper()
+
+	status, resp := serve(t, h.BulkDeleteWebsites, http.MethodPost, "/websites/bulk-delete", "/websites/bulk-delete", userID,
+		BulkDeleteRequest{WebsiteIDs: ids})
+	if status != http.StatusOK {
+		t.Fatalf("bulk delete: status = %d (%s), want 200", status, resp.Error)
+	}
+	var deleted bulkDeleteResponse
+	decodeData(t, resp, &deleted)
+	return deleted
+}
+
+func TestBulkDeleteWebsitesMixedIDs(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	first := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	second := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	kept := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	unowned := createWebsite(t, h.db, models.Website{})
+	alreadyDeleted := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	if err := h.db.Delete(&alreadyDeleted).Error; err != nil {
+		t.Fatal(err)
+	}
+	missing := uuid.New().String()
+
+	got := bulkDelete(t, h, user.ID, []string{first.ID, unowned.ID, missing, second.ID, first.ID, alreadyDeleted.ID, missing})
+
+	want := []BulkDeleteResult{
+		{ID: first.ID, Status: bulkDeleted},
+		{ID: unowned.ID, Status: bulkNotFound},
+		{ID: missing, Status: bulkNotFound},
+		{ID: second.ID, Status: bulkDeleted},
+		{ID: alreadyDeleted.ID, Status: bulkNotFound},
+	}
+	if len(got.Results) != len(want) {
+		t.Fatalf("results %+v, want one per distinct id: %+v", got.Results, want)
+	}
+	for i := range want {
+		if got.Results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, got.Results[i], want[i])
+		}
+	}
+	if got.Deleted != 2 || got.NotFound != 3 {
+		t.Errorf("%d deleted, %d not found, want 2 and 3", got.Deleted, got.NotFound)
+	}
+
+	// Soft-deleted, with the caller's other website and the other user's untouched
+	if n := countWebsites(t, h.db, user.ID); n != 1 {
+		t.Errorf("user has %d websites left, want only %s", n, kept.ID)
+	}
+	if n := countWebsites(t, h.db, unowned.UserID); n != 1 {
+		t.Error("deleted another user's website")
+	}
+	var softDeleted int64
+	if err := h.db.Unscoped().Model(&models.Website{}).Where("id IN ? AND deleted_at IS NOT NULL", []string{first.ID, second.ID}).Count(&softDeleted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if softDeleted != 2 {
+		t.Errorf("%d websites kept as soft-deleted rows, want 2", softDeleted)
+	}
+
+	// Deleting them again finds nothing
+	again := bulkDelete(t, h, user.ID, []string{first.ID, second.ID})
+	if again.Deleted != 0 || again.NotFound != 2 {
+		t.Errorf("repeated delete %+v, want both not found", again)
+	}
+}
+
+func TestBulkDeleteWebsitesRejected(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	website := createWebsite(t, h.db, models.Website{UserID: user.ID})
+
+	tooMany := make([]string, maxBulkDelete+1)
+	for i := range tooMany {
+		tooMany[i] = website.ID
+	}
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{"no ids", map[string]interface{}{}},
+		{"empty list", BulkDeleteRequest{WebsiteIDs: []string{}}},
+		{"empty id", BulkDeleteRequest{WebsiteIDs: []string{website.ID, ""}}},
+		{"over the batch size", BulkDeleteRequest{WebsiteIDs: tooMany}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.BulkDeleteWebsites, http.MethodPost, "/websites/bulk-delete", "/websites/bulk-delete", user.ID, tt.body)
+			if status != http.StatusBadRequest || resp.Code != utils.CodeValidationFailed {
+				t.Errorf("status = %d, code = %s (%s), want 400 %s", status, resp.Code, resp.Error, utils.CodeValidationFailed)
+			}
+			if n := countWebsites(t, h.db, user.ID); n != 1 {
+				t.Error("rejected request deleted the website")
+			}
+		})
+	}
+
+}
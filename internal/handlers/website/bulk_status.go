@@ -0,0 +1,127 @@
+package website
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/incidents"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// WebsiteStatusEntry is one website in the bulk status response
+type WebsiteStatusEntry struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url"`
+	Status        string     `json:"status"` // Good, Degraded, Bad or InsufficientCoverage
+	LastCheckedAt *time.Time `json:"last_checked_at"`
+	Uptime24h     float64    `json:"uptime_24h"`
+	Checks24h     int64      `json:"checks_24h"`
+}
+
+// GetWebsitesStatus - GET /api/v1/websites/status
+// Current status of every active website of the caller in one response. The
+// work is a fixed number of grouped queries however many websites there are.
+func (h *Handler) GetWebsitesStatus(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var websites []models.Website
+	if err := db.Select("id", "url", "min_validators").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&websites).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch websites")
+		return
+	}
+
+	ids := make([]string, len(websites))
+	for i, website := range websites {
+		ids[i] = website.ID
+	}
+
+	consensus, err := incidents.CurrentStatuses(db, ids)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute current status")
+		return
+	}
+
+	lastChecked := make(map[string]time.Time, len(ids))
+	counts := make(map[string]*tickCounts, len(ids))
+	if len(ids) > 0 {
+		var latest []struct {
+			WebsiteID string
+			CheckedAt time.Time
+		}
+		// One index lookup per website instead of aggregating their whole history
+		if err := db.Raw(`
+			SELECT w.id AS website_id, t.created_at AS checked_at
+			FROM "Website" w
+			CROSS JOIN LATERAL (
+				SELECT created_at FROM "WebsiteTick"
+				WHERE website_id = w.id
+				ORDER BY created_at DESC
+				LIMIT 1
+			) t
+			WHERE w.id IN ?`, ids).
+			Scan(&latest).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch last checks")
+			return
+		}
+		for _, row := range latest {
+			lastChecked[row.WebsiteID] = row.CheckedAt
+		}
+
+		var grouped []struct {
+			WebsiteID    string
+			Status       string
+			Count        int64
+			LatencyTotal float64
+		}
+		if err := db.Model(&models.WebsiteTick{}).
+			Select("website_id, status, COUNT(*) AS count, COALESCE(SUM(latency), 0) AS latency_total").
+			Where("website_id IN ? AND created_at >= ?", ids, time.Now().Add(-24*time.Hour)).
+			Group("website_id, status").
+			Scan(&grouped).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute uptime")
+			return
+		}
+		for _, row := range grouped {
+			if counts[row.WebsiteID] == nil {
+				counts[row.WebsiteID] = &tickCounts{}
+			}
+			counts[row.WebsiteID].addStatus(row.Status, row.Count, row.LatencyTotal)
+		}
+	}
+
+	response := make([]WebsiteStatusEntry, len(websites))
+	for i, website := range websites {
+		// Same rule as the summary: too few recent reporters can't be trusted
+		current := consensus[website.ID]
+		entry := WebsiteStatusEntry{
+			ID:     website.ID,
+			URL:    website.URL,
+			Status: current.Status,
+		}
+		if current.Reporting < website.RequiredValidators(h.cfg.MinValidators) || current.Reporting == 0 {
+			entry.Status = models.StatusInsufficientCoverage
+		}
+		if checkedAt, ok := lastChecked[website.ID]; ok {
+			entry.LastCheckedAt = &checkedAt
+		}
+		if day := counts[website.ID]; day != nil {
+			entry.Uptime24h = day.uptime(h.cfg.DegradedCountsAsUp)
+			entry.Checks24h = day.Total
+		}
+		response[i] = entry
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"websites": response,
+		"count":    len(response),
+	})
+}
@@ -0,0 +1,139 @@
+package website
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"gorm.io/gorm"
+)
+
+type bulkStatus struct {
+	Websites []WebsiteStatusEntry `json:"websites"`
+	Count    int                  `json:"count"`
+}
+
+func getWebsitesStatus(t *testing.T, h *Handler, userID string) bulkStatus {
+	t.Helper()
+
+	status, resp := serve(t, h.GetWebsitesStatus, http.MethodGet, "/websites/status", "/websites/status", userID, nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var result bulkStatus
+	decodeData(t, resp, &result)
+	return result
+}
+
+// countQueries counts the statements db runs from now on
+func countQueries(t *testing.T, db *gorm.DB) *int64 {
+	t.Helper()
+
+	var n int64
+	count := func(*gorm.DB) { atomic.AddInt64(&n, 1) }
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_queries", count); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:count_rows", count); err != nil {
+		t.Fatal(err)
+	}
+	return &n
+}
+
+func TestGetWebsitesStatusWithoutWebsites(t *testing.T) {
+	h := newTestHandler(t)
+
+	result := getWebsitesStatus(t, h, createUser(t, h.db).ID)
+	if result.Count != 0 || len(result.Websites) != 0 {
+		t.Errorf("got %+v, want no websites", result)
+	}
+}
+
+func TestGetWebsitesStatus(t *testing.T) {
+	dbtest.RequirePostgres(t) // current status uses DISTINCT ON and a lateral join
+
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MinValidators = 1; cfg.DegradedCountsAsUp = true })
+	user := createUser(t, h.db)
+	healthy := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	failing := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	unchecked := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	deleted := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	createWebsite(t, h.db, models.Website{}) // someone else's
+	if err := h.db.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	v1, v2 := createValidator(t, h.db), createValidator(t, h.db)
+	now := time.Now().UTC()
+	createTick(t, h.db, healthy.ID, v1.ID, models.StatusBad, 0, now.Add(-2*time.Hour))
+	createTick(t, h.db, healthy.ID, v1.ID, models.StatusGood, 100, now.Add(-2*time.Minute))
+	latest := createTick(t, h.db, healthy.ID, v2.ID, models.StatusDegraded, 900, now.Add(-time.Minute))
+	createTick(t, h.db, healthy.ID, v1.ID, models.StatusBad, 0, now.Add(-48*time.Hour)) // outside 24h
+	failed := createTick(t, h.db, failing.ID, v1.ID, models.StatusBad, 0, now.Add(-30*time.Second))
+
+	result := getWebsitesStatus(t, h, user.ID)
+	if result.Count != 3 {
+		t.Fatalf("count = %d, want 3: %+v", result.Count, result.Websites)
+	}
+	byID := make(map[string]WebsiteStatusEntry)
+	for _, entry := range result.Websites {
+		byID[entry.ID] = entry
+	}
+
+	checks := []struct {
+		website     models.Website
+		status      string
+		lastChecked *time.Time
+		uptime      float64
+		checks      int64
+	}{
+		{healthy, models.StatusGood, &latest.CreatedAt, 2.0 / 3 * 100, 3},
+		{failing, models.StatusBad, &failed.CreatedAt, 0, 1},
+		{unchecked, models.StatusInsufficientCoverage, nil, 0, 0},
+	}
+	for _, want := range checks {
+		got, ok := byID[want.website.ID]
+		if !ok {
+			t.Errorf("website %s missing", want.website.ID)
+			continue
+		}
+		if got.Status != want.status || !approx(got.Uptime24h, want.uptime) || got.Checks24h != want.checks {
+			t.Errorf("website %s = %+v, want %s with %.2f%% over %d checks", got.ID, got, want.status, want.uptime, want.checks)
+		}
+		switch {
+		case want.lastChecked == nil && got.LastCheckedAt != nil:
+			t.Errorf("website %s last checked %v, want never", got.ID, got.LastCheckedAt)
+		case want.lastChecked != nil && (got.LastCheckedAt == nil || got.LastCheckedAt.Sub(*want.lastChecked).Abs() > time.Millisecond):
+			t.Errorf("website %s last checked %v, want %v", got.ID, got.LastCheckedAt, *want.lastChecked)
+		}
+	}
+}
+
+func TestGetWebsitesStatusQueryCountIsConstant(t *testing.T) {
+	dbtest.RequirePostgres(t)
+
+	queriesFor := func(websites int) int64 {
+		h := newTestHandler(t)
+		user := createUser(t, h.db)
+		validator := createValidator(t, h.db)
+		for i := 0; i < websites; i++ {
+			website := createWebsite(t, h.db, models.Website{UserID: user.ID})
+			createTick(t, h.db, website.ID, validator.ID, models.StatusGood, 100, time.Now().Add(-time.Minute))
+		}
+
+		queries := countQueries(t, h.db)
+		if got := getWebsitesStatus(t, h, user.ID); got.Count != websites {
+			t.Fatalf("count = %d, want %d", got.Count, websites)
+		}
+		return atomic.LoadInt64(queries)
+	}
+
+	one, many := queriesFor(1), queriesFor(20)
+	if one != many {
+		t.Errorf("1 website took %d queries but 20 took %d", one, many)
+	}
+}
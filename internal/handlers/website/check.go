@@ -0,0 +1,121 @@
+package website
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// checkNowPollInterval is how often CheckNow looks for the hub's results when no
+// event arrives first
+const checkNowPollInterval = 500 * time.Millisecond
+
+// CheckNow - POST /api/v1/website/:id/check-now
+// Asks the hub to check the website immediately and waits for the resulting ticks.
+func (h *Handler) CheckNow(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	website, ok := findOwnedWebsite(c, db, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	trigger := models.CheckTrigger{
+		ID:          uuid.New().String(),
+		WebsiteID:   website.ID,
+		RequestedAt: time.Now(),
+	}
+	if err := db.Create(&trigger).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to trigger check")
+		return
+	}
+
+	// Hub events wake the loop early; polling still covers missed events
+	updates, err := h.events.Subscribe(c.Request.Context())
+	if err != nil {
+		log.Printf("⚠️  Event subscription failed, falling back to polling: %v", err)
+	}
+
+	ticker := time.NewTicker(checkNowPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(h.cfg.CheckNowTimeout)
+
+	var ticks []models.WebsiteTick
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-deadline:
+			// Return whatever arrived; the remaining results are still recorded later
+			utils.SuccessResponse(c, http.StatusAccepted, gin.H{
+				"trigger_id": trigger.ID,
+				"dispatched": trigger.DispatchedCount,
+				"reused":     trigger.ReusedFrom != nil,
+				"complete":   false,
+				"ticks":      ticks,
+			})
+			return
+		case <-ticker.C:
+		case event, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if event.WebsiteID != website.ID {
+				continue
+			}
+		}
+
+		// The wait can outlast DB_QUERY_TIMEOUT, so each poll gets its own deadline
+		complete, err := h.pollCheckResults(c, &trigger, &ticks)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+			return
+		}
+		if !complete {
+			continue
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, gin.H{
+			"trigger_id": trigger.ID,
+			"dispatched": trigger.DispatchedCount,
+			"reused":     trigger.ReusedFrom != nil,
+			"complete":   true,
+			"ticks":      ticks,
+		})
+		return
+	}
+}
+
+// pollCheckResults refreshes trigger and the ticks recorded since it was requested,
+// reporting whether every dispatched check has answered
+func (h *Handler) pollCheckResults(c *gin.Context, trigger *models.CheckTrigger, ticks *[]models.WebsiteTick) (bool, error) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	if err := db.First(trigger, "id = ?", trigger.ID).Error; err != nil {
+		return false, err
+	}
+	if trigger.DispatchedAt == nil {
+		return false, nil
+	}
+
+	// A reused check's ticks may predate the request
+	since := trigger.RequestedAt
+	if trigger.ReusedFrom != nil {
+		since = *trigger.ReusedFrom
+	}
+	if err := db.Where("website_id = ? AND created_at >= ?", trigger.WebsiteID, since).
+		Order("created_at").
+		Find(ticks).Error; err != nil {
+		return false, err
+	}
+	return len(*ticks) >= trigger.DispatchedCount, nil
+}
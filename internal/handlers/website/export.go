@@ -0,0 +1,235 @@
+package website
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// exportVersion is the version of the export document format
+const exportVersion = 1
+
+// maxImportWebsites bounds how many websites one import may contain
+const maxImportWebsites = 500
+
+// Per-website outcomes of an import
+const (
+	importCreated = "created"
+	importSkipped = "skipped"
+	importFailed  = "failed"
+)
+
+// WebsiteExport is the document the export returns and the import accepts. Each
+// website has the same fields as the body of POST /website.
+type WebsiteExport struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Websites   []CreateWebsiteRequest `json:"websites"`
+}
+
+// ImportWebsitesRequest is a WebsiteExport whose websites are validated one by one
+type ImportWebsitesRequest struct {
+	Version  int               `json:"version"`
+	Websites []json.RawMessage `json:"websites" binding:"required,max=500"` // maxImportWebsites
+}
+
+// ImportResult is the outcome for one website of an import
+type ImportResult struct {
+	Index  int    `json:"index"`
+	URL    string `json:"url"`
+	Status string `json:"status"`          // created, skipped or failed
+	ID     string `json:"id,omitempty"`    // the created website
+	Error  string `json:"error,omitempty"` // why it was skipped or failed
+}
+
+// toCreateRequest describes website as the request that would recreate it
+func toCreateRequest(website models.Website) CreateWebsiteRequest {
+	req := CreateWebsiteRequest{
+		URL:                website.URL,
+		LatencyThresholdMs: website.LatencyThresholdMs,
+		AddressFamily:      website.AddressFamily,
+		HTTPVersion:        website.HTTPVersion,
+		MinValidators:      website.MinValidators,
+		SLATarget:          website.SLATarget,
+		IntervalSeconds:    website.IntervalSeconds,
+		TimeoutMs:          website.TimeoutMs,
+		AlertCooldown:      website.CooldownSeconds,
+		Regions:            website.Regions,
+		Assertions:         toAssertionRequests(website.Assertions),
+	}
+	for _, step := range website.Steps {
+		req.Steps = append(req.Steps, StepRequest{
+			Method:       step.Method,
+			URL:          step.URL,
+			Headers:      step.Headers,
+			Body:         step.Body,
+			ExpectStatus: step.ExpectStatus,
+			Assertions:   toAssertionRequests(step.Assertions),
+		})
+	}
+	return req
+}
+
+func toAssertionRequests(assertions []models.Assertion) []AssertionRequest {
+	var reqs []AssertionRequest
+	for _, a := range assertions {
+		reqs = append(reqs, AssertionRequest{Type: a.Type, Expression: a.Expression, Expected: a.Expected})
+	}
+	return reqs
+}
+
+// importState is what an import tracks across its websites
+type importState struct {
+	userID   string
+	limit    int             // 0 for no limit
+	active   int64           // including the websites imported so far
+	existing map[string]bool // URLs the user monitors
+}
+
+// importWebsite validates and creates one website of an import in tx, the
+// transaction holding the user's row lock
+func (h *Handler) importWebsite(tx *gorm.DB, state *importState, raw json.RawMessage) ImportResult {
+	var req CreateWebsiteRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return ImportResult{Status: importFailed, Error: "Invalid website: " + err.Error()}
+	}
+
+	result := ImportResult{URL: req.URL, Status: importFailed}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		result.Error = utils.ValidationMessage(err)
+		return result
+	}
+	if state.existing[req.URL] {
+		result.Status = importSkipped
+		result.Error = "Already monitored"
+		return result
+	}
+	if state.limit > 0 && state.active >= int64(state.limit) {
+		result.Error = fmt.Sprintf("Website limit reached: at most %d active websites allowed", state.limit)
+		return result
+	}
+
+	website, err := h.newWebsite(req, state.userID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	// A savepoint, so a failed create doesn't abort the rest of the import
+	if err := tx.Transaction(func(tx *gorm.DB) error { return tx.Create(&website).Error }); err != nil {
+		result.Error = "Failed to create website"
+		return result
+	}
+
+	state.existing[website.URL] = true
+	state.active++
+	result.Status = importCreated
+	result.ID = website.ID
+	return result
+}
+
+// ExportWebsites - GET /api/v1/websites/export
+// Every active website of the caller with its settings, for backup or to import
+// into another account or instance. Webhook secrets are not exported.
+func (h *Handler) ExportWebsites(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var websites []models.Website
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&websites).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch websites")
+		return
+	}
+
+	export := WebsiteExport{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Websites:   make([]CreateWebsiteRequest, len(websites)),
+	}
+	for i, website := range websites {
+		export.Websites[i] = toCreateRequest(website)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, export)
+}
+
+// ImportWebsites - POST /api/v1/websites/import
+// Creates the websites of an export. Each is validated on its own, so one bad
+// entry doesn't fail the rest; URLs the caller already monitors are skipped.
+func (h *Handler) ImportWebsites(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req ImportWebsitesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+	if req.Version > exportVersion {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest,
+			fmt.Sprintf("Unsupported export version %d (at most %d)", req.Version, exportVersion))
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	// Imported under the user's row lock, like CreateWebsite, so concurrent
+	// imports and creates can't all fill the same free slots
+	results := make([]ImportResult, len(req.Websites))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		limit, active, err := h.websiteQuota(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		var urls []string
+		if err := tx.Model(&models.Website{}).Where("user_id = ?", userID).Pluck("url", &urls).Error; err != nil {
+			return err
+		}
+
+		state := &importState{
+			userID:   userID.(string),
+			limit:    limit,
+			active:   active,
+			existing: make(map[string]bool, len(urls)),
+		}
+		for _, url := range urls {
+			state.existing[url] = true
+		}
+
+		for i, raw := range req.Websites {
+			results[i] = h.importWebsite(tx, state, raw)
+			results[i].Index = i
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUserNotFound, "User not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+
+	counts := map[string]int{importCreated: 0, importSkipped: 0, importFailed: 0}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"results": results,
+		"created": counts[importCreated],
+		"skipped": counts[importSkipped],
+		"failed":  counts[importFailed],
+	})
+}
@@ -0,0 +1,251 @@
+package website
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// importResponse is the payload of ImportWebsites
+type importResponse struct {
+	Results []ImportResult `json:"results"`
+	Created int            `json:"created"`
+	Skipped int            `json:"skipped"`
+	Failed  int            `json:"failed"`
+}
+
+func exportWebsites(t *testing.T, h *Handler, userID string) WebsiteExport {
+	t.Helper()
+
+	status, resp := serve(t, h.ExportWebsites, http.MethodGet, "/websites/export", "/websites/export", userID, nil)
+	if status != http.StatusOK {
+		t.Fatalf("export: status = %d (%s), want 200", status, resp.Error)
+	}
+	var export WebsiteExport
+	decodeData(t, resp, &export)
+	return export
+}
+
+func importWebsites(t *testing.T, h *Handler, userID string, body interface{}) importResponse {
+	t.Helper()
+
+	status, resp := serve(t, h.ImportWebsites, http.MethodPost, "/websites/import", "/websites/import", userID, body)
+	if status != http.StatusOK {
+		t.Fatalf("import: status = %d (%s), want 200", status, resp.Error)
+	}
+	var imported importResponse
+	decodeData(t, resp, &imported)
+	return imported
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	h := newTestHandler(t)
+	from, to := createUser(t, h.db), createUser(t, h.db)
+
+	three, cooldown := 3, 600
+	websites := []CreateWebsiteRequest{
+		{URL: "https://example.com"},
+		{
+			URL:                "https://api.example.com/health",
+			Assertions:         []AssertionRequest{{Type: "jsonpath", Expression: "$.status", Expected: "ok"}},
+			LatencyThresholdMs: 1500,
+			AddressFamily:      "ipv6",
+			HTTPVersion:        "http2",
+			MinValidators:      &three,
+			SLATarget:          99.9,
+			IntervalSeconds:    300,
+			TimeoutMs:          5000,
+			AlertCooldown:      &cooldown,
+			Regions:            []string{"eu-west", "us-east"},
+		},
+		{
+			URL: "https://app.example.com",
+			Steps: []StepRequest{
+				{Method: http.MethodPost, URL: "https://app.example.com/login", Body: "user=alice", Headers: map[string]string{"X-Tenant": "acme"}},
+				{Method: http.MethodGet, URL: "https://app.example.com/dashboard", ExpectStatus: http.StatusOK,
+					Assertions: []AssertionRequest{{Type: "regex", Expression: "Welcome"}}},
+			},
+		},
+	}
+	for _, website := range websites {
+		if status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", from.ID, website); status != http.StatusCreated {
+			t.Fatalf("creating %s: status = %d (%s)", website.URL, status, resp.Error)
+		}
+	}
+
+	exported := exportWebsites(t, h, from.ID)
+	if exported.Version != exportVersion || len(exported.Websites) != len(websites) {
+		t.Fatalf("exported version %d with %d websites, want version %d with %d", exported.Version, len(exported.Websites), exportVersion, len(websites))
+	}
+
+	imported := importWebsites(t, h, to.ID, exported)
+	if imported.Created != len(websites) || imported.Skipped != 0 || imported.Failed != 0 {
+		t.Fatalf("imported %+v, want every website created", imported)
+	}
+	for i, result := range imported.Results {
+		if result.Index != i || result.Status != importCreated || result.ID == "" || result.URL != websites[i].URL {
+			t.Errorf("result %d = %+v, want %s created", i, result, websites[i].URL)
+		}
+	}
+
+	// The importing account now exports exactly what was exported
+	reexported := exportWebsites(t, h, to.ID)
+	if !reflect.DeepEqual(reexported.Websites, exported.Websites) {
+		got, _ := json.Marshal(reexported.Websites)
+		want, _ := json.Marshal(exported.Websites)
+		t.Errorf("re-exported\n%s\nwant\n%s", got, want)
+	}
+
+	// Importing the same export again creates nothing
+	again := importWebsites(t, h, to.ID, exported)
+	if again.Created != 0 || again.Skipped != len(websites) {
+		t.Errorf("second import %+v, want every website skipped", again)
+	}
+	if n := countWebsites(t, h.db, to.ID); n != int64(len(websites)) {
+		t.Errorf("importing account has %d websites, want %d", n, len(websites))
+	}
+}
+
+func TestImportWebsitesPerItemResults(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) {
+		cfg.MinCheckInterval = time.Minute
+		cfg.MaxCheckInterval = time.Hour
+		cfg.MaxWebsitesPerUser = 3
+	})
+	user := createUser(t, h.db)
+	if status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID,
+		CreateWebsiteRequest{URL: "https://existing.example.com"}); status != http.StatusCreated {
+		t.Fatalf("status = %d (%s)", status, resp.Error)
+	}
+
+	imported := importWebsites(t, h, user.ID, map[string]interface{}{
+		"version": exportVersion,
+		"websites": []interface{}{
+			map[string]interface{}{"url": "https://new.example.com", "interval_seconds": 120},
+			map[string]interface{}{"url": "https://existing.example.com"},
+			map[string]interface{}{"url": "not a url"},
+			map[string]interface{}{"url": "https://rare.example.com", "interval_seconds": 7200},
+			map[string]interface{}{"url": "https://new.example.com"},
+			"not an object",
+			map[string]interface{}{"url": "https://third.example.com"},
+			map[string]interface{}{"url": "https://over-quota.example.com"},
+		},
+	})
+
+	want := []string{importCreated, importSkipped, importFailed, importFailed, importSkipped, importFailed, importCreated, importFailed}
+	if len(imported.Results) != len(want) {
+		t.Fatalf("%d results, want %d", len(imported.Results), len(want))
+	}
+	for i, result := range imported.Results {
+		if result.Index != i || result.Status != want[i] {
+			t.Errorf("result %d = %+v, want %s", i, result, want[i])
+		}
+		if result.Status != importCreated && result.Error == "" {
+			t.Errorf("result %d (%s) gives no reason", i, result.Status)
+		}
+	}
+	if imported.Created != 2 || imported.Skipped != 2 || imported.Failed != 4 {
+		t.Errorf("counts %d created, %d skipped, %d failed, want 2, 2 and 4", imported.Created, imported.Skipped, imported.Failed)
+	}
+	if n := countWebsites(t, h.db, user.ID); n != 3 {
+		t.Errorf("user has %d websites, want the 3 allowed", n)
+	}
+}
+
+func TestImportWebsitesRejected(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		wantCode string
+	}{
+		{"newer export version", map[string]interface{}{"version": exportVersion + 1, "websites": []interface{}{}}, utils.CodeInvalidRequest},
+		{"no websites", map[string]interface{}{"version": exportVersion}, utils.CodeValidationFailed},
+		{"too many websites", map[string]interface{}{"version": exportVersion, "websites": make([]map[string]string, maxImportWebsites+1)}, utils.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.ImportWebsites, http.MethodPost, "/websites/import", "/websites/import", user.ID, tt.body)
+			if status != http.StatusBadRequest || resp.Code != tt.wantCode {
+				t.Errorf("status = %d, code = %s (%s), want 400 %s", status, resp.Code, resp.Error, tt.wantCode)
+			}
+			if n := countWebsites(t, h.db, user.ID); n != 0 {
+				t.Errorf("rejected import created %d websites", n)
+			}
+		})
+	}
+}
+
+func TestImportWebsitesQuotaConcurrent(t *testing.T) {
+	const limit, imports, perImport, creates = 5, 4, 3, 4
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MaxWebsitesPerUser = limit })
+	user := createUser(t, h.db)
+
+	router := gin.New()
+	withUser := func(handler gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("userID", user.ID)
+			handler(c)
+		}
+	}
+	router.POST("/websites/import", withUser(h.ImportWebsites))
+	router.POST("/website", withUser(h.CreateWebsite))
+
+	// Imports and creates racing for the same free slots
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < imports+creates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target, body := "/website", fmt.Sprintf(`{"url":"https://create-%d.example.com"}`, i)
+			if i < imports {
+				websites := make([]string, perImport)
+				for j := range websites {
+					websites[j] = fmt.Sprintf(`{"url":"https://import-%d-%d.example.com"}`, i, j)
+				}
+				target, body = "/websites/import", fmt.Sprintf(`{"version":%d,"websites":[%s]}`, exportVersion, strings.Join(websites, ","))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case w.Code == http.StatusCreated:
+				created++
+			case w.Code == http.StatusOK:
+				var resp struct {
+					Data importResponse `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Errorf("decoding import: %v", err)
+				}
+				created += resp.Data.Created
+			case w.Code != http.StatusForbidden:
+				t.Errorf("%s: status = %d (%s)", target, w.Code, w.Body.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if created != limit {
+		t.Errorf("reported %d websites created concurrently, want %d", created, limit)
+	}
+	if n := countWebsites(t, h.db, user.ID); n != limit {
+		t.Errorf("user has %d websites, want the %d allowed", n, limit)
+	}
+}
@@ -1,111 +1,409 @@
 package website
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/events"
 	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/notify"
 	"github.com/datmedevil17/gopher-uptime/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Handler struct {
-	db *gorm.DB
+	db     *gorm.DB
+	cfg    *config.Config
+	events events.Bus
+	probe  probeFunc // nil unless CREATE_REACHABILITY_PROBE is set
 }
 
-func NewHandler(db *gorm.DB) *Handler {
-	return &Handler{db: db}
+func NewHandler(db *gorm.DB, cfg *config.Config, bus events.Bus) *Handler {
+	h := &Handler{db: db, cfg: cfg, events: bus}
+	if cfg.ReachabilityProbe {
+		h.probe = newProbe(cfg.ReachabilityTimeout)
+	}
+	return h
 }
 
 // DTO for creating website
 type CreateWebsiteRequest struct {
-	URL string `json:"url" binding:"required,url"`
+	URL                string             `json:"url" binding:"required,url"`
+	Assertions         []AssertionRequest `json:"assertions" binding:"omitempty,max=10,dive"`
+	LatencyThresholdMs int                `json:"latency_threshold_ms" binding:"omitempty,min=1,max=60000"`
+	AddressFamily      string             `json:"address_family" binding:"omitempty,oneof=auto ipv4 ipv6 dual"`
+	HTTPVersion        string             `json:"http_version" binding:"omitempty,oneof=auto http1 http2"`
+	MinValidators      *int               `json:"min_validators" binding:"omitempty,min=1,max=100"`
+	SLATarget          float64            `json:"sla_target" binding:"omitempty,gt=0,lt=100"`
+	IntervalSeconds    int                `json:"interval_seconds" binding:"omitempty,min=1"` // bounded by MIN/MAX_CHECK_INTERVAL
+	TimeoutMs          int                `json:"timeout_ms" binding:"omitempty,min=1000,max=30000"`
+	AlertCooldown      *int               `json:"alert_cooldown_seconds" binding:"omitempty,min=0,max=86400"`
+	Regions            []string           `json:"regions" binding:"omitempty,max=20,dive,required,max=255"` // validator locations allowed to check it
+	Steps              []StepRequest      `json:"steps" binding:"omitempty,max=5,dive"`                     // at most models.MaxCheckSteps
+}
+
+// checkIntervalInBounds rejects a requested check interval outside the configured
+// range; 0 means the default and is always accepted
+func (h *Handler) checkIntervalInBounds(seconds int) error {
+	if seconds == 0 {
+		return nil
+	}
+	interval := time.Duration(seconds) * time.Second
+	if interval < h.cfg.MinCheckInterval || interval > h.cfg.MaxCheckInterval {
+		return fmt.Errorf("interval_seconds must be between %d and %d",
+			int(h.cfg.MinCheckInterval/time.Second), int(h.cfg.MaxCheckInterval/time.Second))
+	}
+	return nil
+}
+
+// errWebsiteLimit means the user already has as many websites as allowed
+var errWebsiteLimit = errors.New("website limit reached")
+
+// websiteQuota returns how many active websites userID may have (0 for no limit)
+// and how many it has. It locks the user's row, so within a transaction
+// concurrent creates for the same user count one after another.
+func (h *Handler) websiteQuota(db *gorm.DB, userID interface{}) (int, int64, error) {
+	var user models.User
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "max_websites").Where("id = ?", userID).First(&user).Error; err != nil {
+		return 0, 0, err
+	}
+
+	limit := h.cfg.MaxWebsitesPerUser
+	if user.MaxWebsites != nil {
+		limit = *user.MaxWebsites
+	}
+
+	var active int64
+	err := db.Model(&models.Website{}).
+		Where("user_id = ?", userID).
+		Count(&active).Error
+	return limit, active, err
+}
+
+// newWebsite validates req and builds the website it describes for userID
+func (h *Handler) newWebsite(req CreateWebsiteRequest, userID string) (models.Website, error) {
+	if err := h.checkIntervalInBounds(req.IntervalSeconds); err != nil {
+		return models.Website{}, err
+	}
+
+	assertions, err := toAssertions(req.Assertions)
+	if err != nil {
+		return models.Website{}, fmt.Errorf("Invalid request: %w", err)
+	}
+
+	steps, err := toSteps(req.Steps)
+	if err != nil {
+		return models.Website{}, fmt.Errorf("Invalid request: %w", err)
+	}
+
+	website := models.Website{
+		ID:         uuid.New().String(),
+		URL:        req.URL,
+		UserID:     userID,
+		Assertions: assertions,
+
+		LatencyThresholdMs: req.LatencyThresholdMs,
+		AddressFamily:      req.AddressFamily,
+		HTTPVersion:        req.HTTPVersion,
+		MinValidators:      req.MinValidators,
+		SLATarget:          req.SLATarget,
+		IntervalSeconds:    req.IntervalSeconds,
+		TimeoutMs:          req.TimeoutMs,
+		WebhookSecret:      notify.NewWebhookSecret(),
+		CooldownSeconds:    req.AlertCooldown,
+		Regions:            req.Regions,
+		Steps:              steps,
+	}
+	if website.AddressFamily == "" {
+		website.AddressFamily = "auto"
+	}
+	if website.HTTPVersion == "" {
+		website.HTTPVersion = models.HTTPVersionAuto
+	}
+	if website.IntervalSeconds == 0 {
+		website.IntervalSeconds = int(h.cfg.CheckInterval / time.Second)
+	}
+	return website, nil
 }
 
 // CreateWebsite - POST /api/v1/website
 func (h *Handler) CreateWebsite(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUnauthorized, "User not authenticated")
 		return
 	}
 
 	var req CreateWebsiteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	// Create website with GORM
-	website := models.Website{
-		ID:       uuid.New().String(),
-		URL:      req.URL,
-		UserID:   userID.(string),
-		Disabled: false,
-	}
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
 
-	result := h.db.Create(&website)
-	if result.Error != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create website")
+	website, err := h.newWebsite(req, userID.(string))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, gin.H{
-		"id":  website.ID,
-		"url": website.URL,
+	// Enforce the per-user active website quota. Counting under the user's row
+	// lock keeps concurrent creates from all seeing the last free slot.
+	var limit int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var active int64
+		var err error
+		limit, active, err = h.websiteQuota(tx, userID)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && active >= int64(limit) {
+			return errWebsiteLimit
+		}
+		return tx.Create(&website).Error
 	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUserNotFound, "User not found")
+		return
+	case errors.Is(err, errWebsiteLimit):
+		utils.ErrorResponse(c, http.StatusForbidden, utils.CodeWebsiteLimitReached, fmt.Sprintf("Website limit reached: at most %d active websites allowed", limit))
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create website")
+		return
+	}
+
+	response := gin.H{
+		"id":                     website.ID,
+		"url":                    website.URL,
+		"assertions":             website.Assertions,
+		"latency_threshold_ms":   website.LatencyThresholdMs,
+		"address_family":         website.AddressFamily,
+		"http_version":           website.HTTPVersion,
+		"min_validators":         website.RequiredValidators(h.cfg.MinValidators),
+		"sla_target":             website.SLATarget,
+		"interval_seconds":       website.IntervalSeconds,
+		"timeout_ms":             website.TimeoutMs,
+		"webhook_secret":         website.WebhookSecret,
+		"alert_cooldown_seconds": int(website.AlertCooldown(h.cfg.AlertCooldown) / time.Second),
+		"regions":                website.Regions,
+		"steps":                  website.Steps,
+	}
+	if warning := h.probeWebsite(c.Request.Context(), website); warning != "" {
+		response["warnings"] = []string{warning}
+	}
+	utils.SuccessResponse(c, http.StatusCreated, response)
+}
+
+// probeWebsite requests a new website's URL once and describes why it isn't
+// reachable, so typos show up before the first Bad ticks do. The website is
+// created either way. Multi-step websites aren't probed since their URL may
+// only work after the earlier steps.
+func (h *Handler) probeWebsite(ctx context.Context, website models.Website) string {
+	if h.probe == nil || len(website.Steps) > 0 {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.ReachabilityTimeout)
+	defer cancel()
+	if err := h.probe(ctx, website.URL); err != nil {
+		return fmt.Sprintf("%s was not reachable when the website was created: %v", website.URL, err)
+	}
+	return ""
 }
 
-// GetWebsites - GET /api/v1/websites
+// Tick modes for GetWebsites
+const (
+	ticksLatest = "latest"
+	ticksFull   = "full"
+)
+
+// fullTicksLimit is how many recent ticks per website ?ticks=full returns
+const fullTicksLimit = 100
+
+// WebsiteWithTicks exposes a website's recent ticks, which the model hides
+type WebsiteWithTicks struct {
+	models.Website
+	Ticks []models.WebsiteTick
+}
+
+// recentTicks returns up to limit of the newest ticks of each website, newest
+// first. The lateral join reads each website's ticks straight off the
+// (website_id, created_at) index instead of scanning its history.
+func recentTicks(db *gorm.DB, websiteIDs []string, limit int) (map[string][]models.WebsiteTick, error) {
+	byWebsite := make(map[string][]models.WebsiteTick, len(websiteIDs))
+	if len(websiteIDs) == 0 {
+		return byWebsite, nil
+	}
+
+	var ticks []models.WebsiteTick
+	err := db.Raw(`
+		SELECT t.*
+		FROM "Website" w
+		CROSS JOIN LATERAL (
+			SELECT * FROM "WebsiteTick"
+			WHERE website_id = w.id
+			ORDER BY created_at DESC
+			LIMIT ?
+		) t
+		WHERE w.id IN ?
+		ORDER BY t.website_id, t.created_at DESC`,
+		limit, websiteIDs,
+	).Scan(&ticks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tick := range ticks {
+		byWebsite[tick.WebsiteID] = append(byWebsite[tick.WebsiteID], tick)
+	}
+	return byWebsite, nil
+}
+
+// WebsitesWithTicks returns the user's websites, newest first, each with its
+// latest tick or, when full is set, up to fullTicksLimit of its newest ticks
+func (h *Handler) WebsitesWithTicks(ctx context.Context, userID string, full bool) ([]WebsiteWithTicks, error) {
+	limit := 1
+	if full {
+		limit = fullTicksLimit
+	}
+
+	db, cancel := database.WithTimeout(ctx, h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var websites []models.Website
+	if err := db.Where("user_id = ?", userID).Order("created_at DESC").Find(&websites).Error; err != nil {
+		return nil, fmt.Errorf("fetch websites: %w", err)
+	}
+
+	ids := make([]string, len(websites))
+	for i, website := range websites {
+		ids[i] = website.ID
+	}
+
+	ticks, err := recentTicks(db, ids, limit)
+	if err != nil {
+		return nil, fmt.Errorf("fetch ticks: %w", err)
+	}
+
+	response := make([]WebsiteWithTicks, len(websites))
+	for i, website := range websites {
+		response[i] = WebsiteWithTicks{Website: website, Ticks: ticks[website.ID]}
+		if response[i].Ticks == nil {
+			response[i].Ticks = []models.WebsiteTick{}
+		}
+	}
+	return response, nil
+}
+
+// GetWebsites - GET /api/v1/websites?ticks=latest
 func (h *Handler) GetWebsites(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
-	var websites []models.Website
+	full := false
+	switch c.DefaultQuery("ticks", ticksLatest) {
+	case ticksLatest:
+	case ticksFull:
+		full = true
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "ticks must be latest or full")
+		return
+	}
 
-	// Use GORM Preload to eager load ticks
-	result := h.db.
-		Preload("Ticks", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC").Limit(100)
-		}).
-		Where("user_id = ? AND disabled = ?", userID, false).
-		Order("created_at DESC").
-		Find(&websites)
+	response, err := h.WebsitesWithTicks(c.Request.Context(), userID.(string), full)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch websites")
+		return
+	}
 
-	if result.Error != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch websites")
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"websites": response,
+		"count":    len(response),
+	})
+}
+
+// GetWebsite - GET /api/v1/website/:id
+// The website's configuration only; ticks come from the status endpoints.
+func (h *Handler) GetWebsite(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	website, ok := findOwnedWebsite(c, db, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, website)
+}
+
+// RotateWebhookSecret - POST /api/v1/website/:id/webhook-secret
+// Replaces the secret that signs the website's webhooks. The old secret stops
+// working immediately.
+func (h *Handler) RotateWebhookSecret(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	website, ok := findOwnedWebsite(c, db, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	secret := notify.NewWebhookSecret()
+	if err := db.Model(&website).Update("webhook_secret", secret).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to rotate webhook secret")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
-		"websites": websites,
-		"count":    len(websites),
+		"id":             website.ID,
+		"webhook_secret": secret,
 	})
 }
 
+// WebsiteStatus returns one of the user's websites with its 100 newest ticks
+// loaded, or gorm.ErrRecordNotFound
+func (h *Handler) WebsiteStatus(ctx context.Context, userID, websiteID string) (models.Website, error) {
+	db, cancel := database.WithTimeout(ctx, h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var website models.Website
+	err := db.
+		Preload("Ticks", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("created_at DESC").Limit(fullTicksLimit)
+		}).
+		Where("id = ? AND user_id = ?", websiteID, userID).
+		First(&website).Error
+	return website, err
+}
+
 // GetWebsiteStatus - GET /api/v1/website/status?websiteId=xxx
 func (h *Handler) GetWebsiteStatus(c *gin.Context) {
 	websiteID := c.Query("websiteId")
 	if websiteID == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "websiteId query parameter required")
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "websiteId query parameter required")
 		return
 	}
 
 	userID, _ := c.Get("userID")
 
-	var website models.Website
-	result := h.db.
-		Preload("Ticks", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC").Limit(100)
-		}).
-		Where("id = ? AND user_id = ? AND disabled = ?", websiteID, userID, false).
-		First(&website)
-
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			utils.ErrorResponse(c, http.StatusNotFound, "Website not found")
+	website, err := h.WebsiteStatus(c.Request.Context(), userID.(string), websiteID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeWebsiteNotFound, "Website not found")
 		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Database error")
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
 		}
 		return
 	}
@@ -113,31 +411,35 @@ func (h *Handler) GetWebsiteStatus(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, website)
 }
 
+type DeleteWebsiteRequest struct {
+	WebsiteID string `json:"websiteId" binding:"required"`
+}
+
 // DeleteWebsite - DELETE /api/v1/website
 func (h *Handler) DeleteWebsite(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
-	var req struct {
-		WebsiteID string `json:"websiteId" binding:"required"`
-	}
-
+	var req DeleteWebsiteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request")
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	// Soft delete by setting disabled = true
-	result := h.db.Model(&models.Website{}).
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	// Soft delete; the row and its ticks stay recoverable via Unscoped
+	result := db.
 		Where("id = ? AND user_id = ?", req.WebsiteID, userID).
-		Update("disabled", true)
+		Delete(&models.Website{})
 
 	if result.Error != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete website")
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to delete website")
 		return
 	}
 
 	if result.RowsAffected == 0 {
-		utils.ErrorResponse(c, http.StatusNotFound, "Website not found")
+		utils.ErrorResponse(c, http.StatusNotFound, utils.CodeWebsiteNotFound, "Website not found")
 		return
 	}
 
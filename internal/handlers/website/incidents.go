@@ -0,0 +1,95 @@
+package website
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// IncidentResponse is one entry of the caller's incident feed
+type IncidentResponse struct {
+	ID              string     `json:"id"`
+	WebsiteID       string     `json:"website_id"`
+	URL             string     `json:"url"`
+	Status          string     `json:"status"`   // ongoing or resolved
+	Severity        string     `json:"severity"` // Bad for an outage, or Degraded
+	Cause           string     `json:"cause"`
+	StartedAt       time.Time  `json:"started_at"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+	DurationSeconds int64      `json:"duration_seconds"` // so far, for ongoing incidents
+}
+
+// ListIncidents - GET /api/v1/incidents?status=&page=&page_size=
+// Lists incidents across all of the caller's websites, most recent first.
+func (h *Handler) ListIncidents(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	// Soft-deleted websites drop out through the join's default scope
+	query := db.Model(&models.Incident{}).
+		Joins("Website").
+		Where(`"Website".user_id = ?`, userID)
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.IncidentOngoing:
+		query = query.Where(`"Incident".resolved_at IS NULL`)
+	case models.IncidentResolved:
+		query = query.Where(`"Incident".resolved_at IS NOT NULL`)
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "status must be ongoing or resolved")
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count incidents")
+		return
+	}
+
+	var incidents []models.Incident
+	if err := query.Order(`"Incident".started_at DESC`).
+		Offset(page.Offset()).
+		Limit(page.PageSize).
+		Find(&incidents).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch incidents")
+		return
+	}
+
+	now := time.Now()
+	response := make([]IncidentResponse, len(incidents))
+	for i, incident := range incidents {
+		response[i] = IncidentResponse{
+			ID:              incident.ID,
+			WebsiteID:       incident.WebsiteID,
+			Status:          incident.Status(),
+			Severity:        incident.Severity,
+			Cause:           incident.Cause,
+			StartedAt:       incident.StartedAt,
+			ResolvedAt:      incident.ResolvedAt,
+			DurationSeconds: int64(incident.Duration(now).Seconds()),
+		}
+		if incident.Website != nil {
+			response[i].URL = incident.Website.URL
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"incidents": response,
+		"total":     total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
@@ -0,0 +1,137 @@
+package website
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type incidentFeed struct {
+	Incidents []IncidentResponse `json:"incidents"`
+	Total     int64              `json:"total"`
+}
+
+// createIncident stores an incident of website that started at startedAt and,
+// unless resolvedAt is nil, is resolved
+func createIncident(t *testing.T, db *gorm.DB, website models.Website, startedAt time.Time, resolvedAt *time.Time) models.Incident {
+	t.Helper()
+
+	incident := models.Incident{
+		ID:         uuid.New().String(),
+		WebsiteID:  website.ID,
+		StartedAt:  startedAt,
+		ResolvedAt: resolvedAt,
+		Cause:      "connection refused",
+	}
+	if err := db.Create(&incident).Error; err != nil {
+		t.Fatalf("creating incident: %v", err)
+	}
+	return incident
+}
+
+func listIncidents(t *testing.T, h *Handler, userID, query string) incidentFeed {
+	t.Helper()
+
+	status, resp := serve(t, h.ListIncidents, http.MethodGet, "/incidents", "/incidents"+query, userID, nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var feed incidentFeed
+	decodeData(t, resp, &feed)
+	return feed
+}
+
+func TestListIncidentsAcrossWebsites(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	shop := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://shop.example.com"})
+	blog := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://blog.example.com"})
+	now := time.Now().UTC()
+
+	resolvedAt := now.Add(-90 * time.Minute)
+	oldest := createIncident(t, h.db, shop, now.Add(-2*time.Hour), &resolvedAt)
+	ongoing := createIncident(t, h.db, shop, now.Add(-10*time.Minute), nil)
+	blogResolved := now.Add(-20 * time.Minute)
+	middle := createIncident(t, h.db, blog, now.Add(-time.Hour), &blogResolved)
+
+	// Someone else's website, and a website the user deleted
+	createIncident(t, h.db, createWebsite(t, h.db, models.Website{}), now, nil)
+	deleted := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	createIncident(t, h.db, deleted, now, nil)
+	if err := h.db.Delete(&deleted).Error; err != nil {
+		t.Fatalf("deleting website: %v", err)
+	}
+
+	feed := listIncidents(t, h, user.ID, "")
+	if feed.Total != 3 {
+		t.Errorf("total = %d, want 3", feed.Total)
+	}
+
+	want := []struct {
+		id       string
+		url      string
+		status   string
+		duration time.Duration
+	}{
+		{ongoing.ID, shop.URL, models.IncidentOngoing, 10 * time.Minute},
+		{middle.ID, blog.URL, models.IncidentResolved, 40 * time.Minute},
+		{oldest.ID, shop.URL, models.IncidentResolved, 30 * time.Minute},
+	}
+	if len(feed.Incidents) != len(want) {
+		t.Fatalf("got %d incidents, want %d: %+v", len(feed.Incidents), len(want), feed.Incidents)
+	}
+	for i, w := range want {
+		got := feed.Incidents[i]
+		if got.ID != w.id {
+			t.Errorf("incident %d: id = %s, want %s", i, got.ID, w.id)
+			continue
+		}
+		if got.URL != w.url || got.Status != w.status {
+			t.Errorf("incident %d: url %q status %q, want %q %q", i, got.URL, got.Status, w.url, w.status)
+		}
+		// Incidents stored without a severity are outages
+		if got.Severity != models.StatusBad {
+			t.Errorf("incident %d: severity = %q, want %q", i, got.Severity, models.StatusBad)
+		}
+		// Ongoing incidents keep growing while the request runs
+		if d := time.Duration(got.DurationSeconds) * time.Second; d < w.duration || d > w.duration+5*time.Second {
+			t.Errorf("incident %d: duration = %s, want %s", i, d, w.duration)
+		}
+	}
+}
+
+func TestListIncidentsFiltersAndPages(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	now := time.Now().UTC()
+
+	var resolved []models.Incident
+	for i := 0; i < 3; i++ {
+		website := createWebsite(t, h.db, models.Website{UserID: user.ID})
+		resolvedAt := now.Add(-time.Duration(i) * time.Hour)
+		resolved = append(resolved, createIncident(t, h.db, website, resolvedAt.Add(-time.Minute), &resolvedAt))
+	}
+	ongoing := createIncident(t, h.db, createWebsite(t, h.db, models.Website{UserID: user.ID}), now.Add(-5*time.Hour), nil)
+
+	feed := listIncidents(t, h, user.ID, "?status=ongoing")
+	if feed.Total != 1 || len(feed.Incidents) != 1 || feed.Incidents[0].ID != ongoing.ID {
+		t.Errorf("ongoing: got %+v (total %d), want only %s", feed.Incidents, feed.Total, ongoing.ID)
+	}
+
+	feed = listIncidents(t, h, user.ID, "?status=resolved&page=2&page_size=2")
+	if feed.Total != 3 {
+		t.Errorf("resolved total = %d, want 3", feed.Total)
+	}
+	if len(feed.Incidents) != 1 || feed.Incidents[0].ID != resolved[2].ID {
+		t.Errorf("resolved page 2: got %+v, want only %s", feed.Incidents, resolved[2].ID)
+	}
+
+	status, _ := serve(t, h.ListIncidents, http.MethodGet, "/incidents", "/incidents?status=flapping", user.ID, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("unknown status: got %d, want 400", status)
+	}
+}
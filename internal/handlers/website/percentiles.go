@@ -0,0 +1,107 @@
+package website
+
+import (
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/rollups"
+	"gorm.io/gorm"
+)
+
+// latencyPercentiles are a window's tail latencies in milliseconds, 0 without checks
+type latencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+func histogramPercentiles(h rollups.Histogram) latencyPercentiles {
+	return latencyPercentiles{
+		P50: h.Percentile(50),
+		P90: h.Percentile(90),
+		P95: h.Percentile(95),
+		P99: h.Percentile(99),
+	}
+}
+
+// tickPercentiles computes exact percentiles from a website's ticks created at
+// or after since
+func tickPercentiles(db *gorm.DB, websiteID string, since time.Time) (latencyPercentiles, error) {
+	var p latencyPercentiles
+	err := db.Model(&models.WebsiteTick{}).
+		Select("COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY latency), 0) AS p50, "+
+			"COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY latency), 0) AS p90, "+
+			"COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY latency), 0) AS p95, "+
+			"COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY latency), 0) AS p99").
+		Where("website_id = ? AND created_at >= ?", websiteID, since).
+		Scan(&p).Error
+	return p, err
+}
+
+// tickHistogram buckets a website's ticks created in [from, to) like the
+// rollups do; a zero to leaves the range open-ended
+func tickHistogram(db *gorm.DB, websiteID string, from, to time.Time) (rollups.Histogram, error) {
+	var row struct {
+		Histogram []int64 `gorm:"serializer:json"`
+	}
+	query := db.Model(&models.WebsiteTick{}).
+		Select(rollups.HistogramSQL()+" AS histogram").
+		Where("website_id = ? AND created_at >= ?", websiteID, from)
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+	err := query.Scan(&row).Error
+	return row.Histogram, err
+}
+
+// rollupHistogram merges the histograms of a website's rollups with buckets in [from, to)
+func rollupHistogram(db *gorm.DB, websiteID string, from, to time.Time) (rollups.Histogram, error) {
+	var rows []models.WebsiteTickRollup
+	err := db.Select("latency_histogram").
+		Where("website_id = ? AND bucket >= ? AND bucket < ?", websiteID, from, to).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var merged rollups.Histogram
+	for _, row := range rows {
+		merged = merged.Add(row.LatencyHistogram)
+	}
+	return merged, nil
+}
+
+// windowPercentiles computes a website's latency percentiles over the window
+// ending at now. Windows served from raw ticks are exact. Longer ones are
+// estimated from the rollup histograms plus histograms of the raw ends, so
+// they are only as precise as the rollups.LatencyBounds buckets.
+func windowPercentiles(db *gorm.DB, websiteID string, window time.Duration, now time.Time) (latencyPercentiles, error) {
+	start := now.Add(-window)
+	if window <= rawWindowLimit {
+		return tickPercentiles(db, websiteID, start)
+	}
+
+	rollupFrom, rollupTo, ok, err := rollupSpan(db, start, now)
+	if err != nil {
+		return latencyPercentiles{}, err
+	}
+	if !ok {
+		return tickPercentiles(db, websiteID, start)
+	}
+
+	head, err := tickHistogram(db, websiteID, start, rollupFrom)
+	if err != nil {
+		return latencyPercentiles{}, err
+	}
+	middle, err := rollupHistogram(db, websiteID, rollupFrom, rollupTo)
+	if err != nil {
+		return latencyPercentiles{}, err
+	}
+	tail, err := tickHistogram(db, websiteID, rollupTo, time.Time{})
+	if err != nil {
+		return latencyPercentiles{}, err
+	}
+
+	return histogramPercentiles(head.Add(middle).Add(tail)), nil
+}
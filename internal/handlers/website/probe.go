@@ -0,0 +1,60 @@
+package website
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// probeFunc requests url once and reports why it isn't reachable, nil if it is
+type probeFunc func(ctx context.Context, url string) error
+
+// newProbe returns the probe CreateWebsite runs when CREATE_REACHABILITY_PROBE is
+// set. It only connects to public addresses, so the API can't be used to map
+// the network it runs in.
+func newProbe(timeout time.Duration) probeFunc {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%s is not a public address", host)
+			}
+			return nil
+		},
+	}
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+
+	return func(ctx context.Context, url string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("responded with status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast()
+}
@@ -0,0 +1,135 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/google/uuid"
+)
+
+// created is the part of CreateWebsite's payload the probe adds to
+type created struct {
+	ID       string   `json:"id"`
+	Warnings []string `json:"warnings"`
+}
+
+func TestCreateWebsiteReachabilityProbe(t *testing.T) {
+	tests := []struct {
+		name        string
+		probeErr    error
+		steps       []StepRequest
+		wantProbed  bool
+		wantWarning string
+	}{
+		{"reachable", nil, nil, true, ""},
+		{"unreachable", errors.New("dial tcp: lookup exmaple.com: no such host"), nil, true,
+			"was not reachable when the website was created: dial tcp: lookup exmaple.com: no such host"},
+		{"multi-step", errors.New("unreachable"), []StepRequest{{URL: "https://example.com/login"}, {URL: "https://example.com/dashboard"}}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, func(cfg *config.Config) {
+				cfg.ReachabilityProbe = true
+				cfg.ReachabilityTimeout = time.Second
+			})
+			var probed []string
+			h.probe = func(ctx context.Context, url string) error {
+				if _, ok := ctx.Deadline(); !ok {
+					t.Error("probe runs without a deadline")
+				}
+				probed = append(probed, url)
+				return tt.probeErr
+			}
+			user := createUser(t, h.db)
+			url := "https://example.com/" + uuid.New().String()
+
+			status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID, CreateWebsiteRequest{URL: url, Steps: tt.steps})
+			if status != http.StatusCreated {
+				t.Fatalf("status = %d (%s), want 201 whatever the probe found", status, resp.Error)
+			}
+			var got created
+			decodeData(t, resp, &got)
+			if n := countWebsites(t, h.db, user.ID); n != 1 {
+				t.Errorf("%d websites stored, want 1", n)
+			}
+
+			if tt.wantProbed && (len(probed) != 1 || probed[0] != url) {
+				t.Errorf("probed %v, want %s", probed, url)
+			} else if !tt.wantProbed && len(probed) != 0 {
+				t.Errorf("probed %v, want no probe", probed)
+			}
+			if tt.wantWarning == "" {
+				if len(got.Warnings) != 0 {
+					t.Errorf("warnings %v, want none", got.Warnings)
+				}
+				return
+			}
+			if len(got.Warnings) != 1 || got.Warnings[0] != url+" "+tt.wantWarning {
+				t.Errorf("warnings %v, want %q", got.Warnings, url+" "+tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestCreateWebsiteWithoutProbe(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.ReachabilityProbe = false })
+	if h.probe != nil {
+		t.Fatal("probe set up while CREATE_REACHABILITY_PROBE is off")
+	}
+
+	status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", createUser(t, h.db).ID,
+		CreateWebsiteRequest{URL: "https://unreachable.invalid"})
+	if status != http.StatusCreated {
+		t.Fatalf("status = %d (%s), want 201", status, resp.Error)
+	}
+	var got created
+	decodeData(t, resp, &got)
+	if got.Warnings != nil {
+		t.Errorf("warnings %v without a probe", got.Warnings)
+	}
+}
+
+func TestProbeOnlyReachesPublicAddresses(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { requests++ }))
+	t.Cleanup(server.Close)
+
+	err := newProbe(time.Second)(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1 is not a public address") {
+		t.Errorf("probing a loopback server: error = %v, want it refused", err)
+	}
+	if requests != 0 {
+		t.Errorf("loopback server got %d requests", requests)
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"192.168.1.1", false},
+		{"172.16.0.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := publicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package website
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// slaPeriodBounds returns the start and end of the calendar period containing now
+func slaPeriodBounds(period string, now time.Time) (time.Time, time.Time, bool) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case "day":
+		return day, day.AddDate(0, 0, 1), true
+	case "week":
+		// Weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7), true
+	case "month":
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
+
+// GetWebsiteSLA - GET /api/v1/website/:id/sla?period=month
+func (h *Handler) GetWebsiteSLA(c *gin.Context) {
+	period := c.DefaultQuery("period", h.cfg.SLAPeriod)
+	now := time.Now().UTC()
+	start, end, ok := slaPeriodBounds(period, now)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "period must be one of: day, week, month")
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	website, ok := findOwnedWebsite(c, db, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	if website.SLATarget <= 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "Website has no SLA target")
+		return
+	}
+
+	// Monthly periods outlast raw tick retention, so they read from the rollups
+	counts, source, err := countWindow(db, website.ID, now.Sub(start), now)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute SLA")
+		return
+	}
+
+	// With no checks yet there's no evidence of downtime
+	uptime := 100.0
+	if counts.Total > 0 {
+		uptime = counts.uptime(h.cfg.DegradedCountsAsUp)
+	}
+
+	// The error budget covers the whole period; downtime is extrapolated from the
+	// share of failed checks over the time elapsed so far
+	periodMinutes := end.Sub(start).Minutes()
+	elapsedMinutes := now.Sub(start).Minutes()
+	budgetMinutes := (100 - website.SLATarget) / 100 * periodMinutes
+	downtimeMinutes := (100 - uptime) / 100 * elapsedMinutes
+	remainingMinutes := budgetMinutes - downtimeMinutes
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"website_id":               website.ID,
+		"period":                   period,
+		"period_start":             start,
+		"period_end":               end,
+		"source":                   source,
+		"sla_target":               website.SLATarget,
+		"uptime_percentage":        uptime,
+		"total_checks":             counts.Total,
+		"error_budget_minutes":     budgetMinutes,
+		"downtime_minutes":         downtimeMinutes,
+		"remaining_budget_minutes": remainingMinutes,
+		"breached":                 remainingMinutes < 0,
+	})
+}
@@ -0,0 +1,106 @@
+package website
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"gorm.io/gorm"
+)
+
+type slaReport struct {
+	Source          string  `json:"source"`
+	UptimePct       float64 `json:"uptime_percentage"`
+	TotalChecks     int64   `json:"total_checks"`
+	BudgetMinutes   float64 `json:"error_budget_minutes"`
+	DowntimeMinutes float64 `json:"downtime_minutes"`
+	Breached        bool    `json:"breached"`
+}
+
+func getSLA(t *testing.T, h *Handler, website models.Website, period string) slaReport {
+	t.Helper()
+
+	status, resp := serve(t, h.GetWebsiteSLA, http.MethodGet, "/website/:id/sla",
+		"/website/"+website.ID+"/sla?period="+period, website.UserID, nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var report slaReport
+	decodeData(t, resp, &report)
+	return report
+}
+
+// seedTicks records good and bad checks of website made just now
+func seedTicks(t *testing.T, db *gorm.DB, website models.Website, good, bad int) {
+	t.Helper()
+
+	validator := createValidator(t, db)
+	for i := 0; i < good+bad; i++ {
+		status := models.StatusGood
+		if i >= good {
+			status = models.StatusBad
+		}
+		createTick(t, db, website.ID, validator.ID, status, 100, time.Now())
+	}
+}
+
+func TestGetWebsiteSLA(t *testing.T) {
+	if time.Since(time.Now().UTC().Truncate(24*time.Hour)) < time.Minute {
+		t.Skip("the day has barely started; there's no downtime to measure yet")
+	}
+
+	tests := []struct {
+		name         string
+		target       float64
+		good, bad    int
+		wantUptime   float64
+		wantBreached bool
+	}{
+		{"met", 95, 99, 1, 99, false},
+		{"met without checks", 99.9, 0, 0, 100, false},
+		{"breached", 99.999, 9, 1, 90, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			website := createWebsite(t, h.db, models.Website{SLATarget: tt.target})
+			seedTicks(t, h.db, website, tt.good, tt.bad)
+
+			report := getSLA(t, h, website, "day")
+			if !approx(report.UptimePct, tt.wantUptime) || report.Breached != tt.wantBreached {
+				t.Errorf("uptime = %.2f%%, breached = %v; want %.2f%%, %v",
+					report.UptimePct, report.Breached, tt.wantUptime, tt.wantBreached)
+			}
+			if report.TotalChecks != int64(tt.good+tt.bad) || report.Source != sourceTicks {
+				t.Errorf("counted %d checks from %s, want %d from %s", report.TotalChecks, report.Source, tt.good+tt.bad, sourceTicks)
+			}
+		})
+	}
+}
+
+func TestGetWebsiteSLAMonthReadsRollups(t *testing.T) {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if now.Sub(monthStart) < 2*time.Hour {
+		t.Skip("no whole hour of this month has been rolled up yet")
+	}
+
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db, models.Website{SLATarget: 99.9})
+
+	// The raw ticks of the month's first hour are gone; only its rollup is left
+	rollup := models.WebsiteTickRollup{WebsiteID: website.ID, Bucket: monthStart, Good: 60, Bad: 40, LatencyTotal: 10000}
+	if err := h.db.Create(&rollup).Error; err != nil {
+		t.Fatal(err)
+	}
+	seedTicks(t, h.db, website, 100, 0)
+
+	report := getSLA(t, h, website, "month")
+	if report.Source != sourceRollups || report.TotalChecks != 200 {
+		t.Fatalf("counted %d checks from %s, want 200 from %s", report.TotalChecks, report.Source, sourceRollups)
+	}
+	if !approx(report.UptimePct, 80) {
+		t.Errorf("uptime = %.2f%%, want 80%%", report.UptimePct)
+	}
+}
@@ -0,0 +1,339 @@
+package website
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/incidents"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Statuses shown on public status pages
+const (
+	PublicStatusUp       = "up"
+	PublicStatusDegraded = "degraded"
+	PublicStatusDown     = "down"
+	PublicStatusUnknown  = "unknown"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{1,62}[a-z0-9])?$`)
+
+// StatusPageWebsiteRequest selects a website for the status page
+type StatusPageWebsiteRequest struct {
+	WebsiteID string `json:"website_id" binding:"required"`
+	Name      string `json:"name" binding:"max=100"` // defaults to the website's host
+}
+
+// DTO for creating or replacing the caller's status page
+type StatusPageRequest struct {
+	Slug     string                     `json:"slug" binding:"required,min=3,max=64"`
+	Title    string                     `json:"title" binding:"required,max=200"`
+	Websites []StatusPageWebsiteRequest `json:"websites" binding:"max=50,dive"`
+}
+
+// StatusPageResponse is the owner's view of their status page configuration
+type StatusPageResponse struct {
+	Slug     string                     `json:"slug"`
+	Title    string                     `json:"title"`
+	Websites []StatusPageWebsiteRequest `json:"websites"`
+}
+
+// PublicWebsiteStatus is one website as shown on a public status page. It
+// deliberately leaves out ids, URLs and failure details.
+type PublicWebsiteStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"` // up, degraded, down or unknown
+	Uptime24h float64 `json:"uptime_24h"`
+	Uptime7d  float64 `json:"uptime_7d"`
+}
+
+// PublicStatusPage is the unauthenticated status page response
+type PublicStatusPage struct {
+	Title     string                `json:"title"`
+	Status    string                `json:"status"` // worst status across the listed websites
+	Websites  []PublicWebsiteStatus `json:"websites"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// GetStatusPage - GET /api/v1/status-page
+func (h *Handler) GetStatusPage(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var page models.StatusPage
+	if err := db.Preload("Websites", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position ASC")
+	}).Where("user_id = ?", userID).First(&page).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeStatusPageNotFound, "Status page not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, toStatusPageResponse(page))
+}
+
+// PutStatusPage - PUT /api/v1/status-page
+// Creates the caller's status page or replaces its slug, title and websites.
+func (h *Handler) PutStatusPage(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req StatusPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if !slugPattern.MatchString(req.Slug) {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeValidationFailed, "slug may only contain lowercase letters, digits and inner hyphens")
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	// Every listed website must be one of the caller's active websites
+	ids := make([]string, len(req.Websites))
+	for i, w := range req.Websites {
+		ids[i] = w.WebsiteID
+	}
+
+	var owned []models.Website
+	if len(ids) > 0 {
+		if err := db.Select("id", "url").
+			Where("id IN ? AND user_id = ?", ids, userID).
+			Find(&owned).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+			return
+		}
+	}
+
+	urls := make(map[string]string, len(owned))
+	for _, w := range owned {
+		urls[w.ID] = w.URL
+	}
+
+	entries := make([]models.StatusPageWebsite, 0, len(req.Websites))
+	seen := make(map[string]bool, len(req.Websites))
+	for i, w := range req.Websites {
+		websiteURL, ok := urls[w.WebsiteID]
+		if !ok {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeWebsiteNotFound, "Website not found: "+w.WebsiteID)
+			return
+		}
+		if seen[w.WebsiteID] {
+			continue
+		}
+		seen[w.WebsiteID] = true
+
+		name := w.Name
+		if name == "" {
+			name = hostOf(websiteURL)
+		}
+		entries = append(entries, models.StatusPageWebsite{
+			WebsiteID: w.WebsiteID,
+			Name:      name,
+			Position:  i,
+		})
+	}
+
+	var taken int64
+	if err := db.Model(&models.StatusPage{}).
+		Where("slug = ? AND user_id <> ?", req.Slug, userID).
+		Count(&taken).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		return
+	}
+	if taken > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, utils.CodeSlugTaken, "Slug is already in use")
+		return
+	}
+
+	var page models.StatusPage
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).First(&page).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			page = models.StatusPage{ID: uuid.New().String(), UserID: userID.(string)}
+		} else if err != nil {
+			return err
+		}
+
+		page.Slug = req.Slug
+		page.Title = req.Title
+		if err := tx.Omit("Websites").Save(&page).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("status_page_id = ?", page.ID).Delete(&models.StatusPageWebsite{}).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			entries[i].StatusPageID = page.ID
+		}
+		if len(entries) > 0 {
+			if err := tx.Create(&entries).Error; err != nil {
+				return err
+			}
+		}
+		page.Websites = entries
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to save status page")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, toStatusPageResponse(page))
+}
+
+// DeleteStatusPage - DELETE /api/v1/status-page
+func (h *Handler) DeleteStatusPage(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	var page models.StatusPage
+	if err := db.Where("user_id = ?", userID).First(&page).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeStatusPageNotFound, "Status page not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		}
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status_page_id = ?", page.ID).Delete(&models.StatusPageWebsite{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&page).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to delete status page")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"message": "Status page deleted",
+	})
+}
+
+// GetPublicStatusPage - GET /status/:slug
+// Public and unauthenticated: only names, statuses and uptime are exposed.
+func (h *Handler) GetPublicStatusPage(c *gin.Context) {
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	// The inner join applies Website's soft-delete scope, hiding deleted websites
+	var page models.StatusPage
+	if err := db.Preload("Websites", func(tx *gorm.DB) *gorm.DB {
+		return tx.InnerJoins("Website").Order(`"StatusPageWebsite".position ASC`)
+	}).Where("slug = ?", c.Param("slug")).First(&page).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeStatusPageNotFound, "Status page not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		}
+		return
+	}
+
+	now := time.Now()
+	response := PublicStatusPage{
+		Title:     page.Title,
+		Status:    PublicStatusUp,
+		Websites:  make([]PublicWebsiteStatus, 0, len(page.Websites)),
+		UpdatedAt: now,
+	}
+
+	for _, entry := range page.Websites {
+		if entry.Website == nil {
+			continue
+		}
+
+		status, err := h.publicStatus(db, *entry.Website)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute status")
+			return
+		}
+
+		day, _, err := countWindow(db, entry.WebsiteID, summaryWindows["24h"], now)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute uptime")
+			return
+		}
+		week, _, err := countWindow(db, entry.WebsiteID, summaryWindows["7d"], now)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute uptime")
+			return
+		}
+
+		response.Websites = append(response.Websites, PublicWebsiteStatus{
+			Name:      entry.Name,
+			Status:    status,
+			Uptime24h: day.uptime(h.cfg.DegradedCountsAsUp),
+			Uptime7d:  week.uptime(h.cfg.DegradedCountsAsUp),
+		})
+		if statusRank[status] > statusRank[response.Status] {
+			response.Status = status
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response)
+}
+
+// statusRank orders public statuses from best to worst
+var statusRank = map[string]int{
+	PublicStatusUp:       0,
+	PublicStatusUnknown:  1,
+	PublicStatusDegraded: 2,
+	PublicStatusDown:     3,
+}
+
+// publicStatus maps a website's consensus status to its public form
+func (h *Handler) publicStatus(db *gorm.DB, website models.Website) (string, error) {
+	status, reporting, err := incidents.CurrentStatus(db, website.ID)
+	if err != nil {
+		return "", err
+	}
+	if reporting == 0 || reporting < website.RequiredValidators(h.cfg.MinValidators) {
+		return PublicStatusUnknown, nil
+	}
+
+	switch status {
+	case models.StatusGood:
+		return PublicStatusUp, nil
+	case models.StatusDegraded:
+		return PublicStatusDegraded, nil
+	default:
+		return PublicStatusDown, nil
+	}
+}
+
+func toStatusPageResponse(page models.StatusPage) StatusPageResponse {
+	websites := make([]StatusPageWebsiteRequest, len(page.Websites))
+	for i, w := range page.Websites {
+		websites[i] = StatusPageWebsiteRequest{WebsiteID: w.WebsiteID, Name: w.Name}
+	}
+	return StatusPageResponse{Slug: page.Slug, Title: page.Title, Websites: websites}
+}
+
+// hostOf is the default display name for a website
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
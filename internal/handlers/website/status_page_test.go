@@ -0,0 +1,222 @@
+package website
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+)
+
+func putStatusPage(t *testing.T, h *Handler, userID string, req StatusPageRequest) (int, envelope) {
+	t.Helper()
+	return serve(t, h.PutStatusPage, http.MethodPut, "/status-page", "/status-page", userID, req)
+}
+
+func getPublicStatusPage(t *testing.T, h *Handler, slug string) (int, envelope) {
+	t.Helper()
+	return serve(t, h.GetPublicStatusPage, http.MethodGet, "/status/:slug", "/status/"+slug, "", nil)
+}
+
+// keys returns the sorted keys of a JSON object
+func keys(t *testing.T, raw json.RawMessage) []string {
+	t.Helper()
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		t.Fatalf("decoding %s: %v", raw, err)
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPutStatusPage(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	api := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://api.example.com/health"})
+	shop := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://shop.example.com"})
+
+	status, resp := putStatusPage(t, h, user.ID, StatusPageRequest{
+		Slug:  "acme",
+		Title: "Acme status",
+		Websites: []StatusPageWebsiteRequest{
+			{WebsiteID: shop.ID, Name: "Shop"},
+			{WebsiteID: api.ID},
+			{WebsiteID: shop.ID, Name: "Shop again"},
+		},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var page StatusPageResponse
+	decodeData(t, resp, &page)
+
+	// Listed in order, once each, with the host as the default name
+	want := []StatusPageWebsiteRequest{{WebsiteID: shop.ID, Name: "Shop"}, {WebsiteID: api.ID, Name: "api.example.com"}}
+	if page.Slug != "acme" || page.Title != "Acme status" || len(page.Websites) != len(want) {
+		t.Fatalf("saved %+v", page)
+	}
+	for i := range want {
+		if page.Websites[i] != want[i] {
+			t.Errorf("website %d = %+v, want %+v", i, page.Websites[i], want[i])
+		}
+	}
+
+	// Saving again replaces the page rather than adding one
+	status, resp = putStatusPage(t, h, user.ID, StatusPageRequest{Slug: "acme-inc", Title: "Acme", Websites: []StatusPageWebsiteRequest{{WebsiteID: api.ID, Name: "API"}}})
+	if status != http.StatusOK {
+		t.Fatalf("replacing: status = %d (%s), want 200", status, resp.Error)
+	}
+	var pages int64
+	h.db.Model(&models.StatusPage{}).Where("user_id = ?", user.ID).Count(&pages)
+	var listed int64
+	h.db.Model(&models.StatusPageWebsite{}).Count(&listed)
+	if pages != 1 || listed != 1 {
+		t.Errorf("got %d pages listing %d websites after replacing, want 1 and 1", pages, listed)
+	}
+}
+
+func TestPutStatusPageRejects(t *testing.T) {
+	h := newTestHandler(t)
+	user, other := createUser(t, h.db), createUser(t, h.db)
+	own := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	theirs := createWebsite(t, h.db, models.Website{UserID: other.ID})
+
+	if status, resp := putStatusPage(t, h, other.ID, StatusPageRequest{Slug: "taken", Title: "Theirs"}); status != http.StatusOK {
+		t.Fatalf("creating the other page: status = %d (%s)", status, resp.Error)
+	}
+
+	tests := []struct {
+		name     string
+		req      StatusPageRequest
+		wantCode int
+		wantErr  string
+	}{
+		{"someone else's website", StatusPageRequest{Slug: "mine", Title: "Mine", Websites: []StatusPageWebsiteRequest{{WebsiteID: own.ID}, {WebsiteID: theirs.ID}}},
+			http.StatusNotFound, utils.CodeWebsiteNotFound},
+		{"uppercase slug", StatusPageRequest{Slug: "Mine", Title: "Mine"}, http.StatusBadRequest, utils.CodeValidationFailed},
+		{"hyphen at the end", StatusPageRequest{Slug: "mine-", Title: "Mine"}, http.StatusBadRequest, utils.CodeValidationFailed},
+		{"taken slug", StatusPageRequest{Slug: "taken", Title: "Mine"}, http.StatusConflict, utils.CodeSlugTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := putStatusPage(t, h, user.ID, tt.req)
+			if status != tt.wantCode || resp.Code != tt.wantErr {
+				t.Errorf("got %d %s, want %d %s", status, resp.Code, tt.wantCode, tt.wantErr)
+			}
+		})
+	}
+
+	var pages int64
+	h.db.Model(&models.StatusPage{}).Where("user_id = ?", user.ID).Count(&pages)
+	if pages != 0 {
+		t.Errorf("rejected requests created %d pages", pages)
+	}
+}
+
+func TestPublicStatusPageNotFound(t *testing.T) {
+	h := newTestHandler(t)
+
+	if status, resp := getPublicStatusPage(t, h, "nobody"); status != http.StatusNotFound || resp.Code != utils.CodeStatusPageNotFound {
+		t.Errorf("got %d %s, want 404 %s", status, resp.Code, utils.CodeStatusPageNotFound)
+	}
+}
+
+func TestPublicStatusPageHidesDeletedWebsites(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	website := createWebsite(t, h.db, models.Website{UserID: user.ID})
+
+	if status, resp := putStatusPage(t, h, user.ID, StatusPageRequest{Slug: "acme", Title: "Acme", Websites: []StatusPageWebsiteRequest{{WebsiteID: website.ID}}}); status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	if err := h.db.Delete(&website).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	status, resp := getPublicStatusPage(t, h, "acme")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var page PublicStatusPage
+	decodeData(t, resp, &page)
+	if len(page.Websites) != 0 || page.Status != PublicStatusUp {
+		t.Errorf("page of a deleted website = %+v", page)
+	}
+}
+
+func TestPublicStatusPageExposesOnlySelectedData(t *testing.T) {
+	dbtest.RequirePostgres(t) // current status uses DISTINCT ON
+
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MinValidators = 1 })
+	user := createUser(t, h.db)
+	up := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://internal-api.example.com/health?token=s3cret"})
+	down := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://shop.example.com"})
+	hidden := createWebsite(t, h.db, models.Website{UserID: user.ID, URL: "https://hidden.example.com"})
+	validator := createValidator(t, h.db)
+
+	now := time.Now()
+	createTick(t, h.db, up.ID, validator.ID, models.StatusGood, 120, now)
+	createTick(t, h.db, hidden.ID, validator.ID, models.StatusBad, 0, now)
+	bad := createTick(t, h.db, down.ID, validator.ID, models.StatusBad, 0, now)
+	if err := h.db.Model(&bad).Update("detail", "TLS handshake to 10.0.0.12 failed").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	status, resp := putStatusPage(t, h, user.ID, StatusPageRequest{
+		Slug:     "acme",
+		Title:    "Acme status",
+		Websites: []StatusPageWebsiteRequest{{WebsiteID: up.ID, Name: "API"}, {WebsiteID: down.ID, Name: "Shop"}},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+
+	status, resp = getPublicStatusPage(t, h, "acme")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+
+	if got := strings.Join(keys(t, resp.Data), ","); got != "status,title,updated_at,websites" {
+		t.Errorf("page fields = %s", got)
+	}
+	var raw struct {
+		Websites []json.RawMessage `json:"websites"`
+	}
+	decodeData(t, resp, &raw)
+	for i, website := range raw.Websites {
+		if got := strings.Join(keys(t, website), ","); got != "name,status,uptime_24h,uptime_7d" {
+			t.Errorf("website %d fields = %s", i, got)
+		}
+	}
+	for _, secret := range []string{up.ID, down.ID, hidden.ID, user.ID, validator.ID, "internal-api", "s3cret", "hidden", "10.0.0.12", "TLS"} {
+		if strings.Contains(string(resp.Data), secret) {
+			t.Errorf("public page exposes %q: %s", secret, resp.Data)
+		}
+	}
+
+	var page PublicStatusPage
+	decodeData(t, resp, &page)
+	want := []PublicWebsiteStatus{
+		{Name: "API", Status: PublicStatusUp, Uptime24h: 100, Uptime7d: 100},
+		{Name: "Shop", Status: PublicStatusDown, Uptime24h: 0, Uptime7d: 0},
+	}
+	if page.Title != "Acme status" || page.Status != PublicStatusDown || len(page.Websites) != len(want) {
+		t.Fatalf("page = %+v", page)
+	}
+	for i := range want {
+		if page.Websites[i] != want[i] {
+			t.Errorf("website %d = %+v, want %+v", i, page.Websites[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package website
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+func TestWebsiteStatusLatestTicks(t *testing.T) {
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db, models.Website{})
+	other := createWebsite(t, h.db, models.Website{UserID: website.UserID})
+	validator := createValidator(t, h.db)
+
+	// More ticks than are returned, inserted out of order, plus another website's
+	// ticks newer than all of them
+	start := time.Now().Add(-time.Hour)
+	total := fullTicksLimit + 30
+	for i := 0; i < total; i++ {
+		n := (i * 7) % total // a permutation of 0..total-1
+		createTick(t, h.db, website.ID, validator.ID, models.StatusGood, float64(n), start.Add(time.Duration(n)*time.Second))
+	}
+	for i := 0; i < 5; i++ {
+		createTick(t, h.db, other.ID, validator.ID, models.StatusBad, -1, time.Now())
+	}
+
+	got, err := h.WebsiteStatus(context.Background(), website.UserID, website.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Ticks) != fullTicksLimit {
+		t.Fatalf("%d ticks, want the latest %d", len(got.Ticks), fullTicksLimit)
+	}
+	for i, tick := range got.Ticks {
+		// Latency records each tick's position, newest first
+		if want := float64(total - 1 - i); tick.WebsiteID != website.ID || tick.Latency != want {
+			t.Fatalf("tick %d is #%.0f of %s, want #%.0f of %s", i, tick.Latency, tick.WebsiteID, want, website.ID)
+		}
+	}
+}
+
+func TestLatestTicksQueryUsesIndex(t *testing.T) {
+	h := newTestHandler(t)
+	if h.db.Dialector.Name() != "sqlite" {
+		t.Skip("query plans are only checked on SQLite, where they don't depend on table statistics")
+	}
+
+	var plan []struct {
+		Detail string
+	}
+	if err := h.db.Raw(`EXPLAIN QUERY PLAN SELECT * FROM "WebsiteTick" WHERE website_id = ? ORDER BY created_at DESC LIMIT 100`, "w1").
+		Scan(&plan).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var details []string
+	for _, step := range plan {
+		details = append(details, step.Detail)
+	}
+	joined := strings.Join(details, "; ")
+	if !strings.Contains(joined, "idx_website_tick_website_created") {
+		t.Errorf("plan %q doesn't use the (website_id, created_at) index", joined)
+	}
+	// The index is already in created_at DESC order, so no sort step is needed
+	if strings.Contains(joined, "TEMP B-TREE") {
+		t.Errorf("plan %q sorts the ticks", joined)
+	}
+}
@@ -0,0 +1,261 @@
+package website
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/incidents"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/rollups"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// tickCounts aggregates ticks by status over a time range
+type tickCounts struct {
+	Total        int64
+	Good         int64
+	Degraded     int64
+	Bad          int64
+	LatencyTotal float64
+}
+
+// uptime returns the percentage of checks that were up, or 0 with no checks
+func (t tickCounts) uptime(degradedCountsAsUp bool) float64 {
+	if t.Total == 0 {
+		return 0
+	}
+	up := t.Good
+	if degradedCountsAsUp {
+		up += t.Degraded
+	}
+	return float64(up) / float64(t.Total) * 100
+}
+
+// addStatus adds count checks with the given status and summed latency
+func (t *tickCounts) addStatus(status string, count int64, latencyTotal float64) {
+	t.Total += count
+	t.LatencyTotal += latencyTotal
+	switch status {
+	case models.StatusGood:
+		t.Good += count
+	case models.StatusDegraded:
+		t.Degraded += count
+	default:
+		// Anything unrecognised is treated as a failed check
+		t.Bad += count
+	}
+}
+
+// add merges other into t
+func (t *tickCounts) add(other tickCounts) {
+	t.Total += other.Total
+	t.Good += other.Good
+	t.Degraded += other.Degraded
+	t.Bad += other.Bad
+	t.LatencyTotal += other.LatencyTotal
+}
+
+// countTicks groups a website's ticks created at or after since by status
+func countTicks(db *gorm.DB, websiteID string, since time.Time) (tickCounts, error) {
+	return countTicksBetween(db, websiteID, since, time.Time{})
+}
+
+// countTicksBetween groups a website's ticks created in [from, to) by status; a
+// zero to leaves the range open-ended
+func countTicksBetween(db *gorm.DB, websiteID string, from, to time.Time) (tickCounts, error) {
+	var rows []struct {
+		Status       string
+		Count        int64
+		LatencyTotal float64
+	}
+	query := db.Model(&models.WebsiteTick{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(latency), 0) AS latency_total").
+		Where("website_id = ? AND created_at >= ?", websiteID, from)
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+	err := query.Group("status").Scan(&rows).Error
+	if err != nil {
+		return tickCounts{}, err
+	}
+
+	var counts tickCounts
+	for _, row := range rows {
+		counts.addStatus(row.Status, row.Count, row.LatencyTotal)
+	}
+	return counts, nil
+}
+
+// countRollups sums a website's hourly rollups with buckets in [from, to)
+func countRollups(db *gorm.DB, websiteID string, from, to time.Time) (tickCounts, error) {
+	var counts tickCounts
+	err := db.Model(&models.WebsiteTickRollup{}).
+		Select("COALESCE(SUM(good), 0) AS good, COALESCE(SUM(degraded), 0) AS degraded, "+
+			"COALESCE(SUM(bad), 0) AS bad, COALESCE(SUM(latency_total), 0) AS latency_total").
+		Where("website_id = ? AND bucket >= ? AND bucket < ?", websiteID, from, to).
+		Scan(&counts).Error
+	counts.Total = counts.Good + counts.Degraded + counts.Bad
+	return counts, err
+}
+
+// summaryWindows are the uptime windows the summary endpoint accepts. Windows
+// longer than a day are served mostly from hourly rollups.
+var summaryWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+const rawWindowLimit = 24 * time.Hour
+
+// Where window counts came from
+const (
+	sourceTicks   = "ticks"
+	sourceRollups = "rollups"
+)
+
+// rollupSpan picks the whole hours of the window [start, now) to read from
+// rollups. ok is false when no rolled-up hour falls inside, and the window is
+// read from raw ticks alone.
+func rollupSpan(db *gorm.DB, start, now time.Time) (from, to time.Time, ok bool, err error) {
+	covered, err := rollups.CoveredUntil(db)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	from = start.Truncate(rollups.Bucket)
+	if from.Before(start) {
+		from = from.Add(rollups.Bucket)
+	}
+	to = now.Truncate(rollups.Bucket)
+	if covered.Before(to) {
+		to = covered
+	}
+	return from, to, to.After(from), nil
+}
+
+// countWindow counts a website's checks over the window ending at now. Long
+// windows read whole hours from the rollups and only the partial hours at
+// either end, plus anything not rolled up yet, from raw ticks.
+func countWindow(db *gorm.DB, websiteID string, window time.Duration, now time.Time) (tickCounts, string, error) {
+	start := now.Add(-window)
+	if window <= rawWindowLimit {
+		counts, err := countTicksBetween(db, websiteID, start, time.Time{})
+		return counts, sourceTicks, err
+	}
+
+	rollupFrom, rollupTo, ok, err := rollupSpan(db, start, now)
+	if err != nil {
+		return tickCounts{}, "", err
+	}
+	if !ok {
+		counts, err := countTicksBetween(db, websiteID, start, time.Time{})
+		return counts, sourceTicks, err
+	}
+
+	counts, err := countTicksBetween(db, websiteID, start, rollupFrom)
+	if err != nil {
+		return tickCounts{}, "", err
+	}
+	middle, err := countRollups(db, websiteID, rollupFrom, rollupTo)
+	if err != nil {
+		return tickCounts{}, "", err
+	}
+	tail, err := countTicksBetween(db, websiteID, rollupTo, time.Time{})
+	if err != nil {
+		return tickCounts{}, "", err
+	}
+
+	counts.add(middle)
+	counts.add(tail)
+	return counts, sourceRollups, nil
+}
+
+// findOwnedWebsite loads an active website owned by the caller, writing a 404/500
+// response and returning false when it can't
+func findOwnedWebsite(c *gin.Context, db *gorm.DB, websiteID string) (models.Website, bool) {
+	userID, _ := c.Get("userID")
+
+	var website models.Website
+	result := db.Where("id = ? AND user_id = ?", websiteID, userID).First(&website)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			utils.ErrorResponse(c, http.StatusNotFound, utils.CodeWebsiteNotFound, "Website not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+		}
+		return website, false
+	}
+	return website, true
+}
+
+// GetWebsiteSummary - GET /api/v1/website/:id/summary?window=24h
+func (h *Handler) GetWebsiteSummary(c *gin.Context) {
+	windowName := c.DefaultQuery("window", "24h")
+	window, ok := summaryWindows[windowName]
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "window must be one of: 24h, 7d, 30d, 90d")
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	website, ok := findOwnedWebsite(c, db, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	counts, source, err := countWindow(db, website.ID, window, time.Now())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute summary")
+		return
+	}
+
+	degradedPct := 0.0
+	avgLatency := 0.0
+	if counts.Total > 0 {
+		degradedPct = float64(counts.Degraded) / float64(counts.Total) * 100
+		avgLatency = counts.LatencyTotal / float64(counts.Total)
+	}
+
+	percentiles, err := windowPercentiles(db, website.ID, window, time.Now())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute latency percentiles")
+		return
+	}
+
+	currentStatus, reporting, err := incidents.CurrentStatus(db, website.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to compute current status")
+		return
+	}
+
+	// Don't trust a status backed by too few independent validators
+	required := website.RequiredValidators(h.cfg.MinValidators)
+	if reporting < required {
+		currentStatus = models.StatusInsufficientCoverage
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"website_id":             website.ID,
+		"window":                 windowName,
+		"source":                 source,
+		"current_status":         currentStatus,
+		"validators_reporting":   reporting,
+		"min_validators":         required,
+		"total_checks":           counts.Total,
+		"good":                   counts.Good,
+		"degraded":               counts.Degraded,
+		"bad":                    counts.Bad,
+		"uptime_percentage":      counts.uptime(h.cfg.DegradedCountsAsUp),
+		"degraded_percentage":    degradedPct,
+		"uptime_counts_degraded": h.cfg.DegradedCountsAsUp,
+		"avg_latency":            avgLatency,
+		"latency_percentiles":    percentiles,
+	})
+}
@@ -0,0 +1,303 @@
+package website
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/rollups"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"gorm.io/gorm"
+)
+
+func TestCountTicksMixedStatuses(t *testing.T) {
+	db := dbtest.Open(t)
+	website := createWebsite(t, db, models.Website{})
+	validator := createValidator(t, db)
+
+	now := time.Now()
+	for _, tick := range []struct {
+		status  string
+		latency float64
+		age     time.Duration
+	}{
+		{models.StatusGood, 100, time.Minute},
+		{models.StatusGood, 200, 2 * time.Minute},
+		{models.StatusGood, 300, 3 * time.Minute},
+		{models.StatusDegraded, 2000, 4 * time.Minute},
+		{models.StatusBad, 0, 5 * time.Minute},
+		{"Down", 0, 6 * time.Minute},          // written before Degraded existed
+		{models.StatusBad, 0, 48 * time.Hour}, // outside the window
+	} {
+		createTick(t, db, website.ID, validator.ID, tick.status, tick.latency, now.Add(-tick.age))
+	}
+
+	counts, err := countTicks(db, website.ID, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tickCounts{Total: 6, Good: 3, Degraded: 1, Bad: 2, LatencyTotal: 2600}
+	if counts != want {
+		t.Fatalf("counts = %+v, want %+v", counts, want)
+	}
+
+	if got := counts.uptime(true); !approx(got, 4.0/6*100) {
+		t.Errorf("uptime counting degraded as up = %.2f, want %.2f", got, 4.0/6*100)
+	}
+	if got := counts.uptime(false); !approx(got, 50) {
+		t.Errorf("uptime counting degraded as down = %.2f, want 50", got)
+	}
+}
+
+func TestUptimeWithoutChecks(t *testing.T) {
+	if got := (tickCounts{}).uptime(true); got != 0 {
+		t.Errorf("uptime = %v, want 0", got)
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestGetWebsiteSummaryCoverage(t *testing.T) {
+	dbtest.RequirePostgres(t) // current status uses DISTINCT ON
+
+	two, three := 2, 3
+	tests := []struct {
+		name          string
+		minValidators *int
+		reporting     int
+		want          string
+	}{
+		{"met by the default", nil, 2, models.StatusGood},
+		{"unmet by the default", nil, 1, models.StatusInsufficientCoverage},
+		{"met by the override", &two, 2, models.StatusGood},
+		{"unmet by the override", &three, 2, models.StatusInsufficientCoverage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, func(cfg *config.Config) { cfg.MinValidators = 2 })
+			website := createWebsite(t, h.db, models.Website{MinValidators: tt.minValidators})
+			for i := 0; i < tt.reporting; i++ {
+				createTick(t, h.db, website.ID, createValidator(t, h.db).ID, models.StatusGood, 100, time.Now().Add(-time.Minute))
+			}
+
+			status, resp := serve(t, h.GetWebsiteSummary, http.MethodGet, "/website/:id/summary",
+				"/website/"+website.ID+"/summary", website.UserID, nil)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			var summary struct {
+				CurrentStatus       string `json:"current_status"`
+				ValidatorsReporting int    `json:"validators_reporting"`
+				MinValidators       int    `json:"min_validators"`
+			}
+			decodeData(t, resp, &summary)
+			if summary.CurrentStatus != tt.want || summary.ValidatorsReporting != tt.reporting {
+				t.Errorf("current status = %s from %d validators (%d required), want %s from %d",
+					summary.CurrentStatus, summary.ValidatorsReporting, summary.MinValidators, tt.want, tt.reporting)
+			}
+		})
+	}
+}
+
+// rollUp stores hourly rollups of website's ticks in buckets before until, the
+// way rollups.Refresh would
+func rollUp(t *testing.T, db *gorm.DB, websiteID string, until time.Time) {
+	t.Helper()
+
+	var ticks []models.WebsiteTick
+	if err := db.Where("website_id = ? AND created_at < ?", websiteID, until).Find(&ticks).Error; err != nil {
+		t.Fatal(err)
+	}
+	buckets := make(map[time.Time]*models.WebsiteTickRollup)
+	for _, tick := range ticks {
+		bucket := tick.CreatedAt.UTC().Truncate(rollups.Bucket)
+		rollup, ok := buckets[bucket]
+		if !ok {
+			rollup = &models.WebsiteTickRollup{WebsiteID: websiteID, Bucket: bucket,
+				LatencyHistogram: make([]int64, len(rollups.LatencyBounds)+1)}
+			buckets[bucket] = rollup
+		}
+		switch tick.Status {
+		case models.StatusGood:
+			rollup.Good++
+		case models.StatusDegraded:
+			rollup.Degraded++
+		default:
+			rollup.Bad++
+		}
+		rollup.LatencyTotal += tick.Latency
+		bucketed := sort.Search(len(rollups.LatencyBounds), func(i int) bool { return tick.Latency < rollups.LatencyBounds[i] })
+		rollup.LatencyHistogram[bucketed]++
+	}
+	for _, rollup := range buckets {
+		if err := db.Create(rollup).Error; err != nil {
+			t.Fatalf("creating rollup: %v", err)
+		}
+	}
+}
+
+func TestCountWindowNamedWindows(t *testing.T) {
+	db := dbtest.Open(t)
+	website := createWebsite(t, db, models.Website{})
+	validator := createValidator(t, db)
+	now := time.Now().UTC()
+
+	// Ticks spanning the longest window, including some just inside and just
+	// outside each window's start, where only part of the hour is in the window
+	const day = 24 * time.Hour
+	ages := map[time.Duration]string{
+		10 * time.Minute:        models.StatusGood, // not rolled up yet
+		3 * time.Hour:           models.StatusBad,
+		day - 10*time.Minute:    models.StatusGood,
+		day + 10*time.Minute:    models.StatusDegraded,
+		3 * day:                 models.StatusGood,
+		7*day - 10*time.Minute:  models.StatusBad,
+		7*day + 10*time.Minute:  models.StatusGood,
+		20 * day:                models.StatusGood,
+		30*day - 10*time.Minute: models.StatusDegraded,
+		30*day + 10*time.Minute: models.StatusBad,
+		60 * day:                models.StatusGood,
+		90*day - 10*time.Minute: models.StatusGood,
+		90*day + 10*time.Minute: models.StatusBad,
+		120 * day:               models.StatusBad,
+	}
+	for age, status := range ages {
+		createTick(t, db, website.ID, validator.ID, status, 100, now.Add(-age))
+	}
+	// The latest hour or so isn't rolled up yet and comes from raw ticks
+	rollUp(t, db, website.ID, now.Truncate(rollups.Bucket).Add(-rollups.Bucket))
+
+	for _, name := range []string{"24h", "7d", "30d", "90d"} {
+		t.Run(name, func(t *testing.T) {
+			window := summaryWindows[name]
+
+			var want tickCounts
+			for age, status := range ages {
+				if age <= window {
+					want.addStatus(status, 1, 100)
+				}
+			}
+			wantSource := sourceRollups
+			if window <= rawWindowLimit {
+				wantSource = sourceTicks
+			}
+
+			counts, source, err := countWindow(db, website.ID, window, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if counts != want {
+				t.Errorf("counts = %+v, want %+v", counts, want)
+			}
+			if source != wantSource {
+				t.Errorf("source = %s, want %s", source, wantSource)
+			}
+		})
+	}
+}
+
+func TestWindowPercentiles(t *testing.T) {
+	dbtest.RequirePostgres(t) // percentiles use percentile_cont and FILTER
+
+	db := dbtest.Open(t)
+	website := createWebsite(t, db, models.Website{})
+	validator := createValidator(t, db)
+	now := time.Now().UTC()
+
+	// 100 checks at 1, 2, ..., 100ms spread over the last 20 hours
+	for i := 1; i <= 100; i++ {
+		createTick(t, db, website.ID, validator.ID, models.StatusGood, float64(i), now.Add(-time.Duration(i)*12*time.Minute))
+	}
+
+	t.Run("exact from ticks", func(t *testing.T) {
+		got, err := windowPercentiles(db, website.ID, summaryWindows["24h"], now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// percentile_cont interpolates between the two closest checks
+		want := latencyPercentiles{P50: 50.5, P90: 90.1, P95: 95.05, P99: 99.01}
+		if !approx(got.P50, want.P50) || !approx(got.P90, want.P90) || !approx(got.P95, want.P95) || !approx(got.P99, want.P99) {
+			t.Errorf("percentiles = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("estimated from rollups", func(t *testing.T) {
+		rollUp(t, db, website.ID, now.Truncate(rollups.Bucket).Add(-rollups.Bucket))
+
+		got, err := windowPercentiles(db, website.ID, summaryWindows["7d"], now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Each estimate lands in the LatencyBounds bucket holding the exact value
+		for _, tt := range []struct {
+			name         string
+			got          float64
+			lower, upper float64
+		}{
+			{"p50", got.P50, 50, 75},
+			{"p90", got.P90, 75, 100},
+			{"p95", got.P95, 75, 100},
+			{"p99", got.P99, 75, 100},
+		} {
+			if tt.got < tt.lower || tt.got > tt.upper {
+				t.Errorf("%s = %v, want within [%v, %v]", tt.name, tt.got, tt.lower, tt.upper)
+			}
+		}
+		if got.P50 > got.P90 || got.P90 > got.P95 || got.P95 > got.P99 {
+			t.Errorf("percentiles out of order: %+v", got)
+		}
+	})
+
+	t.Run("no checks", func(t *testing.T) {
+		other := createWebsite(t, db, models.Website{})
+		got, err := windowPercentiles(db, other.ID, summaryWindows["24h"], now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != (latencyPercentiles{}) {
+			t.Errorf("percentiles = %+v, want zeros", got)
+		}
+	})
+}
+
+func TestCountWindowWithoutRollups(t *testing.T) {
+	db := dbtest.Open(t)
+	website := createWebsite(t, db, models.Website{})
+	validator := createValidator(t, db)
+	now := time.Now().UTC()
+
+	createTick(t, db, website.ID, validator.ID, models.StatusGood, 100, now.Add(-time.Hour))
+	createTick(t, db, website.ID, validator.ID, models.StatusBad, 0, now.Add(-5*24*time.Hour))
+	createTick(t, db, website.ID, validator.ID, models.StatusBad, 0, now.Add(-8*24*time.Hour))
+
+	counts, source, err := countWindow(db, website.ID, summaryWindows["7d"], now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (tickCounts{Total: 2, Good: 1, Bad: 1, LatencyTotal: 100}); counts != want {
+		t.Errorf("counts = %+v, want %+v", counts, want)
+	}
+	if source != sourceTicks {
+		t.Errorf("source = %s, want %s before anything is rolled up", source, sourceTicks)
+	}
+}
+
+func TestGetWebsiteSummaryRejectsUnsupportedWindows(t *testing.T) {
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db, models.Website{})
+
+	for _, window := range []string{"1h", "14d", "1y", "24H"} {
+		status, resp := serve(t, h.GetWebsiteSummary, http.MethodGet, "/website/:id/summary",
+			"/website/"+website.ID+"/summary?window="+window, website.UserID, nil)
+		if status != http.StatusBadRequest || resp.Code != utils.CodeInvalidRequest {
+			t.Errorf("window %s: got %d %s, want 400 %s", window, status, resp.Code, utils.CodeInvalidRequest)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package website
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TickResponse is one check result of a website
+type TickResponse struct {
+	ID          string    `json:"id"`
+	ValidatorID string    `json:"validator_id"`
+	Status      string    `json:"status"`
+	Latency     float64   `json:"latency"` // milliseconds
+	Detail      string    `json:"detail"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListTicks - GET /api/v1/website/:id/ticks?status=&validator_id=&from=&to=&page=&page_size=
+// Pages through a website's raw check results, most recent first.
+func (h *Handler) ListTicks(c *gin.Context) {
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	db, cancel := database.WithTimeout(c.Request.Context(), h.db, h.cfg.DBQueryTimeout)
+	defer cancel()
+
+	website, ok := findOwnedWebsite(c, db, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	query := db.Model(&models.WebsiteTick{}).Where("website_id = ?", website.ID)
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.StatusGood, models.StatusDegraded, models.StatusBad:
+		query = query.Where("status = ?", status)
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "status must be Good, Degraded or Bad")
+		return
+	}
+	if validatorID := c.Query("validator_id"); validatorID != "" {
+		query = query.Where("validator_id = ?", validatorID)
+	}
+
+	for param, clause := range map[string]string{"from": "created_at >= ?", "to": "created_at <= ?"} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, param+" must be an RFC3339 timestamp")
+				return
+			}
+			query = query.Where(clause, t)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to count ticks")
+		return
+	}
+
+	var ticks []models.WebsiteTick
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).
+		Limit(page.PageSize).
+		Find(&ticks).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to fetch ticks")
+		return
+	}
+
+	response := make([]TickResponse, len(ticks))
+	for i, tick := range ticks {
+		response[i] = TickResponse{
+			ID:          tick.ID,
+			ValidatorID: tick.ValidatorID,
+			Status:      tick.Status,
+			Latency:     tick.Latency,
+			Detail:      tick.Detail,
+			CreatedAt:   tick.CreatedAt,
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"ticks":     response,
+		"total":     total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
@@ -0,0 +1,110 @@
+package website
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/google/uuid"
+)
+
+type ticksPage struct {
+	Ticks    []TickResponse `json:"ticks"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+func TestListTicksFilters(t *testing.T) {
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db, models.Website{})
+	first, second := createValidator(t, h.db), createValidator(t, h.db)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Five checks a minute apart, oldest first
+	ticks := []models.WebsiteTick{
+		createTick(t, h.db, website.ID, first.ID, models.StatusGood, 100, base),
+		createTick(t, h.db, website.ID, second.ID, models.StatusBad, 0, base.Add(time.Minute)),
+		createTick(t, h.db, website.ID, first.ID, models.StatusDegraded, 2500, base.Add(2*time.Minute)),
+		createTick(t, h.db, website.ID, second.ID, models.StatusGood, 90, base.Add(3*time.Minute)),
+		createTick(t, h.db, website.ID, first.ID, models.StatusBad, 0, base.Add(4*time.Minute)),
+	}
+	// Another website's check never shows up
+	createTick(t, h.db, createWebsite(t, h.db, models.Website{}).ID, first.ID, models.StatusGood, 100, base)
+
+	at := func(minutes int) string {
+		return url.QueryEscape(base.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339))
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		want      []int // indexes into ticks, most recent first
+		wantTotal int64
+	}{
+		{"everything", "", []int{4, 3, 2, 1, 0}, 5},
+		{"status", "status=Bad", []int{4, 1}, 2},
+		{"validator", "validator_id=" + second.ID, []int{3, 1}, 2},
+		{"from", "from=" + at(3), []int{4, 3}, 2},
+		{"to", "to=" + at(1), []int{1, 0}, 2},
+		{"time range", "from=" + at(1) + "&to=" + at(3), []int{3, 2, 1}, 3},
+		{"combined", "status=Good&validator_id=" + first.ID, []int{0}, 1},
+		{"first page", "page_size=2", []int{4, 3}, 5},
+		{"last page", "page_size=2&page=3", []int{0}, 5},
+		{"past the end", "page_size=2&page=4", []int{}, 5},
+		{"unknown validator", "validator_id=" + uuid.New().String(), []int{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.ListTicks, http.MethodGet, "/website/:id/ticks",
+				"/website/"+website.ID+"/ticks?"+tt.query, website.UserID, nil)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			var got ticksPage
+			decodeData(t, resp, &got)
+
+			if got.Total != tt.wantTotal || len(got.Ticks) != len(tt.want) {
+				t.Fatalf("got %d of %d ticks, want %d of %d", len(got.Ticks), got.Total, len(tt.want), tt.wantTotal)
+			}
+			for i, index := range tt.want {
+				want := ticks[index]
+				if tick := got.Ticks[i]; tick.ID != want.ID || tick.ValidatorID != want.ValidatorID || tick.Status != want.Status || tick.Latency != want.Latency {
+					t.Errorf("tick %d = %+v, want %+v", i, tick, want)
+				}
+			}
+		})
+	}
+}
+
+func TestListTicksErrors(t *testing.T) {
+	h := newTestHandler(t)
+	website := createWebsite(t, h.db, models.Website{})
+
+	tests := []struct {
+		name       string
+		query      string
+		userID     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"another user's website", "", createUser(t, h.db).ID, http.StatusNotFound, utils.CodeWebsiteNotFound},
+		{"unknown status", "status=Down", website.UserID, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"malformed from", "from=yesterday", website.UserID, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"malformed to", "to=2024-05-01", website.UserID, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"page size too large", "page_size=101", website.UserID, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"page zero", "page=0", website.UserID, http.StatusBadRequest, utils.CodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.ListTicks, http.MethodGet, "/website/:id/ticks",
+				"/website/"+website.ID+"/ticks?"+tt.query, tt.userID, nil)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("status = %d, code = %s, want %d %s", status, resp.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
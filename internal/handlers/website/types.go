@@ -1 +1,79 @@
 package website
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+)
+
+// AssertionRequest is a response rule supplied when creating a website
+type AssertionRequest struct {
+	Type       string `json:"type" binding:"required,oneof=jsonpath regex header"`
+	Expression string `json:"expression" binding:"required,max=500"`
+	Expected   string `json:"expected" binding:"max=500"`
+}
+
+// headerName matches the characters allowed in an HTTP header field name (RFC 9110 token)
+var headerName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// toAssertions validates the requested rules and converts them to models
+func toAssertions(reqs []AssertionRequest) ([]models.Assertion, error) {
+	assertions := make([]models.Assertion, 0, len(reqs))
+	for i, a := range reqs {
+		switch a.Type {
+		case models.AssertionRegex:
+			if _, err := regexp.Compile(a.Expression); err != nil {
+				return nil, fmt.Errorf("assertion %d: invalid regex: %w", i, err)
+			}
+		case models.AssertionHeader:
+			if !headerName.MatchString(a.Expression) {
+				return nil, fmt.Errorf("assertion %d: invalid header name %q", i, a.Expression)
+			}
+		}
+		assertions = append(assertions, models.Assertion{
+			Type:       a.Type,
+			Expression: a.Expression,
+			Expected:   a.Expected,
+		})
+	}
+	return assertions, nil
+}
+
+// StepRequest is one request of a multi-step check supplied when creating a website
+type StepRequest struct {
+	Method       string             `json:"method" binding:"omitempty,oneof=GET POST PUT PATCH DELETE HEAD"`
+	URL          string             `json:"url" binding:"required,url"`
+	Headers      map[string]string  `json:"headers" binding:"omitempty,max=20"`
+	Body         string             `json:"body" binding:"max=10000"`
+	ExpectStatus int                `json:"expect_status" binding:"omitempty,min=100,max=599"`
+	Assertions   []AssertionRequest `json:"assertions" binding:"omitempty,max=10,dive"`
+}
+
+// toSteps validates the requested steps and converts them to models
+func toSteps(reqs []StepRequest) ([]models.CheckStep, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	steps := make([]models.CheckStep, 0, len(reqs))
+	for i, s := range reqs {
+		assertions, err := toAssertions(s.Assertions)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		method := s.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		steps = append(steps, models.CheckStep{
+			Method:       method,
+			URL:          s.URL,
+			Headers:      s.Headers,
+			Body:         s.Body,
+			ExpectStatus: s.ExpectStatus,
+			Assertions:   assertions,
+		})
+	}
+	return steps, nil
+}
@@ -0,0 +1,635 @@
+package website
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/events"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestHandler returns a handler over an empty test database. configure
+// adjusts the default config.
+func newTestHandler(t *testing.T, configure ...func(*config.Config)) *Handler {
+	t.Helper()
+
+	cfg := config.Load()
+	cfg.ReachabilityProbe = false
+	for _, c := range configure {
+		c(cfg)
+	}
+	bus := events.NewMemoryBus()
+	t.Cleanup(func() { bus.Close() })
+	return NewHandler(dbtest.Open(t), cfg, bus)
+}
+
+// envelope is utils.Response with the payload left undecoded
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Code    string          `json:"code"`
+	Details json.RawMessage `json:"details"`
+}
+
+// serve routes one request for target through handler, mounted at route, as
+// userID (anonymous when empty), sending body as JSON unless it is nil
+func serve(t *testing.T, handler gin.HandlerFunc, method, route, target, userID string, body interface{}) (int, envelope) {
+	t.Helper()
+
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		handler(c)
+	})
+
+	var reader bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader.Reset(data)
+	}
+	req := httptest.NewRequest(method, target, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %s response %q: %v", target, w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// decodeData unmarshals the payload of a successful response into v
+func decodeData(t *testing.T, resp envelope, v interface{}) {
+	t.Helper()
+
+	if !resp.Success {
+		t.Fatalf("request failed: %s (%s)", resp.Error, resp.Code)
+	}
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		t.Fatalf("decoding data %s: %v", resp.Data, err)
+	}
+}
+
+func countWebsites(t *testing.T, db *gorm.DB, userID string) int64 {
+	t.Helper()
+
+	var n int64
+	if err := db.Model(&models.Website{}).Where("user_id = ?", userID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// createUser stores a user with a unique email
+func createUser(t *testing.T, db *gorm.DB) models.User {
+	t.Helper()
+
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+// createWebsite stores website, owned by a new user unless it names one
+func createWebsite(t *testing.T, db *gorm.DB, website models.Website) models.Website {
+	t.Helper()
+
+	if website.UserID == "" {
+		website.UserID = createUser(t, db).ID
+	}
+	website.ID = uuid.New().String()
+	if website.URL == "" {
+		website.URL = "https://example.com"
+	}
+	if err := db.Create(&website).Error; err != nil {
+		t.Fatalf("creating website: %v", err)
+	}
+	return website
+}
+
+func createValidator(t *testing.T, db *gorm.DB) models.Validator {
+	t.Helper()
+
+	validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+	if err := db.Create(&validator).Error; err != nil {
+		t.Fatalf("creating validator: %v", err)
+	}
+	return validator
+}
+
+func createTick(t *testing.T, db *gorm.DB, websiteID, validatorID, status string, latency float64, at time.Time) models.WebsiteTick {
+	t.Helper()
+
+	tick := models.WebsiteTick{
+		ID:          uuid.New().String(),
+		WebsiteID:   websiteID,
+		ValidatorID: validatorID,
+		Status:      status,
+		Latency:     latency,
+		CreatedAt:   at,
+	}
+	if err := db.Create(&tick).Error; err != nil {
+		t.Fatalf("creating tick: %v", err)
+	}
+	return tick
+}
+
+func TestCreateWebsiteQuota(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MaxWebsitesPerUser = 2 })
+	user := createUser(t, h.db)
+	create := func() (int, envelope) {
+		return serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID,
+			CreateWebsiteRequest{URL: "https://example.com/" + uuid.New().String()})
+	}
+
+	for i := 0; i < 2; i++ {
+		if status, resp := create(); status != http.StatusCreated {
+			t.Fatalf("website %d: status = %d (%s), want %d", i+1, status, resp.Error, http.StatusCreated)
+		}
+	}
+
+	status, resp := create()
+	if status != http.StatusForbidden || resp.Code != utils.CodeWebsiteLimitReached {
+		t.Fatalf("over the limit: status = %d, code = %s, want %d %s", status, resp.Code, http.StatusForbidden, utils.CodeWebsiteLimitReached)
+	}
+	if n := countWebsites(t, h.db, user.ID); n != 2 {
+		t.Errorf("websites = %d, want 2", n)
+	}
+}
+
+func TestCreateWebsiteInterval(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) {
+		cfg.CheckInterval = time.Minute
+		cfg.MinCheckInterval = 30 * time.Second
+		cfg.MaxCheckInterval = time.Hour
+	})
+	user := createUser(t, h.db)
+
+	tests := []struct {
+		name     string
+		interval int
+		want     int // stored interval; 0 when the request is rejected
+	}{
+		{"omitted uses the default", 0, 60},
+		{"custom", 300, 300},
+		{"at the minimum", 30, 30},
+		{"at the maximum", 3600, 3600},
+		{"too frequent", 10, 0},
+		{"too rare", 3601, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID,
+				CreateWebsiteRequest{URL: "https://example.com/" + uuid.New().String(), IntervalSeconds: tt.interval})
+
+			if tt.want == 0 {
+				if status != http.StatusBadRequest || !strings.Contains(resp.Error, "interval_seconds must be between 30 and 3600") {
+					t.Errorf("status = %d (%s), want 400 naming the allowed range", status, resp.Error)
+				}
+				return
+			}
+			if status != http.StatusCreated {
+				t.Fatalf("status = %d (%s), want 201", status, resp.Error)
+			}
+			var created struct {
+				ID              string `json:"id"`
+				IntervalSeconds int    `json:"interval_seconds"`
+			}
+			decodeData(t, resp, &created)
+			var stored models.Website
+			if err := h.db.Where("id = ?", created.ID).First(&stored).Error; err != nil {
+				t.Fatal(err)
+			}
+			if created.IntervalSeconds != tt.want || stored.IntervalSeconds != tt.want {
+				t.Errorf("interval = %d in the response and %d stored, want %d", created.IntervalSeconds, stored.IntervalSeconds, tt.want)
+			}
+		})
+	}
+
+	// Rejected requests store nothing
+	if n := countWebsites(t, h.db, user.ID); n != 4 {
+		t.Errorf("%d websites stored, want the 4 accepted", n)
+	}
+}
+
+func TestCheckIntervalInBounds(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) {
+		cfg.MinCheckInterval = time.Minute
+		cfg.MaxCheckInterval = 2 * time.Hour
+	})
+
+	tests := []struct {
+		seconds int
+		ok      bool
+	}{
+		{0, true}, // falls back to the default interval
+		{1, false},
+		{59, false},
+		{60, true},
+		{61, true},
+		{7199, true},
+		{7200, true},
+		{7201, false},
+		{-60, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.seconds), func(t *testing.T) {
+			err := h.checkIntervalInBounds(tt.seconds)
+			if tt.ok {
+				if err != nil {
+					t.Errorf("rejected: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != "interval_seconds must be between 60 and 7200" {
+				t.Errorf("error = %v, want one naming the configured range", err)
+			}
+		})
+	}
+}
+
+func TestCreateWebsiteSteps(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	steps := func(n int) []StepRequest {
+		reqs := make([]StepRequest, n)
+		for i := range reqs {
+			reqs[i] = StepRequest{URL: fmt.Sprintf("https://example.com/step/%d", i+1)}
+		}
+		return reqs
+	}
+
+	tests := []struct {
+		name  string
+		steps []StepRequest
+		want  int // stored steps; -1 when the request is rejected
+	}{
+		{"single request", nil, 0},
+		{"login then dashboard", []StepRequest{
+			{Method: http.MethodPost, URL: "https://example.com/login", Body: "user=alice", Headers: map[string]string{"X-Tenant": "acme"}},
+			{URL: "https://example.com/dashboard", ExpectStatus: http.StatusOK},
+		}, 2},
+		{"at the limit", steps(models.MaxCheckSteps), models.MaxCheckSteps},
+		{"too many steps", steps(models.MaxCheckSteps + 1), -1},
+		{"unknown method", []StepRequest{{Method: "TRACE", URL: "https://example.com"}}, -1},
+		{"missing url", []StepRequest{{Method: http.MethodGet}}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID,
+				CreateWebsiteRequest{URL: "https://example.com/" + uuid.New().String(), Steps: tt.steps})
+
+			if tt.want < 0 {
+				if status != http.StatusBadRequest {
+					t.Errorf("status = %d, want 400", status)
+				}
+				return
+			}
+			if status != http.StatusCreated {
+				t.Fatalf("status = %d (%s), want 201", status, resp.Error)
+			}
+			var created struct {
+				ID string `json:"id"`
+			}
+			decodeData(t, resp, &created)
+			var stored models.Website
+			if err := h.db.Where("id = ?", created.ID).First(&stored).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(stored.Steps) != tt.want {
+				t.Fatalf("stored %d steps, want %d", len(stored.Steps), tt.want)
+			}
+			for i, step := range stored.Steps {
+				if step.URL != tt.steps[i].URL || step.Method == "" {
+					t.Errorf("step %d stored as %+v, want %s with a method", i+1, step, tt.steps[i].URL)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateWebsiteHeaderAssertions(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+
+	tests := []struct {
+		name      string
+		assertion AssertionRequest
+		wantOK    bool
+	}{
+		{"required header", AssertionRequest{Type: "header", Expression: "Strict-Transport-Security"}, true},
+		{"header value", AssertionRequest{Type: "header", Expression: "X-Frame-Options", Expected: "DENY"}, true},
+		{"name with a space", AssertionRequest{Type: "header", Expression: "Strict Transport"}, false},
+		{"name with a colon", AssertionRequest{Type: "header", Expression: "X-Frame-Options:"}, false},
+		{"unknown type", AssertionRequest{Type: "cookie", Expression: "session"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID,
+				CreateWebsiteRequest{URL: "https://example.com/" + uuid.New().String(), Assertions: []AssertionRequest{tt.assertion}})
+			if !tt.wantOK {
+				if status != http.StatusBadRequest {
+					t.Errorf("status = %d, want 400", status)
+				}
+				return
+			}
+			if status != http.StatusCreated {
+				t.Fatalf("status = %d (%s), want 201", status, resp.Error)
+			}
+
+			var created struct {
+				ID string `json:"id"`
+			}
+			decodeData(t, resp, &created)
+			var stored models.Website
+			if err := h.db.Where("id = ?", created.ID).First(&stored).Error; err != nil {
+				t.Fatal(err)
+			}
+			want := models.Assertion{Type: models.AssertionHeader, Expression: tt.assertion.Expression, Expected: tt.assertion.Expected}
+			if len(stored.Assertions) != 1 || stored.Assertions[0] != want || !stored.HasHeaderAssertions() {
+				t.Errorf("stored %+v, want %+v", stored.Assertions, want)
+			}
+		})
+	}
+}
+
+func TestCreateWebsiteQuotaPerUserOverride(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MaxWebsitesPerUser = 1 })
+	user := createUser(t, h.db)
+	three := 3
+	if err := h.db.Model(&user).Update("max_websites", three).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		status, _ := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", user.ID,
+			CreateWebsiteRequest{URL: "https://example.com"})
+		want := http.StatusCreated
+		if i == three {
+			want = http.StatusForbidden
+		}
+		if status != want {
+			t.Errorf("website %d: status = %d, want %d", i+1, status, want)
+		}
+	}
+}
+
+func TestCreateWebsiteQuotaConcurrent(t *testing.T) {
+	const limit, attempts = 3, 10
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MaxWebsitesPerUser = limit })
+	user := createUser(t, h.db)
+
+	router := gin.New()
+	router.POST("/website", func(c *gin.Context) {
+		c.Set("userID", user.ID)
+		h.CreateWebsite(c)
+	})
+
+	var wg sync.WaitGroup
+	statuses := make([]int, attempts)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/website", strings.NewReader(`{"url":"https://example.com"}`)))
+			statuses[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, status := range statuses {
+		if status == http.StatusCreated {
+			created++
+		} else if status != http.StatusForbidden {
+			t.Errorf("status = %d, want %d or %d", status, http.StatusCreated, http.StatusForbidden)
+		}
+	}
+	if created != limit {
+		t.Errorf("created %d websites concurrently, want %d", created, limit)
+	}
+	if n := countWebsites(t, h.db, user.ID); n != limit {
+		t.Errorf("websites = %d, want %d", n, limit)
+	}
+}
+
+func TestDeleteWebsiteSoftDeletes(t *testing.T) {
+	h := newTestHandler(t, func(cfg *config.Config) { cfg.MaxWebsitesPerUser = 1 })
+	website := createWebsite(t, h.db, models.Website{})
+	remove := func(userID string) (int, envelope) {
+		return serve(t, h.DeleteWebsite, http.MethodDelete, "/website", "/website", userID,
+			DeleteWebsiteRequest{WebsiteID: website.ID})
+	}
+
+	// Only the owner can delete it
+	if status, resp := remove(createUser(t, h.db).ID); status != http.StatusNotFound || resp.Code != utils.CodeWebsiteNotFound {
+		t.Fatalf("another user's delete: %d %s, want 404", status, resp.Code)
+	}
+
+	if status, resp := remove(website.UserID); status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	if n := countWebsites(t, h.db, website.UserID); n != 0 {
+		t.Errorf("%d websites listed after delete", n)
+	}
+	var kept models.Website
+	if err := h.db.Unscoped().Where("id = ?", website.ID).First(&kept).Error; err != nil || !kept.DeletedAt.Valid {
+		t.Errorf("deleted website not kept for recovery: %v", err)
+	}
+
+	// Deleting again finds nothing, and the quota slot is free
+	if status, _ := remove(website.UserID); status != http.StatusNotFound {
+		t.Errorf("second delete: status = %d, want 404", status)
+	}
+	status, resp := serve(t, h.CreateWebsite, http.MethodPost, "/website", "/website", website.UserID,
+		CreateWebsiteRequest{URL: "https://example.com/replacement"})
+	if status != http.StatusCreated {
+		t.Errorf("create after delete: status = %d (%s), want 201", status, resp.Error)
+	}
+}
+
+func TestGetWebsite(t *testing.T) {
+	h := newTestHandler(t)
+	owner := createUser(t, h.db)
+	website := createWebsite(t, h.db, models.Website{
+		UserID:          owner.ID,
+		URL:             "https://example.com/health",
+		IntervalSeconds: 120,
+		WebhookSecret:   "secret",
+		Regions:         []string{"eu-west"},
+	})
+	validator := createValidator(t, h.db)
+	createTick(t, h.db, website.ID, validator.ID, models.StatusGood, 100, time.Now())
+	deleted := createWebsite(t, h.db, models.Website{UserID: owner.ID})
+	if err := h.db.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+	get := func(websiteID, userID string) (int, envelope) {
+		return serve(t, h.GetWebsite, http.MethodGet, "/website/:id", "/website/"+websiteID, userID, nil)
+	}
+
+	status, resp := get(website.ID, owner.ID)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var got models.Website
+	decodeData(t, resp, &got)
+	if got.ID != website.ID || got.URL != website.URL || got.IntervalSeconds != 120 || len(got.Regions) != 1 {
+		t.Errorf("website = %+v, want the stored config of %s", got, website.ID)
+	}
+	for _, key := range keys(t, resp.Data) {
+		if key == "Ticks" || key == "WebhookSecret" {
+			t.Errorf("response exposes %s", key)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		websiteID string
+		userID    string
+	}{
+		{"another user's website", website.ID, createUser(t, h.db).ID},
+		{"deleted website", deleted.ID, owner.ID},
+		{"unknown website", uuid.New().String(), owner.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := get(tt.websiteID, tt.userID)
+			if status != http.StatusNotFound || resp.Code != utils.CodeWebsiteNotFound {
+				t.Errorf("status = %d, code = %s, want 404 %s", status, resp.Code, utils.CodeWebsiteNotFound)
+			}
+		})
+	}
+}
+
+func TestRotateWebhookSecret(t *testing.T) {
+	h := newTestHandler(t)
+	owner := createUser(t, h.db)
+	website := createWebsite(t, h.db, models.Website{UserID: owner.ID, WebhookSecret: "old-secret"})
+	rotate := func(userID string) (int, envelope) {
+		return serve(t, h.RotateWebhookSecret, http.MethodPost, "/website/:id/webhook-secret",
+			"/website/"+website.ID+"/webhook-secret", userID, nil)
+	}
+
+	if status, resp := rotate(createUser(t, h.db).ID); status != http.StatusNotFound || resp.Code != utils.CodeWebsiteNotFound {
+		t.Errorf("another user's website: status = %d, code = %s, want 404", status, resp.Code)
+	}
+
+	status, resp := rotate(owner.ID)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+	}
+	var rotated struct {
+		ID            string `json:"id"`
+		WebhookSecret string `json:"webhook_secret"`
+	}
+	decodeData(t, resp, &rotated)
+
+	var stored models.Website
+	if err := h.db.Where("id = ?", website.ID).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rotated.ID != website.ID || rotated.WebhookSecret == "old-secret" || stored.WebhookSecret != rotated.WebhookSecret {
+		t.Errorf("rotated to %q, stored %q, want a new secret replacing old-secret", rotated.WebhookSecret, stored.WebhookSecret)
+	}
+}
+
+type websitesList struct {
+	Websites []struct {
+		ID    string               `json:"ID"`
+		Ticks []models.WebsiteTick `json:"Ticks"`
+	} `json:"websites"`
+	Count int `json:"count"`
+}
+
+func TestGetWebsitesTickModes(t *testing.T) {
+	dbtest.RequirePostgres(t) // recent ticks come from a lateral join
+
+	h := newTestHandler(t)
+	user := createUser(t, h.db)
+	busy := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	quiet := createWebsite(t, h.db, models.Website{UserID: user.ID})
+	validator := createValidator(t, h.db)
+
+	start := time.Now().UTC().Add(-time.Hour)
+	total := fullTicksLimit + 20
+	for i := 0; i < total; i++ {
+		// Latency records the tick's position, oldest first
+		createTick(t, h.db, busy.ID, validator.ID, models.StatusGood, float64(i), start.Add(time.Duration(i)*time.Second))
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 1},
+		{"?ticks=latest", 1},
+		{"?ticks=full", fullTicksLimit},
+	}
+	for _, tt := range tests {
+		t.Run("websites"+tt.query, func(t *testing.T) {
+			status, resp := serve(t, h.GetWebsites, http.MethodGet, "/websites", "/websites"+tt.query, user.ID, nil)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%s), want 200", status, resp.Error)
+			}
+			var list websitesList
+			decodeData(t, resp, &list)
+			if list.Count != 2 {
+				t.Fatalf("count = %d, want 2", list.Count)
+			}
+
+			for _, website := range list.Websites {
+				switch website.ID {
+				case quiet.ID:
+					if website.Ticks == nil || len(website.Ticks) != 0 {
+						t.Errorf("website without checks has ticks %v, want []", website.Ticks)
+					}
+				case busy.ID:
+					if len(website.Ticks) != tt.want {
+						t.Fatalf("got %d ticks, want %d", len(website.Ticks), tt.want)
+					}
+					for i, tick := range website.Ticks {
+						if want := float64(total - 1 - i); tick.Latency != want {
+							t.Fatalf("tick %d is #%.0f, want the newest first (#%.0f)", i, tick.Latency, want)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGetWebsitesRejectsUnknownTickMode(t *testing.T) {
+	h := newTestHandler(t)
+
+	status, resp := serve(t, h.GetWebsites, http.MethodGet, "/websites", "/websites?ticks=all", createUser(t, h.db).ID, nil)
+	if status != http.StatusBadRequest || resp.Code != utils.CodeInvalidRequest {
+		t.Errorf("got %d %s, want 400 %s", status, resp.Code, utils.CodeInvalidRequest)
+	}
+}
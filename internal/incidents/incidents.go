@@ -0,0 +1,278 @@
+// Package incidents derives a website's current status from validator reports
+// and tracks the incidents (Bad or Degraded periods) it goes through.
+package incidents
+
+import (
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConsensusWindow is how far back validator reports count towards the current status
+const ConsensusWindow = 5 * time.Minute
+
+// CurrentStatus returns the consensus of each validator's latest recent report
+// for websiteID and how many distinct validators contributed to it
+func CurrentStatus(db *gorm.DB, websiteID string) (string, int, error) {
+	var statuses []string
+	err := db.Raw(`
+		SELECT DISTINCT ON (validator_id) status
+		FROM "WebsiteTick"
+		WHERE website_id = ? AND created_at >= ?
+		ORDER BY validator_id, created_at DESC`,
+		websiteID, time.Now().Add(-ConsensusWindow),
+	).Scan(&statuses).Error
+	if err != nil {
+		return "", 0, err
+	}
+	return models.ConsensusStatus(statuses), len(statuses), nil
+}
+
+// Consensus is a website's current status and how many validators back it
+type Consensus struct {
+	Status    string
+	Reporting int
+}
+
+// CurrentStatuses is CurrentStatus for many websites in a single query. Websites
+// without recent reports are missing from the result.
+func CurrentStatuses(db *gorm.DB, websiteIDs []string) (map[string]Consensus, error) {
+	result := make(map[string]Consensus, len(websiteIDs))
+	if len(websiteIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		WebsiteID string
+		Status    string
+	}
+	err := db.Raw(`
+		SELECT DISTINCT ON (website_id, validator_id) website_id, status
+		FROM "WebsiteTick"
+		WHERE website_id IN ? AND created_at >= ?
+		ORDER BY website_id, validator_id, created_at DESC`,
+		websiteIDs, time.Now().Add(-ConsensusWindow),
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string][]string)
+	for _, row := range rows {
+		statuses[row.WebsiteID] = append(statuses[row.WebsiteID], row.Status)
+	}
+	for websiteID, reported := range statuses {
+		result[websiteID] = Consensus{
+			Status:    models.ConsensusStatus(reported),
+			Reporting: len(reported),
+		}
+	}
+	return result, nil
+}
+
+// Change describes what Update did; every field is nil when nothing changed
+type Change struct {
+	Opened    *models.Incident
+	Escalated *models.Incident // a Degraded incident that turned into an outage
+	Resolved  *models.Incident
+}
+
+// Thresholds are how many of a website's most recent checks must agree before
+// Update opens or resolves an incident. They keep a flapping website from
+// opening (or closing) an incident per transition.
+type Thresholds struct {
+	OpenChecks            int // all Bad to open an outage
+	ResolveChecks         int // all good (Good or Degraded) to resolve one
+	DegradedOpenChecks    int // none Good to open a Degraded incident; 0 never opens one
+	DegradedResolveChecks int // all Good to resolve one
+}
+
+// Update opens an incident when website's consensus status turns Bad or
+// Degraded and resolves the ongoing one once it recovers. Statuses backed by
+// fewer validators than the website requires leave incidents untouched. An
+// ongoing Degraded incident that goes Bad is escalated rather than replaced, and
+// an outage resolves once the website is up, even if still Degraded.
+func Update(db *gorm.DB, website models.Website, minValidators int, thresholds Thresholds) (Change, error) {
+	status, reporting, err := CurrentStatus(db, website.ID)
+	if err != nil || status == "" || reporting < website.RequiredValidators(minValidators) {
+		return Change{}, err
+	}
+	return transition(db, website.ID, status, thresholds)
+}
+
+// transition moves websiteID's incidents on now that its consensus is status
+func transition(db *gorm.DB, websiteID, status string, thresholds Thresholds) (Change, error) {
+	var ongoing models.Incident
+	if err := db.Where("website_id = ? AND resolved_at IS NULL", websiteID).Limit(1).Find(&ongoing).Error; err != nil {
+		return Change{}, err
+	}
+
+	switch {
+	case status == models.StatusBad:
+		if ongoing.ID != "" && ongoing.Severity == models.StatusBad {
+			return Change{}, nil
+		}
+		if confirmed, err := stable(db, websiteID, isBad, thresholds.OpenChecks); err != nil || !confirmed {
+			return Change{}, err
+		}
+		if ongoing.ID != "" {
+			return escalate(db, ongoing)
+		}
+		return open(db, websiteID, models.StatusBad)
+
+	case ongoing.ID == "":
+		if status != models.StatusDegraded || thresholds.DegradedOpenChecks <= 0 {
+			return Change{}, nil
+		}
+		if confirmed, err := stable(db, websiteID, isImpaired, thresholds.DegradedOpenChecks); err != nil || !confirmed {
+			return Change{}, err
+		}
+		return open(db, websiteID, models.StatusDegraded)
+
+	case ongoing.Severity == models.StatusDegraded:
+		if status != models.StatusGood {
+			return Change{}, nil
+		}
+		if confirmed, err := stable(db, websiteID, isGood, thresholds.DegradedResolveChecks); err != nil || !confirmed {
+			return Change{}, err
+		}
+		return resolve(db, ongoing)
+
+	default: // an outage, now Good or Degraded
+		if confirmed, err := stable(db, websiteID, isUp, thresholds.ResolveChecks); err != nil || !confirmed {
+			return Change{}, err
+		}
+		return resolve(db, ongoing)
+	}
+}
+
+// Check statuses stable matches: up and impaired include Degraded
+func isBad(status string) bool      { return status == models.StatusBad }
+func isUp(status string) bool       { return status != models.StatusBad }
+func isGood(status string) bool     { return status == models.StatusGood }
+func isImpaired(status string) bool { return status != models.StatusGood }
+
+// stable reports whether the website's last n checks, from any validators, all
+// match. A single check is the consensus Update already has, so n below 2 needs
+// no query.
+func stable(db *gorm.DB, websiteID string, match func(status string) bool, n int) (bool, error) {
+	if n <= 1 {
+		return true, nil
+	}
+
+	var statuses []string
+	if err := db.Model(&models.WebsiteTick{}).
+		Where("website_id = ?", websiteID).
+		Order("created_at DESC").
+		Limit(n).
+		Pluck("status", &statuses).Error; err != nil {
+		return false, err
+	}
+	if len(statuses) < n {
+		return false, nil
+	}
+	for _, status := range statuses {
+		if !match(status) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// AlertOpened decides whether to alert on a newly opened incident: not when the
+// website's previous alert went out less than cooldown ago. Alerted incidents
+// are marked Notified so their resolution is alerted on too, and only then.
+func AlertOpened(db *gorm.DB, incident *models.Incident, cooldown time.Duration) (bool, error) {
+	if cooldown > 0 {
+		var previous models.Incident
+		if err := db.Where("website_id = ? AND notified = ? AND id <> ?", incident.WebsiteID, true, incident.ID).
+			Order("started_at DESC").
+			Limit(1).
+			Find(&previous).Error; err != nil {
+			return false, err
+		}
+
+		if previous.ID != "" {
+			lastAlert := previous.StartedAt
+			if previous.ResolvedAt != nil {
+				lastAlert = *previous.ResolvedAt
+			}
+			if incident.StartedAt.Sub(lastAlert) < cooldown {
+				return false, nil
+			}
+		}
+	}
+
+	incident.Notified = true
+	return true, db.Model(incident).Update("notified", true).Error
+}
+
+// cause is the detail of the website's latest check with status
+func cause(db *gorm.DB, websiteID, status string) (string, error) {
+	var detail string
+	err := db.Model(&models.WebsiteTick{}).
+		Select("detail").
+		Where("website_id = ? AND status = ?", websiteID, status).
+		Order("created_at DESC").
+		Limit(1).
+		Scan(&detail).Error
+	return detail, err
+}
+
+func open(db *gorm.DB, websiteID, severity string) (Change, error) {
+	detail, err := cause(db, websiteID, severity)
+	if err != nil {
+		return Change{}, err
+	}
+
+	incident := models.Incident{
+		ID:        uuid.New().String(),
+		WebsiteID: websiteID,
+		StartedAt: time.Now(),
+		Cause:     detail,
+		Severity:  severity,
+	}
+
+	// The partial unique index on ongoing incidents turns a concurrent or repeated
+	// open into a no-op
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&incident)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return Change{}, result.Error
+	}
+	return Change{Opened: &incident}, nil
+}
+
+// escalate turns an ongoing Degraded incident into an outage, with the latest
+// failure as its cause
+func escalate(db *gorm.DB, incident models.Incident) (Change, error) {
+	detail, err := cause(db, incident.WebsiteID, models.StatusBad)
+	if err != nil {
+		return Change{}, err
+	}
+
+	result := db.Model(&models.Incident{}).
+		Where("id = ? AND resolved_at IS NULL AND severity = ?", incident.ID, models.StatusDegraded).
+		Updates(map[string]interface{}{"severity": models.StatusBad, "cause": detail})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return Change{}, result.Error
+	}
+
+	incident.Severity, incident.Cause = models.StatusBad, detail
+	return Change{Escalated: &incident}, nil
+}
+
+func resolve(db *gorm.DB, incident models.Incident) (Change, error) {
+	now := time.Now()
+	result := db.Model(&models.Incident{}).
+		Where("id = ? AND resolved_at IS NULL", incident.ID).
+		Update("resolved_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return Change{}, result.Error
+	}
+
+	incident.ResolvedAt = &now
+	return Change{Resolved: &incident}, nil
+}
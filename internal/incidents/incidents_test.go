@@ -0,0 +1,415 @@
+package incidents
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func createWebsite(t *testing.T, db *gorm.DB) models.Website {
+	t.Helper()
+
+	owner := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	website := models.Website{ID: uuid.New().String(), UserID: owner.ID, URL: "https://example.com"}
+	if err := db.Create(&website).Error; err != nil {
+		t.Fatalf("creating website: %v", err)
+	}
+	return website
+}
+
+// createTicks stores one tick per status, oldest first, a second apart and
+// each from its own validator
+func createTicks(t *testing.T, db *gorm.DB, websiteID string, statuses ...string) {
+	t.Helper()
+
+	start := time.Now().Add(-time.Duration(len(statuses)) * time.Second)
+	for i, status := range statuses {
+		validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+		if err := db.Create(&validator).Error; err != nil {
+			t.Fatalf("creating validator: %v", err)
+		}
+		tick := models.WebsiteTick{
+			ID:          uuid.New().String(),
+			WebsiteID:   websiteID,
+			ValidatorID: validator.ID,
+			Status:      status,
+			CreatedAt:   start.Add(time.Duration(i) * time.Second),
+		}
+		if err := db.Create(&tick).Error; err != nil {
+			t.Fatalf("creating tick: %v", err)
+		}
+	}
+}
+
+func TestStable(t *testing.T) {
+	const good, bad, degraded = models.StatusGood, models.StatusBad, models.StatusDegraded
+
+	tests := []struct {
+		name     string
+		statuses []string // oldest first
+		match    func(string) bool
+		want     bool
+	}{
+		{"down three times", []string{good, bad, bad, bad}, isBad, true},
+		{"down twice", []string{bad, good, bad, bad}, isBad, false},
+		{"flapping", []string{bad, good, bad, good, bad}, isBad, false},
+		{"up three times", []string{bad, good, good, good}, isUp, true},
+		{"degraded counts as up", []string{bad, good, degraded, good}, isUp, true},
+		{"up twice", []string{good, good, bad, good, good}, isUp, false},
+		{"too few checks", []string{bad, bad}, isBad, false},
+		{"degraded and bad are impaired", []string{good, degraded, bad, degraded}, isImpaired, true},
+		{"one good check isn't impaired", []string{degraded, degraded, good, degraded}, isImpaired, false},
+		{"degraded isn't good", []string{good, good, degraded, good}, isGood, false},
+		{"good three times", []string{degraded, good, good, good}, isGood, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			website := createWebsite(t, db)
+			createTicks(t, db, website.ID, tt.statuses...)
+
+			got, err := stable(db, website.ID, tt.match, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("stable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlappingAlertsOnce(t *testing.T) {
+	tests := []struct {
+		name     string
+		cooldown time.Duration
+		want     int
+	}{
+		{"with a cooldown", 5 * time.Minute, 1},
+		{"without a cooldown", 0, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			website := createWebsite(t, db)
+
+			// Six outages in quick succession, each opened then resolved
+			alerts := 0
+			for i := 0; i < 6; i++ {
+				opened, err := open(db, website.ID, models.StatusBad)
+				if err != nil || opened.Opened == nil {
+					t.Fatalf("outage %d didn't open an incident: %v", i+1, err)
+				}
+				alert, err := AlertOpened(db, opened.Opened, tt.cooldown)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if alert {
+					alerts++
+				}
+				if resolved, err := resolve(db, *opened.Opened); err != nil || resolved.Resolved == nil {
+					t.Fatalf("outage %d wasn't resolved: %v", i+1, err)
+				} else if resolved.Resolved.Notified != alert {
+					t.Errorf("outage %d resolved with notified %v, want %v to match its alert", i+1, resolved.Resolved.Notified, alert)
+				}
+			}
+			if alerts != tt.want {
+				t.Errorf("%d alerts for 6 outages, want %d", alerts, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertOpenedAfterCooldown(t *testing.T) {
+	db := dbtest.Open(t)
+	website := createWebsite(t, db)
+
+	// The last alerted outage recovered 10 minutes ago
+	started, resolved := time.Now().Add(-time.Hour), time.Now().Add(-10*time.Minute)
+	previous := models.Incident{ID: uuid.New().String(), WebsiteID: website.ID, StartedAt: started, ResolvedAt: &resolved, Notified: true}
+	if err := db.Create(&previous).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cooldown time.Duration
+		want     bool
+	}{
+		{15 * time.Minute, false},
+		{5 * time.Minute, true},
+	}
+	for _, tt := range tests {
+		incident := models.Incident{ID: uuid.New().String(), WebsiteID: website.ID, StartedAt: time.Now()}
+		if err := db.Create(&incident).Error; err != nil {
+			t.Fatal(err)
+		}
+		alert, err := AlertOpened(db, &incident, tt.cooldown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if alert != tt.want {
+			t.Errorf("cooldown %s: alert = %v, want %v", tt.cooldown, alert, tt.want)
+		}
+
+		var stored models.Incident
+		if err := db.Where("id = ?", incident.ID).First(&stored).Error; err != nil {
+			t.Fatal(err)
+		}
+		if stored.Notified != tt.want {
+			t.Errorf("cooldown %s: stored notified = %v, want %v", tt.cooldown, stored.Notified, tt.want)
+		}
+		if err := db.Model(&incident).Update("resolved_at", time.Now()).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUpdateWaitsForConfirmation(t *testing.T) {
+	dbtest.RequirePostgres(t) // current status uses DISTINCT ON
+
+	db := dbtest.Open(t)
+	website := createWebsite(t, db)
+	const good, bad = models.StatusGood, models.StatusBad
+
+	// Alternating reports never settle on a status
+	for i, status := range []string{bad, good, bad, good, bad} {
+		createTicks(t, db, website.ID, status)
+		change, err := Update(db, website, 1, Thresholds{OpenChecks: 3, ResolveChecks: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if change.Opened != nil || change.Resolved != nil {
+			t.Fatalf("report %d (%s) changed an incident while flapping", i+1, status)
+		}
+	}
+
+	createTicks(t, db, website.ID, bad, bad)
+	change, err := Update(db, website, 1, Thresholds{OpenChecks: 3, ResolveChecks: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Opened == nil {
+		t.Error("three Bad reports in a row didn't open an incident")
+	}
+}
+
+func TestUpdateThresholdsNoisySequence(t *testing.T) {
+	dbtest.RequirePostgres(t) // current status uses DISTINCT ON
+
+	const good, bad = models.StatusGood, models.StatusBad
+	// One validator's reports, oldest first: a blip, a real outage with a blip
+	// of recovery, then a steady recovery
+	noisy := []string{good, good, bad, good, bad, bad, bad, good, bad, good, good, good, good}
+
+	tests := []struct {
+		name         string
+		open         int
+		resolve      int
+		wantOpened   []int // indexes into noisy where an incident opened
+		wantResolved []int
+	}{
+		{"every transition", 1, 1, []int{2, 4, 8}, []int{3, 7, 9}},
+		{"confirmed outage, eager recovery", 3, 1, []int{6}, []int{7}},
+		{"eager outage, confirmed recovery", 1, 3, []int{2}, []int{11}},
+		{"confirmed both ways", 3, 3, []int{6}, []int{11}},
+		{"never confirmed", 4, 3, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			website := createWebsite(t, db)
+			validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+			if err := db.Create(&validator).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now().Add(-time.Duration(len(noisy)) * time.Second)
+			var opened, resolved []int
+			for i, status := range noisy {
+				tick := models.WebsiteTick{
+					ID:          uuid.New().String(),
+					WebsiteID:   website.ID,
+					ValidatorID: validator.ID,
+					Status:      status,
+					CreatedAt:   start.Add(time.Duration(i) * time.Second),
+				}
+				if err := db.Create(&tick).Error; err != nil {
+					t.Fatal(err)
+				}
+
+				change, err := Update(db, website, 1, Thresholds{OpenChecks: tt.open, ResolveChecks: tt.resolve})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if change.Opened != nil {
+					opened = append(opened, i)
+				}
+				if change.Resolved != nil {
+					resolved = append(resolved, i)
+				}
+			}
+
+			if !slices.Equal(opened, tt.wantOpened) || !slices.Equal(resolved, tt.wantResolved) {
+				t.Errorf("opened at %v and resolved at %v, want %v and %v", opened, resolved, tt.wantOpened, tt.wantResolved)
+			}
+		})
+	}
+}
+
+func TestUpdateDegradedConsensus(t *testing.T) {
+	dbtest.RequirePostgres(t) // current status uses DISTINCT ON
+
+	db := dbtest.Open(t)
+	website := createWebsite(t, db)
+	thresholds := Thresholds{OpenChecks: 1, ResolveChecks: 1, DegradedOpenChecks: 3, DegradedResolveChecks: 1}
+	const good, degraded = models.StatusGood, models.StatusDegraded
+
+	// Validators agree the website is slow, but not yet for three checks
+	createTicks(t, db, website.ID, degraded, degraded)
+	if change, err := Update(db, website, 1, thresholds); err != nil || change.Opened != nil {
+		t.Fatalf("two Degraded reports: %+v, %v", change, err)
+	}
+	createTicks(t, db, website.ID, degraded)
+	change, err := Update(db, website, 1, thresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Opened == nil || change.Opened.Severity != degraded {
+		t.Fatalf("three Degraded reports opened %+v, want a Degraded incident", change.Opened)
+	}
+
+	// Good reports now outweigh the slow ones
+	createTicks(t, db, website.ID, good, good, good)
+	if change, err = Update(db, website, 1, thresholds); err != nil || change.Resolved == nil {
+		t.Errorf("recovery didn't resolve the Degraded incident: %+v, %v", change, err)
+	}
+}
+
+func TestTransitionMixedSequence(t *testing.T) {
+	const good, bad, degraded = models.StatusGood, models.StatusBad, models.StatusDegraded
+	// One validator's reports, oldest first: a slowdown that becomes an outage
+	// and recovers to Degraded, then a second slowdown that clears up
+	mixed := []string{good, degraded, degraded, degraded, bad, bad, degraded, good, degraded, degraded, degraded, good, good}
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		cooldown   time.Duration
+		want       []string // changes, by index into mixed
+		wantAlerts []string // notifications the hub would send
+	}{
+		{
+			"every transition", Thresholds{1, 1, 1, 1}, 0,
+			[]string{"1 opened Degraded", "4 escalated", "6 resolved Bad", "8 opened Degraded", "11 resolved Degraded"},
+			[]string{"1 alert Degraded", "4 alert Bad", "6 recovery Bad", "8 alert Degraded", "11 recovery Degraded"},
+		},
+		{
+			"confirmed", Thresholds{2, 1, 3, 2}, 0,
+			[]string{"3 opened Degraded", "5 escalated", "6 resolved Bad", "10 opened Degraded", "12 resolved Degraded"},
+			[]string{"3 alert Degraded", "5 alert Bad", "6 recovery Bad", "10 alert Degraded", "12 recovery Degraded"},
+		},
+		{
+			// The second slowdown starts right after the outage's recovery notice
+			"confirmed with a cooldown", Thresholds{2, 1, 3, 2}, time.Hour,
+			[]string{"3 opened Degraded", "5 escalated", "6 resolved Bad", "10 opened Degraded", "12 resolved Degraded"},
+			[]string{"3 alert Degraded", "5 alert Bad", "6 recovery Bad"},
+		},
+		{
+			"degraded incidents off", Thresholds{2, 1, 0, 2}, 0,
+			[]string{"5 opened Bad", "6 resolved Bad"},
+			[]string{"5 alert Bad", "6 recovery Bad"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			website := createWebsite(t, db)
+			validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+			if err := db.Create(&validator).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now().Add(-time.Duration(len(mixed)) * time.Second)
+			var changes, alerts []string
+			alert := func(i int, incident *models.Incident) {
+				ok, err := AlertOpened(db, incident, tt.cooldown)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok {
+					alerts = append(alerts, fmt.Sprintf("%d alert %s", i, incident.Severity))
+				}
+			}
+			for i, status := range mixed {
+				tick := models.WebsiteTick{
+					ID:          uuid.New().String(),
+					WebsiteID:   website.ID,
+					ValidatorID: validator.ID,
+					Status:      status,
+					CreatedAt:   start.Add(time.Duration(i) * time.Second),
+				}
+				if err := db.Create(&tick).Error; err != nil {
+					t.Fatal(err)
+				}
+
+				// With one validator the consensus is its latest report
+				change, err := transition(db, website.ID, status, tt.thresholds)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if change.Opened != nil {
+					changes = append(changes, fmt.Sprintf("%d opened %s", i, change.Opened.Severity))
+					alert(i, change.Opened)
+				}
+				if change.Escalated != nil {
+					changes = append(changes, fmt.Sprintf("%d escalated", i))
+					alert(i, change.Escalated)
+				}
+				if change.Resolved != nil {
+					changes = append(changes, fmt.Sprintf("%d resolved %s", i, change.Resolved.Severity))
+					if change.Resolved.Notified {
+						alerts = append(alerts, fmt.Sprintf("%d recovery %s", i, change.Resolved.Severity))
+					}
+				}
+			}
+
+			if !slices.Equal(changes, tt.want) {
+				t.Errorf("changes %v, want %v", changes, tt.want)
+			}
+			if !slices.Equal(alerts, tt.wantAlerts) {
+				t.Errorf("alerts %v, want %v", alerts, tt.wantAlerts)
+			}
+
+			// Escalating keeps the slowdown and its outage one incident, and every
+			// incident has resolved by the end
+			opened := 0
+			for _, change := range tt.want {
+				if strings.Contains(change, "opened") {
+					opened++
+				}
+			}
+			var incidents []models.Incident
+			if err := db.Where("website_id = ?", website.ID).Find(&incidents).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(incidents) != opened {
+				t.Errorf("%d incidents recorded, want %d", len(incidents), opened)
+			}
+			for _, incident := range incidents {
+				if incident.ResolvedAt == nil {
+					t.Errorf("incident %s (%s) still ongoing", incident.ID, incident.Severity)
+				}
+			}
+		})
+	}
+}
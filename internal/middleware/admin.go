@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware guards operator endpoints with a shared token in the X-Admin-Token
+// header. Admin routes are disabled entirely when no token is configured.
+func AdminMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken == "" {
+			utils.ErrorResponse(c, http.StatusForbidden, utils.CodeForbidden, "Admin API disabled")
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Token")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+			utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUnauthorized, "Invalid admin token")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
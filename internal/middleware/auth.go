@@ -1,34 +1,40 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
 	"github.com/datmedevil17/gopher-uptime/internal/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func AuthMiddleware(jwtCfg *utils.JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required")
+			utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUnauthorized, "Authorization header required")
 			c.Abort()
 			return
 		}
 
 		// Extract token (Bearer <token>)
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			// No Bearer prefix found
-			token = authHeader
+		token, ok := BearerToken(authHeader)
+		if !ok {
+			utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUnauthorized, "Authorization header must be in the form 'Bearer <token>'")
+			c.Abort()
+			return
 		}
 
 		// Verify JWT
-		userID, err := utils.VerifyJWT(token, jwtSecret)
+		userID, err := utils.VerifyJWT(token, jwtCfg)
 		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token: "+err.Error())
+			utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeInvalidToken, "Invalid token: "+err.Error())
 			c.Abort()
 			return
 		}
@@ -38,3 +44,51 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// ActiveUserMiddleware rejects tokens whose user no longer exists, so deleting an
+// account revokes every token issued to it. It runs after AuthMiddleware.
+func ActiveUserMiddleware(db *gorm.DB, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+
+		exists, err := UserExists(c.Request.Context(), db, timeout, userID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, utils.CodeInternal, "Database error")
+			c.Abort()
+			return
+		}
+		if !exists {
+			utils.ErrorResponse(c, http.StatusUnauthorized, utils.CodeUserNotFound, "User not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// UserExists reports whether the user a token was issued to still exists. The
+// REST middleware and the gRPC interceptor both check it.
+func UserExists(ctx context.Context, db *gorm.DB, timeout time.Duration, userID interface{}) (bool, error) {
+	conn, cancel := database.WithTimeout(ctx, db, timeout)
+	defer cancel()
+
+	var count int64
+	if err := conn.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// BearerToken extracts the token from a "Bearer <token>" header. The scheme is
+// case-insensitive and must be followed by exactly one space; other schemes,
+// empty tokens and tokens containing whitespace are rejected.
+func BearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	if token == "" || strings.ContainsAny(token, " \t\r\n") {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func testJWTConfig(t *testing.T) *utils.JWTConfig {
+	t.Helper()
+
+	cfg, err := utils.LoadJWTConfig(utils.JWTOptions{
+		Algorithm: utils.JWTAlgorithmHS256,
+		Secret:    "test-secret",
+		Issuer:    "test",
+		Audience:  "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestAuthMiddlewareErrorCodes(t *testing.T) {
+	jwtCfg := testJWTConfig(t)
+	token, err := utils.GenerateJWT("user-1", jwtCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"no header", "", http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"missing prefix", token, http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"empty token", "Bearer ", http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"extra spaces", "Bearer  " + token, http.StatusUnauthorized, utils.CodeUnauthorized},
+		{"lowercase scheme", "bearer " + token, http.StatusOK, ""},
+		{"garbage token", "Bearer not.a.jwt", http.StatusUnauthorized, utils.CodeInvalidToken},
+		{"valid token", "Bearer " + token, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := serve(AuthMiddleware(jwtCfg), req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantCode != "" {
+				if code := errorCode(t, w); code != tt.wantCode {
+					t.Errorf("code = %s, want %s", code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantToken string
+		wantOK    bool
+	}{
+		{"bearer token", "Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"case-insensitive scheme", "BEARER abc", "abc", true},
+		{"empty header", "", "", false},
+		{"missing prefix", "abc.def.ghi", "", false},
+		{"scheme only", "Bearer", "", false},
+		{"empty token", "Bearer ", "", false},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", "", false},
+		{"scheme without separator", "Bearerabc", "", false},
+		{"two spaces", "Bearer  abc", "", false},
+		{"trailing space", "Bearer abc ", "", false},
+		{"tab separator", "Bearer\tabc", "", false},
+		{"two tokens", "Bearer abc def", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, ok := BearerToken(tt.header)
+			if token != tt.wantToken || ok != tt.wantOK {
+				t.Errorf("BearerToken(%q) = %q, %v; want %q, %v", tt.header, token, ok, tt.wantToken, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestActiveUserMiddleware(t *testing.T) {
+	db := dbtest.Open(t)
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/me", func(c *gin.Context) {
+		c.Set("userID", c.Query("user"))
+	}, ActiveUserMiddleware(db, time.Second), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func(userID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me?user="+userID, nil))
+		return w
+	}
+
+	if w := get(user.ID); w.Code != http.StatusOK {
+		t.Fatalf("existing user: status = %d, want 200", w.Code)
+	}
+	w := get(uuid.New().String())
+	if w.Code != http.StatusUnauthorized || errorCode(t, w) != utils.CodeUserNotFound {
+		t.Errorf("unknown user: status = %d, want 401 %s", w.Code, utils.CodeUserNotFound)
+	}
+
+	// Deleting the account revokes the tokens issued to it
+	if err := db.Unscoped().Delete(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	w = get(user.ID)
+	if w.Code != http.StatusUnauthorized || errorCode(t, w) != utils.CodeUserNotFound {
+		t.Errorf("deleted user: status = %d, want 401 %s", w.Code, utils.CodeUserNotFound)
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware rejects request bodies larger than maxBytes with 413 and JSON
+// bodies nested deeper than maxDepth or holding arrays longer than maxArrayLen with 400
+func BodyLimitMiddleware(maxBytes int64, maxDepth, maxArrayLen int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			rejectTooLarge(c, maxBytes)
+			return
+		}
+
+		// Read one byte past the limit to detect bodies without a Content-Length
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		c.Request.Body.Close()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "Failed to read request body")
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			rejectTooLarge(c, maxBytes)
+			return
+		}
+
+		if len(body) > 0 && strings.Contains(c.ContentType(), "json") {
+			if err := checkJSONShape(body, maxDepth, maxArrayLen); err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "Invalid request body: "+err.Error())
+				c.Abort()
+				return
+			}
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func rejectTooLarge(c *gin.Context, maxBytes int64) {
+	utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, utils.CodeRequestTooLarge,
+		fmt.Sprintf("Request body exceeds %d bytes", maxBytes))
+	c.Abort()
+}
+
+// checkJSONShape walks the token stream without building the document, enforcing
+// nesting depth and array length limits
+func checkJSONShape(body []byte, maxDepth, maxArrayLen int) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+
+	// Element counts for each open array; -1 marks an open object
+	var stack []int
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// io.EOF ends the document; syntax errors are reported by the handler's binding
+			return nil
+		}
+
+		// A value (or nested container) inside an array counts towards its length
+		if delim, ok := tok.(json.Delim); !ok || delim == '[' || delim == '{' {
+			if n := len(stack); n > 0 && stack[n-1] >= 0 {
+				stack[n-1]++
+				if maxArrayLen > 0 && stack[n-1] > maxArrayLen {
+					return fmt.Errorf("array exceeds %d elements", maxArrayLen)
+				}
+			}
+		}
+
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			continue
+		}
+		switch delim {
+		case '[':
+			stack = append(stack, 0)
+		case '{':
+			stack = append(stack, -1)
+		case ']', '}':
+			stack = stack[:len(stack)-1]
+		}
+		if maxDepth > 0 && len(stack) > maxDepth {
+			return fmt.Errorf("nesting exceeds depth %d", maxDepth)
+		}
+	}
+}
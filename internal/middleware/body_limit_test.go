@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	middleware := BodyLimitMiddleware(64, 3, 4)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"small body", `{"url":"https://example.com"}`, http.StatusOK, ""},
+		{"at the limit", `{"url":"` + strings.Repeat("a", 64-10) + `"}`, http.StatusOK, ""},
+		{"oversized", `{"url":"` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, utils.CodeRequestTooLarge},
+		{"too deep", `{"a":{"b":{"c":{}}}}`, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"array too long", `{"ids":[1,2,3,4,5]}`, http.StatusBadRequest, utils.CodeInvalidRequest},
+		{"malformed is left to binding", `{"url":`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := serve(middleware, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode == "" {
+				if w.Body.String() != tt.body {
+					t.Errorf("handler read %q, want %q", w.Body, tt.body)
+				}
+			} else if code := errorCode(t, w); code != tt.wantCode {
+				t.Errorf("code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestBodyLimitMiddlewareWithoutContentLength(t *testing.T) {
+	// A reader the request can't size, as with chunked uploads
+	body := io.MultiReader(strings.NewReader(strings.Repeat("a", 50)), strings.NewReader(strings.Repeat("a", 50)))
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.ContentLength = -1
+
+	w := serve(BodyLimitMiddleware(64, 0, 0), req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if code := errorCode(t, w); code != utils.CodeRequestTooLarge {
+		t.Errorf("code = %s, want %s", code, utils.CodeRequestTooLarge)
+	}
+}
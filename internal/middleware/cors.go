@@ -1,15 +1,42 @@
 package middleware
 
 import (
+	"log"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:5173", "http://127.0.0.1:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		AllowCredentials: true,
-	})
+func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
+	corsConfig := cors.Config{
+		AllowMethods:     cfg.CORSAllowedMethods,
+		AllowHeaders:     cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+	}
+
+	// "*" is an explicit opt-in for development; never combine it with credentials
+	allowAll := false
+	for _, origin := range cfg.CORSAllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+			break
+		}
+	}
+
+	if len(cfg.CORSAllowedOrigins) == 0 {
+		// Nothing to allow; browsers then only make same-origin requests
+		log.Println("⚠️  CORS_ALLOWED_ORIGINS is empty, cross-origin requests are not allowed")
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	if allowAll {
+		log.Println("⚠️  CORS allows all origins; credentials disabled")
+		corsConfig.AllowAllOrigins = true
+		corsConfig.AllowCredentials = false
+	} else {
+		corsConfig.AllowOrigins = cfg.CORSAllowedOrigins
+	}
+
+	return cors.New(corsConfig)
 }
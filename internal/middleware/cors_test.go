@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+)
+
+func corsConfig(origins ...string) *config.Config {
+	return &config.Config{
+		CORSAllowedOrigins:   origins,
+		CORSAllowedMethods:   []string{"GET", "POST"},
+		CORSAllowedHeaders:   []string{"Content-Type", "Authorization"},
+		CORSAllowCredentials: true,
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             *config.Config
+		origin          string
+		wantStatus      int
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{"allowed origin", corsConfig("https://app.example.com"), "https://app.example.com", http.StatusOK, "https://app.example.com", "true"},
+		{"disallowed origin", corsConfig("https://app.example.com"), "https://evil.example.com", http.StatusForbidden, "", ""},
+		{"same origin", corsConfig("https://app.example.com"), "", http.StatusOK, "", ""},
+		{"wildcard drops credentials", corsConfig("*"), "https://anywhere.example.com", http.StatusOK, "*", ""},
+		{"nothing allowed", corsConfig(), "https://app.example.com", http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := serve(CORSMiddleware(tt.cfg), req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+
+	w := serve(CORSMiddleware(corsConfig("https://app.example.com")), req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET,POST" {
+		t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, "GET,POST")
+	}
+}
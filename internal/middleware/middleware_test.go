@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve sends req through middleware to a handler that answers 200 with the
+// request body, and returns the recorder
+func serve(middleware gin.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(middleware)
+	router.Any("/*path", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode returns the code of an error response, failing t on other bodies
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var resp utils.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+	}
+	if resp.Success {
+		t.Fatalf("response %q is not an error", w.Body.String())
+	}
+	return resp.Code
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyMode is the API's maintenance switch. It can be flipped at runtime, so
+// it's shared between the middleware and the admin endpoint rather than read
+// from config on each request.
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	m := &ReadOnlyMode{}
+	m.enabled.Store(enabled)
+	return m
+}
+
+func (m *ReadOnlyMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+func (m *ReadOnlyMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// ReadOnlyMiddleware refuses anything but GET, HEAD and OPTIONS with 503 while
+// mode is enabled. Routes listed in allowed (by their registered path) write
+// nothing, or must keep working during maintenance, and always pass.
+func ReadOnlyMiddleware(mode *ReadOnlyMode, allowed ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(allowed))
+	for _, route := range allowed {
+		exempt[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if !mode.Enabled() || exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, utils.CodeReadOnly, "The API is in read-only mode for maintenance")
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	tests := []struct {
+		method   string
+		readOnly bool
+		want     int
+	}{
+		{http.MethodGet, true, http.StatusOK},
+		{http.MethodHead, true, http.StatusOK},
+		{http.MethodOptions, true, http.StatusOK},
+		{http.MethodPost, true, http.StatusServiceUnavailable},
+		{http.MethodPut, true, http.StatusServiceUnavailable},
+		{http.MethodPatch, true, http.StatusServiceUnavailable},
+		{http.MethodDelete, true, http.StatusServiceUnavailable},
+		{http.MethodPost, false, http.StatusOK},
+		{http.MethodDelete, false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		name := tt.method + " writable"
+		if tt.readOnly {
+			name = tt.method + " read-only"
+		}
+		t.Run(name, func(t *testing.T) {
+			mode := NewReadOnlyMode(tt.readOnly)
+			w := serve(ReadOnlyMiddleware(mode), httptest.NewRequest(tt.method, "/api/v1/website", nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusServiceUnavailable {
+				if code := errorCode(t, w); code != utils.CodeReadOnly {
+					t.Errorf("code = %s, want %s", code, utils.CodeReadOnly)
+				}
+			}
+		})
+	}
+}
+
+func TestReadOnlyMiddlewareExemptRoutes(t *testing.T) {
+	mode := NewReadOnlyMode(true)
+	router := gin.New()
+	router.Use(ReadOnlyMiddleware(mode, "/auth/login", "/read-only"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/auth/login", ok)
+	router.PUT("/read-only", ok)
+	router.POST("/website/:id", ok)
+
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{http.MethodPost, "/auth/login", http.StatusOK},
+		{http.MethodPut, "/read-only", http.StatusOK},
+		{http.MethodPost, "/website/abc", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, w.Code, tt.want)
+		}
+	}
+}
+
+func TestReadOnlyModeSwitchesAtRuntime(t *testing.T) {
+	mode := NewReadOnlyMode(false)
+	handler := ReadOnlyMiddleware(mode)
+	post := func() int {
+		return serve(handler, httptest.NewRequest(http.MethodPost, "/api/v1/website", nil)).Code
+	}
+
+	if got := post(); got != http.StatusOK {
+		t.Fatalf("write before maintenance: %d, want 200", got)
+	}
+	mode.Set(true)
+	if got := post(); got != http.StatusServiceUnavailable {
+		t.Errorf("write during maintenance: %d, want 503", got)
+	}
+	mode.Set(false)
+	if got := post(); got != http.StatusOK {
+		t.Errorf("write after maintenance: %d, want 200", got)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/datmedevil17/gopher-uptime/internal/openapi"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SchemaValidationMiddleware rejects JSON bodies that don't match the request
+// schema of their route in spec, listing the violations like binding failures
+func SchemaValidationMiddleware(spec *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		c.Request.Body.Close()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, utils.CodeInvalidRequest, "Failed to read request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if violations := spec.ValidateBody(c.Request.Method, c.FullPath(), body); len(violations) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, utils.Response{
+				Success: false,
+				Error:   "Request body doesn't match the API schema",
+				Code:    utils.CodeValidationFailed,
+				Details: violations,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/openapi"
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type schemaTestRequest struct {
+	URL string `json:"url" binding:"required,url"`
+}
+
+func TestSchemaValidationMiddleware(t *testing.T) {
+	router := gin.New()
+	spec := openapi.New("test", "1")
+	router.Use(SchemaValidationMiddleware(spec))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	}
+	router.POST("/website/:id", echo)
+	router.POST("/untyped", echo)
+	spec.AddRoutes(router.Routes(), map[string]openapi.Route{
+		"POST /website/:id": {Request: schemaTestRequest{}},
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		want   int
+	}{
+		{"matching body", "/website/abc", `{"url": "https://example.com"}`, http.StatusOK},
+		{"bad body", "/website/abc", `{"url": "not a url"}`, http.StatusBadRequest},
+		{"no body", "/website/abc", ``, http.StatusOK},
+		{"route without a schema", "/untyped", `{"url": "not a url"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d (%s), want %d", w.Code, w.Body.String(), tt.want)
+			}
+			if tt.want == http.StatusOK {
+				// The handler still reads the whole body
+				if w.Body.String() != tt.body {
+					t.Errorf("handler read %q, want %q", w.Body.String(), tt.body)
+				}
+				return
+			}
+
+			var resp struct {
+				Code    string             `json:"code"`
+				Details []utils.FieldError `json:"details"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != utils.CodeValidationFailed || len(resp.Details) != 1 || resp.Details[0].Field != "url" {
+				t.Errorf("rejected with %s %+v, want %s for url", resp.Code, resp.Details, utils.CodeValidationFailed)
+			}
+		})
+	}
+}
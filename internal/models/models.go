@@ -1,45 +1,238 @@
 package models
 
 import (
+	"math"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// CostPerValidation is what a validator is credited for each recorded check
+const CostPerValidation = 100 // lamports
+
+// LongevityMultiplier scales the credit for a validator registered for tenure:
+// perMonth extra for every full 30 days, capped at maxBonus extra. A perMonth of
+// zero disables the bonus.
+func LongevityMultiplier(tenure time.Duration, perMonth, maxBonus float64) float64 {
+	if perMonth <= 0 || tenure <= 0 {
+		return 1
+	}
+	months := math.Floor(tenure.Hours() / (30 * 24))
+	return 1 + math.Min(months*perMonth, math.Max(maxBonus, 0))
+}
+
+// ValidationCredit is what a validator registered for tenure is credited for one
+// check, rounded to the ledger's precision
+func ValidationCredit(tenure time.Duration, perMonth, maxBonus float64) float64 {
+	credit := CostPerValidation * LongevityMultiplier(tenure, perMonth, maxBonus)
+	return math.Round(credit*100) / 100
+}
+
 // User model
 type User struct {
-	ID       string `gorm:"primaryKey;type:varchar(255)"`
-	Email    string `gorm:"type:varchar(255);not null;uniqueIndex"`
-	Password string `gorm:"type:varchar(255);not null"`
+	ID          string `gorm:"primaryKey;type:varchar(255)"`
+	Email       string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Password    string `gorm:"type:varchar(255);not null"`
+	MaxWebsites *int   // overrides the global active website limit when set
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (User) TableName() string {
 	return "User"
 }
 
+// Invite lets one person sign up while INVITE_ONLY is set
+type Invite struct {
+	Code      string  `gorm:"primaryKey;type:varchar(64)"`
+	Email     *string `gorm:"type:varchar(255)"` // only this address may use it when set
+	UsedBy    *string `gorm:"type:varchar(255)"` // the user who signed up with it
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (Invite) TableName() string {
+	return "Invite"
+}
+
 // Website model
 type Website struct {
-	ID        string        `gorm:"primaryKey;type:varchar(255)"`
-	URL       string        `gorm:"type:varchar(500);not null"`
-	UserID    string        `gorm:"type:varchar(255);not null;index"`
-	Disabled  bool          `gorm:"default:false"`
-	Ticks     []WebsiteTick `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE" json:"-"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                 string        `gorm:"primaryKey;type:varchar(255)"`
+	URL                string        `gorm:"type:varchar(500);not null"`
+	UserID             string        `gorm:"type:varchar(255);not null;index"`
+	Assertions         []Assertion   `gorm:"serializer:json;type:jsonb"`
+	LatencyThresholdMs int           `gorm:"default:0"`                       // successful checks slower than this are Degraded (0 disables)
+	AddressFamily      string        `gorm:"type:varchar(10);default:'auto'"` // auto, ipv4, ipv6 or dual
+	HTTPVersion        string        `gorm:"type:varchar(10);default:'auto'"` // auto, http1 or http2
+	MinValidators      *int          // overrides the global minimum validator coverage when set
+	SLATarget          float64       `gorm:"type:decimal(6,3);default:0"` // uptime target percentage, e.g. 99.9 (0 means none)
+	IntervalSeconds    int           `gorm:"default:0"`                   // time between checks (0 uses the global CHECK_INTERVAL)
+	TimeoutMs          int           `gorm:"default:0"`                   // per-request check timeout (0 uses the validators' CHECK_TIMEOUT)
+	WebhookSecret      string        `gorm:"type:varchar(64)" json:"-"`   // signs webhook notifications; shown at creation and rotation only
+	CooldownSeconds    *int          // alert cooldown; overrides ALERT_COOLDOWN when set (0 disables it)
+	Regions            []string      `gorm:"serializer:json;type:jsonb"` // validator locations allowed to check it (empty allows any)
+	Steps              []CheckStep   `gorm:"serializer:json;type:jsonb"` // run instead of a GET of URL when set
+	Ticks              []WebsiteTick `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+
+	// Deleting a website soft-deletes it: it stops being monitored and drops out
+	// of every query, but the row and its ticks remain recoverable via Unscoped
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (Website) TableName() string {
 	return "Website"
 }
 
+// CheckInterval is how often the website is checked
+func (w Website) CheckInterval(defaultInterval time.Duration) time.Duration {
+	if w.IntervalSeconds > 0 {
+		return time.Duration(w.IntervalSeconds) * time.Second
+	}
+	return defaultInterval
+}
+
+// AlertCooldown is how long after an alert further incidents go unannounced
+func (w Website) AlertCooldown(defaultCooldown time.Duration) time.Duration {
+	if w.CooldownSeconds != nil {
+		return time.Duration(*w.CooldownSeconds) * time.Second
+	}
+	return defaultCooldown
+}
+
+// HasHeaderAssertions reports whether any of the website's assertions, including
+// those of its steps, checks a response header
+func (w Website) HasHeaderAssertions() bool {
+	for _, a := range w.Assertions {
+		if a.Type == AssertionHeader {
+			return true
+		}
+	}
+	for _, step := range w.Steps {
+		for _, a := range step.Assertions {
+			if a.Type == AssertionHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ForcesHTTPVersion reports whether the website must be checked over a specific
+// HTTP version rather than whatever the validator negotiates
+func (w Website) ForcesHTTPVersion() bool {
+	return w.HTTPVersion == HTTPVersion1 || w.HTTPVersion == HTTPVersion2
+}
+
+// AllowsRegion reports whether a validator in location may check the website
+func (w Website) AllowsRegion(location string) bool {
+	if len(w.Regions) == 0 {
+		return true
+	}
+	for _, region := range w.Regions {
+		if strings.EqualFold(region, location) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTimeout is how long a validator waits for the website on each request
+func (w Website) CheckTimeout(defaultTimeout time.Duration) time.Duration {
+	if w.TimeoutMs > 0 {
+		return time.Duration(w.TimeoutMs) * time.Millisecond
+	}
+	return defaultTimeout
+}
+
+// RequiredValidators is how many distinct validators must report before the
+// website's status is trusted
+func (w Website) RequiredValidators(defaultMin int) int {
+	if w.MinValidators != nil {
+		return *w.MinValidators
+	}
+	return defaultMin
+}
+
+// HTTP versions a website can be checked over. http2 needs an https URL, since
+// validators don't speak cleartext HTTP/2.
+const (
+	HTTPVersionAuto = "auto" // HTTP/2 when the server offers it, otherwise HTTP/1.1
+	HTTPVersion1    = "http1"
+	HTTPVersion2    = "http2"
+)
+
+// Assertion types supported by validators
+const (
+	AssertionJSONPath = "jsonpath"
+	AssertionRegex    = "regex"
+	AssertionHeader   = "header"
+)
+
+// Assertion is a response rule evaluated by validators after a check. Header
+// assertions name a response header in Expression; the others apply to the body.
+type Assertion struct {
+	Type       string `json:"type"`       // jsonpath, regex or header
+	Expression string `json:"expression"` // e.g. $.status, ^ok$ or Strict-Transport-Security
+	Expected   string `json:"expected"`   // empty means "exists" / "matches"
+}
+
+// MaxCheckSteps bounds how many requests a multi-step check may make
+const MaxCheckSteps = 5
+
+// CheckStep is one request of a multi-step (transaction) check. Validators run a
+// website's steps in order, sharing cookies, and stop at the first that fails.
+type CheckStep struct {
+	Method       string            `json:"method"` // GET, POST, PUT, PATCH, DELETE or HEAD
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers,omitempty"` // also sent by every later step
+	Body         string            `json:"body,omitempty"`
+	ExpectStatus int               `json:"expect_status,omitempty"` // 0 expects 200
+	Assertions   []Assertion       `json:"assertions,omitempty"`
+}
+
 // Validator model
 type Validator struct {
 	ID             string        `gorm:"primaryKey;type:varchar(255)"`
 	PublicKey      string        `gorm:"type:varchar(255);not null;uniqueIndex"`
+	KeyID          string        `gorm:"type:varchar(100)"` // <algorithm>:<fingerprint>, see internal/signing
+	Name           string        `gorm:"type:varchar(255)"` // operator-supplied, set by REST registration
 	Location       string        `gorm:"type:varchar(255)"`
 	IP             string        `gorm:"type:varchar(255)"`
 	PendingPayouts float64       `gorm:"type:decimal(20,2);default:0"`
 	Ticks          []WebsiteTick `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE" json:"-"`
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+
+	// Self-reported health from the validator's periodic status message
+	InFlightChecks   int   `gorm:"default:0"`
+	ChecksCompleted  int64 `gorm:"default:0"`
+	ChecksFailed     int64 `gorm:"default:0"`
+	ReportErrors     int64 `gorm:"default:0"`
+	UptimeSeconds    int64 `gorm:"default:0"`
+	StatusReportedAt *time.Time
+
+	// When the validator's last payout was queued, for PAYOUT_COOLDOWN. A
+	// refunded payout clears it so the validator can retry straight away.
+	LastPayoutAt *time.Time
+
+	// Banned validators can't sign up, get no tasks, earn nothing and can't
+	// request payouts
+	Banned    bool `gorm:"not null;default:false"`
+	BannedAt  *time.Time
+	BanReason string `gorm:"type:text"`
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // soft-deleted validators can't sign up again
+}
+
+// Tenure is how long the validator has been registered as of now
+func (v Validator) Tenure(now time.Time) time.Duration {
+	if now.Before(v.CreatedAt) {
+		return 0
+	}
+	return now.Sub(v.CreatedAt)
 }
 
 func (Validator) TableName() string {
@@ -49,11 +242,17 @@ func (Validator) TableName() string {
 // WebsiteTick model
 type WebsiteTick struct {
 	ID          string    `gorm:"primaryKey;type:varchar(255)"`
-	WebsiteID   string    `gorm:"type:varchar(255);not null;index"`
-	ValidatorID string    `gorm:"type:varchar(255);not null;index"`
-	Status      string    `gorm:"type:varchar(50);not null"` // Good or Bad
-	Latency     float64   `gorm:"type:decimal(10,2)"`
-	CreatedAt   time.Time `gorm:"index"`
+	WebsiteID   string    `gorm:"type:varchar(255);not null;index:idx_website_tick_website_created,priority:1"`
+	ValidatorID string    `gorm:"type:varchar(255);not null;index:idx_website_tick_validator_created,priority:1"`
+	Status      string    `gorm:"type:varchar(50);not null"` // Good, Degraded or Bad
+	Latency     float64   `gorm:"type:decimal(12,3)"`        // milliseconds, to the microsecond
+	Detail      string    `gorm:"type:text"`                 // failure reason reported by the validator
+	Protocol    string    `gorm:"type:varchar(20)"`          // negotiated HTTP protocol, e.g. HTTP/2.0 (empty if unknown)
+	CreatedAt   time.Time `gorm:"index;index:idx_website_tick_website_created,priority:2,sort:desc;index:idx_website_tick_validator_created,priority:2,sort:desc"`
+
+	// The task's callback id; a repeated delivery of the same result can't record
+	// (and pay for) a second tick. Nil on ticks recorded before it was tracked.
+	CallbackID *string `gorm:"type:varchar(255);uniqueIndex:idx_website_tick_callback" json:",omitempty"`
 
 	Website   *Website   `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE" json:",omitempty"`
 	Validator *Validator `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE" json:",omitempty"`
@@ -63,16 +262,57 @@ func (WebsiteTick) TableName() string {
 	return "WebsiteTick"
 }
 
+// Tick statuses
+const (
+	StatusGood     = "Good"
+	StatusDegraded = "Degraded" // up, but slower than the website's latency threshold
+	StatusBad      = "Bad"
+
+	// StatusInsufficientCoverage is an aggregate status only, never stored on a tick
+	StatusInsufficientCoverage = "InsufficientCoverage"
+)
+
+// ConsensusStatus combines the latest status reported by each validator into one.
+// Bad wins on a strict majority, Degraded when up-but-slow and bad reports together
+// form a majority, Good otherwise. Unknown statuses (legacy rows) count as Bad.
+func ConsensusStatus(statuses []string) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+
+	var bad, degraded int
+	for _, status := range statuses {
+		switch status {
+		case StatusGood:
+		case StatusDegraded:
+			degraded++
+		default:
+			bad++
+		}
+	}
+
+	half := len(statuses) / 2
+	switch {
+	case bad > half:
+		return StatusBad
+	case bad+degraded > half:
+		return StatusDegraded
+	default:
+		return StatusGood
+	}
+}
+
 // PayoutTransaction model
 type PayoutTransaction struct {
-	ID           string    `gorm:"primaryKey;type:varchar(255)"`
-	ValidatorID  string    `gorm:"type:varchar(255);not null;index"`
-	Amount       float64   `gorm:"type:decimal(20,2);not null"`
-	Status       string    `gorm:"type:varchar(50);not null;index"` // pending, processing, completed, failed
-	TxSignature  string    `gorm:"type:varchar(255)"`
-	ErrorMessage string    `gorm:"type:text"`
-	CreatedAt    time.Time `gorm:"index"`
-	UpdatedAt    time.Time
+	ID             string    `gorm:"primaryKey;type:varchar(255)"`
+	ValidatorID    string    `gorm:"type:varchar(255);not null;index"`
+	Amount         float64   `gorm:"type:decimal(20,2);not null"`     // credited lamports deducted from the balance
+	TransferAmount uint64    `gorm:"default:0"`                       // lamports actually sent after fee and multiplier
+	Status         string    `gorm:"type:varchar(50);not null;index"` // pending, processing, completed, failed, simulated
+	TxSignature    string    `gorm:"type:varchar(255)"`
+	ErrorMessage   string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"index"`
+	UpdatedAt      time.Time
 
 	Validator *Validator `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE" json:",omitempty"`
 }
@@ -80,3 +320,192 @@ type PayoutTransaction struct {
 func (PayoutTransaction) TableName() string {
 	return "PayoutTransaction"
 }
+
+// EarningsLedger is an append-only record of every credit to a validator's balance
+type EarningsLedger struct {
+	ID          string    `gorm:"primaryKey;type:varchar(255)"`
+	ValidatorID string    `gorm:"type:varchar(255);not null;index"`
+	WebsiteID   string    `gorm:"type:varchar(255);not null;index"`
+	TickID      string    `gorm:"type:varchar(255);not null;uniqueIndex"` // one credit per tick
+	Amount      float64   `gorm:"type:decimal(20,2);not null"`
+	CreatedAt   time.Time `gorm:"index"`
+
+	Validator *Validator `gorm:"foreignKey:ValidatorID;constraint:OnDelete:CASCADE" json:",omitempty"`
+}
+
+func (EarningsLedger) TableName() string {
+	return "EarningsLedger"
+}
+
+// CheckTrigger asks the hub to check a website immediately, outside its regular cycle
+type CheckTrigger struct {
+	ID              string     `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID       string     `gorm:"type:varchar(255);not null;index"`
+	RequestedAt     time.Time  `gorm:"not null;index"`
+	ClaimedBy       string     `gorm:"type:varchar(255);not null;default:''"` // hub instance handling the trigger
+	DispatchedAt    *time.Time `gorm:"index"`
+	DispatchedCount int        `gorm:"default:0"`
+	ReusedFrom      *time.Time // set when the hub reused checks dispatched then instead of dispatching new ones
+
+	Website *Website `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE" json:",omitempty"`
+}
+
+func (CheckTrigger) TableName() string {
+	return "CheckTrigger"
+}
+
+// Incident is a period during which a website's consensus status was Bad. It is
+// ongoing while ResolvedAt is nil; a website has at most one ongoing incident.
+type Incident struct {
+	ID         string     `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID  string     `gorm:"type:varchar(255);not null;index;uniqueIndex:idx_incident_ongoing,where:resolved_at IS NULL"`
+	StartedAt  time.Time  `gorm:"not null;index"`
+	ResolvedAt *time.Time `gorm:"index"`
+	Cause      string     `gorm:"type:text"`                               // failure detail reported when the incident opened
+	Severity   string     `gorm:"type:varchar(20);not null;default:'Bad'"` // the consensus it is about: Bad (an outage) or Degraded
+	Notified   bool       `gorm:"not null;default:false"`                  // alerted on; false when opened during the alert cooldown
+
+	Website *Website `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE" json:",omitempty"`
+}
+
+func (Incident) TableName() string {
+	return "Incident"
+}
+
+// Incident statuses, derived from ResolvedAt
+const (
+	IncidentOngoing  = "ongoing"
+	IncidentResolved = "resolved"
+)
+
+// Status reports whether the incident is ongoing or resolved
+func (i Incident) Status() string {
+	if i.ResolvedAt == nil {
+		return IncidentOngoing
+	}
+	return IncidentResolved
+}
+
+// Duration is how long the incident lasted, or has lasted so far if ongoing
+func (i Incident) Duration(now time.Time) time.Duration {
+	if i.ResolvedAt != nil {
+		return i.ResolvedAt.Sub(i.StartedAt)
+	}
+	return now.Sub(i.StartedAt)
+}
+
+// Notification channel types
+const (
+	ChannelWebhook   = "webhook"
+	ChannelEmail     = "email"
+	ChannelSlack     = "slack"
+	ChannelDiscord   = "discord"
+	ChannelPagerDuty = "pagerduty"
+)
+
+// NotificationChannel is where a user's incident notifications are delivered
+type NotificationChannel struct {
+	ID        string  `gorm:"primaryKey;type:varchar(255)"`
+	UserID    string  `gorm:"type:varchar(255);not null;index"`
+	WebsiteID *string `gorm:"type:varchar(255);index"`    // nil applies to all of the user's websites
+	Type      string  `gorm:"type:varchar(20);not null"`  // webhook, email, slack, discord or pagerduty
+	Target    string  `gorm:"type:varchar(500);not null"` // URL, the address for email or the PagerDuty integration key
+	Enabled   bool    `gorm:"default:true"`
+	CreatedAt time.Time
+}
+
+func (NotificationChannel) TableName() string {
+	return "NotificationChannel"
+}
+
+// WebhookDelivery is one attempt to deliver a notification to a webhook
+// channel. A failed attempt is retried with backoff; Status says whether another
+// attempt follows or the delivery was given up on.
+type WebhookDelivery struct {
+	ID             string    `gorm:"primaryKey;type:varchar(255)"`
+	ChannelID      string    `gorm:"type:varchar(255);not null;index:idx_webhook_delivery_channel_created"`
+	WebsiteID      string    `gorm:"type:varchar(255);not null;index"`
+	URL            string    `gorm:"type:varchar(500);not null"`
+	Event          string    `gorm:"type:varchar(50);not null"`
+	Attempt        int       `gorm:"not null"` // 1 for the first try
+	StatusCode     int       // 0 when no response arrived
+	ResponseTimeMs int64     `gorm:"not null"`
+	Success        bool      `gorm:"not null"`
+	Status         string    `gorm:"type:varchar(20);not null"` // delivered, retrying or failed
+	Error          string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"index:idx_webhook_delivery_channel_created"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "WebhookDelivery"
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryRetrying  = "retrying"
+	DeliveryFailed    = "failed" // retries exhausted or the error is permanent
+)
+
+// StatusPage is a user's public, read-only status page served at /status/:slug
+type StatusPage struct {
+	ID        string `gorm:"primaryKey;type:varchar(255)"`
+	UserID    string `gorm:"type:varchar(255);not null;uniqueIndex"` // one page per user
+	Slug      string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Title     string `gorm:"type:varchar(200);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Websites []StatusPageWebsite `gorm:"foreignKey:StatusPageID;constraint:OnDelete:CASCADE"`
+}
+
+func (StatusPage) TableName() string {
+	return "StatusPage"
+}
+
+// StatusPageWebsite is a website shown on a status page, in Position order
+type StatusPageWebsite struct {
+	StatusPageID string `gorm:"primaryKey;type:varchar(255)"`
+	WebsiteID    string `gorm:"primaryKey;type:varchar(255);index"`
+	Name         string `gorm:"type:varchar(100);not null"` // shown instead of the URL
+	Position     int    `gorm:"not null;default:0"`
+
+	Website *Website `gorm:"foreignKey:WebsiteID;constraint:OnDelete:CASCADE"`
+}
+
+func (StatusPageWebsite) TableName() string {
+	return "StatusPageWebsite"
+}
+
+// WebsiteTickRollup aggregates one website's ticks over one hour so long uptime
+// windows don't have to scan raw ticks
+type WebsiteTickRollup struct {
+	WebsiteID    string    `gorm:"primaryKey;type:varchar(255)"`
+	Bucket       time.Time `gorm:"primaryKey;index"` // start of the hour
+	Good         int64     `gorm:"not null;default:0"`
+	Degraded     int64     `gorm:"not null;default:0"`
+	Bad          int64     `gorm:"not null;default:0"`
+	LatencyTotal float64   `gorm:"not null;default:0"`
+
+	// Check counts per rollups.LatencyBounds bucket, for latency percentiles
+	LatencyHistogram []int64 `gorm:"serializer:json;type:jsonb"`
+}
+
+func (WebsiteTickRollup) TableName() string {
+	return "WebsiteTickRollup"
+}
+
+// PayoutReplay records a dead-lettered payout message an operator put back on
+// the payout queue
+type PayoutReplay struct {
+	ID            string    `gorm:"primaryKey;type:varchar(255)"`
+	TransactionID string    `gorm:"type:varchar(255);index"` // the failed PayoutTransaction, empty for malformed messages
+	ValidatorID   string    `gorm:"type:varchar(255);index"`
+	Amount        float64   `gorm:"type:decimal(20,2)"`
+	Reason        string    `gorm:"type:text"` // why the message was dead-lettered
+	ReplayedAt    time.Time `gorm:"index"`
+}
+
+func (PayoutReplay) TableName() string {
+	return "PayoutReplay"
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestConsensusStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{"no reports", nil, ""},
+		{"all good", []string{StatusGood, StatusGood, StatusGood}, StatusGood},
+		{"bad minority", []string{StatusGood, StatusGood, StatusBad}, StatusGood},
+		{"bad majority", []string{StatusBad, StatusBad, StatusGood}, StatusBad},
+		{"even split stays good", []string{StatusGood, StatusBad}, StatusGood},
+		{"degraded majority", []string{StatusDegraded, StatusDegraded, StatusGood}, StatusDegraded},
+		{"degraded and bad together", []string{StatusDegraded, StatusBad, StatusGood}, StatusDegraded},
+		{"bad outweighs degraded", []string{StatusBad, StatusBad, StatusDegraded}, StatusBad},
+		{"legacy statuses count as bad", []string{"Down", "Down", StatusGood}, StatusBad},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConsensusStatus(tt.statuses); got != tt.want {
+				t.Errorf("ConsensusStatus(%v) = %q, want %q", tt.statuses, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredValidators(t *testing.T) {
+	three := 3
+	if got := (Website{}).RequiredValidators(2); got != 2 {
+		t.Errorf("without an override = %d, want the default 2", got)
+	}
+	if got := (Website{MinValidators: &three}).RequiredValidators(2); got != 3 {
+		t.Errorf("with an override = %d, want 3", got)
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	if got := (Website{}).CheckInterval(time.Minute); got != time.Minute {
+		t.Errorf("without an interval = %s, want the default 1m", got)
+	}
+	if got := (Website{IntervalSeconds: 300}).CheckInterval(time.Minute); got != 5*time.Minute {
+		t.Errorf("with 300 seconds = %s, want 5m", got)
+	}
+}
+
+func TestLongevityMultiplier(t *testing.T) {
+	month := 30 * 24 * time.Hour
+	tests := []struct {
+		name     string
+		tenure   time.Duration
+		perMonth float64
+		maxBonus float64
+		want     float64
+	}{
+		{"disabled", 12 * month, 0, 0.25, 1},
+		{"negative rate disables", 12 * month, -0.05, 0.25, 1},
+		{"new validator", 0, 0.05, 0.25, 1},
+		{"under a month", month - time.Hour, 0.05, 0.25, 1},
+		{"one month", month, 0.05, 0.25, 1.05},
+		{"partial months round down", 3*month + 29*24*time.Hour, 0.05, 0.25, 1.15},
+		{"capped", 12 * month, 0.05, 0.25, 1.25},
+		{"negative cap gives no bonus", 12 * month, 0.05, -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LongevityMultiplier(tt.tenure, tt.perMonth, tt.maxBonus)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("LongevityMultiplier(%s, %v, %v) = %v, want %v", tt.tenure, tt.perMonth, tt.maxBonus, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationCredit(t *testing.T) {
+	month := 30 * 24 * time.Hour
+	if got := ValidationCredit(6*month, 0, 0.25); got != CostPerValidation {
+		t.Errorf("without a bonus = %v, want %v", got, CostPerValidation)
+	}
+	if got := ValidationCredit(2*month, 0.05, 0.25); got != 110 {
+		t.Errorf("after two months = %v, want 110", got)
+	}
+	if got := ValidationCredit(month, 0.00333, 0.25); got != 100.33 {
+		t.Errorf("fractional bonus = %v, want it rounded to 100.33", got)
+	}
+}
+
+func TestValidatorTenure(t *testing.T) {
+	now := time.Now()
+	v := Validator{CreatedAt: now.Add(-48 * time.Hour)}
+	if got := v.Tenure(now); got != 48*time.Hour {
+		t.Errorf("Tenure = %s, want 48h", got)
+	}
+	// A clock behind the registration time doesn't give a negative tenure
+	if got := v.Tenure(now.Add(-72 * time.Hour)); got != 0 {
+		t.Errorf("Tenure before registration = %s, want 0", got)
+	}
+}
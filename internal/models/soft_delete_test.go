@@ -0,0 +1,71 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestSoftDelete(t *testing.T) {
+	db := dbtest.Open(t)
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	website := models.Website{ID: uuid.New().String(), URL: "https://example.com", UserID: user.ID}
+	validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String(), Location: "unknown"}
+	for _, row := range []interface{}{&website, &validator} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		model func() interface{} // a fresh destination of the row's type
+		id    string
+	}{
+		{"user", func() interface{} { return &models.User{} }, user.ID},
+		{"website", func() interface{} { return &models.Website{} }, website.ID},
+		{"validator", func() interface{} { return &models.Validator{} }, validator.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := db.Where("id = ?", tt.id).Delete(tt.model()).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			// Excluded from every default query
+			if err := db.Where("id = ?", tt.id).First(tt.model()).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("First after delete: %v, want record not found", err)
+			}
+			var n int64
+			db.Model(tt.model()).Where("id = ?", tt.id).Count(&n)
+			if n != 0 {
+				t.Errorf("counted %d deleted rows", n)
+			}
+
+			// Still there, marked deleted, when asked for
+			if err := db.Unscoped().Where("id = ?", tt.id).First(tt.model()).Error; err != nil {
+				t.Fatalf("Unscoped lookup: %v", err)
+			}
+			var deleted int64
+			db.Unscoped().Model(tt.model()).Where("id = ? AND deleted_at IS NOT NULL", tt.id).Count(&deleted)
+			if deleted != 1 {
+				t.Errorf("%d rows marked deleted, want 1", deleted)
+			}
+
+			// Recovered by clearing the timestamp
+			if err := db.Unscoped().Model(tt.model()).Where("id = ?", tt.id).Update("deleted_at", nil).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Where("id = ?", tt.id).First(tt.model()).Error; err != nil {
+				t.Errorf("First after recovery: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ChatOptions control delivery to chat webhooks (Slack, Discord)
+type ChatOptions struct {
+	Retries int          // extra attempts after a failed post (PagerDuty uses it too)
+	Limiter *RateLimiter // shared across channels; nil disables rate limiting
+}
+
+// RateLimiter spaces out posts to the same webhook URL. Chat services reject
+// bursts (Slack allows about one message per second per webhook), and an outage
+// across many websites would otherwise send one per site at once.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time
+}
+
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	return &RateLimiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+// Wait blocks until target's next slot, or until ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, target string) error {
+	if l == nil || l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[target]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[target] = slot.Add(l.interval)
+
+	// Forget targets that have been idle for a while
+	for key, next := range l.next {
+		if next.Before(now) {
+			delete(l.next, key)
+		}
+	}
+	l.mu.Unlock()
+
+	return sleep(ctx, time.Until(slot))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// chatPoster posts payloads to a chat webhook, rate limited and retried with
+// exponential backoff on network errors, 429s and 5xx responses
+type chatPoster struct {
+	url    string
+	client *http.Client
+	opts   ChatOptions
+}
+
+func newChatPoster(url string, client *http.Client, opts ChatOptions) chatPoster {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return chatPoster{url: url, client: client, opts: opts}
+}
+
+func (p chatPoster) post(ctx context.Context, payload interface{}) error {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		if err := p.opts.Limiter.Wait(ctx, p.url); err != nil {
+			return err
+		}
+
+		err := postJSON(ctx, p.client, p.url, payload)
+		if err == nil || attempt >= p.opts.Retries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := backoff
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.retryAfter > 0 {
+			delay = statusErr.retryAfter
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether a failed post may succeed when repeated. Other 4xx
+// responses mean the webhook is gone or the payload is wrong.
+func retryable(err error) bool {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+}
@@ -0,0 +1,243 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type slackBlock struct {
+	Type string `json:"type"`
+	Text *struct {
+		Text string `json:"text"`
+	} `json:"text"`
+	Fields []struct {
+		Text string `json:"text"`
+	} `json:"fields"`
+	Elements []struct {
+		Text string `json:"text"`
+	} `json:"elements"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+func TestSlackPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      Message
+		text     string
+		fields   []string
+		context  string
+		headline string
+	}{
+		{
+			name:     "down",
+			msg:      testMessage(false),
+			text:     ":red_circle: https://example.com is down",
+			fields:   []string{"*Site*\n<https://example.com>", "*Status*\nDown", "*Latency*\n1234 ms", "*Time*\n2024-05-01T12:00:00Z"},
+			context:  "Incident `incident-1`: connection refused",
+			headline: ":red_circle: *<https://dashboard.example.com/website/website-1|https://example.com is down>*",
+		},
+		{
+			name:     "resolved",
+			msg:      testMessage(true),
+			text:     ":large_green_circle: https://example.com is back up after 1h0m0s",
+			fields:   []string{"*Site*\n<https://example.com>", "*Status*\nUp", "*Latency*\n87 ms", "*Time*\n2024-05-01T13:00:00Z"},
+			context:  "Incident `incident-1`",
+			headline: ":large_green_circle: *<https://dashboard.example.com/website/website-1|https://example.com is back up after 1h0m0s>*",
+		},
+		{
+			name:     "degraded",
+			msg:      degradedMessage(false),
+			text:     ":large_yellow_circle: https://example.com is degraded",
+			fields:   []string{"*Site*\n<https://example.com>", "*Status*\nDegraded", "*Latency*\n1234 ms", "*Time*\n2024-05-01T12:00:00Z"},
+			context:  "Incident `incident-1`: slow response",
+			headline: ":large_yellow_circle: *<https://dashboard.example.com/website/website-1|https://example.com is degraded>*",
+		},
+		{
+			name:     "no longer degraded",
+			msg:      degradedMessage(true),
+			text:     ":large_green_circle: https://example.com is no longer degraded after 1h0m0s",
+			fields:   []string{"*Site*\n<https://example.com>", "*Status*\nUp", "*Latency*\n87 ms", "*Time*\n2024-05-01T13:00:00Z"},
+			context:  "Incident `incident-1`",
+			headline: ":large_green_circle: *<https://dashboard.example.com/website/website-1|https://example.com is no longer degraded after 1h0m0s>*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newEndpoint(t)
+			tt.msg.Link = "https://dashboard.example.com/website/website-1"
+			if err := NewSlackChannel(receiver.URL, nil, ChatOptions{}).Send(context.Background(), tt.msg); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			var got slackMessage
+			receiver.payload(t, 0, &got)
+			if got.Text != tt.text {
+				t.Errorf("text = %q, want %q", got.Text, tt.text)
+			}
+			if len(got.Blocks) != 3 {
+				t.Fatalf("got %d blocks, want 3: %+v", len(got.Blocks), got.Blocks)
+			}
+			if got.Blocks[0].Text == nil || got.Blocks[0].Text.Text != tt.headline {
+				t.Errorf("headline block = %+v, want %q", got.Blocks[0].Text, tt.headline)
+			}
+			if len(got.Blocks[1].Fields) != len(tt.fields) {
+				t.Fatalf("got fields %+v, want %q", got.Blocks[1].Fields, tt.fields)
+			}
+			for i, want := range tt.fields {
+				if got.Blocks[1].Fields[i].Text != want {
+					t.Errorf("field %d = %q, want %q", i, got.Blocks[1].Fields[i].Text, want)
+				}
+			}
+			if len(got.Blocks[2].Elements) != 1 || got.Blocks[2].Elements[0].Text != tt.context {
+				t.Errorf("context block = %+v, want %q", got.Blocks[2].Elements, tt.context)
+			}
+		})
+	}
+}
+
+func TestSlackPayloadLinksWebsiteWithoutDashboard(t *testing.T) {
+	receiver := newEndpoint(t)
+	if err := NewSlackChannel(receiver.URL, nil, ChatOptions{}).Send(context.Background(), testMessage(false)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var got slackMessage
+	receiver.payload(t, 0, &got)
+	if want := "*<https://example.com|"; !strings.Contains(got.Blocks[0].Text.Text, want) {
+		t.Errorf("headline %q doesn't link %q", got.Blocks[0].Text.Text, want)
+	}
+}
+
+type discordMessage struct {
+	Embeds []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Description string `json:"description"`
+		Color       int    `json:"color"`
+		Timestamp   string `json:"timestamp"`
+		Fields      []struct {
+			Name   string `json:"name"`
+			Value  string `json:"value"`
+			Inline bool   `json:"inline"`
+		} `json:"fields"`
+	} `json:"embeds"`
+}
+
+func TestDiscordPayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         Message
+		title       string
+		description string
+		color       int
+		timestamp   string
+		fields      []string
+	}{
+		{"down", testMessage(false), "https://example.com is down", "Incident `incident-1`: connection refused",
+			discordRed, "2024-05-01T12:00:00Z", []string{"Site=https://example.com", "Status=Down", "Latency=1234 ms"}},
+		{"resolved", testMessage(true), "https://example.com is back up after 1h0m0s", "Incident `incident-1`",
+			discordGreen, "2024-05-01T13:00:00Z", []string{"Site=https://example.com", "Status=Up", "Latency=87 ms"}},
+		{"degraded", degradedMessage(false), "https://example.com is degraded", "Incident `incident-1`: slow response",
+			discordYellow, "2024-05-01T12:00:00Z", []string{"Site=https://example.com", "Status=Degraded", "Latency=1234 ms"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newEndpoint(t)
+			if err := NewDiscordChannel(receiver.URL, nil, ChatOptions{}).Send(context.Background(), tt.msg); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			var got discordMessage
+			receiver.payload(t, 0, &got)
+			if len(got.Embeds) != 1 {
+				t.Fatalf("got %d embeds, want 1", len(got.Embeds))
+			}
+			embed := got.Embeds[0]
+			if embed.Title != tt.title || embed.URL != "https://example.com" || embed.Description != tt.description ||
+				embed.Color != tt.color || embed.Timestamp != tt.timestamp {
+				t.Errorf("embed = %+v", embed)
+			}
+			if len(embed.Fields) != len(tt.fields) {
+				t.Fatalf("got fields %+v, want %q", embed.Fields, tt.fields)
+			}
+			for i, want := range tt.fields {
+				field := embed.Fields[i]
+				if got := field.Name + "=" + field.Value; got != want || !field.Inline {
+					t.Errorf("field %d = %q (inline %v), want inline %q", i, got, field.Inline, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChatPostRetries(t *testing.T) {
+	t.Run("server errors are retried", func(t *testing.T) {
+		receiver := newEndpoint(t, http.StatusServiceUnavailable)
+		if err := NewSlackChannel(receiver.URL, nil, ChatOptions{Retries: 2}).Send(context.Background(), testMessage(false)); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		if got := receiver.count(); got != 2 {
+			t.Errorf("got %d posts, want 2", got)
+		}
+	})
+
+	t.Run("client errors are not", func(t *testing.T) {
+		receiver := newEndpoint(t, http.StatusNotFound)
+		if err := NewDiscordChannel(receiver.URL, nil, ChatOptions{Retries: 2}).Send(context.Background(), testMessage(false)); err == nil {
+			t.Fatal("expected the 404 to fail the post")
+		}
+		if got := receiver.count(); got != 1 {
+			t.Errorf("got %d posts, want 1", got)
+		}
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		receiver := newEndpoint(t, http.StatusBadGateway, http.StatusBadGateway)
+		if err := NewSlackChannel(receiver.URL, nil, ChatOptions{Retries: 1}).Send(context.Background(), testMessage(false)); err == nil {
+			t.Fatal("expected the post to fail")
+		}
+		if got := receiver.count(); got != 2 {
+			t.Errorf("got %d posts, want 2", got)
+		}
+	})
+}
+
+func TestRateLimiterSpacesPostsPerTarget(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	limiter := NewRateLimiter(interval)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "https://hooks.slack.com/a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("three posts to one webhook took %s, want at least %s", elapsed, 2*interval)
+	}
+
+	// Another webhook has its own budget
+	start = time.Now()
+	if err := limiter.Wait(ctx, "https://hooks.slack.com/b"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("first post to another webhook waited %s", elapsed)
+	}
+
+	// A cancelled wait returns early
+	limiter.Wait(ctx, "https://hooks.slack.com/c")
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(cancelled, "https://hooks.slack.com/c"); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
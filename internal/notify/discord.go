@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Embed colours (decimal RGB)
+const (
+	discordRed    = 0xE01E5A
+	discordYellow = 0xECB22E
+	discordGreen  = 0x2EB67D
+)
+
+// DiscordChannel posts an embed to a Discord channel webhook
+type DiscordChannel struct {
+	poster chatPoster
+}
+
+func NewDiscordChannel(webhookURL string, client *http.Client, opts ChatOptions) *DiscordChannel {
+	return &DiscordChannel{poster: newChatPoster(webhookURL, client, opts)}
+}
+
+func (d *DiscordChannel) Send(ctx context.Context, msg Message) error {
+	return d.poster.post(ctx, discordPayload(msg))
+}
+
+func discordPayload(msg Message) map[string]interface{} {
+	color := discordRed
+	switch msg.Status {
+	case StatusUp:
+		color = discordGreen
+	case StatusDegraded:
+		color = discordYellow
+	}
+
+	description := "Incident `" + msg.IncidentID + "`"
+	if msg.Status != StatusUp && msg.Cause != "" {
+		description += ": " + msg.Cause
+	}
+
+	return map[string]interface{}{
+		"embeds": []map[string]interface{}{
+			{
+				"title":       msg.Subject(),
+				"url":         msg.link(),
+				"description": description,
+				"color":       color,
+				"timestamp":   msg.OccurredAt().UTC().Format(time.RFC3339),
+				"fields": []map[string]interface{}{
+					{"name": "Site", "value": msg.URL, "inline": true},
+					{"name": "Status", "value": statusLabel(msg.Status), "inline": true},
+					{"name": "Latency", "value": latencyLabel(msg.Latency), "inline": true},
+				},
+			},
+		},
+	}
+}
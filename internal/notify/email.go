@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPConfig is the outgoing mail server shared by all email channels
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// sendMailFunc matches smtp.SendMail so tests and alternative transports can
+// stand in for it
+type sendMailFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// EmailChannel mails the message to one address over SMTP
+type EmailChannel struct {
+	to       string
+	smtp     SMTPConfig
+	sendMail sendMailFunc
+}
+
+func NewEmailChannel(to string, cfg SMTPConfig) (*EmailChannel, error) {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil, errors.New("email notifications need SMTP_HOST and SMTP_FROM")
+	}
+	return &EmailChannel{to: to, smtp: cfg, sendMail: smtp.SendMail}, nil
+}
+
+func (e *EmailChannel) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if e.smtp.Username != "" {
+		auth = smtp.PlainAuth("", e.smtp.Username, e.smtp.Password, e.smtp.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + e.smtp.From,
+		"To: " + e.to,
+		"Subject: " + msg.Subject(),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Text(),
+	}, "\r\n")
+
+	// net/smtp has no context support; run it aside so a hung server can't
+	// outlive the caller's deadline
+	addr := net.JoinHostPort(e.smtp.Host, strconv.Itoa(e.smtp.Port))
+	done := make(chan error, 1)
+	go func() {
+		done <- e.sendMail(addr, auth, e.smtp.From, []string{e.to}, []byte(body))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
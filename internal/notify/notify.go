@@ -0,0 +1,263 @@
+// Package notify delivers incident notifications to the channels users configure
+// (webhooks, email, Slack, Discord, PagerDuty).
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Event identifies what a notification is about
+type Event string
+
+const (
+	EventIncidentOpened   Event = "incident.opened"
+	EventIncidentResolved Event = "incident.resolved"
+)
+
+// Website status reported in messages
+const (
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+	StatusUp       = "up"
+)
+
+// Message is the payload sent to every channel
+type Message struct {
+	Event      Event      `json:"event"`
+	WebsiteID  string     `json:"website_id"`
+	URL        string     `json:"url"`
+	Status     string     `json:"status"`             // down, degraded or up
+	Degraded   bool       `json:"degraded,omitempty"` // a Degraded incident rather than an outage
+	Latency    float64    `json:"latency_ms"`
+	Link       string     `json:"link,omitempty"`
+	IncidentID string     `json:"incident_id"`
+	Cause      string     `json:"cause,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// IncidentMessage builds the message for an incident opening or resolving.
+// tick is the report that caused the change.
+func IncidentMessage(website models.Website, incident models.Incident, tick models.WebsiteTick) Message {
+	degraded := incident.Severity == models.StatusDegraded
+	event, status := EventIncidentOpened, StatusDown
+	if degraded {
+		status = StatusDegraded
+	}
+	if incident.ResolvedAt != nil {
+		event, status = EventIncidentResolved, StatusUp
+	}
+	return Message{
+		Event:      event,
+		WebsiteID:  website.ID,
+		URL:        website.URL,
+		Status:     status,
+		Degraded:   degraded,
+		Latency:    tick.Latency,
+		IncidentID: incident.ID,
+		Cause:      incident.Cause,
+		StartedAt:  incident.StartedAt,
+		ResolvedAt: incident.ResolvedAt,
+	}
+}
+
+// OccurredAt is when the event happened
+func (m Message) OccurredAt() time.Time {
+	if m.ResolvedAt != nil {
+		return *m.ResolvedAt
+	}
+	return m.StartedAt
+}
+
+// link is where chat messages point readers: the dashboard page when one is
+// configured, the website itself otherwise
+func (m Message) link() string {
+	if m.Link != "" {
+		return m.Link
+	}
+	return m.URL
+}
+
+// Subject is a one-line human readable summary
+func (m Message) Subject() string {
+	if m.Event == EventIncidentResolved && m.ResolvedAt != nil {
+		if m.Degraded {
+			return fmt.Sprintf("%s is no longer degraded after %s", m.URL, m.ResolvedAt.Sub(m.StartedAt).Round(time.Second))
+		}
+		return fmt.Sprintf("%s is back up after %s", m.URL, m.ResolvedAt.Sub(m.StartedAt).Round(time.Second))
+	}
+	if m.Status == StatusDegraded {
+		return fmt.Sprintf("%s is degraded", m.URL)
+	}
+	return fmt.Sprintf("%s is down", m.URL)
+}
+
+// Text is the plain-text body used by email and chat channels
+func (m Message) Text() string {
+	text := m.Subject()
+	if m.Event == EventIncidentOpened && m.Cause != "" {
+		text += ": " + m.Cause
+	}
+	return text + fmt.Sprintf("\nIncident %s started at %s", m.IncidentID, m.StartedAt.UTC().Format(time.RFC3339))
+}
+
+// Channel delivers a message to one destination
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Options are the shared dependencies channels are built with
+type Options struct {
+	HTTPClient *http.Client
+	SMTP       SMTPConfig
+	Chat       ChatOptions
+	Webhook    WebhookOptions
+
+	PagerDutyEventsURL string
+}
+
+// NewChannel builds the Channel described by a stored configuration
+func NewChannel(channel models.NotificationChannel, opts Options) (Channel, error) {
+	switch channel.Type {
+	case models.ChannelWebhook:
+		return NewWebhookChannel(channel.ID, channel.Target, opts.HTTPClient, opts.Webhook), nil
+	case models.ChannelSlack:
+		return NewSlackChannel(channel.Target, opts.HTTPClient, opts.Chat), nil
+	case models.ChannelDiscord:
+		return NewDiscordChannel(channel.Target, opts.HTTPClient, opts.Chat), nil
+	case models.ChannelPagerDuty:
+		return NewPagerDutyChannel(channel.Target, opts.PagerDutyEventsURL, opts.HTTPClient, opts.Chat.Retries), nil
+	case models.ChannelEmail:
+		return NewEmailChannel(channel.Target, opts.SMTP)
+	default:
+		return nil, fmt.Errorf("unknown notification channel type %q", channel.Type)
+	}
+}
+
+// deliveryDeadline bounds one notification to one channel, including retries
+// and time queued behind the chat rate limit. Single attempts are bounded by
+// NOTIFICATION_TIMEOUT through the HTTP client.
+const deliveryDeadline = 2 * time.Minute
+
+// Dispatcher fans a message out to every channel configured for a website
+type Dispatcher struct {
+	db   *gorm.DB
+	cfg  *config.Config
+	opts Options
+}
+
+func NewDispatcher(db *gorm.DB, cfg *config.Config) *Dispatcher {
+	d := &Dispatcher{
+		db:  db,
+		cfg: cfg,
+		opts: Options{
+			HTTPClient: &http.Client{Timeout: cfg.NotificationTimeout},
+			SMTP: SMTPConfig{
+				Host:     cfg.SMTPHost,
+				Port:     cfg.SMTPPort,
+				Username: cfg.SMTPUsername,
+				Password: cfg.SMTPPassword,
+				From:     cfg.SMTPFrom,
+			},
+			Chat: ChatOptions{
+				Retries: cfg.NotificationRetries,
+				Limiter: NewRateLimiter(cfg.ChatRateInterval),
+			},
+			PagerDutyEventsURL: cfg.PagerDutyEventsURL,
+		},
+	}
+	d.opts.Webhook = WebhookOptions{
+		Retries: cfg.WebhookRetries,
+		Record:  d.recordDelivery,
+	}
+	return d
+}
+
+// recordDelivery stores a webhook delivery attempt in the delivery log
+func (d *Dispatcher) recordDelivery(attempt DeliveryAttempt) {
+	db, cancel := database.WithTimeout(context.Background(), d.db, d.cfg.DBQueryTimeout)
+	defer cancel()
+
+	delivery := models.WebhookDelivery{
+		ID:             uuid.New().String(),
+		ChannelID:      attempt.ChannelID,
+		WebsiteID:      attempt.WebsiteID,
+		URL:            attempt.URL,
+		Event:          string(attempt.Event),
+		Attempt:        attempt.Attempt,
+		StatusCode:     attempt.StatusCode,
+		ResponseTimeMs: attempt.ResponseTime.Milliseconds(),
+		Success:        attempt.Err == nil,
+		Status:         models.DeliveryDelivered,
+	}
+	if attempt.Err != nil {
+		delivery.Error = attempt.Err.Error()
+		delivery.Status = models.DeliveryRetrying
+		if attempt.Final {
+			delivery.Status = models.DeliveryFailed
+		}
+	}
+
+	if err := db.Create(&delivery).Error; err != nil {
+		log.Printf("⚠️  Failed to record webhook delivery for channel %s: %v", attempt.ChannelID, err)
+	}
+}
+
+// Notify sends msg to the enabled channels of website's owner that cover all of
+// their websites or this one. Failures are logged per channel, never returned.
+func (d *Dispatcher) Notify(website models.Website, msg Message) {
+	db, cancel := database.WithTimeout(context.Background(), d.db, d.cfg.DBQueryTimeout)
+	defer cancel()
+
+	if d.cfg.DashboardURL != "" {
+		msg.Link = strings.TrimRight(d.cfg.DashboardURL, "/") + "/website/" + website.ID
+	}
+
+	var channels []models.NotificationChannel
+	if err := db.Where("user_id = ? AND enabled = ? AND (website_id IS NULL OR website_id = ?)",
+		website.UserID, true, website.ID).
+		Find(&channels).Error; err != nil {
+		log.Printf("❌ Failed to load notification channels for %s: %v", website.URL, err)
+		return
+	}
+
+	// Webhooks are signed with the website's own secret
+	opts := d.opts
+	opts.Webhook.Secret = website.WebhookSecret
+
+	var wg sync.WaitGroup
+	for _, stored := range channels {
+		channel, err := NewChannel(stored, opts)
+		if err != nil {
+			log.Printf("⚠️  Skipping notification channel %s: %v", stored.ID, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(stored models.NotificationChannel, channel Channel) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), deliveryDeadline)
+			defer cancel()
+
+			if err := channel.Send(ctx, msg); err != nil {
+				log.Printf("❌ %s notification %s for %s failed: %v", stored.Type, stored.ID, website.URL, err)
+				return
+			}
+			log.Printf("📤 Sent %s notification for %s via %s", msg.Event, website.URL, stored.Type)
+		}(stored, channel)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,454 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// endpoint is a webhook receiver that records the requests it gets and answers
+// with the queued status codes, then 200
+type endpoint struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func newEndpoint(t *testing.T, statuses ...int) *endpoint {
+	t.Helper()
+
+	e := &endpoint{statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		e.mu.Lock()
+		e.requests = append(e.requests, r)
+		e.bodies = append(e.bodies, body)
+		status := http.StatusOK
+		if len(e.statuses) > 0 {
+			status, e.statuses = e.statuses[0], e.statuses[1:]
+		}
+		e.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *endpoint) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.bodies)
+}
+
+func (e *endpoint) request(t *testing.T, i int) *http.Request {
+	t.Helper()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i >= len(e.requests) {
+		t.Fatalf("expected at least %d requests, got %d", i+1, len(e.requests))
+	}
+	return e.requests[i]
+}
+
+// payload decodes the i-th request body into v
+func (e *endpoint) payload(t *testing.T, i int, v interface{}) {
+	t.Helper()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i >= len(e.bodies) {
+		t.Fatalf("expected at least %d requests, got %d", i+1, len(e.bodies))
+	}
+	if err := json.Unmarshal(e.bodies[i], v); err != nil {
+		t.Fatalf("decoding request body %s: %v", e.bodies[i], err)
+	}
+}
+
+// testMessage is an incident on https://example.com that opened an hour ago and,
+// when resolved is set, recovered just now
+func testMessage(resolved bool) Message {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	msg := Message{
+		Event:      EventIncidentOpened,
+		WebsiteID:  "website-1",
+		URL:        "https://example.com",
+		Status:     StatusDown,
+		Latency:    1234,
+		IncidentID: "incident-1",
+		Cause:      "connection refused",
+		StartedAt:  started,
+	}
+	if resolved {
+		resolvedAt := started.Add(time.Hour)
+		msg.Event, msg.Status, msg.ResolvedAt, msg.Latency = EventIncidentResolved, StatusUp, &resolvedAt, 87
+	}
+	return msg
+}
+
+// degradedMessage is testMessage for an incident about a slow website
+func degradedMessage(resolved bool) Message {
+	msg := testMessage(resolved)
+	msg.Degraded, msg.Cause = true, "slow response"
+	if !resolved {
+		msg.Status = StatusDegraded
+	}
+	return msg
+}
+
+func TestIncidentMessage(t *testing.T) {
+	website := models.Website{ID: "website-1", URL: "https://example.com"}
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolved := started.Add(time.Hour)
+	tick := models.WebsiteTick{Latency: 87}
+
+	tests := []struct {
+		name     string
+		incident models.Incident
+		status   string
+		degraded bool
+		subject  string
+	}{
+		{"outage opened", models.Incident{Severity: models.StatusBad}, StatusDown, false, "https://example.com is down"},
+		{"outage resolved", models.Incident{Severity: models.StatusBad, ResolvedAt: &resolved}, StatusUp, false,
+			"https://example.com is back up after 1h0m0s"},
+		{"degraded opened", models.Incident{Severity: models.StatusDegraded}, StatusDegraded, true, "https://example.com is degraded"},
+		{"degraded resolved", models.Incident{Severity: models.StatusDegraded, ResolvedAt: &resolved}, StatusUp, true,
+			"https://example.com is no longer degraded after 1h0m0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.incident.ID, tt.incident.StartedAt = "incident-1", started
+			msg := IncidentMessage(website, tt.incident, tick)
+			if msg.Status != tt.status || msg.Degraded != tt.degraded {
+				t.Errorf("status %q, degraded %v, want %q, %v", msg.Status, msg.Degraded, tt.status, tt.degraded)
+			}
+			if got := msg.Subject(); got != tt.subject {
+				t.Errorf("subject = %q, want %q", got, tt.subject)
+			}
+		})
+	}
+}
+
+func TestWebhookChannelPostsMessage(t *testing.T) {
+	receiver := newEndpoint(t)
+	msg := testMessage(false)
+
+	if err := NewWebhookChannel("channel-1", receiver.URL, nil, WebhookOptions{}).Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var got Message
+	receiver.payload(t, 0, &got)
+	if got.Event != msg.Event || got.WebsiteID != msg.WebsiteID || got.IncidentID != msg.IncidentID ||
+		got.Status != StatusDown || got.Cause != msg.Cause || !got.StartedAt.Equal(msg.StartedAt) {
+		t.Errorf("posted %+v, want %+v", got, msg)
+	}
+	if ct := receiver.request(t, 0).Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestWebhookChannelRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // answered before falling back to 200
+		retries  int
+		want     []int // status code of each attempt
+		wantErr  bool
+	}{
+		{"delivered first time", nil, 2, []int{200}, false},
+		{"retried then delivered", []int{503}, 2, []int{503, 200}, false},
+		{"retries exhausted", []int{502, 502}, 1, []int{502, 502}, true},
+		{"client errors are not retried", []int{404}, 2, []int{404}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newEndpoint(t, tt.statuses...)
+			var attempts []DeliveryAttempt
+			record := func(a DeliveryAttempt) { attempts = append(attempts, a) }
+
+			err := NewWebhookChannel("channel-1", receiver.URL, nil, WebhookOptions{Retries: tt.retries, Record: record}).
+				Send(context.Background(), testMessage(false))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send: %v, want error %v", err, tt.wantErr)
+			}
+
+			if len(attempts) != len(tt.want) || receiver.count() != len(tt.want) {
+				t.Fatalf("recorded %d attempts over %d posts, want %d", len(attempts), receiver.count(), len(tt.want))
+			}
+			for i, a := range attempts {
+				last := i == len(attempts)-1
+				if a.Attempt != i+1 || a.StatusCode != tt.want[i] || a.Final != last || (a.Err == nil) != (a.StatusCode == 200) {
+					t.Errorf("attempt %d = %+v, want status %d, final %v", i+1, a, tt.want[i], last)
+				}
+				if a.ChannelID != "channel-1" || a.WebsiteID != "website-1" || a.URL != receiver.URL || a.Event != EventIncidentOpened {
+					t.Errorf("attempt %d describes %s/%s/%s/%s", i+1, a.ChannelID, a.WebsiteID, a.URL, a.Event)
+				}
+			}
+		})
+	}
+}
+
+// deliveries lists the logged delivery attempts of channelID, oldest first
+func deliveries(t *testing.T, db *gorm.DB, channelID string) []models.WebhookDelivery {
+	t.Helper()
+
+	var logged []models.WebhookDelivery
+	if err := db.Where("channel_id = ?", channelID).Order("attempt").Find(&logged).Error; err != nil {
+		t.Fatal(err)
+	}
+	return logged
+}
+
+func TestDispatcherLogsWebhookDeliveries(t *testing.T) {
+	db := dbtest.Open(t)
+	cfg := config.Load()
+	cfg.WebhookRetries = 1
+	d := NewDispatcher(db, cfg)
+
+	user := createUser(t, db)
+	website := createWebsite(t, db, user.ID)
+	delivered, recovered, exhausted := newEndpoint(t), newEndpoint(t, http.StatusServiceUnavailable),
+		newEndpoint(t, http.StatusBadGateway, http.StatusBadGateway)
+
+	tests := []struct {
+		name    string
+		channel models.NotificationChannel
+		want    []string // status of each logged attempt
+		codes   []int
+	}{
+		{"delivered", createChannel(t, db, user.ID, "", models.ChannelWebhook, delivered.URL, true),
+			[]string{models.DeliveryDelivered}, []int{200}},
+		{"retried then delivered", createChannel(t, db, user.ID, "", models.ChannelWebhook, recovered.URL, true),
+			[]string{models.DeliveryRetrying, models.DeliveryDelivered}, []int{503, 200}},
+		{"exhausted", createChannel(t, db, user.ID, "", models.ChannelWebhook, exhausted.URL, true),
+			[]string{models.DeliveryRetrying, models.DeliveryFailed}, []int{502, 502}},
+	}
+
+	msg := testMessage(false)
+	msg.WebsiteID, msg.URL = website.ID, website.URL
+	d.Notify(website, msg)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logged := deliveries(t, db, tt.channel.ID)
+			if len(logged) != len(tt.want) {
+				t.Fatalf("logged %d attempts, want %d", len(logged), len(tt.want))
+			}
+			for i, delivery := range logged {
+				success := tt.want[i] == models.DeliveryDelivered
+				if delivery.Status != tt.want[i] || delivery.StatusCode != tt.codes[i] || delivery.Success != success ||
+					delivery.Attempt != i+1 || (delivery.Error == "") != success {
+					t.Errorf("attempt %d logged as %+v, want %s with status code %d", i+1, delivery, tt.want[i], tt.codes[i])
+				}
+				if delivery.WebsiteID != website.ID || delivery.URL != tt.channel.Target || delivery.Event != string(EventIncidentOpened) {
+					t.Errorf("attempt %d logged for %s, %s, %s", i+1, delivery.WebsiteID, delivery.URL, delivery.Event)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailChannelSendsMail(t *testing.T) {
+	cfg := SMTPConfig{Host: "smtp.example.com", Port: 2525, Username: "mailer", Password: "secret", From: "alerts@example.com"}
+	email, err := NewEmailChannel("ops@example.com", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotAuth smtp.Auth
+	var gotBody []byte
+	email.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotBody = addr, auth, from, to, msg
+		return nil
+	}
+
+	msg := testMessage(false)
+	if err := email.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotAddr != "smtp.example.com:2525" || gotFrom != cfg.From || len(gotTo) != 1 || gotTo[0] != "ops@example.com" {
+		t.Errorf("sent to %s from %s to %v", gotAddr, gotFrom, gotTo)
+	}
+	if gotAuth == nil {
+		t.Error("expected SMTP auth with a username configured")
+	}
+	for _, want := range []string{"To: ops@example.com\r\n", "Subject: " + msg.Subject() + "\r\n", "\r\n\r\n" + msg.Text()} {
+		if !strings.Contains(string(gotBody), want) {
+			t.Errorf("mail %q lacks %q", gotBody, want)
+		}
+	}
+}
+
+func TestEmailChannelErrors(t *testing.T) {
+	if _, err := NewEmailChannel("ops@example.com", SMTPConfig{Host: "smtp.example.com"}); err == nil {
+		t.Error("expected an error without SMTP_FROM")
+	}
+
+	email, err := NewEmailChannel("ops@example.com", SMTPConfig{Host: "smtp.example.com", From: "alerts@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	email.sendMail = func(string, smtp.Auth, string, []string, []byte) error { return errors.New("550 mailbox unavailable") }
+	if err := email.Send(context.Background(), testMessage(false)); err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("expected the SMTP error, got %v", err)
+	}
+
+	// A hung server gives up at the caller's deadline
+	release := make(chan struct{})
+	defer close(release)
+	email.sendMail = func(string, smtp.Auth, string, []string, []byte) error { <-release; return nil }
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := email.Send(ctx, testMessage(false)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestNewChannel(t *testing.T) {
+	opts := Options{SMTP: SMTPConfig{Host: "smtp.example.com", From: "alerts@example.com"}}
+
+	tests := []struct {
+		channelType string
+		want        interface{}
+	}{
+		{models.ChannelWebhook, &WebhookChannel{}},
+		{models.ChannelEmail, &EmailChannel{}},
+		{models.ChannelSlack, &SlackChannel{}},
+		{models.ChannelDiscord, &DiscordChannel{}},
+		{models.ChannelPagerDuty, &PagerDutyChannel{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channelType, func(t *testing.T) {
+			channel, err := NewChannel(models.NotificationChannel{Type: tt.channelType, Target: "https://example.com/hook"}, opts)
+			if err != nil {
+				t.Fatalf("NewChannel: %v", err)
+			}
+			if got, want := fmt.Sprintf("%T", channel), fmt.Sprintf("%T", tt.want); got != want {
+				t.Errorf("built %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewChannel(models.NotificationChannel{Type: "carrier-pigeon"}, opts); err == nil {
+		t.Error("expected an error for an unknown channel type")
+	}
+}
+
+// createChannel stores a notification channel of userID, for every website when
+// websiteID is empty
+func createChannel(t *testing.T, db *gorm.DB, userID, websiteID, channelType, target string, enabled bool) models.NotificationChannel {
+	t.Helper()
+
+	channel := models.NotificationChannel{ID: uuid.New().String(), UserID: userID, Type: channelType, Target: target, Enabled: true}
+	if websiteID != "" {
+		channel.WebsiteID = &websiteID
+	}
+	if err := db.Create(&channel).Error; err != nil {
+		t.Fatalf("creating channel: %v", err)
+	}
+	// Create skips the false zero value in favour of the column default
+	if !enabled {
+		if err := db.Model(&channel).Update("enabled", false).Error; err != nil {
+			t.Fatalf("disabling channel: %v", err)
+		}
+	}
+	return channel
+}
+
+func createWebsite(t *testing.T, db *gorm.DB, userID string) models.Website {
+	t.Helper()
+
+	website := models.Website{ID: uuid.New().String(), UserID: userID, URL: "https://" + uuid.New().String() + ".example.com"}
+	if err := db.Create(&website).Error; err != nil {
+		t.Fatalf("creating website: %v", err)
+	}
+	return website
+}
+
+func createUser(t *testing.T, db *gorm.DB) models.User {
+	t.Helper()
+
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+func TestDispatcherFansOut(t *testing.T) {
+	db := dbtest.Open(t)
+	cfg := config.Load()
+	cfg.ChatRateInterval = 0
+	cfg.NotificationRetries = 0
+	cfg.WebhookRetries = 0
+	cfg.DashboardURL = "https://dashboard.example.com/"
+	cfg.SMTPHost, cfg.SMTPFrom = "", ""
+	d := NewDispatcher(db, cfg)
+
+	user, other := createUser(t, db), createUser(t, db)
+	website, sibling := createWebsite(t, db, user.ID), createWebsite(t, db, user.ID)
+
+	allWebsites, thisWebsite := newEndpoint(t), newEndpoint(t)
+	siblingOnly, disabled, otherUser := newEndpoint(t), newEndpoint(t), newEndpoint(t)
+	failing := newEndpoint(t, http.StatusInternalServerError)
+
+	createChannel(t, db, user.ID, "", models.ChannelWebhook, allWebsites.URL, true)
+	createChannel(t, db, user.ID, website.ID, models.ChannelSlack, thisWebsite.URL, true)
+	createChannel(t, db, user.ID, website.ID, models.ChannelDiscord, failing.URL, true)
+	createChannel(t, db, user.ID, sibling.ID, models.ChannelWebhook, siblingOnly.URL, true)
+	createChannel(t, db, user.ID, "", models.ChannelWebhook, disabled.URL, false)
+	createChannel(t, db, other.ID, "", models.ChannelWebhook, otherUser.URL, true)
+	// Can't be built without SMTP settings; the others are still notified
+	createChannel(t, db, user.ID, "", models.ChannelEmail, "ops@example.com", true)
+
+	msg := testMessage(false)
+	msg.WebsiteID, msg.URL = website.ID, website.URL
+	d.Notify(website, msg)
+
+	for name, tt := range map[string]struct {
+		endpoint *endpoint
+		want     int
+	}{
+		"all websites":  {allWebsites, 1},
+		"this website":  {thisWebsite, 1},
+		"failing":       {failing, 1},
+		"other website": {siblingOnly, 0},
+		"disabled":      {disabled, 0},
+		"other user":    {otherUser, 0},
+	} {
+		if got := tt.endpoint.count(); got != tt.want {
+			t.Errorf("%s channel: got %d requests, want %d", name, got, tt.want)
+		}
+	}
+
+	var posted Message
+	allWebsites.payload(t, 0, &posted)
+	if want := "https://dashboard.example.com/website/" + website.ID; posted.Link != want {
+		t.Errorf("link = %q, want %q", posted.Link, want)
+	}
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyChannel triggers a PagerDuty alert when an incident opens and
+// resolves it when the incident does
+type PagerDutyChannel struct {
+	routingKey string
+	poster     chatPoster
+}
+
+func NewPagerDutyChannel(routingKey, eventsURL string, client *http.Client, retries int) *PagerDutyChannel {
+	if eventsURL == "" {
+		eventsURL = DefaultPagerDutyEventsURL
+	}
+	return &PagerDutyChannel{
+		routingKey: routingKey,
+		poster:     newChatPoster(eventsURL, client, ChatOptions{Retries: retries}),
+	}
+}
+
+// PagerDutyDedupKey ties a website's trigger and resolve events to one alert.
+// Only one incident per website is ever ongoing, so the website id is enough.
+func PagerDutyDedupKey(websiteID string) string {
+	return "gopher-uptime/website/" + websiteID
+}
+
+func (p *PagerDutyChannel) Send(ctx context.Context, msg Message) error {
+	return p.poster.post(ctx, pagerDutyEvent(p.routingKey, msg))
+}
+
+func pagerDutyEvent(routingKey string, msg Message) map[string]interface{} {
+	event := map[string]interface{}{
+		"routing_key": routingKey,
+		"dedup_key":   PagerDutyDedupKey(msg.WebsiteID),
+	}
+
+	if msg.Event == EventIncidentResolved {
+		event["event_action"] = "resolve"
+		return event
+	}
+
+	severity := "critical"
+	if msg.Status == StatusDegraded {
+		severity = "warning"
+	}
+	event["event_action"] = "trigger"
+	event["payload"] = map[string]interface{}{
+		"summary":   msg.Subject(),
+		"source":    msg.URL,
+		"severity":  severity,
+		"timestamp": msg.StartedAt.UTC().Format(time.RFC3339),
+		"component": msg.WebsiteID,
+		"custom_details": map[string]interface{}{
+			"incident_id": msg.IncidentID,
+			"cause":       msg.Cause,
+			"latency_ms":  msg.Latency,
+		},
+	}
+	event["links"] = []map[string]string{{"href": msg.link(), "text": "Website"}}
+	return event
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type pagerDutyRequest struct {
+	RoutingKey  string `json:"routing_key"`
+	DedupKey    string `json:"dedup_key"`
+	EventAction string `json:"event_action"`
+	Payload     *struct {
+		Summary       string                 `json:"summary"`
+		Source        string                 `json:"source"`
+		Severity      string                 `json:"severity"`
+		Timestamp     string                 `json:"timestamp"`
+		Component     string                 `json:"component"`
+		CustomDetails map[string]interface{} `json:"custom_details"`
+	} `json:"payload"`
+	Links []struct {
+		Href string `json:"href"`
+	} `json:"links"`
+}
+
+const routingKey = "0123456789abcdef0123456789abcdef"
+
+func TestPagerDutyTriggersAndResolves(t *testing.T) {
+	events := newEndpoint(t)
+	channel := NewPagerDutyChannel(routingKey, events.URL, nil, 0)
+
+	for _, msg := range []Message{testMessage(false), testMessage(true)} {
+		if err := channel.Send(context.Background(), msg); err != nil {
+			t.Fatalf("sending %s: %v", msg.Event, err)
+		}
+	}
+
+	var trigger, resolve pagerDutyRequest
+	events.payload(t, 0, &trigger)
+	events.payload(t, 1, &resolve)
+
+	if trigger.EventAction != "trigger" || resolve.EventAction != "resolve" {
+		t.Errorf("actions = %q then %q, want trigger then resolve", trigger.EventAction, resolve.EventAction)
+	}
+	if trigger.RoutingKey != routingKey || resolve.RoutingKey != routingKey {
+		t.Errorf("routing keys = %q and %q, want %q", trigger.RoutingKey, resolve.RoutingKey, routingKey)
+	}
+	if want := PagerDutyDedupKey("website-1"); trigger.DedupKey != want || resolve.DedupKey != want {
+		t.Errorf("dedup keys = %q and %q, want both %q", trigger.DedupKey, resolve.DedupKey, want)
+	}
+
+	if trigger.Payload == nil {
+		t.Fatal("trigger has no payload")
+	}
+	p := trigger.Payload
+	if p.Summary != "https://example.com is down" || p.Source != "https://example.com" || p.Severity != "critical" ||
+		p.Timestamp != "2024-05-01T12:00:00Z" || p.Component != "website-1" {
+		t.Errorf("trigger payload = %+v", p)
+	}
+	if p.CustomDetails["incident_id"] != "incident-1" || p.CustomDetails["cause"] != "connection refused" {
+		t.Errorf("custom details = %v", p.CustomDetails)
+	}
+	if len(trigger.Links) != 1 || trigger.Links[0].Href != "https://example.com" {
+		t.Errorf("links = %+v", trigger.Links)
+	}
+	if resolve.Payload != nil {
+		t.Errorf("resolve carries a payload: %+v", resolve.Payload)
+	}
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      Message
+		severity string
+	}{
+		{"outage", testMessage(false), "critical"},
+		{"degraded", degradedMessage(false), "warning"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := newEndpoint(t)
+			if err := NewPagerDutyChannel(routingKey, events.URL, nil, 0).Send(context.Background(), tt.msg); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			var got pagerDutyRequest
+			events.payload(t, 0, &got)
+			if got.Payload == nil || got.Payload.Severity != tt.severity {
+				t.Errorf("payload = %+v, want severity %q", got.Payload, tt.severity)
+			}
+		})
+	}
+}
+
+func TestPagerDutyDedupKeyPerWebsite(t *testing.T) {
+	if PagerDutyDedupKey("website-1") == PagerDutyDedupKey("website-2") {
+		t.Error("different websites share a dedup key")
+	}
+	if PagerDutyDedupKey("website-1") != PagerDutyDedupKey("website-1") {
+		t.Error("a website's dedup key isn't stable")
+	}
+}
+
+func TestPagerDutyRetriesThrottledEvents(t *testing.T) {
+	events := newEndpoint(t, http.StatusTooManyRequests)
+	if err := NewPagerDutyChannel(routingKey, events.URL, nil, 1).Send(context.Background(), testMessage(false)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var first, second pagerDutyRequest
+	events.payload(t, 0, &first)
+	events.payload(t, 1, &second)
+	if first.DedupKey != second.DedupKey || second.EventAction != "trigger" {
+		t.Errorf("retry sent %+v after %+v", second, first)
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignKnownVector(t *testing.T) {
+	// Computed independently: HMAC-SHA256("whsec_test", "1700000000.<body>")
+	const want = "sha256=bf638f65f48a13c3f916376108ace58be2fd0067770ffd2dfc528d2a86d857f1"
+	body := []byte(`{"event":"incident.opened"}`)
+
+	if got := Sign("whsec_test", 1700000000, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	for name, sig := range map[string]string{
+		"other secret":    Sign("whsec_other", 1700000000, body),
+		"other timestamp": Sign("whsec_test", 1700000001, body),
+		"other body":      Sign("whsec_test", 1700000000, []byte(`{"event":"incident.resolved"}`)),
+	} {
+		if sig == want {
+			t.Errorf("%s produced the same signature", name)
+		}
+	}
+}
+
+func TestWebhookChannelSignsPayload(t *testing.T) {
+	const secret = "whsec_test"
+	receiver := newEndpoint(t)
+
+	before := time.Now().Unix()
+	opts := WebhookOptions{Secret: secret}
+	if err := NewWebhookChannel("channel-1", receiver.URL, nil, opts).Send(context.Background(), testMessage(false)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := receiver.request(t, 0)
+	timestamp, err := strconv.ParseInt(req.Header.Get(SignatureTimestampHeader), 10, 64)
+	if err != nil || timestamp < before || timestamp > time.Now().Unix() {
+		t.Fatalf("timestamp header = %q, want the unix time of the send", req.Header.Get(SignatureTimestampHeader))
+	}
+
+	// Verify the way a receiver would, over the exact bytes received
+	receiver.mu.Lock()
+	body := receiver.bodies[0]
+	receiver.mu.Unlock()
+	if got := req.Header.Get(SignatureHeader); !hmac.Equal([]byte(got), []byte(Sign(secret, timestamp, body))) {
+		t.Errorf("signature %q doesn't verify against the body", got)
+	}
+}
+
+func TestWebhookChannelUnsignedWithoutSecret(t *testing.T) {
+	receiver := newEndpoint(t)
+	if err := NewWebhookChannel("channel-1", receiver.URL, nil, WebhookOptions{}).Send(context.Background(), testMessage(false)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := receiver.request(t, 0)
+	if req.Header.Get(SignatureHeader) != "" || req.Header.Get(SignatureTimestampHeader) != "" {
+		t.Errorf("unsigned webhook sent signature headers %v", req.Header)
+	}
+}
+
+func TestNewWebhookSecret(t *testing.T) {
+	a, b := NewWebhookSecret(), NewWebhookSecret()
+	if len(a) != 64 || a == b {
+		t.Errorf("secrets %q and %q, want two distinct 64-character hex strings", a, b)
+	}
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SlackChannel posts a formatted message to a Slack incoming webhook
+type SlackChannel struct {
+	poster chatPoster
+}
+
+func NewSlackChannel(webhookURL string, client *http.Client, opts ChatOptions) *SlackChannel {
+	return &SlackChannel{poster: newChatPoster(webhookURL, client, opts)}
+}
+
+func (s *SlackChannel) Send(ctx context.Context, msg Message) error {
+	return s.poster.post(ctx, slackPayload(msg))
+}
+
+// slackPayload renders msg as Block Kit; text is the fallback used in
+// notifications and by clients without block support
+func slackPayload(msg Message) map[string]interface{} {
+	icon := ":red_circle:"
+	switch msg.Status {
+	case StatusUp:
+		icon = ":large_green_circle:"
+	case StatusDegraded:
+		icon = ":large_yellow_circle:"
+	}
+
+	fields := []map[string]string{
+		{"type": "mrkdwn", "text": "*Site*\n<" + msg.URL + ">"},
+		{"type": "mrkdwn", "text": "*Status*\n" + statusLabel(msg.Status)},
+		{"type": "mrkdwn", "text": "*Latency*\n" + latencyLabel(msg.Latency)},
+		{"type": "mrkdwn", "text": "*Time*\n" + msg.OccurredAt().UTC().Format(time.RFC3339)},
+	}
+
+	detail := "Incident `" + msg.IncidentID + "`"
+	if msg.Status != StatusUp && msg.Cause != "" {
+		detail += ": " + msg.Cause
+	}
+
+	return map[string]interface{}{
+		"text": icon + " " + msg.Subject(),
+		"blocks": []map[string]interface{}{
+			{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": fmt.Sprintf("%s *<%s|%s>*", icon, msg.link(), msg.Subject())},
+			},
+			{
+				"type":   "section",
+				"fields": fields,
+			},
+			{
+				"type":     "context",
+				"elements": []map[string]string{{"type": "mrkdwn", "text": detail}},
+			},
+		},
+	}
+}
+
+func statusLabel(status string) string {
+	switch status {
+	case StatusUp:
+		return "Up"
+	case StatusDegraded:
+		return "Degraded"
+	}
+	return "Down"
+}
+
+func latencyLabel(latency float64) string {
+	if latency <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f ms", latency)
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// statusError is a non-2xx response; retryAfter is the delay the server asked for
+type statusError struct {
+	code       int
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.code)
+}
+
+// postJSON sends body to url and treats any non-2xx response as a failure
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	_, err = postPayload(ctx, client, url, payload, nil)
+	return err
+}
+
+// postPayload sends an encoded JSON payload with any extra headers and returns
+// the response status code, 0 when no response arrived
+func postPayload(ctx context.Context, client *http.Client, url string, payload []byte, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := &statusError{code: resp.StatusCode}
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			err.retryAfter = time.Duration(seconds) * time.Second
+		}
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+// DeliveryAttempt describes one POST to a webhook channel
+type DeliveryAttempt struct {
+	ChannelID    string
+	WebsiteID    string
+	URL          string
+	Event        Event
+	Attempt      int // 1 for the first try
+	StatusCode   int // 0 when no response arrived
+	ResponseTime time.Duration
+	Err          error
+	Final        bool // no further attempt follows
+}
+
+// WebhookOptions control delivery to webhook channels
+type WebhookOptions struct {
+	Retries int                   // extra attempts after a failed delivery
+	Record  func(DeliveryAttempt) // called after every attempt; may be nil
+	Secret  string                // the website's signing secret; empty sends unsigned requests
+}
+
+// WebhookChannel POSTs the message as JSON to a user-supplied URL, signed with
+// the website's secret and retried with exponential backoff on network errors,
+// 429s and 5xx responses
+type WebhookChannel struct {
+	id     string
+	url    string
+	client *http.Client
+	opts   WebhookOptions
+}
+
+// NewWebhookChannel builds the webhook channel stored as id
+func NewWebhookChannel(id, url string, client *http.Client, opts WebhookOptions) *WebhookChannel {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookChannel{id: id, url: url, client: client, opts: opts}
+}
+
+func (w *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		code, err := postPayload(ctx, w.client, w.url, payload, signatureHeaders(w.opts.Secret, payload, start))
+		final := err == nil || attempt > w.opts.Retries || !retryable(err) || ctx.Err() != nil
+
+		if w.opts.Record != nil {
+			w.opts.Record(DeliveryAttempt{
+				ChannelID:    w.id,
+				WebsiteID:    msg.WebsiteID,
+				URL:          w.url,
+				Event:        msg.Event,
+				Attempt:      attempt,
+				StatusCode:   code,
+				ResponseTime: time.Since(start),
+				Err:          err,
+				Final:        final,
+			})
+		}
+		if final {
+			return err
+		}
+
+		delay := backoff
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.retryAfter > 0 {
+			delay = statusErr.retryAfter
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,203 @@
+// Package openapi builds the API's OpenAPI 3.0 description from its router and
+// handler types, and checks request bodies against it.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Security schemes routes can require
+const (
+	BearerAuth = "bearerAuth" // JWT from /api/v1/auth/login
+	AdminAuth  = "adminToken" // ADMIN_TOKEN in X-Admin-Token
+)
+
+// Route describes what the router doesn't know about an endpoint
+type Route struct {
+	Summary  string
+	Auth     string      // BearerAuth, AdminAuth or empty for public routes
+	Query    []string    // optional query parameters
+	Request  interface{} // a value of the JSON body type, nil without a body
+	Response interface{} // a value of the type in the success envelope's data, nil for any
+	Status   int         // success status, 200 when zero
+	Raw      bool        // the response isn't wrapped in the success envelope
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+
+	bodies map[string]*Schema // request schemas by method and gin route path
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// New returns an empty document; routes are added once they're registered
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				AdminAuth:  {Type: "apiKey", In: "header", Name: "X-Admin-Token"},
+			},
+		},
+		bodies: map[string]*Schema{},
+	}
+}
+
+// AddRoutes describes every route of the router, using described (keyed by
+// "METHOD /path" as registered) for their bodies, responses and auth. Routes
+// missing from it are still listed, with untyped responses.
+func (d *Document) AddRoutes(routes gin.RoutesInfo, described map[string]Route) {
+	errorSchema := d.schemaOf(reflect.TypeOf(utils.Response{}))
+
+	for _, info := range routes {
+		route := described[info.Method+" "+info.Path]
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		op := Operation{
+			OperationID: operationID(info.Method, info.Path),
+			Summary:     route.Summary,
+			Tags:        []string{tag(info.Path)},
+			Responses: map[string]Response{
+				strconv.Itoa(status): {
+					Description: http.StatusText(status),
+					Content:     jsonContent(d.responseSchema(route)),
+				},
+				"default": {Description: "Error", Content: jsonContent(errorSchema)},
+			},
+		}
+
+		path := info.Path
+		for _, segment := range strings.Split(info.Path, "/") {
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				name := segment[1:]
+				path = strings.Replace(path, segment, "{"+name+"}", 1)
+				op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+			}
+		}
+		for _, name := range route.Query {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+		}
+
+		if route.Request != nil {
+			body := d.schemaOf(reflect.TypeOf(route.Request))
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(body)}
+			d.bodies[info.Method+" "+info.Path] = body
+		}
+		if route.Auth != "" {
+			op.Security = []map[string][]string{{route.Auth: {}}}
+		}
+
+		if d.Paths[path] == nil {
+			d.Paths[path] = map[string]Operation{}
+		}
+		d.Paths[path][strings.ToLower(info.Method)] = op
+	}
+}
+
+// responseSchema is the route's success response: the envelope, with data of
+// the route's response type
+func (d *Document) responseSchema(route Route) *Schema {
+	dataSchema := &Schema{}
+	if route.Response != nil {
+		dataSchema = d.schemaOf(reflect.TypeOf(route.Response))
+	}
+	if route.Raw {
+		return dataSchema
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"data":    dataSchema,
+		},
+		Required: []string{"success"},
+	}
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// operationID derives a stable id from the route, e.g. GET /api/v1/website/:id/ticks
+// becomes get_website_id_ticks
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/v1"), "/") {
+		segment = strings.Trim(segment, ":*")
+		segment = strings.NewReplacer("-", "_", ".", "_").Replace(segment)
+		if segment != "" {
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// tag groups a route by its first segment under /api/v1
+func tag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+	return strings.Trim(segments[0], ":*")
+}
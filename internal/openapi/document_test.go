@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testItem struct {
+	Name string `json:"name" binding:"required,max=5"`
+}
+
+type testRequest struct {
+	URL      string     `json:"url" binding:"required,url"`
+	Interval int        `json:"interval" binding:"omitempty,min=1,max=60"`
+	Family   string     `json:"family" binding:"omitempty,oneof=ipv4 ipv6"`
+	Ratio    float64    `json:"ratio" binding:"omitempty,gt=0,lt=100"`
+	Items    []testItem `json:"items" binding:"omitempty,max=2,dive"`
+	Tags     []string   `json:"tags" binding:"omitempty,dive,required,max=3"`
+	Internal string     `json:"-"`
+}
+
+type testResponse struct {
+	ID string `json:"id"`
+}
+
+// testDocument describes a small router: a typed POST, a GET with a path and
+// query parameter, and a route missing from the descriptions
+func testDocument() *Document {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {}
+	router.POST("/api/v1/website", handler)
+	router.GET("/api/v1/website/:id/ticks", handler)
+	router.GET("/api/v1/read-only", handler)
+	router.GET("/undescribed", handler)
+
+	d := New("Test API", "1.2.3")
+	d.AddRoutes(router.Routes(), map[string]Route{
+		"POST /api/v1/website":          {Summary: "Create", Auth: BearerAuth, Request: testRequest{}, Response: testResponse{}, Status: http.StatusCreated},
+		"GET /api/v1/website/:id/ticks": {Summary: "Ticks", Auth: BearerAuth, Query: []string{"page"}},
+		"GET /api/v1/read-only":         {Summary: "Read-only state", Auth: AdminAuth},
+	})
+	return d
+}
+
+func TestAddRoutes(t *testing.T) {
+	d := testDocument()
+	if d.OpenAPI != "3.0.3" || d.Info.Title != "Test API" || d.Info.Version != "1.2.3" {
+		t.Errorf("document header %s %+v", d.OpenAPI, d.Info)
+	}
+
+	create, ok := d.Paths["/api/v1/website"]["post"]
+	if !ok {
+		t.Fatalf("POST /api/v1/website not described; paths %v", d.Paths)
+	}
+	if create.OperationID != "post_website" || create.Summary != "Create" || !reflect.DeepEqual(create.Tags, []string{"website"}) {
+		t.Errorf("create is %s %q tagged %v", create.OperationID, create.Summary, create.Tags)
+	}
+	if create.RequestBody == nil || !create.RequestBody.Required {
+		t.Fatal("create has no required request body")
+	}
+	if _, ok := create.Responses["201"]; !ok {
+		t.Errorf("create responses %v, want 201", create.Responses)
+	}
+	if !reflect.DeepEqual(create.Security, []map[string][]string{{BearerAuth: {}}}) {
+		t.Errorf("create security %v, want bearer", create.Security)
+	}
+
+	body := d.resolve(create.RequestBody.Content["application/json"].Schema)
+	if !reflect.DeepEqual(body.Required, []string{"url"}) {
+		t.Errorf("required fields %v, want url", body.Required)
+	}
+	if _, ok := body.Properties["Internal"]; ok {
+		t.Error(`json:"-" field is described`)
+	}
+	if url := body.Properties["url"]; url.Type != "string" || url.Format != "uri" {
+		t.Errorf("url is %+v, want a uri string", url)
+	}
+	if interval := body.Properties["interval"]; interval.Type != "integer" || *interval.Minimum != 1 || *interval.Maximum != 60 || !interval.OmitEmpty {
+		t.Errorf("interval is %+v, want an optional integer from 1 to 60", interval)
+	}
+	if family := body.Properties["family"]; !reflect.DeepEqual(family.Enum, []string{"ipv4", "ipv6"}) {
+		t.Errorf("family enum %v", family.Enum)
+	}
+	if ratio := body.Properties["ratio"]; !ratio.ExclusiveMinimum || !ratio.ExclusiveMaximum {
+		t.Errorf("ratio is %+v, want exclusive bounds", ratio)
+	}
+	if tags := body.Properties["tags"]; tags.Items == nil || *tags.Items.MaxLength != 3 {
+		t.Errorf("tags are %+v, want items of at most 3 characters", tags)
+	}
+
+	ticks, ok := d.Paths["/api/v1/website/{id}/ticks"]["get"]
+	if !ok {
+		t.Fatalf("GET /api/v1/website/{id}/ticks not described; paths %v", d.Paths)
+	}
+	wantParams := []Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "page", In: "query", Schema: &Schema{Type: "string"}},
+	}
+	if ticks.OperationID != "get_website_id_ticks" || !reflect.DeepEqual(ticks.Parameters, wantParams) {
+		t.Errorf("ticks is %s with parameters %+v", ticks.OperationID, ticks.Parameters)
+	}
+	if ticks.RequestBody != nil {
+		t.Error("GET route has a request body")
+	}
+
+	if readOnly := d.Paths["/api/v1/read-only"]["get"]; !reflect.DeepEqual(readOnly.Security, []map[string][]string{{AdminAuth: {}}}) {
+		t.Errorf("read-only security %v, want the admin token", readOnly.Security)
+	}
+	undescribed, ok := d.Paths["/undescribed"]["get"]
+	if !ok || undescribed.Security != nil || undescribed.Responses["200"].Description != "OK" {
+		t.Errorf("undescribed route is %+v, want a public route answering 200", undescribed)
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	d := testDocument()
+
+	tests := []struct {
+		name      string
+		method    string
+		route     string
+		body      string
+		wantRules map[string]string // field to rule
+	}{
+		{"valid", "POST", "/api/v1/website", `{"url": "https://example.com", "interval": 30, "items": [{"name": "a"}]}`, nil},
+		{"zero values skip optional rules", "POST", "/api/v1/website", `{"url": "https://example.com", "interval": 0, "family": "", "items": []}`, nil},
+		{"missing required", "POST", "/api/v1/website", `{"interval": 30}`, map[string]string{"url": "required"}},
+		{"bad format", "POST", "/api/v1/website", `{"url": "not a url"}`, map[string]string{"url": "format"}},
+		{"out of range", "POST", "/api/v1/website", `{"url": "https://example.com", "interval": 61, "ratio": 100}`, map[string]string{"interval": "maximum", "ratio": "maximum"}},
+		{"not an integer", "POST", "/api/v1/website", `{"url": "https://example.com", "interval": 1.5}`, map[string]string{"interval": "type"}},
+		{"wrong type", "POST", "/api/v1/website", `{"url": 42}`, map[string]string{"url": "type"}},
+		{"not in enum", "POST", "/api/v1/website", `{"url": "https://example.com", "family": "ipv5"}`, map[string]string{"family": "enum"}},
+		{"nested", "POST", "/api/v1/website", `{"url": "https://example.com", "items": [{"name": "toolong"}, {}]}`, map[string]string{"items[0].name": "maxLength", "items[1].name": "required"}},
+		{"too many items", "POST", "/api/v1/website", `{"url": "https://example.com", "items": [{"name": "a"}, {"name": "b"}, {"name": "c"}]}`, map[string]string{"items": "maxItems"}},
+		{"not an object", "POST", "/api/v1/website", `[1, 2]`, map[string]string{"body": "type"}},
+		{"empty body", "POST", "/api/v1/website", ``, nil},
+		{"malformed json", "POST", "/api/v1/website", `{"url":`, nil},
+		{"route without a body", "GET", "/api/v1/website/:id/ticks", `{"url": 42}`, nil},
+		{"unknown route", "POST", "/nowhere", `{"url": 42}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := d.ValidateBody(tt.method, tt.route, []byte(tt.body))
+			got := map[string]string{}
+			for _, v := range violations {
+				got[v.Field] = v.Rule
+				if v.Message == "" {
+					t.Errorf("%s violates %s without a message", v.Field, v.Rule)
+				}
+			}
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, tt.wantRules) {
+				t.Errorf("violations %v, want %v", violations, tt.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of an OpenAPI 3.0 schema object the API's types need
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// Zero values (0, "", false, empty arrays) skip the other constraints, like
+	// omitempty in the handlers' binding rules
+	OmitEmpty bool `json:"x-omitempty,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaOf returns the schema of t. Named structs are added to the document's
+// components and referenced, which keeps recursive models finite.
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Custom encodings (json.RawMessage, gorm.DeletedAt, ...) can be anything
+		s = &Schema{}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := schemaName(t)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			d.Components.Schemas[name] = &Schema{} // claimed while its fields refer back to it
+			d.Components.Schemas[name] = d.structSchema(t)
+		}
+		ref := &Schema{Ref: "#/components/schemas/" + name}
+		if nullable {
+			// $ref siblings are ignored in OpenAPI 3.0, so nullability wraps it
+			return &Schema{AllOf: []*Schema{ref}, Nullable: true}
+		}
+		return ref
+	case t.Kind() == reflect.Struct:
+		s = d.structSchema(t)
+	case t.Kind() == reflect.Slice:
+		// nil slices and maps encode as null
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem()), Nullable: true}
+	case t.Kind() == reflect.Array:
+		s = &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem()), Nullable: true}
+	case t.Kind() == reflect.String:
+		s = &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		s = &Schema{}
+	}
+	s.Nullable = nullable
+	return s
+}
+
+// schemaName names a component after its package and type, e.g. website.CreateWebsiteRequest
+func schemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	d.addFields(s, t)
+	return s
+}
+
+// addFields adds t's JSON fields to s, flattening embedded structs the way
+// encoding/json does
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				d.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := d.schemaOf(field.Type)
+		if applyBinding(prop, field.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// applyBinding adds the constraints of a validator binding tag to s and reports
+// whether it makes the field required. Rules after dive constrain the items.
+func applyBinding(s *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			// On pointers and slices it also rules out null
+			required, s.Nullable = true, false
+			continue
+		}
+		// References and untyped values take no constraints of their own
+		if s.Type == "" {
+			continue
+		}
+
+		switch name {
+		case "dive":
+			if s.Items != nil {
+				applyBinding(s.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "omitempty":
+			s.OmitEmpty = true
+		case "min", "gte":
+			s.bound(param, true, false)
+		case "max", "lte":
+			s.bound(param, false, false)
+		case "gt":
+			s.bound(param, true, true)
+		case "lt":
+			s.bound(param, false, true)
+		case "len":
+			s.bound(param, true, false)
+			s.bound(param, false, false)
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		}
+	}
+	return required
+}
+
+// bound sets a lower or upper limit from a binding rule's parameter: a length for
+// strings, a count for arrays and a value for numbers
+func (s *Schema) bound(param string, lower, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch s.Type {
+	case "string", "array":
+		count := int(n)
+		if exclusive && lower {
+			count++
+		} else if exclusive {
+			count--
+		}
+		switch {
+		case s.Type == "string" && lower:
+			s.MinLength = &count
+		case s.Type == "string":
+			s.MaxLength = &count
+		case lower:
+			s.MinItems = &count
+		default:
+			s.MaxItems = &count
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum, s.ExclusiveMinimum = &n, exclusive
+		} else {
+			s.Maximum, s.ExclusiveMaximum = &n, exclusive
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/datmedevil17/gopher-uptime/internal/utils"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// formatTags checks string formats with the same validator rules the handlers bind with
+var formatTags = map[string]string{"email": "email", "uri": "url", "uuid": "uuid"}
+
+// ValidateBody checks a JSON request body against the request schema of the
+// route (method and gin path, e.g. "POST", "/api/v1/website") and lists the
+// violations. Routes without a request schema, empty bodies and malformed JSON
+// pass; the handler's binding reports the latter.
+func (d *Document) ValidateBody(method, route string, body []byte) []utils.FieldError {
+	schema := d.bodies[method+" "+route]
+	if schema == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil
+	}
+
+	var violations []utils.FieldError
+	d.validate(schema, value, "", &violations)
+	return violations
+}
+
+func (d *Document) resolve(s *Schema) *Schema {
+	for {
+		switch {
+		case s.Ref != "":
+			s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		case len(s.AllOf) == 1:
+			s = s.AllOf[0]
+		default:
+			return s
+		}
+	}
+}
+
+func (d *Document) validate(s *Schema, value interface{}, path string, violations *[]utils.FieldError) {
+	fail := func(rule, format string, args ...interface{}) {
+		field := path
+		if field == "" {
+			field = "body"
+		}
+		*violations = append(*violations, utils.FieldError{Field: field, Rule: rule, Message: field + " " + fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if !s.Nullable && (s.Type != "" || s.Ref != "") {
+			fail("nullable", "must not be null")
+		}
+		return
+	}
+	s = d.resolve(s)
+	if s.Type == "" {
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("type", "must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				*violations = append(*violations, utils.FieldError{Field: join(path, name), Rule: "required", Message: join(path, name) + " is required"})
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := s.Properties[name]; ok {
+				d.validate(prop, object[name], join(path, name), violations)
+			} else if s.AdditionalProperties != nil {
+				d.validate(s.AdditionalProperties, object[name], join(path, name), violations)
+			}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("type", "must be an array")
+			return
+		}
+		if s.OmitEmpty && len(items) == 0 {
+			return
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			fail("minItems", "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			fail("maxItems", "must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				d.validate(s.Items, item, path+"["+strconv.Itoa(i)+"]", violations)
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("type", "must be a string")
+			return
+		}
+		if s.OmitEmpty && str == "" {
+			return
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("minLength", "must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("maxLength", "must be at most %d characters", *s.MaxLength)
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			fail("enum", "must be one of: %s", strings.Join(s.Enum, ", "))
+		}
+		if tag, ok := formatTags[s.Format]; ok {
+			if v, ok := binding.Validator.Engine().(*validator.Validate); ok && v.Var(str, tag) != nil {
+				fail("format", "must be a valid %s", s.Format)
+			}
+		}
+
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		n, err := number.Float64()
+		if s.Type == "integer" && ok {
+			_, err = number.Int64()
+		}
+		if !ok || err != nil {
+			fail("type", "must be %s", map[string]string{"integer": "an integer", "number": "a number"}[s.Type])
+			return
+		}
+		if s.OmitEmpty && n == 0 {
+			return
+		}
+		if s.Minimum != nil && (n < *s.Minimum || s.ExclusiveMinimum && n == *s.Minimum) {
+			fail("minimum", "must be %s %v", above(s.ExclusiveMinimum), *s.Minimum)
+		}
+		if s.Maximum != nil && (n > *s.Maximum || s.ExclusiveMaximum && n == *s.Maximum) {
+			fail("maximum", "must be %s %v", below(s.ExclusiveMaximum), *s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("type", "must be a boolean")
+		}
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func above(exclusive bool) string {
+	if exclusive {
+		return "greater than"
+	}
+	return "at least"
+}
+
+func below(exclusive bool) string {
+	if exclusive {
+		return "less than"
+	}
+	return "at most"
+}
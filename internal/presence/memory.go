@@ -0,0 +1,87 @@
+package presence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	Entry
+	expiresAt time.Time
+}
+
+// MemoryStore keeps presence for a single process
+type MemoryStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+func (s *MemoryStore) Register(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entry.LastSeen = now
+	s.entries[entry.ValidatorID] = memoryEntry{Entry: entry, expiresAt: now.Add(s.ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Heartbeat(ctx context.Context, validatorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entry, ok := s.entries[validatorID]
+	if !ok || !now.Before(entry.expiresAt) {
+		delete(s.entries, validatorID)
+		return ErrNotRegistered
+	}
+
+	entry.LastSeen = now
+	entry.expiresAt = now.Add(s.ttl)
+	s.entries[validatorID] = entry
+	return nil
+}
+
+func (s *MemoryStore) Deregister(ctx context.Context, validatorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, validatorID)
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entries := make([]Entry, 0, len(s.entries))
+	for id, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, id)
+			continue
+		}
+		entries = append(entries, entry.Entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ValidatorID < entries[j].ValidatorID })
+	return entries, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+)
+
+// Entry describes a validator currently connected to some hub instance
+type Entry struct {
+	ValidatorID string    `json:"validator_id"`
+	PublicKey   string    `json:"public_key"`
+	HubID       string    `json:"hub_id"`
+	ConnectedAt time.Time `json:"connected_at"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// ErrNotRegistered is returned by Heartbeat for validators that are not (or no longer) online
+var ErrNotRegistered = errors.New("validator not registered")
+
+// Store tracks which validators are online. Entries expire unless refreshed
+// with Heartbeat within the store's TTL, so a crashed hub's validators drop out.
+type Store interface {
+	Register(ctx context.Context, entry Entry) error
+	Heartbeat(ctx context.Context, validatorID string) error
+	Deregister(ctx context.Context, validatorID string) error
+	List(ctx context.Context) ([]Entry, error)
+	Close() error
+}
+
+// New returns a Redis-backed store when REDIS_URL is set, otherwise an in-memory one
+// that only sees validators connected to this process
+func New(cfg *config.Config) (Store, error) {
+	if cfg.RedisURL == "" {
+		return NewMemoryStore(cfg.PresenceTTL), nil
+	}
+	return NewRedisStore(cfg.RedisURL, cfg.PresenceTTL)
+}
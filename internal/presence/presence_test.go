@@ -0,0 +1,131 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// stores runs test against each Store implementation, with entries expiring after ttl
+func stores(t *testing.T, ttl time.Duration, test func(t *testing.T, store Store)) {
+	t.Run("memory", func(t *testing.T) {
+		store := NewMemoryStore(ttl)
+		defer store.Close()
+		test(t, store)
+	})
+	t.Run("redis", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		store, err := NewRedisStore("redis://"+server.Addr(), ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		test(t, store)
+	})
+}
+
+// online lists the IDs of the validators store reports as online
+func online(t *testing.T, store Store) []string {
+	t.Helper()
+
+	entries, err := store.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ValidatorID
+	}
+	return ids
+}
+
+func register(t *testing.T, store Store, validatorID string) {
+	t.Helper()
+
+	entry := Entry{ValidatorID: validatorID, PublicKey: "key-" + validatorID, HubID: "hub-1", ConnectedAt: time.Now()}
+	if err := store.Register(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	stores(t, time.Minute, func(t *testing.T, store Store) {
+		register(t, store, "v2")
+		register(t, store, "v1")
+
+		entries, err := store.List(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || entries[0].ValidatorID != "v1" || entries[1].ValidatorID != "v2" {
+			t.Fatalf("listed %+v, want v1 and v2 in order", entries)
+		}
+		if entries[0].PublicKey != "key-v1" || entries[0].HubID != "hub-1" {
+			t.Errorf("v1 listed as %+v", entries[0])
+		}
+		if time.Since(entries[0].LastSeen) > time.Minute {
+			t.Errorf("v1 last seen %s, want about now", entries[0].LastSeen)
+		}
+	})
+}
+
+func TestHeartbeatExpiry(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+
+	stores(t, ttl, func(t *testing.T, store Store) {
+		register(t, store, "beating")
+		register(t, store, "silent")
+
+		// Heartbeats keep one validator online past the TTL the other stops sending
+		for i := 0; i < 4; i++ {
+			time.Sleep(ttl / 2)
+			if err := store.Heartbeat(context.Background(), "beating"); err != nil {
+				t.Fatalf("heartbeat: %v", err)
+			}
+		}
+		if got := online(t, store); !slices.Equal(got, []string{"beating"}) {
+			t.Fatalf("online %v, want only the validator heartbeating", got)
+		}
+
+		// An expired validator has to register again
+		if err := store.Heartbeat(context.Background(), "silent"); !errors.Is(err, ErrNotRegistered) {
+			t.Errorf("heartbeat after expiry: %v, want ErrNotRegistered", err)
+		}
+	})
+}
+
+func TestHeartbeatUnknownValidator(t *testing.T) {
+	stores(t, time.Minute, func(t *testing.T, store Store) {
+		if err := store.Heartbeat(context.Background(), "nobody"); !errors.Is(err, ErrNotRegistered) {
+			t.Errorf("heartbeat: %v, want ErrNotRegistered", err)
+		}
+		if got := online(t, store); len(got) != 0 {
+			t.Errorf("online %v after a heartbeat from an unknown validator", got)
+		}
+	})
+}
+
+func TestDeregister(t *testing.T) {
+	stores(t, time.Minute, func(t *testing.T, store Store) {
+		register(t, store, "v1")
+		register(t, store, "v2")
+
+		if err := store.Deregister(context.Background(), "v1"); err != nil {
+			t.Fatal(err)
+		}
+		if got := online(t, store); !slices.Equal(got, []string{"v2"}) {
+			t.Fatalf("online %v, want v2", got)
+		}
+		if err := store.Heartbeat(context.Background(), "v1"); !errors.Is(err, ErrNotRegistered) {
+			t.Errorf("heartbeat after deregister: %v, want ErrNotRegistered", err)
+		}
+		// Deregistering twice, as a hub may on reconnect races, is harmless
+		if err := store.Deregister(context.Background(), "v1"); err != nil {
+			t.Errorf("second deregister: %v", err)
+		}
+	})
+}
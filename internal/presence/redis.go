@@ -0,0 +1,144 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisExpiryKey is a sorted set of validator IDs scored by expiry (unix ms)
+	redisExpiryKey = "gopher-uptime:presence"
+	// redisEntriesKey is a hash of validator ID to JSON-encoded Entry
+	redisEntriesKey = "gopher-uptime:presence:entries"
+)
+
+// RedisStore shares presence between every hub instance and the API
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisStore(url string, ttl time.Duration) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis ping failed: %w", err)
+	}
+	return &RedisStore{client: client, ttl: ttl}, nil
+}
+
+func (s *RedisStore) Register(ctx context.Context, entry Entry) error {
+	now := time.Now()
+	entry.LastSeen = now
+
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, redisEntriesKey, entry.ValidatorID, payload)
+		pipe.ZAdd(ctx, redisExpiryKey, redis.Z{Score: expiryScore(now, s.ttl), Member: entry.ValidatorID})
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Heartbeat(ctx context.Context, validatorID string) error {
+	// XX only refreshes members that still exist, so an expired validator must re-register
+	updated, err := s.client.ZAddArgs(ctx, redisExpiryKey, redis.ZAddArgs{
+		XX:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: expiryScore(time.Now(), s.ttl), Member: validatorID}},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrNotRegistered
+	}
+	return nil
+}
+
+func (s *RedisStore) Deregister(ctx context.Context, validatorID string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, redisExpiryKey, validatorID)
+		pipe.HDel(ctx, redisEntriesKey, validatorID)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) List(ctx context.Context) ([]Entry, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	// Drop expired validators left behind by hubs that stopped heartbeating
+	expired, err := s.client.ZRangeByScore(ctx, redisExpiryKey, &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(expired) > 0 {
+		if _, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRemRangeByScore(ctx, redisExpiryKey, "-inf", now)
+			pipe.HDel(ctx, redisEntriesKey, expired...)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	live, err := s.client.ZRangeByScoreWithScores(ctx, redisExpiryKey, &redis.ZRangeBy{Min: "(" + now, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(live) == 0 {
+		return []Entry{}, nil
+	}
+
+	ids := make([]string, len(live))
+	for i, z := range live {
+		ids[i] = z.Member.(string)
+	}
+
+	payloads, err := s.client.HMGet(ctx, redisEntriesKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(payloads))
+	for i, payload := range payloads {
+		raw, ok := payload.(string)
+		if !ok {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			continue
+		}
+		// The last heartbeat is implied by the expiry score
+		entry.LastSeen = time.UnixMilli(int64(live[i].Score)).Add(-s.ttl)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ValidatorID < entries[j].ValidatorID })
+	return entries, nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+func expiryScore(now time.Time, ttl time.Duration) float64 {
+	return float64(now.Add(ttl).UnixMilli())
+}
@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// Version is the hub/validator message schema spoken by this build.
+	// Version 2 added timestamps and nonces to signed messages; version 3 made the
+	// signup nonce a challenge issued by the hub on connect; version 4 added
+	// multi-step checks, which are only sent to version 4 validators; version 5
+	// added response header assertions; version 6 added forced HTTP versions and
+	// reports the negotiated protocol with each result.
+	Version = 6
+	// MinVersion is the oldest peer version this build still understands
+	MinVersion = 3
+
+	// CloseUnsupportedVersion is the websocket close code sent when a peer's version is rejected
+	CloseUnsupportedVersion = 4001
+)
+
+// StepsVersion is the first version whose validators run multi-step checks
+const StepsVersion = 4
+
+// HeaderAssertionsVersion is the first version whose validators evaluate header assertions
+const HeaderAssertionsVersion = 5
+
+// HTTPVersionsVersion is the first version whose validators honour a website's HTTP version
+const HTTPVersionsVersion = 6
+
+// Normalize treats a missing version as 1, the schema used before versioning was introduced
+func Normalize(version int) int {
+	if version == 0 {
+		return 1
+	}
+	return version
+}
+
+// Supported reports whether this build can talk to a peer speaking version
+func Supported(version int) bool {
+	version = Normalize(version)
+	return version >= MinVersion && version <= Version
+}
+
+// SignupMessage is what a validator signs to prove ownership of its key at signup.
+// timestamp is in unix seconds; nonce is the challenge the hub sent on connect.
+func SignupMessage(callbackID, publicKey string, timestamp int64, nonce string) string {
+	return fmt.Sprintf("Signed message for %s, %s at %d nonce %s", callbackID, publicKey, timestamp, nonce)
+}
+
+// ValidateMessage is what a validator signs when replying to a validation task.
+// nonce is the one the hub sent with the task.
+func ValidateMessage(callbackID string, timestamp int64, nonce string) string {
+	return fmt.Sprintf("Replying to %s at %d nonce %s", callbackID, timestamp, nonce)
+}
+
+// RegisterMessage is what an operator signs to pre-register a validator key over REST
+func RegisterMessage(publicKey string, timestamp int64) string {
+	return fmt.Sprintf("Register validator %s at %d", publicKey, timestamp)
+}
+
+// NewNonce returns a random 128-bit hex nonce
+func NewNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
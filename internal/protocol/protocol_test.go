@@ -0,0 +1,31 @@
+package protocol
+
+import "testing"
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		version int
+		want    bool
+	}{
+		{0, false}, // unversioned peers speak version 1
+		{1, false},
+		{MinVersion - 1, false},
+		{MinVersion, true},
+		{Version, true},
+		{Version + 1, false},
+	}
+	for _, tt := range tests {
+		if got := Supported(tt.version); got != tt.want {
+			t.Errorf("Supported(%d) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(0); got != 1 {
+		t.Errorf("Normalize(0) = %d, want 1", got)
+	}
+	if got := Normalize(Version); got != Version {
+		t.Errorf("Normalize(%d) = %d", Version, got)
+	}
+}
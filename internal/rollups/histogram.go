@@ -0,0 +1,91 @@
+package rollups
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LatencyBounds are the exclusive upper bounds, in milliseconds, of the latency
+// histogram kept with each rollup. A final bucket holds anything slower.
+var LatencyBounds = []float64{
+	10, 25, 50, 75, 100, 150, 200, 300, 400, 500, 750,
+	1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 20000, 30000, 60000,
+}
+
+// histogramSQL aggregates the latency column into a JSON array of counts, one
+// per LatencyBounds bucket plus the overflow bucket
+var histogramSQL = buildHistogramSQL()
+
+func buildHistogramSQL() string {
+	counts := make([]string, 0, len(LatencyBounds)+1)
+	lower := ""
+	for _, bound := range LatencyBounds {
+		upper := strconv.FormatFloat(bound, 'f', -1, 64)
+		if lower == "" {
+			counts = append(counts, fmt.Sprintf("COUNT(*) FILTER (WHERE latency < %s)", upper))
+		} else {
+			counts = append(counts, fmt.Sprintf("COUNT(*) FILTER (WHERE latency >= %s AND latency < %s)", lower, upper))
+		}
+		lower = upper
+	}
+	counts = append(counts, fmt.Sprintf("COUNT(*) FILTER (WHERE latency >= %s)", lower))
+	return "to_jsonb(ARRAY[" + strings.Join(counts, ", ") + "])"
+}
+
+// HistogramSQL is the select expression building a Histogram from ticks
+func HistogramSQL() string {
+	return histogramSQL
+}
+
+// Histogram counts latencies per LatencyBounds bucket
+type Histogram []int64
+
+// Add merges other into h. Rollups from before histograms were kept are empty
+// and add nothing.
+func (h Histogram) Add(other Histogram) Histogram {
+	if len(h) < len(other) {
+		h = append(h, make(Histogram, len(other)-len(h))...)
+	}
+	for i, count := range other {
+		h[i] += count
+	}
+	return h
+}
+
+// Total is the number of latencies counted
+func (h Histogram) Total() int64 {
+	var total int64
+	for _, count := range h {
+		total += count
+	}
+	return total
+}
+
+// Percentile estimates the pth percentile (0-100), interpolating linearly
+// within the bucket it falls in. The overflow bucket has no upper bound, so
+// percentiles landing there report the largest bound. 0 when h is empty.
+func (h Histogram) Percentile(p float64) float64 {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(total)
+	var seen int64
+	for i, count := range h {
+		if count == 0 || float64(seen+count) < rank {
+			seen += count
+			continue
+		}
+		if i >= len(LatencyBounds) {
+			return LatencyBounds[len(LatencyBounds)-1]
+		}
+		lower, upper := 0.0, LatencyBounds[i]
+		if i > 0 {
+			lower = LatencyBounds[i-1]
+		}
+		return lower + (upper-lower)*(rank-float64(seen))/float64(count)
+	}
+	return LatencyBounds[len(LatencyBounds)-1]
+}
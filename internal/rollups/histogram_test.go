@@ -0,0 +1,86 @@
+package rollups
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// histogramOf buckets latencies by LatencyBounds
+func histogramOf(latencies ...float64) Histogram {
+	h := make(Histogram, len(LatencyBounds)+1)
+	for _, latency := range latencies {
+		h[sort.Search(len(LatencyBounds), func(i int) bool { return latency < LatencyBounds[i] })]++
+	}
+	return h
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	// 50 checks under 10ms, 40 in [10, 25), 5 in [25, 50), 4 in [50, 75) and one
+	// slower than the largest bound
+	h := make(Histogram, len(LatencyBounds)+1)
+	h[0], h[1], h[2], h[3], h[len(LatencyBounds)] = 50, 40, 5, 4, 1
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{50, 10},
+		{70, 17.5}, // halfway through [10, 25)
+		{90, 25},
+		{95, 50},
+		{99, 75},
+		{100, 60000}, // the overflow bucket reports the largest bound
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := h.Percentile(tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("p%v = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestHistogramPercentileKnownDistribution(t *testing.T) {
+	// 1000 checks at 1, 2, ..., 1000ms: the estimate must land within the
+	// bucket holding the exact percentile
+	latencies := make([]float64, 1000)
+	for i := range latencies {
+		latencies[i] = float64(i + 1)
+	}
+	h := histogramOf(latencies...)
+
+	previous := 0.0
+	for _, p := range []float64{50, 90, 95, 99} {
+		got := h.Percentile(p)
+		exact := latencies[int(p*10)-1]
+		bucket := sort.Search(len(LatencyBounds), func(i int) bool { return exact < LatencyBounds[i] })
+		if got < LatencyBounds[bucket-1] || got > LatencyBounds[bucket] {
+			t.Errorf("p%v = %v, want within [%v, %v] around the exact %v", p, got, LatencyBounds[bucket-1], LatencyBounds[bucket], exact)
+		}
+		if got < previous {
+			t.Errorf("p%v = %v is below the previous percentile %v", p, got, previous)
+		}
+		previous = got
+	}
+}
+
+func TestHistogramEmpty(t *testing.T) {
+	for _, h := range []Histogram{nil, make(Histogram, len(LatencyBounds)+1)} {
+		if got := h.Percentile(99); got != 0 {
+			t.Errorf("p99 of %d empty buckets = %v, want 0", len(h), got)
+		}
+	}
+}
+
+func TestHistogramAdd(t *testing.T) {
+	recent := histogramOf(5, 5, 120)
+
+	// Rollups from before histograms were kept have none and add nothing
+	merged := Histogram(nil).Add(recent).Add(nil).Add(histogramOf(5, 70000))
+	if merged.Total() != 5 || merged[0] != 3 || merged[5] != 1 || merged[len(LatencyBounds)] != 1 {
+		t.Errorf("merged = %v, want 3 under 10ms, 1 in [100, 150) and 1 overflowing", merged)
+	}
+	if recent.Total() != 3 {
+		t.Errorf("adding to a copy changed the original to %v", recent)
+	}
+}
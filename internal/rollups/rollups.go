@@ -0,0 +1,76 @@
+// Package rollups maintains hourly per-website tick aggregates used to compute
+// uptime over long windows.
+package rollups
+
+import (
+	"errors"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"gorm.io/gorm"
+)
+
+// Bucket is the rollup granularity
+const Bucket = time.Hour
+
+// Horizon is how far back an empty rollup table is backfilled; it covers the
+// longest uptime window
+const Horizon = 90 * 24 * time.Hour
+
+// CoveredUntil returns the end of the latest rolled-up hour, or the zero time
+// when nothing has been rolled up yet. Every website is rolled up together, so
+// hours before it are complete for all of them.
+func CoveredUntil(db *gorm.DB) (time.Time, error) {
+	var latest models.WebsiteTickRollup
+	err := db.Select("bucket").Order("bucket DESC").Take(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return latest.Bucket.Add(Bucket), nil
+}
+
+// Refresh recomputes the rollups of every completed hour since the last one
+// covered, redoing that hour to pick up late reports. It is idempotent, so
+// several hubs may run it concurrently.
+func Refresh(db *gorm.DB, now time.Time) (int64, error) {
+	covered, err := CoveredUntil(db)
+	if err != nil {
+		return 0, err
+	}
+
+	since := now.Add(-Horizon)
+	if !covered.IsZero() {
+		since = covered.Add(-Bucket)
+	}
+	return refreshRange(db, since.Truncate(Bucket), now.Truncate(Bucket))
+}
+
+func refreshRange(db *gorm.DB, from, to time.Time) (int64, error) {
+	if !to.After(from) {
+		return 0, nil
+	}
+
+	result := db.Exec(`
+		INSERT INTO "WebsiteTickRollup" (website_id, bucket, good, degraded, bad, latency_total, latency_histogram)
+		SELECT website_id, date_trunc('hour', created_at),
+			COUNT(*) FILTER (WHERE status = ?),
+			COUNT(*) FILTER (WHERE status = ?),
+			COUNT(*) FILTER (WHERE status NOT IN (?, ?)),
+			COALESCE(SUM(latency), 0),
+			`+histogramSQL+`
+		FROM "WebsiteTick"
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY 1, 2
+		ON CONFLICT (website_id, bucket) DO UPDATE SET
+			good = EXCLUDED.good,
+			degraded = EXCLUDED.degraded,
+			bad = EXCLUDED.bad,
+			latency_total = EXCLUDED.latency_total,
+			latency_histogram = EXCLUDED.latency_histogram`,
+		models.StatusGood, models.StatusDegraded, models.StatusGood, models.StatusDegraded,
+		from, to,
+	)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,81 @@
+package rollups_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/datmedevil17/gopher-uptime/internal/rollups"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func createTick(t *testing.T, db *gorm.DB, websiteID, validatorID, status string, latency float64, at time.Time) {
+	t.Helper()
+
+	tick := models.WebsiteTick{ID: uuid.New().String(), WebsiteID: websiteID, ValidatorID: validatorID, Status: status, Latency: latency, CreatedAt: at}
+	if err := db.Create(&tick).Error; err != nil {
+		t.Fatalf("creating tick: %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	dbtest.RequirePostgres(t) // rollups are computed with FILTER and date_trunc
+
+	db := dbtest.Open(t)
+	user := models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x"}
+	website := models.Website{ID: uuid.New().String(), UserID: user.ID, URL: "https://example.com"}
+	validator := models.Validator{ID: uuid.New().String(), PublicKey: uuid.New().String()}
+	for _, row := range []interface{}{&user, &website, &validator} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(rollups.Bucket).Add(30 * time.Minute)
+	hour := now.Truncate(rollups.Bucket).Add(-3 * rollups.Bucket)
+	createTick(t, db, website.ID, validator.ID, models.StatusGood, 100, hour.Add(time.Minute))
+	createTick(t, db, website.ID, validator.ID, models.StatusDegraded, 900, hour.Add(2*time.Minute))
+	createTick(t, db, website.ID, validator.ID, models.StatusBad, 0, hour.Add(3*time.Minute))
+	createTick(t, db, website.ID, validator.ID, models.StatusGood, 50, hour.Add(rollups.Bucket))
+	createTick(t, db, website.ID, validator.ID, models.StatusGood, 50, now.Add(-time.Minute)) // the current, incomplete hour
+	createTick(t, db, website.ID, validator.ID, models.StatusBad, 0, now.Add(-rollups.Horizon-rollups.Bucket))
+
+	if covered, err := rollups.CoveredUntil(db); err != nil || !covered.IsZero() {
+		t.Fatalf("CoveredUntil before any refresh = %v, %v", covered, err)
+	}
+
+	check := func() {
+		t.Helper()
+
+		var got []models.WebsiteTickRollup
+		if err := db.Order("bucket").Find(&got).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("got %d rollups, want 2: %+v", len(got), got)
+		}
+		first, second := got[0], got[1]
+		if !first.Bucket.Equal(hour) || first.Good != 1 || first.Degraded != 1 || first.Bad != 1 || first.LatencyTotal != 1000 {
+			t.Errorf("first rollup = %+v", first)
+		}
+		if !second.Bucket.Equal(hour.Add(rollups.Bucket)) || second.Good != 1 || second.LatencyTotal != 50 {
+			t.Errorf("second rollup = %+v", second)
+		}
+	}
+
+	if _, err := rollups.Refresh(db, now); err != nil {
+		t.Fatal(err)
+	}
+	check()
+	if covered, err := rollups.CoveredUntil(db); err != nil || !covered.Equal(hour.Add(2*rollups.Bucket)) {
+		t.Errorf("CoveredUntil = %v, %v, want %v", covered, err, hour.Add(2*rollups.Bucket))
+	}
+
+	// Refreshing again redoes the last hour without counting it twice
+	if _, err := rollups.Refresh(db, now); err != nil {
+		t.Fatal(err)
+	}
+	check()
+}
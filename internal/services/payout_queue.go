@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"gorm.io/gorm"
+)
+
+// Queue names
+const (
+	PayoutQueue           = "payout_queue"
+	PayoutDeadLetterQueue = "payout_dlq" // payouts that failed in a way an operator must look at
+)
+
+// Headers set on dead-lettered payout messages
+const (
+	headerDeadLetterReason = "x-dead-letter-reason"
+	headerTransactionID    = "x-payout-transaction-id"
+	headerTxSignature      = "x-payout-tx-signature"
+	headerDeadLetteredAt   = "x-dead-lettered-at"
+)
+
+// queueDeclarer declares queues, as *amqp.Channel does
+type queueDeclarer interface {
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+}
+
+// DeadLetterChannel is the part of *amqp.Channel ReplayDeadLetters uses
+type DeadLetterChannel interface {
+	queueDeclarer
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Nack(tag uint64, multiple, requeue bool) error
+}
+
+// declarePayoutQueues declares the payout queue and its dead-letter queue (idempotent)
+func declarePayoutQueues(ch queueDeclarer) error {
+	for _, name := range []string{PayoutQueue, PayoutDeadLetterQueue} {
+		if _, err := ch.QueueDeclare(
+			name,  // name
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			nil,   // arguments
+		); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// deadLetter moves delivery to the dead-letter queue with why it failed. If the
+// dead-letter queue can't be reached the message is dropped as before, and logged.
+func (w *PayoutWorker) deadLetter(delivery amqp.Delivery, reason string, txRecord *models.PayoutTransaction) {
+	headers := amqp.Table{
+		headerDeadLetterReason: reason,
+		headerDeadLetteredAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if txRecord != nil {
+		headers[headerTransactionID] = txRecord.ID
+		if txRecord.TxSignature != "" {
+			headers[headerTxSignature] = txRecord.TxSignature
+		}
+	}
+
+	err := w.rabbitMQ.Publish(
+		"",                    // exchange
+		PayoutDeadLetterQueue, // routing key
+		false,                 // mandatory
+		false,                 // immediate
+		amqp.Publishing{
+			ContentType:  delivery.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Body:         delivery.Body,
+			Headers:      headers,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		log.Printf("❌ Failed to dead-letter payout message (%s), dropping it: %v", reason, err)
+		delivery.Nack(false, false)
+		return
+	}
+
+	log.Printf("⚠️  Payout message dead-lettered: %s", reason)
+	delivery.Ack(false)
+}
+
+// ReplayOptions control ReplayDeadLetters
+type ReplayOptions struct {
+	Limit        int           // messages to replay at most
+	DryRun       bool          // list the messages without moving them
+	QueryTimeout time.Duration // deadline for recording each replay
+}
+
+// DeadLetter describes a dead-lettered payout message
+type DeadLetter struct {
+	ValidatorID   string
+	Amount        float64
+	Reason        string
+	TransactionID string
+	TxSignature   string
+}
+
+// ReplayDeadLetters republishes up to opts.Limit dead-lettered payouts to the
+// payout queue, recording each in PayoutReplay. In dry-run mode the messages are
+// only listed and stay in the dead-letter queue.
+//
+// Payouts whose confirmation timed out may still have landed on chain; check
+// TxSignature before replaying them or the validator may be paid twice.
+func ReplayDeadLetters(ch DeadLetterChannel, db *gorm.DB, opts ReplayOptions) ([]DeadLetter, error) {
+	if err := declarePayoutQueues(ch); err != nil {
+		return nil, err
+	}
+
+	var replayed []DeadLetter
+	var lastTag uint64
+	for len(replayed) < opts.Limit {
+		delivery, ok, err := ch.Get(PayoutDeadLetterQueue, false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		lastTag = delivery.DeliveryTag
+
+		letter := describeDeadLetter(delivery)
+		if opts.DryRun {
+			// Held unacknowledged until the end so the same message isn't read twice
+			replayed = append(replayed, letter)
+			continue
+		}
+
+		if err := ch.Publish("", PayoutQueue, false, false, amqp.Publishing{
+			ContentType:  delivery.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Body:         delivery.Body,
+			Timestamp:    time.Now(),
+		}); err != nil {
+			delivery.Nack(false, true)
+			return replayed, fmt.Errorf("failed to republish payout: %w", err)
+		}
+		if err := delivery.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to remove replayed payout from dead-letter queue: %w", err)
+		}
+
+		if err := recordReplay(db, letter, opts.QueryTimeout); err != nil {
+			log.Printf("⚠️  Replayed payout for %s but failed to record it: %v", letter.ValidatorID, err)
+		}
+		replayed = append(replayed, letter)
+	}
+
+	if opts.DryRun && lastTag != 0 {
+		if err := ch.Nack(lastTag, true, true); err != nil {
+			return replayed, fmt.Errorf("failed to return messages to dead-letter queue: %w", err)
+		}
+	}
+	return replayed, nil
+}
+
+func describeDeadLetter(delivery amqp.Delivery) DeadLetter {
+	header := func(key string) string {
+		value, _ := delivery.Headers[key].(string)
+		return value
+	}
+
+	letter := DeadLetter{
+		Reason:        header(headerDeadLetterReason),
+		TransactionID: header(headerTransactionID),
+		TxSignature:   header(headerTxSignature),
+	}
+
+	// Malformed bodies are replayed as-is; they just have no validator to show
+	var req PayoutRequest
+	if err := json.Unmarshal(delivery.Body, &req); err == nil {
+		letter.ValidatorID = req.ValidatorID
+		letter.Amount = req.Amount
+	}
+	return letter
+}
+
+func recordReplay(db *gorm.DB, letter DeadLetter, timeout time.Duration) error {
+	db, cancel := database.WithTimeout(context.Background(), db, timeout)
+	defer cancel()
+
+	return db.Create(&models.PayoutReplay{
+		ID:            uuid.New().String(),
+		TransactionID: letter.TransactionID,
+		ValidatorID:   letter.ValidatorID,
+		Amount:        letter.Amount,
+		Reason:        letter.Reason,
+		ReplayedAt:    time.Now(),
+	}).Error
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/streadway/amqp"
+)
+
+// memoryBroker is an in-memory stand-in for a RabbitMQ channel: published
+// messages queue up, and messages fetched with Get stay unacknowledged until
+// they are acked, or nacked back onto the front of their queue
+type memoryBroker struct {
+	mu      sync.Mutex
+	queues  map[string][]amqp.Publishing
+	unacked map[uint64]pendingMessage
+	nextTag uint64
+
+	failPublish error
+}
+
+type pendingMessage struct {
+	queue string
+	msg   amqp.Publishing
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{queues: make(map[string][]amqp.Publishing), unacked: make(map[uint64]pendingMessage)}
+}
+
+func (b *memoryBroker) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return amqp.Queue{Name: name, Messages: len(b.queues[name])}, nil
+}
+
+func (b *memoryBroker) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failPublish != nil {
+		return b.failPublish
+	}
+	b.queues[key] = append(b.queues[key], msg)
+	return nil
+}
+
+func (b *memoryBroker) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.queues[queue]) == 0 {
+		return amqp.Delivery{}, false, nil
+	}
+	msg := b.queues[queue][0]
+	b.queues[queue] = b.queues[queue][1:]
+	b.nextTag++
+	if !autoAck {
+		b.unacked[b.nextTag] = pendingMessage{queue: queue, msg: msg}
+	}
+	return amqp.Delivery{
+		Acknowledger: b,
+		DeliveryTag:  b.nextTag,
+		ContentType:  msg.ContentType,
+		Headers:      msg.Headers,
+		Body:         msg.Body,
+	}, true, nil
+}
+
+func (b *memoryBroker) Ack(tag uint64, multiple bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.unacked, tag)
+	return nil
+}
+
+func (b *memoryBroker) Nack(tag uint64, multiple, requeue bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Requeued messages go back in their original order
+	for t := b.nextTag; t >= 1; t-- {
+		if t > tag || (!multiple && t != tag) {
+			continue
+		}
+		pending, ok := b.unacked[t]
+		if !ok {
+			continue
+		}
+		delete(b.unacked, t)
+		if requeue {
+			b.queues[pending.queue] = append([]amqp.Publishing{pending.msg}, b.queues[pending.queue]...)
+		}
+	}
+	return nil
+}
+
+func (b *memoryBroker) Reject(tag uint64, requeue bool) error {
+	return b.Nack(tag, false, requeue)
+}
+
+// queued returns the payout requests waiting in queue
+func (b *memoryBroker) queued(t *testing.T, queue string) []PayoutRequest {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	requests := make([]PayoutRequest, len(b.queues[queue]))
+	for i, msg := range b.queues[queue] {
+		if err := json.Unmarshal(msg.Body, &requests[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return requests
+}
+
+// deadLetterPayout queues req on the dead-letter queue the way deadLetter does
+func (b *memoryBroker) deadLetterPayout(t *testing.T, req PayoutRequest, reason, transactionID, signature string) {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := amqp.Table{headerDeadLetterReason: reason}
+	if transactionID != "" {
+		headers[headerTransactionID] = transactionID
+	}
+	if signature != "" {
+		headers[headerTxSignature] = signature
+	}
+	b.Publish("", PayoutDeadLetterQueue, false, false, amqp.Publishing{ContentType: "application/json", Body: body, Headers: headers})
+}
+
+func TestReplayDeadLettersMovesMessagesBack(t *testing.T) {
+	db := dbtest.Open(t)
+	broker := newMemoryBroker()
+	broker.deadLetterPayout(t, PayoutRequest{ValidatorID: "v1", Amount: 1000}, "transaction not confirmed", "tx-1", "sig-1")
+	broker.deadLetterPayout(t, PayoutRequest{ValidatorID: "v2", Amount: 2000}, "transaction not confirmed", "tx-2", "")
+	broker.deadLetterPayout(t, PayoutRequest{ValidatorID: "v3", Amount: 3000}, "transaction not confirmed", "tx-3", "")
+
+	letters, err := ReplayDeadLetters(broker, db, ReplayOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(letters) != 2 || letters[0].ValidatorID != "v1" || letters[0].TxSignature != "sig-1" || letters[1].ValidatorID != "v2" {
+		t.Fatalf("replayed %+v, want v1 and v2", letters)
+	}
+	if queued := broker.queued(t, PayoutQueue); len(queued) != 2 || queued[0].ValidatorID != "v1" || queued[1] != (PayoutRequest{ValidatorID: "v2", Amount: 2000}) {
+		t.Errorf("payout queue holds %+v, want v1 and v2", queued)
+	}
+	if left := broker.queued(t, PayoutDeadLetterQueue); len(left) != 1 || left[0].ValidatorID != "v3" {
+		t.Errorf("dead-letter queue holds %+v, want only v3 past the limit", left)
+	}
+	if len(broker.unacked) != 0 {
+		t.Errorf("%d replayed messages left unacknowledged", len(broker.unacked))
+	}
+
+	var replays []models.PayoutReplay
+	if err := db.Order("transaction_id").Find(&replays).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(replays) != 2 || replays[0].TransactionID != "tx-1" || replays[0].ValidatorID != "v1" || replays[0].Amount != 1000 ||
+		replays[0].Reason != "transaction not confirmed" || replays[1].TransactionID != "tx-2" {
+		t.Errorf("recorded replays %+v", replays)
+	}
+}
+
+func TestReplayDeadLettersDryRun(t *testing.T) {
+	db := dbtest.Open(t)
+	broker := newMemoryBroker()
+	broker.deadLetterPayout(t, PayoutRequest{ValidatorID: "v1", Amount: 1000}, "transaction not confirmed", "tx-1", "")
+	broker.deadLetterPayout(t, PayoutRequest{ValidatorID: "v2", Amount: 2000}, "malformed payout request", "", "")
+
+	letters, err := ReplayDeadLetters(broker, db, ReplayOptions{Limit: 10, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 2 {
+		t.Fatalf("listed %+v, want both messages", letters)
+	}
+	if queued := broker.queued(t, PayoutQueue); len(queued) != 0 {
+		t.Errorf("dry run moved %+v to the payout queue", queued)
+	}
+	if left := broker.queued(t, PayoutDeadLetterQueue); len(left) != 2 || left[0].ValidatorID != "v1" || left[1].ValidatorID != "v2" {
+		t.Errorf("dead-letter queue holds %+v, want both messages in order", left)
+	}
+
+	var replays int64
+	db.Model(&models.PayoutReplay{}).Count(&replays)
+	if replays != 0 {
+		t.Errorf("dry run recorded %d replays", replays)
+	}
+}
+
+func TestReplayDeadLettersPublishFailureKeepsMessage(t *testing.T) {
+	db := dbtest.Open(t)
+	broker := newMemoryBroker()
+	broker.deadLetterPayout(t, PayoutRequest{ValidatorID: "v1", Amount: 1000}, "transaction not confirmed", "tx-1", "")
+	broker.failPublish = errors.New("channel closed")
+
+	if _, err := ReplayDeadLetters(broker, db, ReplayOptions{Limit: 10}); err == nil {
+		t.Fatal("expected the publish failure to be returned")
+	}
+	broker.failPublish = nil
+	if left := broker.queued(t, PayoutDeadLetterQueue); len(left) != 1 {
+		t.Errorf("dead-letter queue holds %+v, want the message back", left)
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"sync"
 	"time"
 
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database"
 	"github.com/datmedevil17/gopher-uptime/internal/models"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/system"
@@ -14,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/streadway/amqp"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PayoutWorker struct {
@@ -21,6 +26,17 @@ type PayoutWorker struct {
 	rabbitMQ       *amqp.Channel
 	solanaClient   *rpc.Client
 	platformWallet solana.PrivateKey
+
+	commitment     rpc.CommitmentType
+	pollInterval   time.Duration
+	confirmTimeout time.Duration
+	dryRun         bool
+	feePercent     float64
+	multiplier     float64
+	queryTimeout   time.Duration
+
+	concurrency int
+	validators  keyedMutex // serializes payouts to the same validator across consumers
 }
 
 type PayoutRequest struct {
@@ -29,72 +45,201 @@ type PayoutRequest struct {
 	PublicKey   string  `json:"public_key"`
 }
 
-func NewPayoutWorker(db *gorm.DB, rabbitMQ *amqp.Channel, platformPrivateKey string) (*PayoutWorker, error) {
-	// Initialize Solana client for devnet
-	solanaClient := rpc.New(rpc.DevNet_RPC)
+func NewPayoutWorker(db *gorm.DB, rabbitMQ *amqp.Channel, cfg *config.Config) (*PayoutWorker, error) {
+	// Initialize Solana client for the configured cluster (devnet outside production)
+	solanaClient := rpc.New(cfg.SolanaRPCURL)
 
 	// Parse platform private key
-	privateKey, err := solana.PrivateKeyFromBase58(platformPrivateKey)
+	privateKey, err := solana.PrivateKeyFromBase58(cfg.PlatformPrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("invalid platform private key: %w", err)
 	}
 
-	log.Printf("✅ Payout worker initialized with wallet: %s", privateKey.PublicKey().String())
+	commitment, err := parseCommitment(cfg.PayoutCommitment)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.PayoutFeePercent < 0 || cfg.PayoutFeePercent >= 100 {
+		return nil, fmt.Errorf("invalid payout fee %.2f%% (expected 0 <= fee < 100)", cfg.PayoutFeePercent)
+	}
+	if cfg.PayoutMultiplier <= 0 {
+		return nil, fmt.Errorf("invalid payout multiplier %v (must be positive)", cfg.PayoutMultiplier)
+	}
+	if cfg.PayoutWorkers < 1 {
+		return nil, fmt.Errorf("invalid payout worker count %d (must be at least 1)", cfg.PayoutWorkers)
+	}
+
+	log.Printf("✅ Payout worker initialized with wallet: %s (commitment: %s)", privateKey.PublicKey().String(), commitment)
+	if cfg.PayoutDryRun {
+		log.Println("⚠️  Payout dry-run enabled: transfers will be simulated, no funds will move")
+	} else if cfg.PayoutMinWallet > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := checkWalletBalance(ctx, solanaClient, privateKey.PublicKey(), commitment, uint64(cfg.PayoutMinWallet), cfg.PayoutRequireFunded)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &PayoutWorker{
 		db:             db,
 		rabbitMQ:       rabbitMQ,
 		solanaClient:   solanaClient,
 		platformWallet: privateKey,
+		commitment:     commitment,
+		pollInterval:   cfg.PayoutPollInterval,
+		confirmTimeout: cfg.PayoutConfirmTimeout,
+		dryRun:         cfg.PayoutDryRun,
+		feePercent:     cfg.PayoutFeePercent,
+		multiplier:     cfg.PayoutMultiplier,
+		queryTimeout:   cfg.DBQueryTimeout,
+		concurrency:    cfg.PayoutWorkers,
 	}, nil
 }
 
-// Start begins consuming from RabbitMQ
-func (w *PayoutWorker) Start() error {
-	// Declare queue (idempotent)
-	q, err := w.rabbitMQ.QueueDeclare(
-		"payout_queue", // name
-		true,           // durable
-		false,          // delete when unused
-		false,          // exclusive
-		false,          // no-wait
-		nil,            // arguments
-	)
+// checkWalletBalance makes sure the platform wallet can cover payouts, since an
+// empty wallet only shows up once transfers fail. A balance below min, or one
+// that can't be fetched, is an error when required and a warning otherwise.
+func checkWalletBalance(ctx context.Context, client *rpc.Client, wallet solana.PublicKey, commitment rpc.CommitmentType, min uint64, required bool) error {
+	result, err := client.GetBalance(ctx, wallet, commitment)
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		if required {
+			return fmt.Errorf("failed to check platform wallet balance: %w", err)
+		}
+		log.Printf("⚠️  Failed to check platform wallet balance: %v", err)
+		return nil
 	}
 
-	// Set QoS - process one message at a time
-	err = w.rabbitMQ.Qos(1, 0, false)
-	if err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
+	if result.Value >= min {
+		log.Printf("💰 Platform wallet balance: %d lamports", result.Value)
+		return nil
 	}
+	if required {
+		return fmt.Errorf("platform wallet %s holds %d lamports, below PAYOUT_MIN_WALLET_BALANCE (%d)", wallet, result.Value, min)
+	}
+	log.Printf("🚨 Platform wallet %s holds %d lamports, below PAYOUT_MIN_WALLET_BALANCE (%d): payouts will fail until it is funded",
+		wallet, result.Value, min)
+	return nil
+}
 
-	// Start consuming
-	msgs, err := w.rabbitMQ.Consume(
-		q.Name, // queue
-		"",     // consumer
-		false,  // auto-ack (use manual ack for reliability)
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
+// keyedMutex hands out one lock per key, dropping locks nobody holds or waits on
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock blocks until key is free and returns the function that releases it
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l := k.locks[key]
+	if l == nil {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// parseCommitment maps a config value to a Solana commitment level
+func parseCommitment(value string) (rpc.CommitmentType, error) {
+	switch rpc.CommitmentType(value) {
+	case rpc.CommitmentProcessed, rpc.CommitmentConfirmed, rpc.CommitmentFinalized:
+		return rpc.CommitmentType(value), nil
+	default:
+		return "", fmt.Errorf("invalid payout commitment %q (expected processed, confirmed or finalized)", value)
+	}
+}
+
+// meetsCommitment reports whether a signature's confirmation status satisfies the
+// configured commitment; a finalized transaction satisfies every level
+func meetsCommitment(status rpc.ConfirmationStatusType, commitment rpc.CommitmentType) bool {
+	switch commitment {
+	case rpc.CommitmentProcessed:
+		return status == rpc.ConfirmationStatusProcessed ||
+			status == rpc.ConfirmationStatusConfirmed ||
+			status == rpc.ConfirmationStatusFinalized
+	case rpc.CommitmentConfirmed:
+		return status == rpc.ConfirmationStatusConfirmed ||
+			status == rpc.ConfirmationStatusFinalized
+	default:
+		return status == rpc.ConfirmationStatusFinalized
+	}
+}
+
+// transferAmount converts a credited amount into the lamports actually sent: the
+// multiplier is applied first, then the platform fee. Fractions are rounded down so
+// the platform never pays more than configured; the epsilon absorbs float error
+// (e.g. 1000 * 0.7 = 699.9999999999999).
+func transferAmount(credited, feePercent, multiplier float64) uint64 {
+	amount := credited * multiplier * (100 - feePercent) / 100
+	if amount <= 0 {
+		return 0
+	}
+	return uint64(math.Floor(amount + 1e-6))
+}
+
+// Start begins consuming from RabbitMQ
+func (w *PayoutWorker) Start() error {
+	// Declare the queue and its dead-letter queue (idempotent)
+	if err := declarePayoutQueues(w.rabbitMQ); err != nil {
+		return err
 	}
 
-	log.Println("💰 Payout worker started, waiting for messages...")
+	// Set QoS - each consumer holds one unacknowledged message at a time
+	if err := w.rabbitMQ.Qos(1, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
 
-	// Process messages
-	forever := make(chan bool)
-	go func() {
-		for d := range msgs {
-			w.processPayoutRequest(d)
+	// One consumer per worker; RabbitMQ spreads messages between them
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		msgs, err := w.rabbitMQ.Consume(
+			PayoutQueue,                        // queue
+			fmt.Sprintf("payout-worker-%d", i), // consumer
+			false,                              // auto-ack (use manual ack for reliability)
+			false,                              // exclusive
+			false,                              // no-local
+			false,                              // no-wait
+			nil,                                // args
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register consumer: %w", err)
 		}
-	}()
 
-	<-forever
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range msgs {
+				w.processPayoutRequest(d)
+			}
+		}()
+	}
+
+	log.Printf("💰 Payout worker started with %d consumers, waiting for messages...", w.concurrency)
+
+	wg.Wait()
+	log.Println("⚠️  Payout consumers stopped: RabbitMQ channel closed")
 	return nil
 }
 
@@ -103,67 +248,98 @@ func (w *PayoutWorker) processPayoutRequest(delivery amqp.Delivery) {
 	var req PayoutRequest
 	if err := json.Unmarshal(delivery.Body, &req); err != nil {
 		log.Printf("❌ Error unmarshaling payout request: %v", err)
-		delivery.Nack(false, false) // Don't requeue malformed messages
+		w.deadLetter(delivery, "malformed payout request: "+err.Error(), nil) // Don't requeue malformed messages
 		return
 	}
 
-	log.Printf("💸 Processing payout for validator %s: %.2f lamports", req.ValidatorID, req.Amount)
+	// Another consumer may be paying this validator; wait for it to finish
+	unlock := w.validators.lock(req.ValidatorID)
+	defer unlock()
+
+	lamports := transferAmount(req.Amount, w.feePercent, w.multiplier)
+	log.Printf("💸 Processing payout for validator %s: %.2f lamports credited, %d to transfer", req.ValidatorID, req.Amount, lamports)
 
 	// Create transaction record using GORM
 	txRecord := &models.PayoutTransaction{
-		ID:          uuid.New().String(),
-		ValidatorID: req.ValidatorID,
-		Amount:      req.Amount,
-		Status:      "processing",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:             uuid.New().String(),
+		ValidatorID:    req.ValidatorID,
+		Amount:         req.Amount,
+		TransferAmount: lamports,
+		Status:         "processing",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
-	if err := w.db.Create(txRecord).Error; err != nil {
+	if err := w.createRecord(txRecord); err != nil {
 		log.Printf("❌ Failed to create transaction record: %v", err)
 		delivery.Nack(false, true) // Requeue
 		return
 	}
 
+	// In dry-run mode do the bookkeeping but never touch the chain
+	if w.dryRun {
+		log.Printf("🧪 [dry-run] Would transfer %d lamports from %s to %s",
+			lamports, w.platformWallet.PublicKey().String(), req.PublicKey)
+
+		w.updateRecord(txRecord, map[string]interface{}{
+			"status":     "simulated",
+			"updated_at": time.Now(),
+		})
+
+		delivery.Ack(false)
+		return
+	}
+
+	// Nothing left after the fee (tiny balances); settle without an on-chain transfer
+	if lamports == 0 {
+		log.Printf("⚠️  Payout for %s rounds to 0 lamports after fees, settling without transfer", req.ValidatorID)
+
+		w.updateRecord(txRecord, map[string]interface{}{
+			"status":     "completed",
+			"updated_at": time.Now(),
+		})
+
+		delivery.Ack(false)
+		return
+	}
+
 	// Execute Solana transfer
-	signature, err := w.executeSolanaTransfer(req.PublicKey, uint64(req.Amount))
+	signature, err := w.executeSolanaTransfer(req.PublicKey, lamports)
 	if err != nil {
 		log.Printf("❌ Solana transfer failed: %v", err)
 
-		// Update transaction as failed using GORM
-		w.db.Model(txRecord).Updates(map[string]interface{}{
-			"status":        "failed",
-			"error_message": err.Error(),
-			"updated_at":    time.Now(),
-		})
-
-		// Refund validator's pending balance using GORM
-		w.db.Model(&models.Validator{}).
-			Where("id = ?", req.ValidatorID).
-			UpdateColumn("pending_payouts", gorm.Expr("pending_payouts + ?", req.Amount))
+		// Mark the transaction failed and refund the validator atomically
+		if refundErr := w.refundFailedPayout(txRecord, req, err.Error()); refundErr != nil {
+			log.Printf("❌ Failed to refund validator %s: %v", req.ValidatorID, refundErr)
+			delivery.Nack(false, true) // Requeue so the refund isn't lost
+			return
+		}
 
+		// Refunded, so the validator can simply request the payout again
 		delivery.Nack(false, false)
 		return
 	}
 
 	// Poll for confirmation
-	confirmed, err := w.waitForConfirmation(signature, 30*time.Second)
+	confirmed, err := w.waitForConfirmation(signature, w.confirmTimeout)
 	if err != nil || !confirmed {
 		log.Printf("❌ Transaction confirmation failed: %v", err)
 
-		w.db.Model(txRecord).Updates(map[string]interface{}{
+		w.updateRecord(txRecord, map[string]interface{}{
 			"status":        "failed",
 			"error_message": "Transaction confirmation timeout",
 			"tx_signature":  signature,
 			"updated_at":    time.Now(),
 		})
 
-		delivery.Nack(false, false)
+		// The transfer may still land, so an operator has to decide whether to replay
+		txRecord.TxSignature = signature
+		w.deadLetter(delivery, "transaction not confirmed", txRecord)
 		return
 	}
 
 	// Update transaction as completed using GORM
-	w.db.Model(txRecord).Updates(map[string]interface{}{
+	w.updateRecord(txRecord, map[string]interface{}{
 		"status":       "completed",
 		"tx_signature": signature,
 		"updated_at":   time.Now(),
@@ -173,6 +349,55 @@ func (w *PayoutWorker) processPayoutRequest(delivery amqp.Delivery) {
 	delivery.Ack(false)
 }
 
+// createRecord inserts txRecord within DB_QUERY_TIMEOUT
+func (w *PayoutWorker) createRecord(txRecord *models.PayoutTransaction) error {
+	db, cancel := database.WithTimeout(context.Background(), w.db, w.queryTimeout)
+	defer cancel()
+
+	return db.Create(txRecord).Error
+}
+
+// updateRecord applies fields to txRecord within DB_QUERY_TIMEOUT. Each write gets
+// its own deadline since a payout can spend much longer waiting on the chain.
+func (w *PayoutWorker) updateRecord(txRecord *models.PayoutTransaction, fields map[string]interface{}) error {
+	db, cancel := database.WithTimeout(context.Background(), w.db, w.queryTimeout)
+	defer cancel()
+
+	return db.Model(txRecord).Updates(fields).Error
+}
+
+// refundFailedPayout marks txRecord failed and returns its amount to the validator's
+// pending balance. The validator row is locked the same way RequestPayout locks it, so a
+// refund and a concurrent payout request serialize instead of overwriting each other.
+func (w *PayoutWorker) refundFailedPayout(txRecord *models.PayoutTransaction, req PayoutRequest, reason string) error {
+	db, cancel := database.WithTimeout(context.Background(), w.db, w.queryTimeout)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var validator models.Validator
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", req.ValidatorID).
+			First(&validator).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(txRecord).Updates(map[string]interface{}{
+			"status":        "failed",
+			"error_message": reason,
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&validator).
+			UpdateColumns(map[string]interface{}{
+				"pending_payouts": gorm.Expr("pending_payouts + ?", req.Amount),
+				"last_payout_at":  nil,
+			}).
+			Error
+	})
+}
+
 // executeSolanaTransfer creates and sends Solana transaction
 func (w *PayoutWorker) executeSolanaTransfer(recipientPublicKey string, lamports uint64) (string, error) {
 	ctx := context.Background()
@@ -184,7 +409,7 @@ func (w *PayoutWorker) executeSolanaTransfer(recipientPublicKey string, lamports
 	}
 
 	// Get latest blockhash
-	recent, err := w.solanaClient.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	recent, err := w.solanaClient.GetLatestBlockhash(ctx, w.commitment)
 	if err != nil {
 		return "", fmt.Errorf("failed to get latest blockhash: %w", err)
 	}
@@ -223,7 +448,7 @@ func (w *PayoutWorker) executeSolanaTransfer(recipientPublicKey string, lamports
 		tx,
 		rpc.TransactionOpts{
 			SkipPreflight:       false,
-			PreflightCommitment: rpc.CommitmentFinalized,
+			PreflightCommitment: w.commitment,
 		},
 	)
 	if err != nil {
@@ -239,7 +464,7 @@ func (w *PayoutWorker) waitForConfirmation(signature string, timeout time.Durati
 	defer cancel()
 
 	sig := solana.MustSignatureFromBase58(signature)
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -254,12 +479,12 @@ func (w *PayoutWorker) waitForConfirmation(signature string, timeout time.Durati
 			}
 
 			if len(status.Value) > 0 && status.Value[0] != nil {
-				if status.Value[0].ConfirmationStatus == rpc.ConfirmationStatusFinalized {
-					return true, nil
-				}
 				if status.Value[0].Err != nil {
 					return false, fmt.Errorf("transaction failed: %v", status.Value[0].Err)
 				}
+				if meetsCommitment(status.Value[0].ConfirmationStatus, w.commitment) {
+					return true, nil
+				}
 			}
 		}
 	}
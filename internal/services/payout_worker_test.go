@@ -0,0 +1,708 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datmedevil17/gopher-uptime/internal/config"
+	"github.com/datmedevil17/gopher-uptime/internal/database/dbtest"
+	"github.com/datmedevil17/gopher-uptime/internal/handlers/user"
+	"github.com/datmedevil17/gopher-uptime/internal/models"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// recordingQueue collects published payout requests instead of sending them to RabbitMQ
+type recordingQueue struct {
+	mu       sync.Mutex
+	requests []PayoutRequest
+}
+
+func (q *recordingQueue) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	var req PayoutRequest
+	if err := json.Unmarshal(msg.Body, &req); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.requests = append(q.requests, req)
+	return nil
+}
+
+func (q *recordingQueue) total() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var total float64
+	for _, req := range q.requests {
+		total += req.Amount
+	}
+	return total
+}
+
+func createValidator(t *testing.T, db *gorm.DB, pending float64) models.Validator {
+	t.Helper()
+
+	validator := models.Validator{
+		ID:             uuid.New().String(),
+		PublicKey:      uuid.New().String(),
+		Location:       "unknown",
+		PendingPayouts: pending,
+	}
+	if err := db.Create(&validator).Error; err != nil {
+		t.Fatalf("creating validator: %v", err)
+	}
+	return validator
+}
+
+func pendingPayouts(t *testing.T, db *gorm.DB, validatorID string) float64 {
+	t.Helper()
+
+	var validator models.Validator
+	if err := db.Where("id = ?", validatorID).First(&validator).Error; err != nil {
+		t.Fatal(err)
+	}
+	return validator.PendingPayouts
+}
+
+// TestRefundRacesPayoutRequest refunds a failed payout while the validator asks
+// for a new one. Whichever runs first, every lamport ends up either queued or
+// back in the balance, exactly once.
+func TestRefundRacesPayoutRequest(t *testing.T) {
+	db := dbtest.Open(t)
+	cfg := config.Load()
+	cfg.PlatformPrivateKey = "configured"
+	cfg.PayoutCooldown = 0
+	queue := &recordingQueue{}
+	handler := user.NewHandler(db, queue, cfg, nil)
+	worker := &PayoutWorker{db: db, queryTimeout: 10 * time.Second}
+
+	router := gin.New()
+	router.POST("/payout/:validatorId", handler.RequestPayout)
+
+	const balance, refund = 50.0, 100.0
+	for i := 0; i < 20; i++ {
+		validator := createValidator(t, db, balance)
+		txRecord := &models.PayoutTransaction{
+			ID:          uuid.New().String(),
+			ValidatorID: validator.ID,
+			Amount:      refund,
+			Status:      "processing",
+		}
+		if err := db.Create(txRecord).Error; err != nil {
+			t.Fatal(err)
+		}
+		queuedBefore := queue.total()
+
+		var wg sync.WaitGroup
+		var refundErr error
+		var status int
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			refundErr = worker.refundFailedPayout(txRecord, PayoutRequest{ValidatorID: validator.ID, Amount: refund}, "transfer failed")
+		}()
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/payout/"+validator.ID, nil))
+			status = w.Code
+		}()
+		wg.Wait()
+
+		if refundErr != nil {
+			t.Fatalf("refund: %v", refundErr)
+		}
+		if status != http.StatusOK {
+			t.Fatalf("payout request: status = %d, want 200", status)
+		}
+
+		queued := queue.total() - queuedBefore
+		remaining := pendingPayouts(t, db, validator.ID)
+		if queued+remaining != balance+refund {
+			t.Fatalf("round %d: queued %.0f + remaining %.0f, want %.0f in total", i, queued, remaining, balance+refund)
+		}
+		if queued != balance && queued != balance+refund {
+			t.Fatalf("round %d: queued %.0f, want the balance before or after the refund", i, queued)
+		}
+
+		var record models.PayoutTransaction
+		if err := db.Where("id = ?", txRecord.ID).First(&record).Error; err != nil {
+			t.Fatal(err)
+		}
+		if record.Status != "failed" {
+			t.Errorf("round %d: failed payout status = %s, want failed", i, record.Status)
+		}
+	}
+}
+
+func TestRefundFailedPayoutCreditsOnce(t *testing.T) {
+	db := dbtest.Open(t)
+	worker := &PayoutWorker{db: db, queryTimeout: 10 * time.Second}
+	validator := createValidator(t, db, 0)
+	if err := db.Model(&validator).Update("last_payout_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	txRecord := &models.PayoutTransaction{ID: uuid.New().String(), ValidatorID: validator.ID, Amount: 75, Status: "processing"}
+	if err := db.Create(txRecord).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := worker.refundFailedPayout(txRecord, PayoutRequest{ValidatorID: validator.ID, Amount: 75}, "boom"); err != nil {
+		t.Fatal(err)
+	}
+	if got := pendingPayouts(t, db, validator.ID); got != 75 {
+		t.Errorf("pending payouts = %.0f, want 75", got)
+	}
+
+	var stored models.Validator
+	if err := db.Where("id = ?", validator.ID).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.LastPayoutAt != nil {
+		t.Errorf("last payout at %v, want the cooldown cleared so the refund can be requested again", stored.LastPayoutAt)
+	}
+}
+
+// rpcCall is one JSON-RPC request received by a stubRPC
+type rpcCall struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// stubRPC is a Solana JSON-RPC server answering each method with a canned
+// result and recording every call
+type stubRPC struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	calls []rpcCall
+
+	// before, when set, runs ahead of answering each call
+	before func(method string)
+}
+
+func newStubRPC(t *testing.T, results map[string]interface{}) *stubRPC {
+	t.Helper()
+
+	stub := &stubRPC{}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			rpcCall
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stub.mu.Lock()
+		stub.calls = append(stub.calls, req.rpcCall)
+		stub.mu.Unlock()
+		if stub.before != nil {
+			stub.before(req.Method)
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if result, ok := results[req.Method]; ok {
+			resp["result"] = result
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+// called returns the calls of method
+func (s *stubRPC) called(method string) []rpcCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []rpcCall
+	for _, call := range s.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// rpcValue wraps value the way RPC methods with a context return it
+func rpcValue(value interface{}) map[string]interface{} {
+	return map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": value}
+}
+
+// newStubbedWorker returns a worker paying from a fresh wallet through an RPC stub
+func newStubbedWorker(t *testing.T, db *gorm.DB, rpcURL string, commitment rpc.CommitmentType) *PayoutWorker {
+	t.Helper()
+
+	wallet, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &PayoutWorker{
+		db:             db,
+		solanaClient:   rpc.New(rpcURL),
+		platformWallet: wallet,
+		commitment:     commitment,
+		pollInterval:   10 * time.Millisecond,
+		confirmTimeout: 200 * time.Millisecond,
+		multiplier:     1,
+		queryTimeout:   10 * time.Second,
+	}
+}
+
+// captureLog collects what's logged during t
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestNewPayoutWorkerChecksWalletBalance(t *testing.T) {
+	const minBalance = 10_000_000
+
+	tests := []struct {
+		name     string
+		balance  interface{} // getBalance result; nil makes the call fail
+		required bool
+		dryRun   bool
+		wantErr  string
+		wantLog  string
+		wantCall bool
+	}{
+		{"funded", rpcValue(minBalance), false, false, "", "balance: 10000000 lamports", true},
+		{"low balance warns", rpcValue(5000), false, false, "", "holds 5000 lamports, below PAYOUT_MIN_WALLET_BALANCE (10000000)", true},
+		{"low balance required", rpcValue(5000), true, false, "holds 5000 lamports, below PAYOUT_MIN_WALLET_BALANCE (10000000)", "", true},
+		{"unreachable RPC warns", nil, false, false, "", "Failed to check platform wallet balance", true},
+		{"unreachable RPC required", nil, true, false, "failed to check platform wallet balance", "", true},
+		{"dry run skips the check", rpcValue(0), true, true, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := map[string]interface{}{}
+			if tt.balance != nil {
+				results["getBalance"] = tt.balance
+			}
+			stub := newStubRPC(t, results)
+			wallet, err := solana.NewRandomPrivateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{
+				SolanaRPCURL:        stub.URL,
+				PlatformPrivateKey:  wallet.String(),
+				PayoutCommitment:    "finalized",
+				PayoutMultiplier:    1,
+				PayoutWorkers:       1,
+				PayoutDryRun:        tt.dryRun,
+				PayoutMinWallet:     minBalance,
+				PayoutRequireFunded: tt.required,
+			}
+			logged := captureLog(t)
+
+			worker, err := NewPayoutWorker(dbtest.Open(t), nil, cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil || worker == nil {
+				t.Fatalf("error = %v, want the worker to start", err)
+			}
+			if !strings.Contains(logged.String(), tt.wantLog) {
+				t.Errorf("log %q doesn't mention %q", logged.String(), tt.wantLog)
+			}
+
+			calls := stub.called("getBalance")
+			if called := len(calls) > 0; called != tt.wantCall {
+				t.Fatalf("getBalance called = %v, want %v", called, tt.wantCall)
+			}
+			if tt.wantCall && (len(calls[0].Params) == 0 || string(calls[0].Params[0]) != `"`+wallet.PublicKey().String()+`"`) {
+				t.Errorf("balance queried with %s, want the platform wallet %s", calls[0].Params, wallet.PublicKey())
+			}
+		})
+	}
+}
+
+func TestParseCommitment(t *testing.T) {
+	for _, value := range []string{"processed", "confirmed", "finalized"} {
+		if got, err := parseCommitment(value); err != nil || string(got) != value {
+			t.Errorf("parseCommitment(%q) = %q, %v", value, got, err)
+		}
+	}
+	for _, value := range []string{"", "max", "Finalized"} {
+		if _, err := parseCommitment(value); err == nil {
+			t.Errorf("parseCommitment(%q) accepted an invalid commitment", value)
+		}
+	}
+}
+
+func TestTransferUsesConfiguredCommitment(t *testing.T) {
+	for _, commitment := range []rpc.CommitmentType{rpc.CommitmentConfirmed, rpc.CommitmentFinalized} {
+		t.Run(string(commitment), func(t *testing.T) {
+			stub := newStubRPC(t, map[string]interface{}{
+				"getLatestBlockhash": rpcValue(map[string]interface{}{"blockhash": solana.Hash{}.String(), "lastValidBlockHeight": 100}),
+				"sendTransaction":    solana.Signature{1}.String(),
+			})
+			worker := newStubbedWorker(t, nil, stub.URL, commitment)
+			recipient, _ := solana.NewRandomPrivateKey()
+
+			if _, err := worker.executeSolanaTransfer(recipient.PublicKey().String(), 1000); err != nil {
+				t.Fatal(err)
+			}
+
+			want := `"commitment":"` + string(commitment) + `"`
+			blockhash := stub.called("getLatestBlockhash")
+			if len(blockhash) != 1 || len(blockhash[0].Params) == 0 || !strings.Contains(string(blockhash[0].Params[0]), want) {
+				t.Errorf("getLatestBlockhash calls = %+v, want one with %s", blockhash, want)
+			}
+			want = `"preflightCommitment":"` + string(commitment) + `"`
+			send := stub.called("sendTransaction")
+			if len(send) != 1 || len(send[0].Params) < 2 || !strings.Contains(string(send[0].Params[1]), want) {
+				t.Errorf("sendTransaction calls = %+v, want one with %s", send, want)
+			}
+		})
+	}
+}
+
+func TestWaitForConfirmationMeetsCommitment(t *testing.T) {
+	tests := []struct {
+		commitment rpc.CommitmentType
+		status     rpc.ConfirmationStatusType
+		want       bool
+	}{
+		{rpc.CommitmentProcessed, rpc.ConfirmationStatusProcessed, true},
+		{rpc.CommitmentConfirmed, rpc.ConfirmationStatusProcessed, false},
+		{rpc.CommitmentConfirmed, rpc.ConfirmationStatusConfirmed, true},
+		{rpc.CommitmentFinalized, rpc.ConfirmationStatusConfirmed, false},
+		{rpc.CommitmentFinalized, rpc.ConfirmationStatusFinalized, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.commitment)+"/"+string(tt.status), func(t *testing.T) {
+			stub := newStubRPC(t, map[string]interface{}{
+				"getSignatureStatuses": rpcValue([]interface{}{
+					map[string]interface{}{"slot": 1, "confirmations": nil, "err": nil, "confirmationStatus": tt.status},
+				}),
+			})
+			worker := newStubbedWorker(t, nil, stub.URL, tt.commitment)
+
+			confirmed, _ := worker.waitForConfirmation(solana.Signature{1}.String(), worker.confirmTimeout)
+			if confirmed != tt.want {
+				t.Errorf("confirmed = %v, want %v", confirmed, tt.want)
+			}
+		})
+	}
+}
+
+// recordingAcknowledger records how a delivery was settled
+type recordingAcknowledger struct {
+	mu                    sync.Mutex
+	acks, nacks, requeued int
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acks++
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacks++
+	if requeue {
+		a.requeued++
+	}
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+// delivery is req as consumed from the payout queue
+func delivery(t *testing.T, req PayoutRequest, ack amqp.Acknowledger) amqp.Delivery {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amqp.Delivery{Acknowledger: ack, ContentType: "application/json", Body: body}
+}
+
+func TestDryRunSendsNoTransfer(t *testing.T) {
+	db := dbtest.Open(t)
+	stub := newStubRPC(t, nil)
+	worker := newStubbedWorker(t, db, stub.URL, rpc.CommitmentFinalized)
+	worker.dryRun = true
+	worker.feePercent = 10
+	validator := createValidator(t, db, 0)
+	recipient, _ := solana.NewRandomPrivateKey()
+
+	ack := &recordingAcknowledger{}
+	worker.processPayoutRequest(delivery(t, PayoutRequest{ValidatorID: validator.ID, Amount: 1000, PublicKey: recipient.PublicKey().String()}, ack))
+
+	stub.mu.Lock()
+	calls := len(stub.calls)
+	stub.mu.Unlock()
+	if calls != 0 {
+		t.Errorf("dry run made %d RPC calls, want none", calls)
+	}
+	if ack.acks != 1 || ack.nacks != 0 {
+		t.Errorf("acks = %d, nacks = %d, want the delivery acked", ack.acks, ack.nacks)
+	}
+
+	var records []models.PayoutTransaction
+	if err := db.Where("validator_id = ?", validator.ID).Find(&records).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d payout records, want 1", len(records))
+	}
+	if records[0].Status != "simulated" || records[0].TxSignature != "" || records[0].TransferAmount != 900 {
+		t.Errorf("record = %s, signature %q, %d lamports; want simulated, unsigned, 900 lamports",
+			records[0].Status, records[0].TxSignature, records[0].TransferAmount)
+	}
+	if got := pendingPayouts(t, db, validator.ID); got != 0 {
+		t.Errorf("pending payouts = %.0f, want 0: a simulated payout isn't refunded", got)
+	}
+}
+
+func TestTransferAmount(t *testing.T) {
+	tests := []struct {
+		name       string
+		credited   float64
+		feePercent float64
+		multiplier float64
+		want       uint64
+	}{
+		{"no fee", 1000, 0, 1, 1000},
+		{"2.5% fee", 1000, 2.5, 1, 975},
+		{"10% fee", 12345, 10, 1, 11110}, // 11110.5 rounds down
+		{"30% fee without float error", 1000, 30, 1, 700},
+		{"99% fee", 1000, 99, 1, 10},
+		{"multiplier", 1000, 0, 1.5, 1500},
+		{"multiplier then fee", 1000, 20, 2, 1600},
+		{"fractional credit", 999.99, 0, 1, 999},
+		{"rounds to nothing", 1, 50, 1, 0},
+		{"nothing credited", 0, 10, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transferAmount(tt.credited, tt.feePercent, tt.multiplier); got != tt.want {
+				t.Errorf("transferAmount(%v, %v%%, x%v) = %d, want %d", tt.credited, tt.feePercent, tt.multiplier, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayoutRoundingToZeroSettlesWithoutTransfer(t *testing.T) {
+	db := dbtest.Open(t)
+	stub := newStubRPC(t, nil)
+	worker := newStubbedWorker(t, db, stub.URL, rpc.CommitmentFinalized)
+	worker.feePercent = 50
+	validator := createValidator(t, db, 0)
+	recipient, _ := solana.NewRandomPrivateKey()
+
+	ack := &recordingAcknowledger{}
+	worker.processPayoutRequest(delivery(t, PayoutRequest{ValidatorID: validator.ID, Amount: 1, PublicKey: recipient.PublicKey().String()}, ack))
+
+	if calls := stub.called("sendTransaction"); len(calls) != 0 {
+		t.Errorf("sent %d transactions for a payout worth nothing after fees", len(calls))
+	}
+	var record models.PayoutTransaction
+	if err := db.Where("validator_id = ?", validator.ID).First(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != "completed" || record.Amount != 1 || record.TransferAmount != 0 {
+		t.Errorf("record = %s, %.0f credited, %d transferred; want completed, 1 and 0", record.Status, record.Amount, record.TransferAmount)
+	}
+	if ack.acks != 1 {
+		t.Errorf("acks = %d, want the delivery acked", ack.acks)
+	}
+}
+
+func TestKeyedMutex(t *testing.T) {
+	var k keyedMutex
+
+	unlockA := k.lock("a")
+	// Another key isn't held up
+	done := make(chan struct{})
+	go func() {
+		k.lock("b")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locking b waited for a")
+	}
+
+	// The same key is
+	acquired := make(chan func())
+	go func() { acquired <- k.lock("a") }()
+	select {
+	case <-acquired:
+		t.Fatal("a was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlockA()
+	(<-acquired)()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.locks) != 0 {
+		t.Errorf("%d locks left after every holder released them", len(k.locks))
+	}
+}
+
+// concurrencyRPC is an RPC stub confirming every transfer whose sendTransaction
+// takes delay, and reporting the most transfers it saw in flight at once
+type concurrencyRPC struct {
+	*stubRPC
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func newConcurrencyRPC(t *testing.T, delay time.Duration) *concurrencyRPC {
+	t.Helper()
+
+	c := &concurrencyRPC{stubRPC: newStubRPC(t, map[string]interface{}{
+		"getLatestBlockhash": rpcValue(map[string]interface{}{"blockhash": solana.Hash{}.String(), "lastValidBlockHeight": 100}),
+		"sendTransaction":    solana.Signature{1}.String(),
+		"getSignatureStatuses": rpcValue([]interface{}{
+			map[string]interface{}{"slot": 1, "confirmations": nil, "err": nil, "confirmationStatus": "finalized"},
+		}),
+	})}
+	c.before = func(method string) {
+		if method != "sendTransaction" {
+			return
+		}
+		c.mu.Lock()
+		c.inFlight++
+		if c.inFlight > c.peak {
+			c.peak = c.inFlight
+		}
+		c.mu.Unlock()
+
+		time.Sleep(delay)
+
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}
+	return c
+}
+
+func (c *concurrencyRPC) maxInFlight() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peak
+}
+
+// consume processes deliveries on workers goroutines, the way Start runs one
+// per consumer, and returns how long they took
+func consume(worker *PayoutWorker, deliveries []amqp.Delivery, workers int) time.Duration {
+	queue := make(chan amqp.Delivery, len(deliveries))
+	for _, d := range deliveries {
+		queue <- d
+	}
+	close(queue)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range queue {
+				worker.processPayoutRequest(d)
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+func TestConcurrentPayoutWorkers(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	const payouts = 4
+
+	run := func(t *testing.T, workers int, sameValidator bool) (time.Duration, int) {
+		db := dbtest.Open(t)
+		stub := newConcurrencyRPC(t, delay)
+		worker := newStubbedWorker(t, db, stub.URL, rpc.CommitmentFinalized)
+		worker.confirmTimeout = 5 * time.Second
+		recipient, _ := solana.NewRandomPrivateKey()
+
+		ack := &recordingAcknowledger{}
+		validator := createValidator(t, db, 0)
+		deliveries := make([]amqp.Delivery, payouts)
+		for i := range deliveries {
+			if !sameValidator {
+				validator = createValidator(t, db, 0)
+			}
+			deliveries[i] = delivery(t, PayoutRequest{ValidatorID: validator.ID, Amount: 1000, PublicKey: recipient.PublicKey().String()}, ack)
+		}
+
+		elapsed := consume(worker, deliveries, workers)
+
+		var completed int64
+		if err := db.Model(&models.PayoutTransaction{}).Where("status = ?", "completed").Count(&completed).Error; err != nil {
+			t.Fatal(err)
+		}
+		if completed != payouts || ack.acks != payouts {
+			t.Errorf("%d payouts completed and %d acked, want %d", completed, ack.acks, payouts)
+		}
+		return elapsed, stub.maxInFlight()
+	}
+
+	t.Run("workers pay different validators in parallel", func(t *testing.T) {
+		serial, serialPeak := run(t, 1, false)
+		parallel, parallelPeak := run(t, payouts, false)
+
+		if serialPeak != 1 {
+			t.Errorf("a single worker had %d transfers in flight", serialPeak)
+		}
+		if parallelPeak < 2 {
+			t.Errorf("%d workers never had more than %d transfer in flight", payouts, parallelPeak)
+		}
+		if parallel >= serial/2 {
+			t.Errorf("%d workers took %s, a single one %s", payouts, parallel, serial)
+		}
+	})
+
+	t.Run("the same validator is paid one at a time", func(t *testing.T) {
+		elapsed, peak := run(t, payouts, true)
+		if peak != 1 {
+			t.Errorf("%d transfers to the same validator were in flight at once", peak)
+		}
+		if elapsed < payouts*delay {
+			t.Errorf("%d payouts to one validator took %s, less than %s one after another", payouts, elapsed, payouts*delay)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// AlgorithmEd25519 signs with Solana ed25519 keys; public keys are base58 encoded
+const AlgorithmEd25519 = "ed25519"
+
+func init() {
+	Register(Ed25519Verifier{})
+}
+
+// Ed25519Signer signs with a validator's Solana keypair
+type Ed25519Signer struct {
+	key   solana.PrivateKey
+	keyID string
+}
+
+func NewEd25519Signer(key solana.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{
+		key:   key,
+		keyID: KeyID(AlgorithmEd25519, key.PublicKey().Bytes()),
+	}
+}
+
+func (s *Ed25519Signer) Algorithm() string { return AlgorithmEd25519 }
+func (s *Ed25519Signer) KeyID() string     { return s.keyID }
+func (s *Ed25519Signer) PublicKey() string { return s.key.PublicKey().String() }
+
+func (s *Ed25519Signer) Sign(message []byte) string {
+	signature := ed25519.Sign(ed25519.PrivateKey(s.key), message)
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// Ed25519Verifier verifies base64 signatures against base58 public keys
+type Ed25519Verifier struct{}
+
+func (Ed25519Verifier) Algorithm() string { return AlgorithmEd25519 }
+
+func (Ed25519Verifier) Verify(publicKey string, message []byte, signature string) error {
+	key, err := solana.PublicKeyFromBase58(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if len(sig) != ed25519.SignatureSize || !ed25519.Verify(ed25519.PublicKey(key.Bytes()), message, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Ed25519KeyID derives the key ID for a base58 public key, used for validators
+// that signed up before key IDs were sent
+func Ed25519KeyID(publicKey string) (string, error) {
+	key, err := solana.PublicKeyFromBase58(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid public key: %w", err)
+	}
+	return KeyID(AlgorithmEd25519, key.Bytes()), nil
+}
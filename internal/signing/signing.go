@@ -0,0 +1,73 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidSignature is returned when a signature does not match the message and key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Signer produces signatures for messages sent by a validator
+type Signer interface {
+	Algorithm() string
+	// KeyID identifies the signing key, e.g. "ed25519:1a2b3c4d5e6f7a8b"
+	KeyID() string
+	// PublicKey is the encoded public key registered with the hub
+	PublicKey() string
+	// Sign returns the base64-encoded signature of message
+	Sign(message []byte) string
+}
+
+// Verifier checks signatures for one algorithm
+type Verifier interface {
+	Algorithm() string
+	Verify(publicKey string, message []byte, signature string) error
+}
+
+var (
+	verifiersMu sync.RWMutex
+	verifiers   = map[string]Verifier{}
+)
+
+// Register makes a verifier available to Verify; it replaces any verifier for the same algorithm
+func Register(v Verifier) {
+	verifiersMu.Lock()
+	defer verifiersMu.Unlock()
+	verifiers[v.Algorithm()] = v
+}
+
+// Verify checks signature using the verifier for algorithm ("" means ed25519)
+func Verify(algorithm, publicKey string, message []byte, signature string) error {
+	if algorithm == "" {
+		algorithm = AlgorithmEd25519
+	}
+
+	verifiersMu.RLock()
+	v, ok := verifiers[algorithm]
+	verifiersMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+	return v.Verify(publicKey, message, signature)
+}
+
+// KeyID derives a stable identifier for a public key: the algorithm plus a short
+// fingerprint, so rotated keys are distinguishable in storage and logs
+func KeyID(algorithm string, publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	return algorithm + ":" + hex.EncodeToString(sum[:8])
+}
+
+// AlgorithmOf returns the algorithm part of a key ID ("" if it has none)
+func AlgorithmOf(keyID string) string {
+	algorithm, _, found := strings.Cut(keyID, ":")
+	if !found {
+		return ""
+	}
+	return algorithm
+}
@@ -0,0 +1,125 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func newSigner(t *testing.T) Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEd25519Signer(solana.PrivateKey(key))
+}
+
+func TestSignVerify(t *testing.T) {
+	signer, other := newSigner(t), newSigner(t)
+	message := []byte("Replying to cb-1 at 1700000000 nonce abc")
+	signature := signer.Sign(message)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		publicKey string
+		message   []byte
+		signature string
+		wantErr   error
+	}{
+		{"valid", signer.Algorithm(), signer.PublicKey(), message, signature, nil},
+		{"default algorithm", "", signer.PublicKey(), message, signature, nil},
+		{"algorithm from key ID", AlgorithmOf(signer.KeyID()), signer.PublicKey(), message, signature, nil},
+		{"tampered message", signer.Algorithm(), signer.PublicKey(), []byte("Replying to cb-2 at 1700000000 nonce abc"), signature, ErrInvalidSignature},
+		{"another key", signer.Algorithm(), other.PublicKey(), message, signature, ErrInvalidSignature},
+		{"another key's signature", signer.Algorithm(), signer.PublicKey(), message, other.Sign(message), ErrInvalidSignature},
+		{"truncated signature", signer.Algorithm(), signer.PublicKey(), message, signature[:20], ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.algorithm, tt.publicKey, tt.message, tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformedInput(t *testing.T) {
+	signer := newSigner(t)
+	message := []byte("message")
+
+	tests := []struct {
+		name      string
+		algorithm string
+		publicKey string
+		signature string
+		wantErr   string
+	}{
+		{"unknown algorithm", "rsa", signer.PublicKey(), signer.Sign(message), `unsupported signature algorithm "rsa"`},
+		{"bad public key", AlgorithmEd25519, "not-base58!", signer.Sign(message), "invalid public key"},
+		{"bad encoding", AlgorithmEd25519, signer.PublicKey(), "%%%", "invalid signature encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.algorithm, tt.publicKey, message, tt.signature)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// reversingVerifier accepts signatures that are the message reversed, standing
+// in for another scheme registered alongside ed25519
+type reversingVerifier struct{}
+
+func (reversingVerifier) Algorithm() string { return "reverse" }
+
+func (reversingVerifier) Verify(publicKey string, message []byte, signature string) error {
+	for i := range message {
+		if i >= len(signature) || signature[i] != message[len(message)-1-i] {
+			return ErrInvalidSignature
+		}
+	}
+	return nil
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	Register(reversingVerifier{})
+	t.Cleanup(func() {
+		verifiersMu.Lock()
+		delete(verifiers, "reverse")
+		verifiersMu.Unlock()
+	})
+
+	if err := Verify("reverse", "key", []byte("abc"), "cba"); err != nil {
+		t.Errorf("registered algorithm: %v", err)
+	}
+	if err := Verify("reverse", "key", []byte("abc"), "abc"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("registered algorithm, bad signature: %v", err)
+	}
+}
+
+func TestKeyID(t *testing.T) {
+	signer := newSigner(t)
+
+	if !strings.HasPrefix(signer.KeyID(), AlgorithmEd25519+":") || len(signer.KeyID()) != len(AlgorithmEd25519)+1+16 {
+		t.Errorf("key ID %q, want ed25519 and a 16 hex digit fingerprint", signer.KeyID())
+	}
+	derived, err := Ed25519KeyID(signer.PublicKey())
+	if err != nil || derived != signer.KeyID() {
+		t.Errorf("Ed25519KeyID = %q, %v; want the signer's %q", derived, err, signer.KeyID())
+	}
+	if other := newSigner(t); other.KeyID() == signer.KeyID() {
+		t.Error("two keys share a key ID")
+	}
+	if got := AlgorithmOf("legacy"); got != "" {
+		t.Errorf("AlgorithmOf without a prefix = %q, want empty", got)
+	}
+}
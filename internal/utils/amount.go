@@ -0,0 +1,15 @@
+package utils
+
+import "math"
+
+// Token describes the currency payouts are made in. Balances are stored in its
+// base units (lamports for SOL), of which one whole token has 10^Decimals.
+type Token struct {
+	Symbol   string
+	Decimals int
+}
+
+// ToWhole converts an amount in base units to whole tokens
+func (t Token) ToWhole(baseUnits float64) float64 {
+	return baseUnits / math.Pow10(t.Decimals)
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTokenToWhole(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     Token
+		baseUnits float64
+		want      float64
+	}{
+		{"SOL", Token{Symbol: "SOL", Decimals: 9}, 2_500_000_000, 2.5},
+		{"one lamport", Token{Symbol: "SOL", Decimals: 9}, 1, 1e-9},
+		{"six decimals", Token{Symbol: "USDC", Decimals: 6}, 2_500_000, 2.5},
+		{"SOL-sized amount with six decimals", Token{Symbol: "USDC", Decimals: 6}, 2_500_000_000, 2500},
+		{"no decimals", Token{Symbol: "PTS", Decimals: 0}, 42, 42},
+		{"zero", Token{Symbol: "SOL", Decimals: 9}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.token.ToWhole(tt.baseUnits)
+			if math.Abs(got-tt.want) > 1e-12*math.Max(1, tt.want) {
+				t.Errorf("ToWhole(%v) = %v, want %v", tt.baseUnits, got, tt.want)
+			}
+		})
+	}
+}
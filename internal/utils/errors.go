@@ -0,0 +1,31 @@
+package utils
+
+// Stable, machine-readable error codes returned in the "code" field of error responses
+const (
+	CodeInvalidRequest      = "INVALID_REQUEST"
+	CodeValidationFailed    = "VALIDATION_FAILED"
+	CodeRequestTooLarge     = "REQUEST_TOO_LARGE"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeInvalidToken        = "INVALID_TOKEN"
+	CodeForbidden           = "FORBIDDEN"
+	CodeInvalidCredentials  = "INVALID_CREDENTIALS"
+	CodeUserExists          = "USER_EXISTS"
+	CodeUserNotFound        = "USER_NOT_FOUND"
+	CodeInviteRequired      = "INVITE_REQUIRED"
+	CodeInviteInvalid       = "INVITE_INVALID"
+	CodeWebsiteNotFound     = "WEBSITE_NOT_FOUND"
+	CodeWebsiteLimitReached = "WEBSITE_LIMIT_REACHED"
+	CodeValidatorNotFound   = "VALIDATOR_NOT_FOUND"
+	CodeValidatorExists     = "VALIDATOR_EXISTS"
+	CodeValidatorBanned     = "VALIDATOR_BANNED"
+	CodeInvalidSignature    = "INVALID_SIGNATURE"
+	CodePayoutFailed        = "PAYOUT_FAILED"
+	CodeBalanceChanged      = "BALANCE_CHANGED"
+	CodePayoutsDisabled     = "PAYOUTS_DISABLED"
+	CodePayoutCooldown      = "PAYOUT_COOLDOWN"
+	CodeReadOnly            = "READ_ONLY"
+	CodeChannelNotFound     = "CHANNEL_NOT_FOUND"
+	CodeStatusPageNotFound  = "STATUS_PAGE_NOT_FOUND"
+	CodeSlugTaken           = "SLUG_TAKEN"
+	CodeInternal            = "INTERNAL_ERROR"
+)
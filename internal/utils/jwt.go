@@ -1,33 +1,140 @@
 package utils
 
 import (
+	"crypto/rsa"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateJWT(userID string, secret string) (string, error) {
+// Supported JWT signing algorithms
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+)
+
+// JWTConfig holds the keys for the configured signing algorithm. With RS256 the
+// private key is only needed by the service issuing tokens; verifiers need just
+// the public key.
+type JWTConfig struct {
+	Algorithm string
+	Issuer    string
+	Audience  string
+
+	secret     []byte
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+}
+
+// JWTOptions configures LoadJWTConfig
+type JWTOptions struct {
+	Algorithm      string
+	Secret         string // HS256
+	PrivateKeyPath string // RS256, PEM; needed to issue tokens
+	PublicKeyPath  string // RS256, PEM; derived from the private key when empty
+	Issuer         string
+	Audience       string
+}
+
+// LoadJWTConfig builds a JWTConfig for opts.Algorithm. Issuer and audience are
+// required: they are set on issued tokens and enforced on verification.
+func LoadJWTConfig(opts JWTOptions) (*JWTConfig, error) {
+	if opts.Issuer == "" || opts.Audience == "" {
+		return nil, errors.New("JWT issuer and audience are required")
+	}
+
+	switch opts.Algorithm {
+	case JWTAlgorithmHS256:
+		if opts.Secret == "" {
+			return nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		return &JWTConfig{
+			Algorithm: opts.Algorithm,
+			Issuer:    opts.Issuer,
+			Audience:  opts.Audience,
+			secret:    []byte(opts.Secret),
+		}, nil
+
+	case JWTAlgorithmRS256:
+		cfg := &JWTConfig{Algorithm: opts.Algorithm, Issuer: opts.Issuer, Audience: opts.Audience}
+		if opts.PrivateKeyPath != "" {
+			pem, err := os.ReadFile(opts.PrivateKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+			}
+			if cfg.privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem); err != nil {
+				return nil, fmt.Errorf("invalid JWT private key: %w", err)
+			}
+			cfg.publicKey = &cfg.privateKey.PublicKey
+		}
+		if opts.PublicKeyPath != "" {
+			pem, err := os.ReadFile(opts.PublicKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+			}
+			if cfg.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+				return nil, fmt.Errorf("invalid JWT public key: %w", err)
+			}
+		}
+		if cfg.publicKey == nil {
+			return nil, errors.New("RS256 requires JWT_PRIVATE_KEY_PATH or JWT_PUBLIC_KEY_PATH")
+		}
+		return cfg, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q (expected HS256 or RS256)", opts.Algorithm)
+	}
+}
+
+func (c *JWTConfig) signingKey() (interface{}, error) {
+	if c.Algorithm == JWTAlgorithmRS256 {
+		if c.privateKey == nil {
+			return nil, errors.New("no JWT private key configured for signing")
+		}
+		return c.privateKey, nil
+	}
+	return c.secret, nil
+}
+
+func (c *JWTConfig) verificationKey() interface{} {
+	if c.Algorithm == JWTAlgorithmRS256 {
+		return c.publicKey
+	}
+	return c.secret
+}
+
+func GenerateJWT(userID string, cfg *JWTConfig) (string, error) {
 	claims := jwt.MapClaims{
 		"sub": userID,
+		"iss": cfg.Issuer,
+		"aud": cfg.Audience,
 		"exp": time.Now().Add(24 * time.Hour).Unix(),
 		"iat": time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	key, err := cfg.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(cfg.Algorithm), claims)
+	return token.SignedString(key)
 }
 
-func VerifyJWT(tokenString string, secret string) (string, error) {
-	// Parse and validate token
+func VerifyJWT(tokenString string, cfg *JWTConfig) (string, error) {
+	// Parse and validate token; only the configured algorithm is accepted, so an
+	// HS256 token can't be forged with the RS256 public key as its secret.
+	// Tokens issued for another service (or without iss/aud) are rejected.
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+		return cfg.verificationKey(), nil
+	},
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+	)
 
 	if err != nil {
 		return "", fmt.Errorf("failed to parse token: %w", err)
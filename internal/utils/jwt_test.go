@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeRSAKeys writes a new RSA key pair as PEM files and returns their paths
+// along with the PEM encoded public key
+func writeRSAKeys(t *testing.T) (privatePath, publicPath string, publicPEM []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	dir := t.TempDir()
+	privatePath, publicPath = filepath.Join(dir, "jwt.key"), filepath.Join(dir, "jwt.pub")
+	private := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(privatePath, private, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath, publicPEM
+}
+
+func loadJWTConfig(t *testing.T, opts JWTOptions) *JWTConfig {
+	t.Helper()
+
+	if opts.Issuer == "" {
+		opts.Issuer, opts.Audience = "gopher-uptime", "gopher-uptime-api"
+	}
+	cfg, err := LoadJWTConfig(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestRS256SignVerify(t *testing.T) {
+	privatePath, publicPath, _ := writeRSAKeys(t)
+	issuer := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmRS256, PrivateKeyPath: privatePath})
+
+	token, err := GenerateJWT("user-1", issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := strings.Split(token, ".")[0]; !strings.Contains(decodeSegment(t, header), `"RS256"`) {
+		t.Errorf("token header %s, want RS256", decodeSegment(t, header))
+	}
+
+	// The issuer verifies with the public half of its key, other services with just the public key
+	verifier := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmRS256, PublicKeyPath: publicPath})
+	for name, cfg := range map[string]*JWTConfig{"issuer": issuer, "public key only": verifier} {
+		if sub, err := VerifyJWT(token, cfg); err != nil || sub != "user-1" {
+			t.Errorf("%s: VerifyJWT = %q, %v; want user-1", name, sub, err)
+		}
+	}
+
+	if _, err := GenerateJWT("user-1", verifier); err == nil {
+		t.Error("signed a token without a private key")
+	}
+}
+
+func TestRS256RejectsOtherKeys(t *testing.T) {
+	privatePath, _, _ := writeRSAKeys(t)
+	_, otherPublic, _ := writeRSAKeys(t)
+
+	token, err := GenerateJWT("user-1", loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmRS256, PrivateKeyPath: privatePath}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyJWT(token, loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmRS256, PublicKeyPath: otherPublic})); err == nil {
+		t.Error("verified a token signed with another key")
+	}
+}
+
+func TestVerifyRejectsMismatchedAlgorithm(t *testing.T) {
+	privatePath, publicPath, publicPEM := writeRSAKeys(t)
+	rs256 := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmRS256, PrivateKeyPath: privatePath, PublicKeyPath: publicPath})
+	hs256 := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmHS256, Secret: "shared-secret"})
+
+	hsToken, err := GenerateJWT("user-1", hs256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsToken, err := GenerateJWT("user-1", rs256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The classic confusion attack: HS256 keyed with the published RSA public key
+	forged := signWith(t, jwt.SigningMethodHS256, publicPEM, jwt.MapClaims{
+		"sub": "admin", "iss": rs256.Issuer, "aud": rs256.Audience, "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	// Unsigned tokens must never be accepted
+	unsigned := signWith(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{
+		"sub": "admin", "iss": hs256.Issuer, "aud": hs256.Audience, "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	tests := []struct {
+		name  string
+		token string
+		cfg   *JWTConfig
+	}{
+		{"HS256 token, RS256 config", hsToken, rs256},
+		{"RS256 token, HS256 config", rsToken, hs256},
+		{"HS256 keyed with the RSA public key", forged, rs256},
+		{"alg none", unsigned, hs256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sub, err := VerifyJWT(tt.token, tt.cfg); err == nil {
+				t.Errorf("accepted the token for %q", sub)
+			}
+		})
+	}
+}
+
+func TestLoadJWTConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    JWTOptions
+		wantErr string
+	}{
+		{"unknown algorithm", JWTOptions{Algorithm: "ES256", Issuer: "i", Audience: "a"}, `unsupported JWT algorithm "ES256"`},
+		{"HS256 without secret", JWTOptions{Algorithm: JWTAlgorithmHS256, Issuer: "i", Audience: "a"}, "JWT_SECRET is required"},
+		{"RS256 without keys", JWTOptions{Algorithm: JWTAlgorithmRS256, Issuer: "i", Audience: "a"}, "RS256 requires"},
+		{"missing key file", JWTOptions{Algorithm: JWTAlgorithmRS256, PublicKeyPath: "/nonexistent.pem", Issuer: "i", Audience: "a"}, "failed to read JWT public key"},
+		{"missing issuer", JWTOptions{Algorithm: JWTAlgorithmHS256, Secret: "s", Audience: "a"}, "issuer and audience are required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadJWTConfig(tt.opts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadJWTConfig = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// signWith signs claims with method and key directly, bypassing JWTConfig
+func signWith(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func decodeSegment(t *testing.T, segment string) string {
+	t.Helper()
+
+	data, err := jwt.NewParser().DecodeSegment(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestVerifyJWTClaims(t *testing.T) {
+	cfg := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmHS256, Secret: "shared-secret"})
+	otherService := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmHS256, Secret: "shared-secret", Issuer: "gopher-uptime", Audience: "billing"})
+	otherIssuer := loadJWTConfig(t, JWTOptions{Algorithm: JWTAlgorithmHS256, Secret: "shared-secret", Issuer: "someone-else", Audience: cfg.Audience})
+
+	valid, err := GenerateJWT("user-1", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongAudience, err := GenerateJWT("user-1", otherService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongIssuer, err := GenerateJWT("user-1", otherIssuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(overrides jwt.MapClaims) string {
+		c := jwt.MapClaims{"sub": "user-1", "iss": cfg.Issuer, "aud": cfg.Audience, "exp": time.Now().Add(time.Hour).Unix()}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return signWith(t, jwt.SigningMethodHS256, []byte("shared-secret"), c)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantSub string
+	}{
+		{"valid", valid, "user-1"},
+		{"audience list including ours", claims(jwt.MapClaims{"aud": []string{"billing", cfg.Audience}}), "user-1"},
+		{"wrong audience", wrongAudience, ""},
+		{"wrong issuer", wrongIssuer, ""},
+		{"no audience", claims(jwt.MapClaims{"aud": nil}), ""},
+		{"no issuer", claims(jwt.MapClaims{"iss": nil}), ""},
+		{"expired", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), ""},
+		{"no subject", claims(jwt.MapClaims{"sub": nil}), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := VerifyJWT(tt.token, cfg)
+			if tt.wantSub == "" {
+				if err == nil {
+					t.Errorf("accepted the token for %q", sub)
+				}
+				return
+			}
+			if err != nil || sub != tt.wantSub {
+				t.Errorf("VerifyJWT = %q, %v; want %q", sub, err, tt.wantSub)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Pagination holds the 1-based page and bounded page size from ?page=&page_size=
+type Pagination struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// ParsePagination reads page and page_size query parameters, rejecting values
+// outside 1..MaxPageSize
+func ParsePagination(c *gin.Context) (Pagination, error) {
+	p := Pagination{Page: 1, PageSize: DefaultPageSize}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("page must be a positive integer")
+		}
+		p.Page = page
+	}
+
+	if raw := c.Query("page_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 || size > MaxPageSize {
+			return p, fmt.Errorf("page_size must be between 1 and %d", MaxPageSize)
+		}
+		p.PageSize = size
+	}
+
+	return p, nil
+}
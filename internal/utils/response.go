@@ -6,6 +6,8 @@ type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 func SuccessResponse(c *gin.Context, statusCode int, data interface{}) {
@@ -15,9 +17,12 @@ func SuccessResponse(c *gin.Context, statusCode int, data interface{}) {
 	})
 }
 
-func ErrorResponse(c *gin.Context, statusCode int, message string) {
+// ErrorResponse writes a failure envelope with a stable code (see errors.go)
+// alongside the human-readable message
+func ErrorResponse(c *gin.Context, statusCode int, code string, message string) {
 	c.JSON(statusCode, Response{
 		Success: false,
 		Error:   message,
+		Code:    code,
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single failed validation rule on a request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Report fields by their JSON names rather than Go struct names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// BindingErrorResponse writes a 400 for a failed ShouldBind* call, listing each
+// failed field when err is a validation error
+func BindingErrorResponse(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		ErrorResponse(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	c.JSON(http.StatusBadRequest, Response{
+		Success: false,
+		Error:   "Validation failed",
+		Code:    CodeValidationFailed,
+		Details: fields,
+	})
+}
+
+// fieldPath strips the root struct name from the namespace (e.g. "assertions[0].type")
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i != -1 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	field := fieldPath(fe)
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "url":
+		return field + " must be a valid URL"
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", field, fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", field, fe.Param(), unit)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("%s failed the '%s' rule", field, fe.Tag())
+	}
+}
+
+// ValidationMessage describes err on one line, listing each failed field of a
+// validation error, for places that report errors without a response of their own
+func ValidationMessage(err error) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err.Error()
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		messages = append(messages, fieldMessage(fe))
+	}
+	return strings.Join(messages, "; ")
+}